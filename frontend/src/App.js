@@ -1,16 +1,29 @@
 import React from 'react';
-import { BrowserRouter as Router, Routes, Route, Navigate } from 'react-router-dom';
+import { BrowserRouter as Router, Routes, Route, Navigate, useLocation } from 'react-router-dom';
 import { AuthProvider, useAuth } from './contexts/AuthContext';
 import Login from './components/Login';
 import Register from './components/Register';
+import ForgotPassword from './components/ForgotPassword';
+import ResetPassword from './components/ResetPassword';
+import VerifyEmail from './components/VerifyEmail';
 import Dashboard from './components/Dashboard';
+import Profile from './components/Profile';
 import StDomDetail from './components/StDomDetail';
 import RoomDetail from './components/RoomDetail';
 import AdvancedRoomSearch from './components/AdvancedRoomSearch';
 import OpenDataDashboard from './components/OpenDataDashboard';
+import OAuthAuthorize from './components/OAuthAuthorize';
 import ProtectedRoute from './components/ProtectedRoute';
 import './App.css';
 
+// prijavljenog korisnika vraca na stranicu sa koje je poslat na prijavu, inace na dashboard
+function RedirectAfterLogin() {
+  const location = useLocation();
+  const from = location.state?.from;
+
+  return <Navigate to={from ? from.pathname + (from.search || '') : '/dashboard'} replace />;
+}
+
 function AppContent() {
   const { isAuthenticated, loading } = useAuth();
 
@@ -29,12 +42,28 @@ function AppContent() {
         <Routes>
           <Route 
             path="/login" 
-            element={isAuthenticated ? <Navigate to="/dashboard" replace /> : <Login />} 
+            element={isAuthenticated ? <RedirectAfterLogin /> : <Login />} 
           />
           <Route 
             path="/register" 
             element={isAuthenticated ? <Navigate to="/dashboard" replace /> : <Register />} 
           />
+          <Route 
+            path="/forgot-password" 
+            element={<ForgotPassword mode="reset" />} 
+          />
+          <Route 
+            path="/resend-verification" 
+            element={<ForgotPassword mode="verify" />} 
+          />
+          <Route 
+            path="/reset-password" 
+            element={<ResetPassword />} 
+          />
+          <Route 
+            path="/verify-email" 
+            element={<VerifyEmail />} 
+          />
           <Route 
             path="/dashboard" 
             element={
@@ -43,6 +72,22 @@ function AppContent() {
               </ProtectedRoute>
             } 
           />
+          <Route 
+            path="/profile" 
+            element={
+              <ProtectedRoute>
+                <Profile />
+              </ProtectedRoute>
+            } 
+          />
+          <Route 
+            path="/oauth/authorize" 
+            element={
+              <ProtectedRoute>
+                <OAuthAuthorize />
+              </ProtectedRoute>
+            } 
+          />
           <Route 
             path="/st-dom/:id" 
             element={
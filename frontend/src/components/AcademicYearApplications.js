@@ -49,16 +49,6 @@ const AcademicYearApplications = () => {
     setHasSearched(false);
   };
 
-  const formatDate = (dateString) => {
-    return new Date(dateString).toLocaleDateString('hr-HR', {
-      year: 'numeric',
-      month: 'long',
-      day: 'numeric',
-      hour: '2-digit',
-      minute: '2-digit'
-    });
-  };
-
 
   return (
     <div className="academic-year-container">
@@ -124,41 +114,25 @@ const AcademicYearApplications = () => {
             {applications.length > 0 ? (
               <div className="applications-grid">
                 {applications.map((app, index) => (
-                  <div key={app.id || index} className="application-card">
+                  <div key={app.pseudonim || index} className="application-card">
                     <div className="application-header">
                       <h3>Aplikacija #{index + 1}</h3>
-                      <span className="application-id">ID: {app.id}</span>
+                      <span className="application-id">{app.pseudonim}</span>
                     </div>
                     
                     <div className="application-details">
                       <div className="detail-row">
-                        <span className="label">Korisnik ID:</span>
-                        <span className="value">{app.user_id}</span>
-                      </div>
-                      <div className="detail-row">
-                        <span className="label">Broj indeksa:</span>
-                        <span className="value">{app.broj_indexa}</span>
-                      </div>
-                      <div className="detail-row">
-                        <span className="label">Prosek:</span>
-                        <span className="value prosek">{app.prosek}</span>
+                        <span className="label">Dom:</span>
+                        <span className="value">{app.ime_doma}</span>
                       </div>
                       <div className="detail-row">
-                        <span className="label">Soba ID:</span>
-                        <span className="value">{app.soba_id}</span>
+                        <span className="label">Raspon proseka:</span>
+                        <span className="value prosek">{app.raspon_proseka}</span>
                       </div>
                       <div className="detail-row">
                         <span className="label">Akademska godina:</span>
                         <span className="value">{app.academic_year}</span>
                       </div>
-                      <div className="detail-row">
-                        <span className="label">Kreirana:</span>
-                        <span className="value">{app.created_at ? formatDate(app.created_at) : 'N/A'}</span>
-                      </div>
-                      <div className="detail-row">
-                        <span className="label">Ažurirana:</span>
-                        <span className="value">{app.updated_at ? formatDate(app.updated_at) : 'N/A'}</span>
-                      </div>
                     </div>
                   </div>
                 ))}
@@ -7,7 +7,9 @@ const AddStDomModal = ({ isOpen, onClose, onSuccess }) => {
     ime: '',
     address: '',
     telephone_number: '',
-    email: ''
+    email: '',
+    latitude: '',
+    longitude: ''
   });
   const [loading, setLoading] = useState(false);
   const [error, setError] = useState('');
@@ -26,7 +28,13 @@ const AddStDomModal = ({ isOpen, onClose, onSuccess }) => {
     setError('');
 
     try {
-      await stDomService.createStDom(formData);
+      // Coordinates are optional; without them the service looks the address up in the gazetteer
+      const { latitude, longitude, ...data } = formData;
+      if (latitude !== '' || longitude !== '') {
+        data.latitude = Number(latitude);
+        data.longitude = Number(longitude);
+      }
+      await stDomService.createStDom(data);
       onSuccess();
       onClose();
       // Reset form
@@ -34,7 +42,9 @@ const AddStDomModal = ({ isOpen, onClose, onSuccess }) => {
         ime: '',
         address: '',
         telephone_number: '',
-        email: ''
+        email: '',
+        latitude: '',
+        longitude: ''
       });
     } catch (err) {
       setError(err.message || 'Greška pri kreiranju studentskog doma');
@@ -52,7 +62,9 @@ const AddStDomModal = ({ isOpen, onClose, onSuccess }) => {
         ime: '',
         address: '',
         telephone_number: '',
-        email: ''
+        email: '',
+        latitude: '',
+        longitude: ''
       });
     }
   };
@@ -136,6 +148,38 @@ const AddStDomModal = ({ isOpen, onClose, onSuccess }) => {
             />
           </div>
 
+          <div className="form-group">
+            <label htmlFor="latitude">Geografska širina</label>
+            <input
+              type="number"
+              id="latitude"
+              name="latitude"
+              value={formData.latitude}
+              onChange={handleInputChange}
+              disabled={loading}
+              min="-90"
+              max="90"
+              step="any"
+              placeholder="npr. 45.2461"
+            />
+          </div>
+
+          <div className="form-group">
+            <label htmlFor="longitude">Geografska dužina</label>
+            <input
+              type="number"
+              id="longitude"
+              name="longitude"
+              value={formData.longitude}
+              onChange={handleInputChange}
+              disabled={loading}
+              min="-180"
+              max="180"
+              step="any"
+              placeholder="npr. 19.8516"
+            />
+          </div>
+
           <div className="modal-buttons">
             <button 
               type="button" 
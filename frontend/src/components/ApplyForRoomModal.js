@@ -1,15 +1,17 @@
 import React, { useState, useEffect } from 'react';
 import { stDomService } from '../services/stDomService';
+import { useAuth } from '../contexts/AuthContext';
 import './ApplyForRoomModal.css';
 
 const ApplyForRoomModal = ({ isOpen, onClose, onSuccess }) => {
+  const { user } = useAuth();
+  const student = user?.student;
   const [step, setStep] = useState(1); // 1: Select Dorm, 2: Select Room, 3: Fill Details
   const [stDoms, setStDoms] = useState([]);
   const [rooms, setRooms] = useState([]);
   const [selectedStDom, setSelectedStDom] = useState(null);
   const [selectedRoom, setSelectedRoom] = useState(null);
   const [formData, setFormData] = useState({
-    broj_indexa: '',
     prosek: ''
   });
   const [loading, setLoading] = useState(false);
@@ -25,7 +27,7 @@ const ApplyForRoomModal = ({ isOpen, onClose, onSuccess }) => {
       setSelectedStDom(null);
       setSelectedRoom(null);
       setRooms([]);
-      setFormData({ broj_indexa: '', prosek: '' });
+      setFormData({ prosek: '' });
       setError('');
     }
   }, [isOpen]);
@@ -81,7 +83,6 @@ const ApplyForRoomModal = ({ isOpen, onClose, onSuccess }) => {
 
     try {
       const aplikacijaData = {
-        broj_indexa: formData.broj_indexa,
         prosek: parseInt(formData.prosek),
         soba_id: selectedRoom.id
       };
@@ -98,7 +99,7 @@ const ApplyForRoomModal = ({ isOpen, onClose, onSuccess }) => {
       setSelectedStDom(null);
       setSelectedRoom(null);
       setRooms([]);
-      setFormData({ broj_indexa: '', prosek: '' });
+      setFormData({ prosek: '' });
     } catch (err) {
       console.error('=== APPLICATION ERROR ===');
       console.error('Error:', err);
@@ -117,7 +118,7 @@ const ApplyForRoomModal = ({ isOpen, onClose, onSuccess }) => {
     } else if (step === 3) {
       setStep(2);
       setSelectedRoom(null);
-      setFormData({ broj_indexa: '', prosek: '' });
+      setFormData({ prosek: '' });
     }
   };
 
@@ -128,7 +129,7 @@ const ApplyForRoomModal = ({ isOpen, onClose, onSuccess }) => {
       setSelectedStDom(null);
       setSelectedRoom(null);
       setRooms([]);
-      setFormData({ broj_indexa: '', prosek: '' });
+      setFormData({ prosek: '' });
       setError('');
     }
   };
@@ -256,17 +257,17 @@ const ApplyForRoomModal = ({ isOpen, onClose, onSuccess }) => {
             </div>
             <form onSubmit={handleSubmit} className="application-form">
               <div className="form-group">
-                <label htmlFor="broj_indexa">Broj indeksa *</label>
+                <label htmlFor="broj_indexa">Broj indeksa</label>
                 <input
                   type="text"
                   id="broj_indexa"
-                  name="broj_indexa"
-                  value={formData.broj_indexa}
-                  onChange={handleInputChange}
-                  required
-                  disabled={loading}
-                  placeholder="Unesite broj indeksa (npr. RI12/2021)"
+                  value={student?.index_number || ''}
+                  disabled
+                  placeholder="Unesite broj indeksa na stranici profila"
                 />
+                {!student?.verified && (
+                  <small>Podaci o studiranju moraju biti potvrđeni od strane administratora prije apliciranja.</small>
+                )}
               </div>
 
               <div className="form-group">
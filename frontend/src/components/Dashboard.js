@@ -235,6 +235,12 @@ const Dashboard = () => {
               <span className="value">{user?.created_at ? formatDate(user.created_at) : 'N/A'}</span>
             </div>
           </div>
+          <button 
+            onClick={() => navigate('/profile')}
+            className="edit-profile-button"
+          >
+            Uredi profil
+          </button>
         </div>
 
         <div className="actions-card">
@@ -321,6 +327,8 @@ const Dashboard = () => {
             <h3>Potvrdite brisanje računa</h3>
             <p>
               Jeste li sigurni da želite obrisati svoj račun? Ova akcija se ne može poništiti.
+              Vaše aplikacije ostaju sačuvane bez ličnih podataka, a broj indeksa uz uplate se čuva
+              do isteka zakonskog roka.
             </p>
             <div className="modal-buttons">
               <button 
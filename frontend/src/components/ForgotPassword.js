@@ -0,0 +1,74 @@
+import React, { useState } from 'react';
+import { Link } from 'react-router-dom';
+import { authService } from '../services/authService';
+import './Auth.css';
+
+// forma koja trazi samo email - za zaboravljenu lozinku ili ponovno slanje linka za potvrdu
+// mode "reset" salje link za promenu lozinke, a mode "verify" ponovo salje link za potvrdu email-a
+const ForgotPassword = ({ mode = 'reset' }) => {
+  const [email, setEmail] = useState('');
+  const [error, setError] = useState('');
+  const [success, setSuccess] = useState('');
+  const [loading, setLoading] = useState(false);
+
+  const isVerify = mode === 'verify';
+
+  // rukuje slanjem forme
+  const handleSubmit = async (e) => {
+    e.preventDefault();
+    setError('');
+    setSuccess('');
+    setLoading(true);
+
+    try {
+      if (isVerify) {
+        await authService.resendVerification(email);
+        setSuccess('Ako nalog postoji i nije potvrđen, poslali smo novi link za potvrdu.');
+      } else {
+        await authService.forgotPassword(email);
+        setSuccess('Ako nalog postoji, poslali smo link za promjenu lozinke.');
+      }
+    } catch (err) {
+      setError(err.response?.data?.error || 'Slanje nije uspjelo');
+    }
+
+    setLoading(false);
+  };
+
+  return (
+    <div className="auth-container">
+      <div className="auth-card">
+        <h2>{isVerify ? 'Potvrda email adrese' : 'Zaboravljena lozinka'}</h2>
+        <form onSubmit={handleSubmit}>
+          <div className="form-group">
+            <label htmlFor="email">Email:</label>
+            <input
+              type="email"
+              id="email"
+              name="email"
+              value={email}
+              onChange={(e) => setEmail(e.target.value)}
+              required
+              placeholder="Unesite vaš email"
+            />
+          </div>
+
+          {error && <div className="error-message">{error}</div>}
+          {success && <div className="success-message">{success}</div>}
+
+          <button type="submit" disabled={loading} className="auth-button">
+            {loading ? 'Slanje...' : 'Pošalji link'}
+          </button>
+        </form>
+
+        <div className="auth-links">
+          <p>
+            <Link to="/login">Nazad na prijavu</Link>
+          </p>
+        </div>
+      </div>
+    </div>
+  );
+};
+
+export default ForgotPassword;
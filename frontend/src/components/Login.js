@@ -1,6 +1,7 @@
-import React, { useState } from 'react';
+import React, { useState, useEffect } from 'react';
 import { useAuth } from '../contexts/AuthContext';
-import { Link, useNavigate } from 'react-router-dom';
+import { authService } from '../services/authService';
+import { Link, useNavigate, useLocation } from 'react-router-dom';
 import './Auth.css';
 
 // komponenta za prijavu korisnika - forma sa email i lozinkom
@@ -11,9 +12,52 @@ const Login = () => {
   });
   const [error, setError] = useState('');
   const [loading, setLoading] = useState(false);
+  // drugi korak prijave - izazov, podaci za podesavanje TOTP-a i kodovi za oporavak
+  const [mfa, setMfa] = useState(null);
+  const [code, setCode] = useState('');
+  const [totpSetup, setTotpSetup] = useState(null);
+  const [recovery, setRecovery] = useState(null);
+  // prijava univerzitetskim nalogom - dostupnost provajdera i stranica na koju se korisnik vraca
+  const [federation, setFederation] = useState(null);
+  const [returnTo, setReturnTo] = useState(null);
   
-  const { login } = useAuth();
+  const { login, completeFederatedLogin, verifyMfa } = useAuth();
   const navigate = useNavigate();
+  const location = useLocation();
+  // stranica sa koje je korisnik preusmeren na prijavu (npr. odobravanje pristupa drugoj aplikaciji)
+  const from = location.state?.from;
+  const redirectTo = returnTo || (from ? from.pathname + (from.search || '') : '/dashboard');
+
+  // proverava da li je prijava univerzitetskim nalogom dostupna
+  // i zavrsava je ako se korisnik vratio sa provajdera (tiket ili greska u URL-u)
+  useEffect(() => {
+    const params = new URLSearchParams(location.search);
+    const ticket = params.get('ticket');
+    const federationError = params.get('federation_error');
+
+    const fetchFederation = async () => {
+      try {
+        setFederation(await authService.getFederationStatus());
+      } catch (err) {
+        console.error('Failed to load federated login status:', err);
+      }
+    };
+
+    const finishFederatedLogin = async () => {
+      setLoading(true);
+      const result = await completeFederatedLogin(ticket);
+      setReturnTo(result.returnTo || null);
+      await handleLoginResult(result, result.returnTo);
+    };
+
+    fetchFederation();
+    if (ticket) {
+      finishFederatedLogin();
+    } else if (federationError) {
+      setError(federationError);
+    }
+    // eslint-disable-next-line react-hooks/exhaustive-deps
+  }, []);
 
   // rukuje promenama u input poljima
   const handleChange = (e) => {
@@ -30,9 +74,22 @@ const Login = () => {
     setLoading(true);
 
     const result = await login(formData.email, formData.password);
-    
+    await handleLoginResult(result);
+  };
+
+  // obradjuje rezultat prijave - preusmerava, prelazi na drugi faktor ili prikazuje gresku
+  const handleLoginResult = async (result, target) => {
     if (result.success) {
-      navigate('/dashboard');
+      navigate(target || redirectTo, { replace: true });
+    } else if (result.mfaRequired) {
+      setMfa(result);
+      if (result.enrollmentRequired) {
+        try {
+          setTotpSetup(await authService.enrollMfa(result.mfaToken));
+        } catch (err) {
+          setError(err.response?.data?.error || 'Podešavanje dvofaktorske autentifikacije nije uspjelo');
+        }
+      }
     } else {
       setError(result.error);
     }
@@ -40,6 +97,118 @@ const Login = () => {
     setLoading(false);
   };
 
+  // salje kod iz authenticator aplikacije ili kod za oporavak
+  const handleMfaSubmit = async (e) => {
+    e.preventDefault();
+    setError('');
+    setLoading(true);
+
+    const result = await verifyMfa(mfa.mfaToken, code);
+
+    if (result.success && result.recoveryCodes) {
+      setRecovery(result);
+    } else if (result.success) {
+      navigate(redirectTo, { replace: true });
+    } else {
+      setError(result.error);
+    }
+
+    setLoading(false);
+  };
+
+  // kodovi za oporavak se prikazuju samo jednom - prijava se zavrsava kada ih korisnik sacuva
+  if (recovery) {
+    return (
+      <div className="auth-container">
+        <div className="auth-card">
+          <h2>Kodovi za oporavak</h2>
+          <p>
+            Dvofaktorska autentifikacija je uključena. Sačuvajte ove kodove na sigurnom mjestu -
+            svaki kod možete iskoristiti jednom ako izgubite pristup aplikaciji za autentifikaciju.
+          </p>
+          <pre>{recovery.recoveryCodes.join('\n')}</pre>
+          <button
+            type="button"
+            className="auth-button"
+            onClick={() => {
+              recovery.finish();
+              navigate(redirectTo, { replace: true });
+            }}
+          >
+            Sačuvao sam kodove
+          </button>
+        </div>
+      </div>
+    );
+  }
+
+  if (mfa) {
+    return (
+      <div className="auth-container">
+        <div className="auth-card">
+          <h2>Dvofaktorska autentifikacija</h2>
+          {mfa.enrollmentRequired ? (
+            <div>
+              <p>
+                Administratorski nalozi moraju koristiti dvofaktorsku autentifikaciju.
+                Dodajte nalog u aplikaciju za autentifikaciju (npr. Google Authenticator) i unesite prvi kod.
+              </p>
+              {totpSetup && (
+                <div className="form-group">
+                  <label>Tajni ključ:</label>
+                  <code>{totpSetup.secret}</code>
+                  <p>
+                    <a href={totpSetup.otpauth_url}>Otvori u aplikaciji za autentifikaciju</a>
+                  </p>
+                </div>
+              )}
+            </div>
+          ) : (
+            <p>Unesite kod iz aplikacije za autentifikaciju ili jedan od kodova za oporavak.</p>
+          )}
+          <form onSubmit={handleMfaSubmit}>
+            <div className="form-group">
+              <label htmlFor="code">Kod:</label>
+              <input
+                type="text"
+                id="code"
+                name="code"
+                value={code}
+                onChange={(e) => setCode(e.target.value)}
+                required
+                autoComplete="one-time-code"
+                placeholder="123456"
+              />
+            </div>
+
+            {error && <div className="error-message">{error}</div>}
+
+            <button type="submit" disabled={loading} className="auth-button">
+              {loading ? 'Provjera...' : 'Potvrdi'}
+            </button>
+          </form>
+
+          <div className="auth-links">
+            <p>
+              <button
+                type="button"
+                className="link-button"
+                onClick={() => {
+                  setMfa(null);
+                  setTotpSetup(null);
+                  setCode('');
+                  setError('');
+                }}
+              >
+                Nazad na prijavu
+              </button>
+            </p>
+          </div>
+        </div>
+      </div>
+    );
+  }
+
   return (
     <div className="auth-container">
       <div className="auth-card">
@@ -78,10 +247,27 @@ const Login = () => {
           </button>
         </form>
 
+        {federation?.enabled && (
+          <button
+            type="button"
+            disabled={loading}
+            className="auth-button federated-login-button"
+            onClick={() => window.location.assign(authService.federatedLoginUrl(redirectTo))}
+          >
+            Prijava: {federation.name}
+          </button>
+        )}
+
         <div className="auth-links">
           <p>
             Nemate račun? <Link to="/register">Registrirajte se</Link>
           </p>
+          <p>
+            <Link to="/forgot-password">Zaboravili ste lozinku?</Link>
+          </p>
+          <p>
+            Niste dobili email za potvrdu? <Link to="/resend-verification">Pošalji ponovo</Link>
+          </p>
         </div>
 
         <div className="open-data-section">
@@ -0,0 +1,99 @@
+import React, { useState, useEffect } from 'react';
+import { useLocation } from 'react-router-dom';
+import { useAuth } from '../contexts/AuthContext';
+import { authService } from '../services/authService';
+import './Auth.css';
+
+// opisi dozvola koje aplikacija moze traziti
+const SCOPE_DESCRIPTIONS = {
+  openid: 'Potvrda vašeg identiteta',
+  profile: 'Ime, prezime i korisničko ime',
+  email: 'Email adresa',
+};
+
+// stranica za odobravanje pristupa drugoj univerzitetskoj aplikaciji (OpenID Connect)
+// korisnik se nakon odluke vraca u aplikaciju sa kodom ili greskom
+const OAuthAuthorize = () => {
+  const { token, user } = useAuth();
+  const location = useLocation();
+
+  const [details, setDetails] = useState(null);
+  const [error, setError] = useState('');
+  const [submitting, setSubmitting] = useState(false);
+
+  // ucitava naziv aplikacije i trazene dozvole
+  useEffect(() => {
+    const fetchDetails = async () => {
+      try {
+        const data = await authService.getAuthorizeDetails(token, location.search);
+        if (data.redirect_to) {
+          window.location.assign(data.redirect_to);
+          return;
+        }
+        setDetails(data);
+      } catch (err) {
+        setError(err.response?.data?.error || 'Zahtjev za prijavu nije ispravan');
+      }
+    };
+
+    fetchDetails();
+  }, [token, location.search]);
+
+  // salje odluku korisnika i vraca ga u aplikaciju
+  const handleDecision = async (approve) => {
+    setSubmitting(true);
+    setError('');
+
+    try {
+      const params = Object.fromEntries(new URLSearchParams(location.search));
+      const data = await authService.authorizeClient(token, params, approve);
+      window.location.assign(data.redirect_to);
+    } catch (err) {
+      setError(err.response?.data?.error || 'Odobravanje pristupa nije uspjelo');
+      setSubmitting(false);
+    }
+  };
+
+  return (
+    <div className="auth-container">
+      <div className="auth-card">
+        {error && <div className="error-message">{error}</div>}
+
+        {!details && !error && <p>Učitavanje...</p>}
+
+        {details && (
+          <>
+            <h2>{details.client_name}</h2>
+            <p>
+              Aplikacija traži pristup vašem nalogu <strong>{user?.username}</strong>. Dijeliće se sljedeći podaci:
+            </p>
+            <ul>
+              {details.scopes.map((scope) => (
+                <li key={scope}>{SCOPE_DESCRIPTIONS[scope] || scope}</li>
+              ))}
+            </ul>
+
+            <button
+              type="button"
+              className="auth-button"
+              disabled={submitting}
+              onClick={() => handleDecision(true)}
+            >
+              Dozvoli
+            </button>
+            <button
+              type="button"
+              className="link-button"
+              disabled={submitting}
+              onClick={() => handleDecision(false)}
+            >
+              Odbij
+            </button>
+          </>
+        )}
+      </div>
+    </div>
+  );
+};
+
+export default OAuthAuthorize;
@@ -36,6 +36,11 @@ const OpenDataDashboard = () => {
   // Occupancy Heatmap
   const [heatmapData, setHeatmapData] = useState(null);
 
+  // Occupancy History
+  const [historyGranularity, setHistoryGranularity] = useState('week');
+  const [historyDormId, setHistoryDormId] = useState('');
+  const [historyData, setHistoryData] = useState(null);
+
   // Academic Year Applications
   const [academicYear, setAcademicYear] = useState('');
   const [yearApplications, setYearApplications] = useState([]);
@@ -68,6 +73,23 @@ const OpenDataDashboard = () => {
     loadTabData();
   }, [activeTab]);
 
+  // Load occupancy history when the heatmap tab is open or the filters change
+  useEffect(() => {
+    if (activeTab !== 'heatmap') return;
+
+    const from = new Date();
+    from.setMonth(from.getMonth() - 6);
+
+    openDataService.getOccupancyHistory({
+      from: from.toISOString().slice(0, 10),
+      granularity: historyGranularity,
+      level: historyDormId ? 'dorm' : 'total',
+      dormId: historyDormId,
+    })
+      .then(data => setHistoryData(data.history))
+      .catch(err => console.error('Error loading occupancy history:', err));
+  }, [activeTab, historyGranularity, historyDormId]);
+
   const loadTabData = async () => {
     setLoading(true);
     setError('');
@@ -546,6 +568,65 @@ const OpenDataDashboard = () => {
             ))}
         </div>
 
+        <div className="occupancy-history">
+          <h3>Istorija Popunjenosti (poslednjih 6 meseci)</h3>
+          <div className="search-filters">
+            <div className="filter-group">
+              <label>Dom</label>
+              <select value={historyDormId} onChange={(e) => setHistoryDormId(e.target.value)}>
+                <option value="">Svi Domovi</option>
+                {dormList.map(dorm => (
+                  <option key={dorm.id} value={dorm.id}>{dorm.name}</option>
+                ))}
+              </select>
+            </div>
+            <div className="filter-group">
+              <label>Prikaz</label>
+              <select value={historyGranularity} onChange={(e) => setHistoryGranularity(e.target.value)}>
+                <option value="day">Po danima</option>
+                <option value="week">Po nedeljama</option>
+                <option value="month">Po mesecima</option>
+              </select>
+            </div>
+          </div>
+
+          {historyData && historyData.series.length === 0 && (
+            <p>Još nema zabeležene istorije popunjenosti.</p>
+          )}
+
+          {historyData && historyData.series.map(series => (
+            <div key={series.dorm_id || 'total'} className="table-container">
+              <table className="dorm-stats-table">
+                <thead>
+                  <tr>
+                    <th>Period od</th>
+                    <th>Kapacitet</th>
+                    <th>Popunjeno</th>
+                    <th>Aktivne prijave</th>
+                    <th>Popunjenost</th>
+                  </tr>
+                </thead>
+                <tbody>
+                  {series.points.map(point => (
+                    <tr key={point.period_start}>
+                      <td>{point.period_start}</td>
+                      <td>{Math.round(point.capacity)}</td>
+                      <td>{Math.round(point.occupied)} (najviše {point.peak_occupied})</td>
+                      <td>{Math.round(point.active_applications)}</td>
+                      <td>
+                        <div className="occupancy-meter">
+                          <div className="occupancy-fill" style={{ width: `${Math.min(point.occupancy_rate, 100)}%` }} />
+                        </div>
+                        {point.occupancy_rate.toFixed(1)}%
+                      </td>
+                    </tr>
+                  ))}
+                </tbody>
+              </table>
+            </div>
+          ))}
+        </div>
+
         <div className="export-section">
           <h3>Izvoz Podataka</h3>
           <div className="export-buttons">
@@ -555,6 +636,12 @@ const OpenDataDashboard = () => {
             <button onClick={() => handleExport('room-types', 'csv')} className="btn btn-secondary">
               Izvezi Tipove Soba (CSV)
             </button>
+            <button onClick={() => handleExport('occupancy-history', 'json')} className="btn btn-secondary">
+              Izvezi Istoriju Popunjenosti (JSON)
+            </button>
+            <button onClick={() => handleExport('occupancy-history', 'csv')} className="btn btn-secondary">
+              Izvezi Istoriju Popunjenosti (CSV)
+            </button>
           </div>
         </div>
       </div>
@@ -604,17 +691,6 @@ const OpenDataDashboard = () => {
       setHasSearchedYear(false);
     };
 
-    const formatDate = (dateString) => {
-      if (!dateString) return 'N/A';
-      return new Date(dateString).toLocaleDateString('hr-HR', {
-        year: 'numeric',
-        month: 'long',
-        day: 'numeric',
-        hour: '2-digit',
-        minute: '2-digit'
-      });
-    };
-
     return (
       <div className="tab-content">
         <h2>Prihvaćene aplikacije po akademskoj godini</h2>
@@ -676,41 +752,25 @@ const OpenDataDashboard = () => {
             {yearApplications.length > 0 ? (
               <div className="applications-grid">
                 {yearApplications.map((app, index) => (
-                  <div key={app.id || index} className="application-card">
+                  <div key={app.pseudonim || index} className="application-card">
                     <div className="application-header">
                       <h4>Aplikacija #{index + 1}</h4>
-                      <span className="application-id">ID: {app.id}</span>
+                      <span className="application-id">{app.pseudonim}</span>
                     </div>
                     
                     <div className="application-details">
                       <div className="detail-row">
-                        <span className="label">Korisnik ID:</span>
-                        <span className="value">{app.user_id}</span>
-                      </div>
-                      <div className="detail-row">
-                        <span className="label">Broj indeksa:</span>
-                        <span className="value">{app.broj_indexa}</span>
-                      </div>
-                      <div className="detail-row">
-                        <span className="label">Prosek:</span>
-                        <span className="value prosek">{app.prosek}</span>
+                        <span className="label">Dom:</span>
+                        <span className="value">{app.ime_doma}</span>
                       </div>
                       <div className="detail-row">
-                        <span className="label">Soba ID:</span>
-                        <span className="value">{app.soba_id}</span>
+                        <span className="label">Raspon proseka:</span>
+                        <span className="value prosek">{app.raspon_proseka}</span>
                       </div>
                       <div className="detail-row">
                         <span className="label">Akademska godina:</span>
                         <span className="value">{app.academic_year}</span>
                       </div>
-                      <div className="detail-row">
-                        <span className="label">Kreirana:</span>
-                        <span className="value">{app.created_at ? formatDate(app.created_at) : 'N/A'}</span>
-                      </div>
-                      <div className="detail-row">
-                        <span className="label">Ažurirana:</span>
-                        <span className="value">{app.updated_at ? formatDate(app.updated_at) : 'N/A'}</span>
-                      </div>
                     </div>
                   </div>
                 ))}
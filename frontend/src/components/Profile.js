@@ -0,0 +1,571 @@
+import React, { useState, useEffect } from 'react';
+import { useNavigate } from 'react-router-dom';
+import { useAuth } from '../contexts/AuthContext';
+import { authService } from '../services/authService';
+import './Auth.css';
+import './Dashboard.css';
+
+// stranica profila - izmjena licnih podataka, promjena lozinke, pregled poslednjih prijava i preuzimanje licnih podataka
+const Profile = () => {
+  const { user, token, updateProfile, updateStudentProfile, changePassword } = useAuth();
+  const navigate = useNavigate();
+
+  const [profileData, setProfileData] = useState({
+    first_name: user?.first_name || '',
+    last_name: user?.last_name || '',
+    email: user?.email || '',
+  });
+  const [profileError, setProfileError] = useState('');
+  const [profileSuccess, setProfileSuccess] = useState('');
+  const [profileLoading, setProfileLoading] = useState(false);
+
+  const [studentData, setStudentData] = useState({
+    index_number: user?.student?.index_number || '',
+    faculty: user?.student?.faculty || '',
+    study_program: user?.student?.study_program || '',
+    year_of_study: user?.student?.year_of_study || '',
+    gender: user?.student?.gender || '',
+    jmbg: '',
+  });
+  const [studentError, setStudentError] = useState('');
+  const [studentSuccess, setStudentSuccess] = useState('');
+  const [studentLoading, setStudentLoading] = useState(false);
+
+  const [passwordData, setPasswordData] = useState({
+    currentPassword: '',
+    newPassword: '',
+    confirmPassword: '',
+  });
+  const [passwordError, setPasswordError] = useState('');
+  const [passwordSuccess, setPasswordSuccess] = useState('');
+  const [passwordLoading, setPasswordLoading] = useState(false);
+
+  const [recentSignIns, setRecentSignIns] = useState([]);
+
+  const [exportError, setExportError] = useState('');
+  const [exportLoading, setExportLoading] = useState(false);
+
+  const [apiKeys, setApiKeys] = useState([]);
+  const [apiKeyName, setApiKeyName] = useState('');
+  const [createdApiKey, setCreatedApiKey] = useState(null);
+  const [apiKeyUsage, setApiKeyUsage] = useState({});
+  const [apiKeyError, setApiKeyError] = useState('');
+  const [apiKeyLoading, setApiKeyLoading] = useState(false);
+
+  // ucitava poslednje prijave na nalog
+  useEffect(() => {
+    const fetchDetails = async () => {
+      try {
+        const details = await authService.getProfileDetails(token);
+        setRecentSignIns(details.recent_sign_ins || []);
+      } catch (err) {
+        console.error('Failed to load recent sign-ins:', err);
+      }
+    };
+
+    fetchDetails();
+  }, [token]);
+
+  // ucitava API kljuceve za otvorene podatke
+  useEffect(() => {
+    const fetchApiKeys = async () => {
+      try {
+        const data = await authService.listApiKeys(token);
+        setApiKeys(data.api_keys || []);
+      } catch (err) {
+        console.error('Failed to load API keys:', err);
+      }
+    };
+
+    fetchApiKeys();
+  }, [token]);
+
+  // kreira novi API kljuc i prikazuje ga jednom
+  const handleCreateApiKey = async (e) => {
+    e.preventDefault();
+    setApiKeyError('');
+    setCreatedApiKey(null);
+    setApiKeyLoading(true);
+
+    try {
+      const data = await authService.createApiKey(token, apiKeyName);
+      const { key, ...apiKey } = data.api_key;
+      setCreatedApiKey(key);
+      setApiKeys([apiKey, ...apiKeys]);
+      setApiKeyName('');
+    } catch (err) {
+      setApiKeyError(err.response?.data?.error || 'Kreiranje ključa nije uspjelo.');
+    }
+
+    setApiKeyLoading(false);
+  };
+
+  // opoziva API kljuc
+  const handleRevokeApiKey = async (keyId) => {
+    if (!window.confirm('Opozvani ključ više neće raditi. Nastaviti?')) {
+      return;
+    }
+    setApiKeyError('');
+
+    try {
+      await authService.revokeApiKey(token, keyId);
+      setApiKeys(apiKeys.map((key) => (key.id === keyId ? { ...key, revoked_at: new Date().toISOString() } : key)));
+    } catch (err) {
+      setApiKeyError(err.response?.data?.error || 'Opoziv ključa nije uspio.');
+    }
+  };
+
+  // ucitava potrosnju kljuca za poslednjih 30 dana
+  const handleShowUsage = async (keyId) => {
+    setApiKeyError('');
+
+    try {
+      const usage = await authService.getApiKeyUsage(token, keyId);
+      setApiKeyUsage({ ...apiKeyUsage, [keyId]: usage });
+    } catch (err) {
+      setApiKeyError(err.response?.data?.error || 'Potrošnja ključa trenutno nije dostupna.');
+    }
+  };
+
+  // preuzima arhivu sa svim podacima o korisniku iz svih servisa
+  const handleExport = async () => {
+    setExportError('');
+    setExportLoading(true);
+
+    try {
+      const archive = await authService.exportPersonalData(token);
+      const url = window.URL.createObjectURL(archive);
+      const link = document.createElement('a');
+      link.href = url;
+      link.download = `licni-podaci-${new Date().toISOString().slice(0, 10)}.zip`;
+      document.body.appendChild(link);
+      link.click();
+      link.remove();
+      window.URL.revokeObjectURL(url);
+    } catch (err) {
+      setExportError('Preuzimanje podataka trenutno nije moguće. Pokušajte ponovo kasnije.');
+    }
+
+    setExportLoading(false);
+  };
+
+  // formatira datum i vrijeme prijave
+  const formatDateTime = (dateString) => {
+    return new Date(dateString).toLocaleString('sr-RS');
+  };
+
+  // opis ishoda pokusaja prijave
+  const formatOutcome = (outcome) => {
+    switch (outcome) {
+      case 'success':
+        return 'Uspješna';
+      case 'mfa_challenge':
+        return 'Čeka drugi faktor';
+      case 'locked_out':
+        return 'Blokirana';
+      default:
+        return 'Neuspješna';
+    }
+  };
+
+  const handleProfileChange = (e) => {
+    setProfileData({
+      ...profileData,
+      [e.target.name]: e.target.value,
+    });
+  };
+
+  const handleStudentChange = (e) => {
+    setStudentData({
+      ...studentData,
+      [e.target.name]: e.target.value,
+    });
+  };
+
+  const handlePasswordChange = (e) => {
+    setPasswordData({
+      ...passwordData,
+      [e.target.name]: e.target.value,
+    });
+  };
+
+  // cuva izmjene profila - nova email adresa vazi tek kada se potvrdi linkom
+  const handleProfileSubmit = async (e) => {
+    e.preventDefault();
+    setProfileError('');
+    setProfileSuccess('');
+    setProfileLoading(true);
+
+    const result = await updateProfile(profileData);
+    if (result.success) {
+      setProfileSuccess(
+        profileData.email !== user?.email
+          ? 'Profil je sačuvan. Potvrdite novu email adresu putem linka koji smo vam poslali.'
+          : 'Profil je sačuvan.'
+      );
+    } else {
+      setProfileError(result.error);
+    }
+
+    setProfileLoading(false);
+  };
+
+  // cuva podatke o studiranju - izmjena ponistava raniju potvrdu administratora
+  // JMBG se salje samo ako je unesen, inace ostaje postojeci
+  const handleStudentSubmit = async (e) => {
+    e.preventDefault();
+    setStudentError('');
+    setStudentSuccess('');
+    setStudentLoading(true);
+
+    const { jmbg, ...data } = studentData;
+    const payload = {
+      ...data,
+      year_of_study: parseInt(data.year_of_study),
+    };
+    if (jmbg) {
+      payload.jmbg = jmbg;
+    }
+
+    const result = await updateStudentProfile(payload);
+    if (result.success) {
+      setStudentSuccess('Podaci o studiranju su sačuvani.');
+      setStudentData({ ...studentData, jmbg: '' });
+    } else {
+      setStudentError(result.error);
+    }
+
+    setStudentLoading(false);
+  };
+
+  // mijenja lozinku - ostale sesije se odjavljuju
+  const handlePasswordSubmit = async (e) => {
+    e.preventDefault();
+    setPasswordError('');
+    setPasswordSuccess('');
+
+    if (passwordData.newPassword !== passwordData.confirmPassword) {
+      setPasswordError('Lozinke se ne poklapaju');
+      return;
+    }
+
+    if (passwordData.newPassword.length < 6) {
+      setPasswordError('Lozinka mora imati najmanje 6 karaktera');
+      return;
+    }
+
+    setPasswordLoading(true);
+
+    const result = await changePassword(passwordData.currentPassword, passwordData.newPassword);
+    if (result.success) {
+      setPasswordSuccess('Lozinka je promijenjena. Odjavljeni ste sa svih ostalih uređaja.');
+      setPasswordData({ currentPassword: '', newPassword: '', confirmPassword: '' });
+    } else {
+      setPasswordError(result.error);
+    }
+
+    setPasswordLoading(false);
+  };
+
+  return (
+    <div className="dashboard-container">
+      <div className="dashboard-header">
+        <h1>Moj profil</h1>
+        <button onClick={() => navigate('/dashboard')} className="logout-button">
+          Nazad
+        </button>
+      </div>
+
+      <div className="dashboard-content">
+        <div className="profile-card">
+          <h2>Lični podaci</h2>
+          {user?.pending_email && (
+            <div className="success-message">
+              Čeka se potvrda nove email adrese: {user.pending_email}
+            </div>
+          )}
+          <form onSubmit={handleProfileSubmit}>
+            <div className="form-group">
+              <label htmlFor="first_name">Ime:</label>
+              <input
+                type="text"
+                id="first_name"
+                name="first_name"
+                value={profileData.first_name}
+                onChange={handleProfileChange}
+                required
+              />
+            </div>
+            <div className="form-group">
+              <label htmlFor="last_name">Prezime:</label>
+              <input
+                type="text"
+                id="last_name"
+                name="last_name"
+                value={profileData.last_name}
+                onChange={handleProfileChange}
+                required
+              />
+            </div>
+            <div className="form-group">
+              <label htmlFor="email">Email:</label>
+              <input
+                type="email"
+                id="email"
+                name="email"
+                value={profileData.email}
+                onChange={handleProfileChange}
+                required
+              />
+            </div>
+
+            {profileError && <div className="error-message">{profileError}</div>}
+            {profileSuccess && <div className="success-message">{profileSuccess}</div>}
+
+            <button type="submit" disabled={profileLoading} className="auth-button">
+              {profileLoading ? 'Čuvanje...' : 'Sačuvaj'}
+            </button>
+          </form>
+        </div>
+
+        {user?.role === 'user' && (
+          <div className="profile-card">
+            <h2>Podaci o studiranju</h2>
+            <div className={user?.student?.verified ? 'success-message' : 'error-message'}>
+              {user?.student?.verified
+                ? 'Podatke je potvrdio administrator.'
+                : 'Podaci još nisu potvrđeni. Aplicirati za sobu možete tek nakon potvrde.'}
+            </div>
+            <form onSubmit={handleStudentSubmit}>
+              <div className="form-group">
+                <label htmlFor="index_number">Broj indeksa:</label>
+                <input
+                  type="text"
+                  id="index_number"
+                  name="index_number"
+                  value={studentData.index_number}
+                  onChange={handleStudentChange}
+                  required
+                />
+              </div>
+              <div className="form-group">
+                <label htmlFor="faculty">Fakultet:</label>
+                <input
+                  type="text"
+                  id="faculty"
+                  name="faculty"
+                  value={studentData.faculty}
+                  onChange={handleStudentChange}
+                  required
+                />
+              </div>
+              <div className="form-group">
+                <label htmlFor="study_program">Studijski program:</label>
+                <input
+                  type="text"
+                  id="study_program"
+                  name="study_program"
+                  value={studentData.study_program}
+                  onChange={handleStudentChange}
+                  required
+                />
+              </div>
+              <div className="form-group">
+                <label htmlFor="year_of_study">Godina studija:</label>
+                <input
+                  type="number"
+                  id="year_of_study"
+                  name="year_of_study"
+                  value={studentData.year_of_study}
+                  onChange={handleStudentChange}
+                  min="1"
+                  max="8"
+                  required
+                />
+              </div>
+              <div className="form-group">
+                <label htmlFor="gender">Pol:</label>
+                <select
+                  id="gender"
+                  name="gender"
+                  value={studentData.gender}
+                  onChange={handleStudentChange}
+                  required
+                >
+                  <option value="">Odaberite</option>
+                  <option value="male">Muški</option>
+                  <option value="female">Ženski</option>
+                </select>
+              </div>
+              <div className="form-group">
+                <label htmlFor="jmbg">JMBG (opciono):</label>
+                <input
+                  type="text"
+                  id="jmbg"
+                  name="jmbg"
+                  value={studentData.jmbg}
+                  onChange={handleStudentChange}
+                  maxLength="13"
+                  placeholder={user?.student?.has_jmbg ? 'JMBG je unesen - ostavite prazno da ostane isti' : ''}
+                />
+              </div>
+
+              {studentError && <div className="error-message">{studentError}</div>}
+              {studentSuccess && <div className="success-message">{studentSuccess}</div>}
+
+              <button type="submit" disabled={studentLoading} className="auth-button">
+                {studentLoading ? 'Čuvanje...' : 'Sačuvaj podatke o studiranju'}
+              </button>
+            </form>
+          </div>
+        )}
+
+        <div className="profile-card">
+          <h2>Promjena lozinke</h2>
+          <form onSubmit={handlePasswordSubmit}>
+            <div className="form-group">
+              <label htmlFor="currentPassword">Trenutna lozinka:</label>
+              <input
+                type="password"
+                id="currentPassword"
+                name="currentPassword"
+                value={passwordData.currentPassword}
+                onChange={handlePasswordChange}
+                required
+              />
+            </div>
+            <div className="form-group">
+              <label htmlFor="newPassword">Nova lozinka:</label>
+              <input
+                type="password"
+                id="newPassword"
+                name="newPassword"
+                value={passwordData.newPassword}
+                onChange={handlePasswordChange}
+                required
+              />
+            </div>
+            <div className="form-group">
+              <label htmlFor="confirmPassword">Potvrdite novu lozinku:</label>
+              <input
+                type="password"
+                id="confirmPassword"
+                name="confirmPassword"
+                value={passwordData.confirmPassword}
+                onChange={handlePasswordChange}
+                required
+              />
+            </div>
+
+            {passwordError && <div className="error-message">{passwordError}</div>}
+            {passwordSuccess && <div className="success-message">{passwordSuccess}</div>}
+
+            <button type="submit" disabled={passwordLoading} className="auth-button">
+              {passwordLoading ? 'Mijenjanje...' : 'Promijeni lozinku'}
+            </button>
+          </form>
+        </div>
+
+        <div className="profile-card">
+          <h2>Poslednje prijave</h2>
+          <div className="profile-info">
+            {recentSignIns.length === 0 ? (
+              <p>Nema zabilježenih prijava.</p>
+            ) : (
+              recentSignIns.map((attempt) => (
+                <div key={attempt.id} className="info-row">
+                  <span className="label">
+                    {formatDateTime(attempt.created_at)} · {attempt.ip_address}
+                    {attempt.user_agent && <><br /><small>{attempt.user_agent}</small></>}
+                  </span>
+                  <span className="value">{formatOutcome(attempt.outcome)}</span>
+                </div>
+              ))
+            )}
+          </div>
+        </div>
+
+        <div className="profile-card">
+          <h2>API ključevi za otvorene podatke</h2>
+          <p>
+            Bez ključa API otvorenih podataka prihvata ograničen broj zahtjeva u minuti. Ključ pošaljite
+            u zaglavlju <code>X-API-Key</code> da biste dobili veći limit i dnevnu kvotu.
+          </p>
+
+          <form onSubmit={handleCreateApiKey}>
+            <div className="form-group">
+              <label htmlFor="apiKeyName">Naziv ključa:</label>
+              <input
+                type="text"
+                id="apiKeyName"
+                value={apiKeyName}
+                onChange={(e) => setApiKeyName(e.target.value)}
+                maxLength="100"
+                placeholder="npr. Istraživački projekat"
+                required
+              />
+            </div>
+
+            {apiKeyError && <div className="error-message">{apiKeyError}</div>}
+            {createdApiKey && (
+              <div className="success-message">
+                Sačuvajte ključ sada, kasnije ga nećete moći vidjeti:<br />
+                <code>{createdApiKey}</code>
+              </div>
+            )}
+
+            <button type="submit" disabled={apiKeyLoading} className="auth-button">
+              {apiKeyLoading ? 'Kreiranje...' : 'Kreiraj ključ'}
+            </button>
+          </form>
+
+          <div className="profile-info">
+            {apiKeys.map((key) => (
+              <div key={key.id} className="info-row">
+                <span className="label">
+                  {key.name} · <code>{key.prefix}…</code>
+                  <br />
+                  <small>
+                    {key.rate_limit_per_minute} zahtjeva/min · {key.daily_quota} jedinica dnevno
+                    {key.revoked_at && ` · opozvan ${formatDateTime(key.revoked_at)}`}
+                  </small>
+                  {apiKeyUsage[key.id] && (
+                    <>
+                      <br />
+                      <small>
+                        Poslednjih 30 dana: {apiKeyUsage[key.id].requests} zahtjeva,{' '}
+                        {apiKeyUsage[key.id].units} jedinica, {apiKeyUsage[key.id].rejected} odbijenih
+                      </small>
+                    </>
+                  )}
+                </span>
+                <span className="value">
+                  <button type="button" onClick={() => handleShowUsage(key.id)}>Potrošnja</button>
+                  {!key.revoked_at && (
+                    <button type="button" onClick={() => handleRevokeApiKey(key.id)}>Opozovi</button>
+                  )}
+                </span>
+              </div>
+            ))}
+          </div>
+        </div>
+
+        <div className="profile-card">
+          <h2>Moji podaci</h2>
+          <p>
+            Preuzmite arhivu sa svim podacima koje sistem čuva o vama: podacima naloga, prijavama,
+            aplikacijama za sobe, uplatama i obavještenjima.
+          </p>
+
+          {exportError && <div className="error-message">{exportError}</div>}
+
+          <button onClick={handleExport} disabled={exportLoading} className="auth-button">
+            {exportLoading ? 'Priprema arhive...' : 'Preuzmi moje podatke'}
+          </button>
+        </div>
+      </div>
+    </div>
+  );
+};
+
+export default Profile;
@@ -55,10 +55,10 @@ const Register = () => {
     const result = await register(registerData);
     
     if (result.success) {
-      setSuccess('Registracija uspješna! Možete se sada prijaviti.');
+      setSuccess('Registracija uspješna! Potvrdite email adresu putem linka koji smo vam poslali, a zatim se prijavite.');
       setTimeout(() => {
         navigate('/login');
-      }, 2000);
+      }, 4000);
     } else {
       setError(result.error);
     }
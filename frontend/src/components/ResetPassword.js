@@ -0,0 +1,124 @@
+import React, { useState } from 'react';
+import { Link, useNavigate, useSearchParams } from 'react-router-dom';
+import { authService } from '../services/authService';
+import './Auth.css';
+
+// komponenta za postavljanje nove lozinke - token dolazi iz linka poslatog email-om
+const ResetPassword = () => {
+  const [searchParams] = useSearchParams();
+  const [formData, setFormData] = useState({
+    password: '',
+    confirmPassword: '',
+  });
+  const [error, setError] = useState('');
+  const [success, setSuccess] = useState('');
+  const [loading, setLoading] = useState(false);
+
+  const navigate = useNavigate();
+  const token = searchParams.get('token');
+
+  // rukuje promenama u input poljima
+  const handleChange = (e) => {
+    setFormData({
+      ...formData,
+      [e.target.name]: e.target.value,
+    });
+  };
+
+  // rukuje slanjem nove lozinke
+  const handleSubmit = async (e) => {
+    e.preventDefault();
+    setError('');
+
+    if (formData.password !== formData.confirmPassword) {
+      setError('Lozinke se ne poklapaju');
+      return;
+    }
+
+    if (formData.password.length < 6) {
+      setError('Lozinka mora imati najmanje 6 karaktera');
+      return;
+    }
+
+    setLoading(true);
+
+    try {
+      await authService.resetPassword(token, formData.password);
+      setSuccess('Lozinka je promijenjena. Možete se prijaviti novom lozinkom.');
+      setTimeout(() => {
+        navigate('/login');
+      }, 2000);
+    } catch (err) {
+      setError(err.response?.data?.error || 'Promjena lozinke nije uspjela');
+    }
+
+    setLoading(false);
+  };
+
+  if (!token) {
+    return (
+      <div className="auth-container">
+        <div className="auth-card">
+          <h2>Nova lozinka</h2>
+          <div className="error-message">Link za promjenu lozinke nije ispravan.</div>
+          <div className="auth-links">
+            <p>
+              <Link to="/forgot-password">Zatražite novi link</Link>
+            </p>
+          </div>
+        </div>
+      </div>
+    );
+  }
+
+  return (
+    <div className="auth-container">
+      <div className="auth-card">
+        <h2>Nova lozinka</h2>
+        <form onSubmit={handleSubmit}>
+          <div className="form-group">
+            <label htmlFor="password">Nova lozinka:</label>
+            <input
+              type="password"
+              id="password"
+              name="password"
+              value={formData.password}
+              onChange={handleChange}
+              required
+              minLength="6"
+              placeholder="Unesite novu lozinku (min. 6 karaktera)"
+            />
+          </div>
+
+          <div className="form-group">
+            <label htmlFor="confirmPassword">Potvrda lozinke:</label>
+            <input
+              type="password"
+              id="confirmPassword"
+              name="confirmPassword"
+              value={formData.confirmPassword}
+              onChange={handleChange}
+              required
+              placeholder="Ponovite novu lozinku"
+            />
+          </div>
+
+          {error && <div className="error-message">{error}</div>}
+          {success && <div className="success-message">{success}</div>}
+
+          <button type="submit" disabled={loading} className="auth-button">
+            {loading ? 'Čuvanje...' : 'Promijeni lozinku'}
+          </button>
+        </form>
+
+        <div className="auth-links">
+          <p>
+            <Link to="/login">Nazad na prijavu</Link>
+          </p>
+        </div>
+      </div>
+    </div>
+  );
+};
+
+export default ResetPassword;
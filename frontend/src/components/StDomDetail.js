@@ -150,6 +150,20 @@ const StDomDetail = () => {
                 <a href={`mailto:${stDom.email}`}>{stDom.email}</a>
               </span>
             </div>
+            {stDom.latitude != null && stDom.longitude != null && (
+              <div className="info-item">
+                <span className="label">Lokacija:</span>
+                <span className="value">
+                  <a
+                    href={`https://www.openstreetmap.org/?mlat=${stDom.latitude}&mlon=${stDom.longitude}#map=17/${stDom.latitude}/${stDom.longitude}`}
+                    target="_blank"
+                    rel="noopener noreferrer"
+                  >
+                    {stDom.latitude.toFixed(5)}, {stDom.longitude.toFixed(5)}
+                  </a>
+                </span>
+              </div>
+            )}
             <div className="info-item">
               <span className="label">Kreiran:</span>
               <span className="value">{formatDate(stDom.created_at)}</span>
@@ -0,0 +1,62 @@
+import React, { useEffect, useRef, useState } from 'react';
+import { Link, useSearchParams } from 'react-router-dom';
+import { authService } from '../services/authService';
+import './Auth.css';
+
+// komponenta koja potvrdjuje email adresu tokenom iz linka poslatog pri registraciji
+const VerifyEmail = () => {
+  const [searchParams] = useSearchParams();
+  const [status, setStatus] = useState('loading');
+  const [error, setError] = useState('');
+  const requested = useRef(false);
+
+  const token = searchParams.get('token');
+
+  // salje token jednom pri ucitavanju stranice (token je jednokratan)
+  useEffect(() => {
+    if (requested.current) {
+      return;
+    }
+    requested.current = true;
+
+    if (!token) {
+      setStatus('error');
+      setError('Link za potvrdu nije ispravan.');
+      return;
+    }
+
+    authService.verifyEmail(token)
+      .then(() => setStatus('success'))
+      .catch((err) => {
+        setStatus('error');
+        setError(err.response?.data?.error || 'Potvrda email adrese nije uspjela');
+      });
+  }, [token]);
+
+  return (
+    <div className="auth-container">
+      <div className="auth-card">
+        <h2>Potvrda email adrese</h2>
+
+        {status === 'loading' && <p>Potvrđivanje...</p>}
+        {status === 'success' && (
+          <div className="success-message">Email adresa je potvrđena. Možete se prijaviti.</div>
+        )}
+        {status === 'error' && <div className="error-message">{error}</div>}
+
+        <div className="auth-links">
+          <p>
+            <Link to="/login">Prijava</Link>
+          </p>
+          {status === 'error' && (
+            <p>
+              <Link to="/resend-verification">Pošalji novi link</Link>
+            </p>
+          )}
+        </div>
+      </div>
+    </div>
+  );
+};
+
+export default VerifyEmail;
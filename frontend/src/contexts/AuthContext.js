@@ -28,6 +28,7 @@ export const AuthProvider = ({ children }) => {
         } catch (error) {
           console.error('Failed to get user profile:', error);
           localStorage.removeItem('token');
+          localStorage.removeItem('refresh_token');
           setToken(null);
         }
       }
@@ -37,17 +38,22 @@ export const AuthProvider = ({ children }) => {
     initAuth();
   }, [token]);
 
+  // cuva tokene iz odgovora na prijavu u localStorage i stanje
+  const startSession = (response) => {
+    const { token: newToken, refresh_token: refreshToken, user: userData } = response;
+
+    localStorage.setItem('token', newToken);
+    localStorage.setItem('refresh_token', refreshToken);
+    setToken(newToken);
+    setUser(userData);
+  };
+
   // prijavljuje korisnika i cuva token u localStorage
+  // ako je potreban drugi faktor, vraca token izazova umesto prijave
   const login = async (email, password) => {
     try {
       const response = await authService.login(email, password);
-      const { token: newToken, user: userData } = response;
-      
-      localStorage.setItem('token', newToken);
-      setToken(newToken);
-      setUser(userData);
-      
-      return { success: true };
+      return handleLoginResponse(response);
     } catch (error) {
       return { 
         success: false, 
@@ -56,6 +62,58 @@ export const AuthProvider = ({ children }) => {
     }
   };
 
+  // zavrsava prijavu univerzitetskim nalogom tiketom iz povratnog linka
+  // odgovor je isti kao kod prijave lozinkom, uz stranicu na koju se korisnik vraca
+  const completeFederatedLogin = async (ticket) => {
+    try {
+      const response = await authService.completeFederatedLogin(ticket);
+      return { ...handleLoginResponse(response), returnTo: response.return_to };
+    } catch (error) {
+      return {
+        success: false,
+        error: error.response?.data?.error || 'Login failed'
+      };
+    }
+  };
+
+  // zapocinje sesiju ili vraca token izazova ako je potreban drugi faktor
+  const handleLoginResponse = (response) => {
+    if (response.mfa_required) {
+      return {
+        success: false,
+        mfaRequired: true,
+        mfaToken: response.mfa_token,
+        enrollmentRequired: response.enrollment_required,
+      };
+    }
+
+    startSession(response);
+
+    return { success: true };
+  };
+
+  // zavrsava prijavu kodom za drugi faktor
+  // pri prvom podesavanju vraca kodove za oporavak - sesija pocinje tek kada ih korisnik sacuva (finish)
+  const verifyMfa = async (mfaToken, code) => {
+    try {
+      const response = await authService.verifyMfa(mfaToken, code);
+      if (response.recovery_codes) {
+        return {
+          success: true,
+          recoveryCodes: response.recovery_codes,
+          finish: () => startSession(response),
+        };
+      }
+      startSession(response);
+      return { success: true };
+    } catch (error) {
+      return {
+        success: false,
+        error: error.response?.data?.error || 'Verification failed'
+      };
+    }
+  };
+
   // registruje novog korisnika
   const register = async (userData) => {
     try {
@@ -69,18 +127,86 @@ export const AuthProvider = ({ children }) => {
     }
   };
 
-  // odjavljuje korisnika i brise token
-  const logout = () => {
+  // brise tokene iz localStorage i stanja
+  const clearSession = () => {
     localStorage.removeItem('token');
+    localStorage.removeItem('refresh_token');
     setToken(null);
     setUser(null);
   };
 
+  // odjavljuje korisnika - opoziva sesiju na serveru i brise tokene
+  const logout = async () => {
+    try {
+      if (token) {
+        await authService.logout(token);
+      }
+    } catch (error) {
+      console.error('Failed to revoke session:', error);
+    }
+    clearSession();
+  };
+
+  // odjavljuje korisnika sa svih uredjaja
+  const logoutAll = async () => {
+    try {
+      await authService.logoutAll(token);
+      clearSession();
+      return { success: true };
+    } catch (error) {
+      return { 
+        success: false, 
+        error: error.response?.data?.error || 'Failed to log out all sessions' 
+      };
+    }
+  };
+
+  // menja podatke profila i osvezava korisnika u stanju
+  const updateProfile = async (profileData) => {
+    try {
+      const response = await authService.updateProfile(token, profileData);
+      setUser(response.user);
+      return { success: true, message: response.message };
+    } catch (error) {
+      return {
+        success: false,
+        error: error.response?.data?.error || 'Failed to update profile'
+      };
+    }
+  };
+
+  // cuva podatke o studiranju i osvezava korisnika u stanju
+  const updateStudentProfile = async (studentData) => {
+    try {
+      const response = await authService.updateStudentProfile(token, studentData);
+      setUser(response.user);
+      return { success: true, message: response.message };
+    } catch (error) {
+      return {
+        success: false,
+        error: error.response?.data?.error || 'Failed to update student profile'
+      };
+    }
+  };
+
+  // menja lozinku prijavljenog korisnika
+  const changePassword = async (currentPassword, newPassword) => {
+    try {
+      const response = await authService.changePassword(token, currentPassword, newPassword);
+      return { success: true, message: response.message };
+    } catch (error) {
+      return {
+        success: false,
+        error: error.response?.data?.error || 'Failed to change password'
+      };
+    }
+  };
+
   // brise nalog korisnika i automatski ga odjavljuje
   const deleteAccount = async () => {
     try {
       await authService.deleteAccount(token);
-      logout();
+      clearSession();
       return { success: true };
     } catch (error) {
       return { 
@@ -95,8 +221,14 @@ export const AuthProvider = ({ children }) => {
     token,
     loading,
     login,
+    completeFederatedLogin,
+    verifyMfa,
     register,
     logout,
+    logoutAll,
+    updateProfile,
+    updateStudentProfile,
+    changePassword,
     deleteAccount,
     isAuthenticated: !!token && !!user
   };
@@ -20,12 +20,99 @@ export const authService = {
     return response.data;
   },
 
+  // proverava da li je dostupna prijava univerzitetskim nalogom (spoljni provajder identiteta)
+  async getFederationStatus() {
+    const response = await api.get('/api/v1/auth/federated');
+    return response.data;
+  },
+
+  // adresa koja pokrece prijavu univerzitetskim nalogom - korisnik se posle prijave vraca na returnTo
+  federatedLoginUrl(returnTo) {
+    return `${API_BASE_URL}/api/v1/auth/federated/login?return_to=${encodeURIComponent(returnTo)}`;
+  },
+
+  // zavrsava prijavu univerzitetskim nalogom tiketom iz povratnog linka
+  // salje i kolacic koji vezuje prijavu za ovaj browser - bez njega tiket ne vazi
+  async completeFederatedLogin(ticket) {
+    const response = await api.post('/api/v1/auth/federated/complete', { ticket }, { withCredentials: true });
+    return response.data;
+  },
+
+  // zavrsava prijavu kodom iz authenticator aplikacije ili kodom za oporavak
+  async verifyMfa(mfaToken, code) {
+    const response = await api.post('/api/v1/auth/mfa/verify', {
+      mfa_token: mfaToken,
+      code,
+    });
+    return response.data;
+  },
+
+  // zapocinje podesavanje dvofaktorske autentifikacije pri prijavi (obavezno za administratore)
+  async enrollMfa(mfaToken) {
+    const response = await api.post('/api/v1/auth/mfa/enroll', {
+      mfa_token: mfaToken,
+    });
+    return response.data.totp;
+  },
+
   // registruje novog korisnika
   async register(userData) {
     const response = await api.post('/api/v1/auth/register', userData);
     return response.data;
   },
 
+  // potvrdjuje email adresu tokenom iz poruke
+  async verifyEmail(token) {
+    const response = await api.post('/api/v1/auth/verify-email', { token });
+    return response.data;
+  },
+
+  // ponovo salje link za potvrdu email adrese
+  async resendVerification(email) {
+    const response = await api.post('/api/v1/auth/resend-verification', { email });
+    return response.data;
+  },
+
+  // salje link za reset lozinke na email
+  async forgotPassword(email) {
+    const response = await api.post('/api/v1/auth/forgot-password', { email });
+    return response.data;
+  },
+
+  // postavlja novu lozinku tokenom iz poruke
+  async resetPassword(token, password) {
+    const response = await api.post('/api/v1/auth/reset-password', { token, password });
+    return response.data;
+  },
+
+  // dobija novi par tokena na osnovu refresh tokena (stari refresh token postaje nevazeci)
+  async refresh(refreshToken) {
+    const response = await api.post('/api/v1/auth/refresh', {
+      refresh_token: refreshToken,
+    });
+    return response.data;
+  },
+
+  // odjavljuje trenutnu sesiju na serveru - opoziva access i refresh token
+  async logout(token) {
+    const response = await api.post('/api/v1/auth/logout', {}, {
+      headers: {
+        Authorization: `Bearer ${token}`,
+      },
+    });
+    return response.data;
+  },
+
+  // odjavljuje korisnika sa svih uredjaja
+  async logoutAll(token) {
+    const response = await api.post('/api/v1/auth/logout-all', {}, {
+      headers: {
+        Authorization: `Bearer ${token}`,
+      },
+    });
+    return response.data;
+  },
+
   // dobija profil korisnika na osnovu tokena
   async getProfile(token) {
     const response = await api.get('/api/v1/profile', {
@@ -36,7 +123,122 @@ export const authService = {
     return response.data.user;
   },
 
-  // brise nalog korisnika
+  // dobija profil zajedno sa poslednjim prijavama na nalog
+  async getProfileDetails(token) {
+    const response = await api.get('/api/v1/profile', {
+      headers: {
+        Authorization: `Bearer ${token}`,
+      },
+    });
+    return response.data;
+  },
+
+  // menja ime, prezime ili email (nova adresa se mora potvrditi)
+  async updateProfile(token, profileData) {
+    const response = await api.put('/api/v1/profile', profileData, {
+      headers: {
+        Authorization: `Bearer ${token}`,
+      },
+    });
+    return response.data;
+  },
+
+  // cuva podatke o studiranju (broj indeksa, fakultet, JMBG...)
+  async updateStudentProfile(token, studentData) {
+    const response = await api.put('/api/v1/profile/student', studentData, {
+      headers: {
+        Authorization: `Bearer ${token}`,
+      },
+    });
+    return response.data;
+  },
+
+  // menja lozinku - ostale sesije korisnika se odjavljuju
+  async changePassword(token, currentPassword, newPassword) {
+    const response = await api.post('/api/v1/profile/password', {
+      current_password: currentPassword,
+      new_password: newPassword,
+    }, {
+      headers: {
+        Authorization: `Bearer ${token}`,
+      },
+    });
+    return response.data;
+  },
+
+  // dobija naziv aplikacije i trazene dozvole za ekran odobravanja pristupa (OpenID Connect)
+  async getAuthorizeDetails(token, query) {
+    const response = await api.get(`/api/v1/oauth/authorize/details${query}`, {
+      headers: {
+        Authorization: `Bearer ${token}`,
+      },
+    });
+    return response.data;
+  },
+
+  // salje odluku korisnika - vraca adresu na koju se korisnik vraca u aplikaciju
+  async authorizeClient(token, params, approve) {
+    const response = await api.post('/api/v1/oauth/authorize', { ...params, approve }, {
+      headers: {
+        Authorization: `Bearer ${token}`,
+      },
+    });
+    return response.data;
+  },
+
+  // preuzima ZIP arhivu sa svim podacima koje sistem cuva o korisniku
+  async exportPersonalData(token) {
+    const response = await api.get('/api/v1/account/export', {
+      headers: {
+        Authorization: `Bearer ${token}`,
+      },
+      responseType: 'blob',
+    });
+    return response.data;
+  },
+
+  // vraca API kljuceve korisnika za otvorene podatke
+  async listApiKeys(token) {
+    const response = await api.get('/api/v1/api-keys', {
+      headers: {
+        Authorization: `Bearer ${token}`,
+      },
+    });
+    return response.data;
+  },
+
+  // kreira novi API kljuc - kljuc je u odgovoru samo ovaj put
+  async createApiKey(token, name) {
+    const response = await api.post('/api/v1/api-keys', { name }, {
+      headers: {
+        Authorization: `Bearer ${token}`,
+      },
+    });
+    return response.data;
+  },
+
+  // opoziva API kljuc
+  async revokeApiKey(token, keyId) {
+    const response = await api.delete(`/api/v1/api-keys/${keyId}`, {
+      headers: {
+        Authorization: `Bearer ${token}`,
+      },
+    });
+    return response.data;
+  },
+
+  // vraca dnevnu potrosnju API kljuca
+  async getApiKeyUsage(token, keyId, days = 30) {
+    const response = await api.get(`/api/v1/api-keys/${keyId}/usage`, {
+      headers: {
+        Authorization: `Bearer ${token}`,
+      },
+      params: { days },
+    });
+    return response.data;
+  },
+
+  // brise nalog korisnika i anonimizuje njegove podatke u studentskom domu
   async deleteAccount(token) {
     const response = await api.delete('/api/v1/account', {
       headers: {
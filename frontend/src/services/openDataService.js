@@ -130,6 +130,56 @@ class OpenDataService {
     return this.makeRequest('/open-data/occupancy/heatmap');
   }
 
+  /**
+   * Get occupancy history over a date range
+   * @param {Object} options - Query options
+   * @param {string} options.from - First day (YYYY-MM-DD), default 30 days ago
+   * @param {string} options.to - Last day (YYYY-MM-DD), default today
+   * @param {string} options.granularity - 'day', 'week' or 'month'
+   * @param {string} options.level - 'total', 'dorm' or 'room'
+   * @param {string} options.dormId - Filter by dorm ID
+   * @param {string} options.roomId - Filter by room ID
+   * @returns {Promise} Time series with one series per dorm or room
+   */
+  async getOccupancyHistory(options = {}) {
+    const params = new URLSearchParams();
+    if (options.from) params.append('from', options.from);
+    if (options.to) params.append('to', options.to);
+    if (options.granularity) params.append('granularity', options.granularity);
+    if (options.level) params.append('level', options.level);
+    if (options.dormId) params.append('dorm_id', options.dormId);
+    if (options.roomId) params.append('room_id', options.roomId);
+
+    return this.makeRequest(`/open-data/occupancy/history?${params}`);
+  }
+
+  /**
+   * Get dorm locations with occupancy as a GeoJSON FeatureCollection
+   * @returns {Promise} GeoJSON with one Point feature per dorm that has coordinates
+   */
+  async getDormsGeoJSON() {
+    return this.makeRequest('/open-data/dorms.geojson');
+  }
+
+  /**
+   * Find the dorms nearest to a point
+   * @param {number} latitude - Latitude of the point
+   * @param {number} longitude - Longitude of the point
+   * @param {Object} options - Query options
+   * @param {number} options.limit - Number of dorms (default 5, max 50)
+   * @param {boolean} options.availableOnly - Only dorms with free spots
+   * @param {number} options.maxDistanceKm - Distance limit in kilometers
+   * @returns {Promise} Dorms with distance_km, nearest first
+   */
+  async findNearestDorms(latitude, longitude, options = {}) {
+    const params = new URLSearchParams({ lat: latitude, lng: longitude });
+    if (options.limit) params.append('limit', options.limit);
+    if (options.availableOnly) params.append('available_only', 'true');
+    if (options.maxDistanceKm) params.append('max_distance_km', options.maxDistanceKm);
+
+    return this.makeRequest(`/open-data/dorms/nearest?${params}`);
+  }
+
   // ====================
   // 6. Open Data Export (CSV/JSON)
   // ====================
@@ -8,8 +8,32 @@ class StDomService {
     this.baseURL = API_BASE_URL;
   }
 
+  //osvezava access token pomocu refresh tokena i cuva novi par u localStorage
+  async refreshTokens() {
+    const refreshToken = localStorage.getItem('refresh_token');
+    if (!refreshToken) {
+      return false;
+    }
+
+    const response = await fetch(`${this.baseURL}/auth/refresh`, {
+      method: 'POST',
+      headers: { 'Content-Type': 'application/json' },
+      body: JSON.stringify({ refresh_token: refreshToken }),
+    });
+
+    if (!response.ok) {
+      return false;
+    }
+
+    const data = await response.json();
+    localStorage.setItem('token', data.token);
+    localStorage.setItem('refresh_token', data.refresh_token);
+    return true;
+  }
+
   //HTTP zahtev sa automatskim dodavanjem JWT tokena
-  async makeRequest(endpoint, options = {}) {
+  //ako je access token istekao, jednom pokusava osvezavanje pa ponavlja zahtev
+  async makeRequest(endpoint, options = {}, retried = false) {
     const token = localStorage.getItem('token');
     
     const config = {
@@ -22,6 +46,10 @@ class StDomService {
     };
 
     const response = await fetch(`${this.baseURL}${endpoint}`, config);
+
+    if (response.status === 401 && token && !retried && await this.refreshTokens()) {
+      return this.makeRequest(endpoint, options, true);
+    }
     
     if (!response.ok) {
       const errorData = await response.json().catch(() => ({}));
@@ -1,8 +1,13 @@
 package config
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"log"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -14,6 +19,35 @@ type Config struct {
 	Port            string
 	GinMode         string
 	StDomServiceURL string
+	// SSO service and credentials used to obtain service tokens for calls to other services
+	SSOServiceURL       string
+	ServiceClientID     string
+	ServiceClientSecret string
+	// Rate limits and API keys (keys are issued by the SSO service)
+	AnonymousRateLimit   int
+	ExportRequestCost    int
+	ExportTimeout        time.Duration
+	APIKeyCacheTTL       time.Duration
+	APIKeyUsageRetention time.Duration
+	JWKSRefresh          time.Duration
+	// Proxies (addresses or CIDR ranges) whose X-Real-IP header is trusted for the client address
+	TrustedProxies []string
+	// DCAT-AP dataset catalog; PublicBaseURL is the address harvesters reach the API on
+	PublicBaseURL       string
+	CatalogPublisher    string
+	CatalogContactEmail string
+	CatalogLicense      string
+	// Scheduled, immutable dataset snapshots
+	SnapshotEnabled    bool
+	SnapshotPeriod     time.Duration
+	SnapshotStorageDir string
+	// Daily occupancy history of every dorm and room
+	OccupancyHistoryEnabled bool
+	OccupancyHistoryPeriod  time.Duration
+	// Anonymization of published student data
+	AnonymizationPseudonymKey string
+	AnonymizationMinGroupSize int
+	GradeBucketWidth          int
 }
 
 // LoadConfig loads configuration from environment variables or config.env file
@@ -28,6 +62,48 @@ func LoadConfig() *Config {
 		Port:            getEnv("PORT", "8082"),
 		GinMode:         getEnv("GIN_MODE", "debug"),
 		StDomServiceURL: getEnv("ST_DOM_SERVICE_URL", "http://localhost:8081"),
+
+		SSOServiceURL:       getEnv("SSO_SERVICE_URL", "http://localhost:8080"),
+		ServiceClientID:     getEnv("SERVICE_CLIENT_ID", "open_data_service"),
+		ServiceClientSecret: getEnv("SERVICE_CLIENT_SECRET", ""),
+
+		AnonymousRateLimit:   getIntEnv("ANONYMOUS_RATE_LIMIT", 30),
+		ExportRequestCost:    getIntEnv("EXPORT_REQUEST_COST", 10),
+		ExportTimeout:        getDurationEnv("EXPORT_TIMEOUT", 10*time.Minute),
+		APIKeyCacheTTL:       getDurationEnv("API_KEY_CACHE_TTL", time.Minute),
+		APIKeyUsageRetention: getDurationEnv("API_KEY_USAGE_RETENTION", 90*24*time.Hour),
+		JWKSRefresh:          getDurationEnv("JWKS_REFRESH", 10*time.Minute),
+		TrustedProxies:       getListEnv("TRUSTED_PROXIES", "127.0.0.1,::1,10.0.0.0/8,172.16.0.0/12,192.168.0.0/16"),
+
+		PublicBaseURL:       strings.TrimSuffix(getEnv("PUBLIC_BASE_URL", "http://localhost"), "/"),
+		CatalogPublisher:    getEnv("CATALOG_PUBLISHER", "Studentski centar"),
+		CatalogContactEmail: getEnv("CATALOG_CONTACT_EMAIL", "opendata@localhost"),
+		CatalogLicense:      getEnv("CATALOG_LICENSE", "http://creativecommons.org/licenses/by/4.0/"),
+
+		SnapshotEnabled:    getBoolEnv("SNAPSHOT_ENABLED", true),
+		SnapshotPeriod:     getDurationEnv("SNAPSHOT_PERIOD", 24*time.Hour),
+		SnapshotStorageDir: getEnv("SNAPSHOT_STORAGE_DIR", "./snapshots"),
+
+		OccupancyHistoryEnabled: getBoolEnv("OCCUPANCY_HISTORY_ENABLED", true),
+		OccupancyHistoryPeriod:  getDurationEnv("OCCUPANCY_HISTORY_PERIOD", 24*time.Hour),
+
+		AnonymizationPseudonymKey: getEnv("ANONYMIZATION_PSEUDONYM_KEY", ""),
+		AnonymizationMinGroupSize: getIntEnv("ANONYMIZATION_MIN_GROUP_SIZE", 5),
+		GradeBucketWidth:          getIntEnv("GRADE_BUCKET_WIDTH", 2),
+	}
+
+	if config.AnonymizationPseudonymKey == "" {
+		log.Println("WARNING: ANONYMIZATION_PSEUDONYM_KEY is not set, using a random key - published pseudonyms will change on every restart")
+		key := make([]byte, 32)
+		if _, err := rand.Read(key); err != nil {
+			log.Fatal("Failed to generate pseudonym key:", err)
+		}
+		config.AnonymizationPseudonymKey = hex.EncodeToString(key)
+	}
+
+	// Internal endpoints of the other services cannot be called without it, and a default secret would be public
+	if config.ServiceClientSecret == "" {
+		log.Fatal("SERVICE_CLIENT_SECRET is not set")
 	}
 
 	return config
@@ -40,3 +116,47 @@ func getEnv(key, fallback string) string {
 	}
 	return fallback
 }
+
+// getListEnv gets a comma separated list from an environment variable or the fallback, skipping empty items
+func getListEnv(key, fallback string) []string {
+	var items []string
+	for _, item := range strings.Split(getEnv(key, fallback), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// getIntEnv gets an integer environment variable or returns a fallback value
+func getIntEnv(key string, fallback int) int {
+	if value, exists := os.LookupEnv(key); exists {
+		if parsed, err := strconv.Atoi(value); err == nil {
+			return parsed
+		}
+		log.Printf("Invalid value for %s, using default %d", key, fallback)
+	}
+	return fallback
+}
+
+// getBoolEnv gets a boolean environment variable (e.g. "true", "0") or returns a fallback value
+func getBoolEnv(key string, fallback bool) bool {
+	if value, exists := os.LookupEnv(key); exists {
+		if parsed, err := strconv.ParseBool(value); err == nil {
+			return parsed
+		}
+		log.Printf("Invalid value for %s, using default %t", key, fallback)
+	}
+	return fallback
+}
+
+// getDurationEnv gets a duration environment variable (e.g. "10m") or returns a fallback value
+func getDurationEnv(key string, fallback time.Duration) time.Duration {
+	if value, exists := os.LookupEnv(key); exists {
+		if parsed, err := time.ParseDuration(value); err == nil {
+			return parsed
+		}
+		log.Printf("Invalid value for %s, using default %s", key, fallback)
+	}
+	return fallback
+}
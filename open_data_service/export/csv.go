@@ -0,0 +1,86 @@
+package export
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"time"
+)
+
+// csvEncoder writes a dataset as CSV with the column titles as header
+type csvEncoder struct {
+	writer  *csv.Writer
+	out     io.Writer
+	columns []Column
+	record  []string
+	rows    int
+}
+
+func newCSVEncoder(w io.Writer, _ string) Encoder {
+	return &csvEncoder{writer: csv.NewWriter(w), out: w}
+}
+
+func (e *csvEncoder) Columns(columns []Column) error {
+	e.columns = columns
+	e.record = make([]string, len(columns))
+
+	for i, column := range columns {
+		e.record[i] = column.Title
+	}
+	return e.writer.Write(e.record)
+}
+
+func (e *csvEncoder) Row(values []interface{}) error {
+	if err := checkRow(e.columns, values); err != nil {
+		return err
+	}
+
+	for i, value := range values {
+		e.record[i] = formatText(e.columns[i].Type, value)
+	}
+	if err := e.writer.Write(e.record); err != nil {
+		return err
+	}
+
+	e.rows++
+	if e.rows%flushRows == 0 {
+		return e.flush()
+	}
+	return nil
+}
+
+func (e *csvEncoder) Close() error {
+	return e.flush()
+}
+
+func (e *csvEncoder) flush() error {
+	e.writer.Flush()
+	if err := e.writer.Error(); err != nil {
+		return err
+	}
+	return flushOutput(e.out)
+}
+
+// formatText formats a value for CSV: decimals with two places, booleans as da/ne
+func formatText(columnType ColumnType, value interface{}) string {
+	if value == nil {
+		return ""
+	}
+
+	switch columnType {
+	case Int:
+		return strconv.Itoa(value.(int))
+	case Float:
+		return strconv.FormatFloat(value.(float64), 'f', 2, 64)
+	case Bool:
+		if value.(bool) {
+			return "da"
+		}
+		return "ne"
+	case Date:
+		return value.(time.Time).UTC().Format("2006-01-02")
+	case Timestamp:
+		return value.(time.Time).UTC().Format("2006-01-02 15:04:05")
+	}
+	return value.(string)
+}
@@ -0,0 +1,120 @@
+// Package export writes the typed rows of the open data datasets in the supported file formats
+// Every dataset produces its rows once; an Encoder per format decides how each column type is written
+package export
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"open_data_service/models"
+	"time"
+)
+
+// flushRows is the number of rows written between two flushes of the output,
+// so the client receives data while the rest of the export is still being read
+const flushRows = 500
+
+// ColumnType is the type of the values in a column
+type ColumnType int
+
+const (
+	String    ColumnType = iota // string
+	Int                         // int
+	Float                       // float64
+	Bool                        // bool
+	Date                        // time.Time, only the date is written
+	Timestamp                   // time.Time
+)
+
+// Column describes one column of a dataset
+type Column struct {
+	Key   string // Field name in JSON, XML and Parquet
+	Title string // Header in CSV and spreadsheets
+	Type  ColumnType
+}
+
+// RowWriter receives the rows of a dataset one at a time
+type RowWriter interface {
+	// Columns is called once, before the first row
+	Columns(columns []Column) error
+	// Row writes one row; values are in column order and nil is an empty (null) value
+	Row(values []interface{}) error
+}
+
+// Encoder writes the rows of a dataset to an output in one file format
+type Encoder interface {
+	RowWriter
+	// Close writes the end of the file and flushes everything that is buffered
+	Close() error
+}
+
+// Format is an export file format
+type Format struct {
+	ContentType string
+	Extension   string
+	// Attachment is whether the file is downloaded instead of being shown by the browser
+	Attachment bool
+	// Compressed is whether the file is compressed already, so compressing the response again gains nothing
+	Compressed bool
+	// NewEncoder creates an encoder that writes the dataset to w
+	NewEncoder func(w io.Writer, dataset string) Encoder
+}
+
+// formats are the encoders of the export formats
+var formats = map[models.ExportFormat]Format{
+	models.ExportFormatCSV:     {ContentType: "text/csv", Extension: "csv", Attachment: true, NewEncoder: newCSVEncoder},
+	models.ExportFormatJSON:    {ContentType: "application/json; charset=utf-8", Extension: "json", NewEncoder: newJSONEncoder},
+	models.ExportFormatNDJSON:  {ContentType: "application/x-ndjson", Extension: "ndjson", Attachment: true, NewEncoder: newNDJSONEncoder},
+	models.ExportFormatXML:     {ContentType: "application/xml; charset=utf-8", Extension: "xml", Attachment: true, NewEncoder: newXMLEncoder},
+	models.ExportFormatXLSX:    {ContentType: "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", Extension: "xlsx", Attachment: true, Compressed: true, NewEncoder: newXLSXEncoder},
+	models.ExportFormatODS:     {ContentType: "application/vnd.oasis.opendocument.spreadsheet", Extension: "ods", Attachment: true, Compressed: true, NewEncoder: newODSEncoder},
+	models.ExportFormatParquet: {ContentType: "application/vnd.apache.parquet", Extension: "parquet", Attachment: true, Compressed: true, NewEncoder: newParquetEncoder},
+}
+
+// Lookup returns the export format with the given name
+func Lookup(format models.ExportFormat) (Format, bool) {
+	f, exists := formats[format]
+	return f, exists
+}
+
+// checkRow verifies that a row matches the columns, so a dataset cannot write a value its column type does not allow
+func checkRow(columns []Column, values []interface{}) error {
+	if len(values) != len(columns) {
+		return fmt.Errorf("row has %d values, expected %d", len(values), len(columns))
+	}
+
+	for i, value := range values {
+		if value == nil {
+			continue
+		}
+
+		var ok bool
+		switch columns[i].Type {
+		case String:
+			_, ok = value.(string)
+		case Int:
+			_, ok = value.(int)
+		case Float:
+			_, ok = value.(float64)
+		case Bool:
+			_, ok = value.(bool)
+		case Date, Timestamp:
+			_, ok = value.(time.Time)
+		}
+		if !ok {
+			return fmt.Errorf("column %s: unexpected value of type %T", columns[i].Key, value)
+		}
+	}
+	return nil
+}
+
+// flushOutput sends the data buffered by the output (e.g. the response and its compression) to the client
+func flushOutput(out io.Writer) error {
+	switch flusher := out.(type) {
+	case interface{ Flush() error }:
+		return flusher.Flush()
+	case http.Flusher:
+		flusher.Flush()
+	}
+	return nil
+}
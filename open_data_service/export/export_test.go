@@ -0,0 +1,98 @@
+package export
+
+import (
+	"bytes"
+	"open_data_service/models"
+	"testing"
+	"time"
+)
+
+// testColumns has one column of every column type
+var testColumns = []Column{
+	{Key: "ime", Title: "Ime", Type: String},
+	{Key: "broj", Title: "Broj", Type: Int},
+	{Key: "prosek", Title: "Prosek", Type: Float},
+	{Key: "aktivna", Title: "Aktivna", Type: Bool},
+	{Key: "datum", Title: "Datum", Type: Date},
+	{Key: "vreme", Title: "Vreme", Type: Timestamp},
+}
+
+// testRows covers typical values, a row of nulls, negative numbers, dates before 1970 and text that must be escaped
+var testRows = [][]interface{}{
+	{"Dom Košutnjak & <Blok 1>", 42, 8.75, true, time.Date(2024, time.March, 15, 10, 20, 0, 0, time.UTC), time.Date(2024, time.March, 15, 12, 0, 0, 250e6, time.UTC)},
+	{nil, nil, nil, nil, nil, nil},
+	{"", -7, -0.5, false, time.Date(1969, time.December, 31, 22, 0, 0, 0, time.UTC), time.Date(1969, time.December, 31, 23, 59, 59, 0, time.UTC)},
+	{"x", 1 << 40, nil, true, nil, time.Date(2024, time.March, 15, 12, 0, 0, 0, time.FixedZone("CET", 3600))},
+}
+
+// encode writes the rows with the encoder of the format and returns the file
+func encode(t *testing.T, format models.ExportFormat, dataset string, columns []Column, rows [][]interface{}) []byte {
+	t.Helper()
+
+	f, ok := Lookup(format)
+	if !ok {
+		t.Fatalf("format %s is not registered", format)
+	}
+
+	var out bytes.Buffer
+	encoder := f.NewEncoder(&out, dataset)
+	if err := encoder.Columns(columns); err != nil {
+		t.Fatalf("Columns: %v", err)
+	}
+	for _, row := range rows {
+		if err := encoder.Row(row); err != nil {
+			t.Fatalf("Row: %v", err)
+		}
+	}
+	if err := encoder.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	return out.Bytes()
+}
+
+// expectedRows returns the rows as a format stores them: dates at midnight UTC and timestamps in UTC with the given precision
+func expectedRows(columns []Column, rows [][]interface{}, precision time.Duration) [][]interface{} {
+	expected := make([][]interface{}, len(rows))
+	for i, row := range rows {
+		expected[i] = make([]interface{}, len(row))
+		for j, value := range row {
+			if value != nil {
+				switch columns[j].Type {
+				case Date:
+					value = value.(time.Time).UTC().Truncate(24 * time.Hour)
+				case Timestamp:
+					value = value.(time.Time).UTC().Truncate(precision)
+				}
+			}
+			expected[i][j] = value
+		}
+	}
+	return expected
+}
+
+// compareRows fails the test if the rows read back differ from the expected rows
+func compareRows(t *testing.T, got, want [][]interface{}) {
+	t.Helper()
+
+	if len(got) != len(want) {
+		t.Fatalf("got %d rows, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if len(got[i]) != len(want[i]) {
+			t.Fatalf("row %d: got %d values, want %d", i, len(got[i]), len(want[i]))
+		}
+		for j := range want[i] {
+			if !equalValues(got[i][j], want[i][j]) {
+				t.Errorf("row %d, column %d: got %#v, want %#v", i, j, got[i][j], want[i][j])
+			}
+		}
+	}
+}
+
+func equalValues(a, b interface{}) bool {
+	if at, ok := a.(time.Time); ok {
+		bt, ok := b.(time.Time)
+		return ok && at.Equal(bt)
+	}
+	return a == b
+}
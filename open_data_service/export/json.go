@@ -0,0 +1,114 @@
+package export
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"time"
+)
+
+// jsonEncoder writes a dataset as {"data": [...]} or, for NDJSON, as one JSON object per line
+// Objects keep the column order of the dataset
+type jsonEncoder struct {
+	writer  *bufio.Writer
+	out     io.Writer
+	ndjson  bool
+	columns []Column
+	keys    [][]byte
+	rows    int
+}
+
+func newJSONEncoder(w io.Writer, _ string) Encoder {
+	return &jsonEncoder{writer: bufio.NewWriter(w), out: w}
+}
+
+func newNDJSONEncoder(w io.Writer, _ string) Encoder {
+	return &jsonEncoder{writer: bufio.NewWriter(w), out: w, ndjson: true}
+}
+
+func (e *jsonEncoder) Columns(columns []Column) error {
+	e.columns = columns
+	e.keys = make([][]byte, len(columns))
+	for i, column := range columns {
+		key, err := json.Marshal(column.Key)
+		if err != nil {
+			return err
+		}
+		e.keys[i] = append(key, ':')
+	}
+
+	if e.ndjson {
+		return nil
+	}
+	_, err := e.writer.WriteString(`{"data":[`)
+	return err
+}
+
+func (e *jsonEncoder) Row(values []interface{}) error {
+	if err := checkRow(e.columns, values); err != nil {
+		return err
+	}
+
+	if !e.ndjson && e.rows > 0 {
+		if err := e.writer.WriteByte(','); err != nil {
+			return err
+		}
+	}
+
+	e.writer.WriteByte('{')
+	for i, value := range values {
+		if i > 0 {
+			e.writer.WriteByte(',')
+		}
+		e.writer.Write(e.keys[i])
+
+		content, err := json.Marshal(jsonValue(e.columns[i].Type, value))
+		if err != nil {
+			return err
+		}
+		if _, err := e.writer.Write(content); err != nil {
+			return err
+		}
+	}
+	e.writer.WriteByte('}')
+	if e.ndjson {
+		e.writer.WriteByte('\n')
+	}
+
+	e.rows++
+	if e.rows%flushRows == 0 {
+		return e.flush()
+	}
+	return nil
+}
+
+func (e *jsonEncoder) Close() error {
+	if !e.ndjson {
+		if _, err := e.writer.WriteString("]}\n"); err != nil {
+			return err
+		}
+	}
+	return e.flush()
+}
+
+func (e *jsonEncoder) flush() error {
+	if err := e.writer.Flush(); err != nil {
+		return err
+	}
+	return flushOutput(e.out)
+}
+
+// jsonValue converts dates to "YYYY-MM-DD" strings and timestamps to UTC; other values are written as they are
+func jsonValue(columnType ColumnType, value interface{}) interface{} {
+	if value == nil {
+		return nil
+	}
+
+	switch columnType {
+	case Date:
+		return value.(time.Time).UTC().Format("2006-01-02")
+	case Timestamp:
+		return value.(time.Time).UTC()
+	}
+	return value
+}
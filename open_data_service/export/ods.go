@@ -0,0 +1,161 @@
+package export
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/xml"
+	"hash/crc32"
+	"io"
+	"strconv"
+	"time"
+)
+
+// odsMimeType must be the first file of the archive, stored uncompressed
+const odsMimeType = "application/vnd.oasis.opendocument.spreadsheet"
+
+const odsManifest = xml.Header + `<manifest:manifest xmlns:manifest="urn:oasis:names:tc:opendocument:xmlns:manifest:1.0" manifest:version="1.2">` +
+	`<manifest:file-entry manifest:full-path="/" manifest:version="1.2" manifest:media-type="` + odsMimeType + `"/>` +
+	`<manifest:file-entry manifest:full-path="content.xml" manifest:media-type="text/xml"/>` +
+	`</manifest:manifest>`
+
+// odsContentStart opens the document and defines the cell styles: ISO dates and timestamps, two decimal places and bold header
+const odsContentStart = xml.Header + `<office:document-content` +
+	` xmlns:office="urn:oasis:names:tc:opendocument:xmlns:office:1.0"` +
+	` xmlns:style="urn:oasis:names:tc:opendocument:xmlns:style:1.0"` +
+	` xmlns:text="urn:oasis:names:tc:opendocument:xmlns:text:1.0"` +
+	` xmlns:table="urn:oasis:names:tc:opendocument:xmlns:table:1.0"` +
+	` xmlns:number="urn:oasis:names:tc:opendocument:xmlns:datastyle:1.0"` +
+	` xmlns:fo="urn:oasis:names:tc:opendocument:xmlns:xsl-fo-compatible:1.0"` +
+	` office:version="1.2">` +
+	`<office:automatic-styles>` +
+	`<number:date-style style:name="N1"><number:year number:style="long"/><number:text>-</number:text><number:month number:style="long"/><number:text>-</number:text><number:day number:style="long"/></number:date-style>` +
+	`<number:date-style style:name="N2"><number:year number:style="long"/><number:text>-</number:text><number:month number:style="long"/><number:text>-</number:text><number:day number:style="long"/>` +
+	`<number:text> </number:text><number:hours number:style="long"/><number:text>:</number:text><number:minutes number:style="long"/><number:text>:</number:text><number:seconds number:style="long"/></number:date-style>` +
+	`<number:number-style style:name="N3"><number:number number:decimal-places="2" number:min-integer-digits="1"/></number:number-style>` +
+	`<style:style style:name="ce1" style:family="table-cell" style:data-style-name="N1"/>` +
+	`<style:style style:name="ce2" style:family="table-cell" style:data-style-name="N2"/>` +
+	`<style:style style:name="ce3" style:family="table-cell" style:data-style-name="N3"/>` +
+	`<style:style style:name="ce4" style:family="table-cell"><style:text-properties fo:font-weight="bold"/></style:style>` +
+	`</office:automatic-styles>` +
+	`<office:body><office:spreadsheet>`
+
+// odsEncoder writes a dataset as an OpenDocument spreadsheet with one table
+type odsEncoder struct {
+	zip     *zip.Writer
+	content *bufio.Writer
+	out     io.Writer
+	dataset string
+	columns []Column
+	rows    int
+}
+
+func newODSEncoder(w io.Writer, dataset string) Encoder {
+	return &odsEncoder{zip: zip.NewWriter(w), out: w, dataset: dataset}
+}
+
+func (e *odsEncoder) Columns(columns []Column) error {
+	e.columns = columns
+
+	mimeType, err := e.zip.CreateRaw(&zip.FileHeader{
+		Name:               "mimetype",
+		Method:             zip.Store,
+		CRC32:              crc32.ChecksumIEEE([]byte(odsMimeType)),
+		CompressedSize64:   uint64(len(odsMimeType)),
+		UncompressedSize64: uint64(len(odsMimeType)),
+	})
+	if err != nil {
+		return err
+	}
+	if _, err := io.WriteString(mimeType, odsMimeType); err != nil {
+		return err
+	}
+	if err := writeZipFile(e.zip, "META-INF/manifest.xml", odsManifest); err != nil {
+		return err
+	}
+
+	content, err := e.zip.Create("content.xml")
+	if err != nil {
+		return err
+	}
+	e.content = bufio.NewWriter(content)
+
+	e.content.WriteString(odsContentStart + `<table:table table:name="` + escapeXML(e.dataset) + `">`)
+	e.content.WriteString(`<table:table-column table:number-columns-repeated="` + strconv.Itoa(len(columns)) + `"/>`)
+	e.content.WriteString(`<table:table-header-rows><table:table-row>`)
+	for _, column := range columns {
+		e.writeCell(`office:value-type="string" table:style-name="ce4"`, column.Title)
+	}
+	_, err = e.content.WriteString(`</table:table-row></table:table-header-rows>`)
+	return err
+}
+
+func (e *odsEncoder) Row(values []interface{}) error {
+	if err := checkRow(e.columns, values); err != nil {
+		return err
+	}
+
+	e.content.WriteString(`<table:table-row>`)
+	for i, value := range values {
+		if value == nil {
+			e.content.WriteString(`<table:table-cell/>`)
+			continue
+		}
+
+		columnType := e.columns[i].Type
+		text := formatText(columnType, value)
+		switch columnType {
+		case Int:
+			e.writeCell(`office:value-type="float" office:value="`+text+`"`, text)
+		case Float:
+			number := strconv.FormatFloat(value.(float64), 'f', -1, 64)
+			e.writeCell(`office:value-type="float" office:value="`+number+`" table:style-name="ce3"`, text)
+		case Bool:
+			e.writeCell(`office:value-type="boolean" office:boolean-value="`+strconv.FormatBool(value.(bool))+`"`, text)
+		case Date:
+			e.writeCell(`office:value-type="date" office:date-value="`+value.(time.Time).UTC().Format("2006-01-02")+`" table:style-name="ce1"`, text)
+		case Timestamp:
+			e.writeCell(`office:value-type="date" office:date-value="`+value.(time.Time).UTC().Format("2006-01-02T15:04:05")+`" table:style-name="ce2"`, text)
+		default:
+			e.writeCell(`office:value-type="string"`, text)
+		}
+	}
+	if _, err := e.content.WriteString(`</table:table-row>`); err != nil {
+		return err
+	}
+
+	e.rows++
+	if e.rows%flushRows == 0 {
+		return e.flush()
+	}
+	return nil
+}
+
+func (e *odsEncoder) Close() error {
+	if _, err := e.content.WriteString(`</table:table></office:spreadsheet></office:body></office:document-content>`); err != nil {
+		return err
+	}
+	if err := e.content.Flush(); err != nil {
+		return err
+	}
+	if err := e.zip.Close(); err != nil {
+		return err
+	}
+	return flushOutput(e.out)
+}
+
+func (e *odsEncoder) flush() error {
+	if err := e.content.Flush(); err != nil {
+		return err
+	}
+	if err := e.zip.Flush(); err != nil {
+		return err
+	}
+	return flushOutput(e.out)
+}
+
+// writeCell writes a cell with the given attributes and displayed text
+func (e *odsEncoder) writeCell(attributes, text string) {
+	e.content.WriteString(`<table:table-cell ` + attributes + `><text:p>`)
+	xml.EscapeText(e.content, []byte(text))
+	e.content.WriteString(`</text:p></table:table-cell>`)
+}
@@ -0,0 +1,167 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"open_data_service/models"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// odsTestContent is the part of content.xml read by the tests
+type odsTestContent struct {
+	Tables []struct {
+		Name   string       `xml:"name,attr"`
+		Header []odsTestRow `xml:"table-header-rows>table-row"`
+		Rows   []odsTestRow `xml:"table-row"`
+	} `xml:"body>spreadsheet>table"`
+}
+
+type odsTestRow struct {
+	Cells []odsTestCell `xml:"table-cell"`
+}
+
+type odsTestCell struct {
+	ValueType    string `xml:"value-type,attr"`
+	Value        string `xml:"value,attr"`
+	BooleanValue string `xml:"boolean-value,attr"`
+	DateValue    string `xml:"date-value,attr"`
+	StyleName    string `xml:"style-name,attr"`
+	Text         string `xml:"p"`
+}
+
+// readODS returns the table name, the header titles and the typed values of the data rows
+// Number cells with the decimal style are floats and date cells with the timestamp style are timestamps
+func readODS(t *testing.T, data []byte, columns []Column) (string, []string, [][]interface{}) {
+	t.Helper()
+
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The mimetype must be the first file and stored uncompressed, so the type can be read at a fixed offset
+	if len(archive.File) == 0 || archive.File[0].Name != "mimetype" || archive.File[0].Method != zip.Store {
+		t.Fatal("mimetype is not the first, uncompressed file")
+	}
+	if mimeType := string(readZipFile(t, archive, "mimetype")); mimeType != "application/vnd.oasis.opendocument.spreadsheet" {
+		t.Errorf("got mimetype %q", mimeType)
+	}
+	if !bytes.HasPrefix(data[30:], []byte("mimetypeapplication/vnd.oasis.opendocument.spreadsheet")) {
+		t.Error("mimetype is not at offset 30")
+	}
+	if err := xml.Unmarshal(readZipFile(t, archive, "META-INF/manifest.xml"), new(struct{})); err != nil {
+		t.Errorf("manifest is not valid XML: %v", err)
+	}
+
+	var content odsTestContent
+	if err := xml.Unmarshal(readZipFile(t, archive, "content.xml"), &content); err != nil {
+		t.Fatal(err)
+	}
+	if len(content.Tables) != 1 || len(content.Tables[0].Header) != 1 {
+		t.Fatal("content has no table with one header row")
+	}
+	table := content.Tables[0]
+
+	var header []string
+	for _, cell := range table.Header[0].Cells {
+		if cell.ValueType != "string" || cell.StyleName != "ce4" {
+			t.Errorf("header cell %q: type %q, style %q", cell.Text, cell.ValueType, cell.StyleName)
+		}
+		header = append(header, cell.Text)
+	}
+
+	var rows [][]interface{}
+	for i, row := range table.Rows {
+		if len(row.Cells) != len(columns) {
+			t.Fatalf("row %d has %d cells, want %d", i, len(row.Cells), len(columns))
+		}
+
+		values := make([]interface{}, len(columns))
+		for j, cell := range row.Cells {
+			var err error
+			switch cell.ValueType {
+			case "":
+				if cell.Text != "" {
+					t.Errorf("row %d, column %d: empty cell has text %q", i, j, cell.Text)
+				}
+			case "string":
+				values[j] = cell.Text
+			case "boolean":
+				values[j], err = strconv.ParseBool(cell.BooleanValue)
+			case "float":
+				if cell.StyleName == "ce3" {
+					values[j], err = strconv.ParseFloat(cell.Value, 64)
+				} else {
+					values[j], err = strconv.Atoi(cell.Value)
+				}
+			case "date":
+				switch cell.StyleName {
+				case "ce1":
+					values[j], err = time.Parse("2006-01-02", cell.DateValue)
+				case "ce2":
+					values[j], err = time.Parse("2006-01-02T15:04:05", cell.DateValue)
+				default:
+					t.Fatalf("row %d, column %d: unexpected date style %q", i, j, cell.StyleName)
+				}
+			default:
+				t.Fatalf("row %d, column %d: unexpected value type %q", i, j, cell.ValueType)
+			}
+			if err != nil {
+				t.Fatalf("row %d, column %d: %v", i, j, err)
+			}
+		}
+		rows = append(rows, values)
+	}
+
+	return table.Name, header, rows
+}
+
+func TestODSRoundTrip(t *testing.T) {
+	data := encode(t, models.ExportFormatODS, "accepted-applications", testColumns, testRows)
+	tableName, header, rows := readODS(t, data, testColumns)
+
+	if tableName != "accepted-applications" {
+		t.Errorf("got table name %q", tableName)
+	}
+	for i, column := range testColumns {
+		if header[i] != column.Title {
+			t.Errorf("header %d: got %q, want %q", i, header[i], column.Title)
+		}
+	}
+	// ODS dates have no fractions of a second
+	compareRows(t, rows, expectedRows(testColumns, testRows, time.Second))
+}
+
+func TestODSDisplayedText(t *testing.T) {
+	data := encode(t, models.ExportFormatODS, "test", testColumns, testRows[:1])
+
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var content odsTestContent
+	if err := xml.Unmarshal(readZipFile(t, archive, "content.xml"), &content); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"Dom Košutnjak & <Blok 1>", "42", "8.75", "da", "2024-03-15", "2024-03-15 12:00:00"}
+	for i, cell := range content.Tables[0].Rows[0].Cells {
+		if cell.Text != want[i] {
+			t.Errorf("column %d: got text %q, want %q", i, cell.Text, want[i])
+		}
+	}
+}
+
+func TestODSEmptyDataset(t *testing.T) {
+	_, header, rows := readODS(t, encode(t, models.ExportFormatODS, "test", testColumns, nil), testColumns)
+
+	if len(header) != len(testColumns) {
+		t.Errorf("got %d header cells, want %d", len(header), len(testColumns))
+	}
+	if len(rows) != 0 {
+		t.Errorf("got %d data rows, want none", len(rows))
+	}
+}
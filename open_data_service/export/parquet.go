@@ -0,0 +1,332 @@
+package export
+
+import (
+	"encoding/binary"
+	"io"
+	"math"
+	"time"
+
+	"github.com/golang/snappy"
+)
+
+// parquetRowGroupRows is the number of rows buffered before they are written as a row group,
+// so memory use does not depend on the size of the dataset
+const parquetRowGroupRows = 10000
+
+const parquetMagic = "PAR1"
+
+// Values from the Parquet format specification (parquet.thrift)
+const (
+	parquetBoolean   = 0
+	parquetInt32     = 1
+	parquetInt64     = 2
+	parquetDouble    = 5
+	parquetByteArray = 6
+
+	parquetOptional = 1
+
+	parquetUTF8            = 0
+	parquetDate            = 6
+	parquetTimestampMillis = 9
+
+	parquetPlain = 0
+	parquetRLE   = 3
+
+	parquetSnappy = 1
+
+	parquetDataPage = 0
+)
+
+// parquetColumnChunk buffers the values of one column in the current row group
+type parquetColumnChunk struct {
+	defined []bool // Definition level of every row: false is null
+	values  []byte // PLAIN encoded values that are not null; booleans are kept in bools
+	bools   []bool
+}
+
+// parquetChunkMetadata describes a column chunk that was written
+type parquetChunkMetadata struct {
+	offset           int64
+	numValues        int64
+	uncompressedSize int64
+	compressedSize   int64
+}
+
+// parquetRowGroup describes a row group that was written
+type parquetRowGroup struct {
+	chunks  []parquetChunkMetadata
+	numRows int64
+}
+
+// parquetEncoder writes a dataset as an Apache Parquet file with one optional column per dataset column
+// Rows are written in row groups of parquetRowGroupRows rows, each column as one snappy compressed data page
+type parquetEncoder struct {
+	out       io.Writer
+	offset    int64
+	columns   []Column
+	chunks    []parquetColumnChunk
+	rows      int
+	rowGroups []parquetRowGroup
+}
+
+func newParquetEncoder(w io.Writer, _ string) Encoder {
+	return &parquetEncoder{out: w}
+}
+
+// Columns only prepares the column buffers; the file starts with the first row group,
+// so nothing is written before a query of the dataset can still fail
+func (e *parquetEncoder) Columns(columns []Column) error {
+	e.columns = columns
+	e.chunks = make([]parquetColumnChunk, len(columns))
+	return nil
+}
+
+func (e *parquetEncoder) Row(values []interface{}) error {
+	if err := checkRow(e.columns, values); err != nil {
+		return err
+	}
+
+	for i, value := range values {
+		chunk := &e.chunks[i]
+		chunk.defined = append(chunk.defined, value != nil)
+		if value == nil {
+			continue
+		}
+
+		switch e.columns[i].Type {
+		case Int:
+			chunk.values = binary.LittleEndian.AppendUint64(chunk.values, uint64(value.(int)))
+		case Float:
+			chunk.values = binary.LittleEndian.AppendUint64(chunk.values, math.Float64bits(value.(float64)))
+		case Bool:
+			chunk.bools = append(chunk.bools, value.(bool))
+		case Date:
+			// Days since 1970-01-01, rounded down for times before it
+			seconds := value.(time.Time).Unix()
+			days := seconds / (24 * 60 * 60)
+			if seconds%(24*60*60) < 0 {
+				days--
+			}
+			chunk.values = binary.LittleEndian.AppendUint32(chunk.values, uint32(int32(days)))
+		case Timestamp:
+			chunk.values = binary.LittleEndian.AppendUint64(chunk.values, uint64(value.(time.Time).UnixMilli()))
+		default:
+			text := value.(string)
+			chunk.values = binary.LittleEndian.AppendUint32(chunk.values, uint32(len(text)))
+			chunk.values = append(chunk.values, text...)
+		}
+	}
+
+	e.rows++
+	if e.rows == parquetRowGroupRows {
+		return e.writeRowGroup()
+	}
+	return nil
+}
+
+func (e *parquetEncoder) Close() error {
+	if e.rows > 0 {
+		if err := e.writeRowGroup(); err != nil {
+			return err
+		}
+	}
+
+	if err := e.begin(); err != nil {
+		return err
+	}
+
+	metadata := e.fileMetadata()
+	footer := binary.LittleEndian.AppendUint32(metadata, uint32(len(metadata)))
+	if err := e.write(append(footer, parquetMagic...)); err != nil {
+		return err
+	}
+	return flushOutput(e.out)
+}
+
+// writeRowGroup writes the buffered rows as a row group with one data page per column
+func (e *parquetEncoder) writeRowGroup() error {
+	if err := e.begin(); err != nil {
+		return err
+	}
+
+	rowGroup := parquetRowGroup{numRows: int64(e.rows)}
+
+	for i := range e.chunks {
+		chunk := &e.chunks[i]
+
+		// Data page: definition levels (prefixed with their length), then the PLAIN encoded values
+		levels := encodeDefinitionLevels(chunk.defined)
+		page := binary.LittleEndian.AppendUint32(nil, uint32(len(levels)))
+		page = append(page, levels...)
+		if e.columns[i].Type == Bool {
+			page = append(page, packBools(chunk.bools)...)
+		} else {
+			page = append(page, chunk.values...)
+		}
+		compressed := snappy.Encode(nil, page)
+
+		header := newThriftWriter()
+		header.I32Field(1, parquetDataPage)
+		header.I32Field(2, int32(len(page)))
+		header.I32Field(3, int32(len(compressed)))
+		header.StructField(5)
+		header.I32Field(1, int32(len(chunk.defined)))
+		header.I32Field(2, parquetPlain)
+		header.I32Field(3, parquetRLE)
+		header.I32Field(4, parquetRLE)
+		header.EndStruct()
+		header.EndStruct()
+
+		metadata := parquetChunkMetadata{
+			offset:           e.offset,
+			numValues:        int64(len(chunk.defined)),
+			uncompressedSize: int64(len(header.Bytes()) + len(page)),
+			compressedSize:   int64(len(header.Bytes()) + len(compressed)),
+		}
+		if err := e.write(header.Bytes()); err != nil {
+			return err
+		}
+		if err := e.write(compressed); err != nil {
+			return err
+		}
+		rowGroup.chunks = append(rowGroup.chunks, metadata)
+
+		chunk.defined = chunk.defined[:0]
+		chunk.values = chunk.values[:0]
+		chunk.bools = chunk.bools[:0]
+	}
+
+	e.rowGroups = append(e.rowGroups, rowGroup)
+	e.rows = 0
+	return flushOutput(e.out)
+}
+
+// fileMetadata encodes the FileMetaData struct with the schema and the written row groups
+func (e *parquetEncoder) fileMetadata() []byte {
+	var numRows int64
+	for _, rowGroup := range e.rowGroups {
+		numRows += rowGroup.numRows
+	}
+
+	metadata := newThriftWriter()
+	metadata.I32Field(1, 1) // version
+
+	// The schema is a root element followed by one element per column
+	metadata.ListField(2, thriftStruct, len(e.columns)+1)
+	metadata.BeginStruct()
+	metadata.StringField(4, "schema")
+	metadata.I32Field(5, int32(len(e.columns)))
+	metadata.EndStruct()
+	for _, column := range e.columns {
+		physicalType, convertedType := parquetTypes(column.Type)
+
+		metadata.BeginStruct()
+		metadata.I32Field(1, physicalType)
+		metadata.I32Field(3, parquetOptional)
+		metadata.StringField(4, column.Key)
+		if convertedType >= 0 {
+			metadata.I32Field(6, convertedType)
+		}
+		metadata.EndStruct()
+	}
+
+	metadata.I64Field(3, numRows)
+
+	metadata.ListField(4, thriftStruct, len(e.rowGroups))
+	for _, rowGroup := range e.rowGroups {
+		var totalSize int64
+		metadata.BeginStruct()
+		metadata.ListField(1, thriftStruct, len(rowGroup.chunks))
+		for i, chunk := range rowGroup.chunks {
+			physicalType, _ := parquetTypes(e.columns[i].Type)
+			totalSize += chunk.uncompressedSize
+
+			metadata.BeginStruct()
+			metadata.I64Field(2, chunk.offset)
+			metadata.StructField(3)
+			metadata.I32Field(1, physicalType)
+			metadata.ListField(2, thriftI32, 2)
+			metadata.I32Element(parquetPlain)
+			metadata.I32Element(parquetRLE)
+			metadata.ListField(3, thriftBinary, 1)
+			metadata.StringElement(e.columns[i].Key)
+			metadata.I32Field(4, parquetSnappy)
+			metadata.I64Field(5, chunk.numValues)
+			metadata.I64Field(6, chunk.uncompressedSize)
+			metadata.I64Field(7, chunk.compressedSize)
+			metadata.I64Field(9, chunk.offset)
+			metadata.EndStruct()
+			metadata.EndStruct()
+		}
+		metadata.I64Field(2, totalSize)
+		metadata.I64Field(3, rowGroup.numRows)
+		metadata.EndStruct()
+	}
+
+	metadata.StringField(6, "open_data_service")
+	metadata.EndStruct()
+	return metadata.Bytes()
+}
+
+// begin writes the magic number that starts the file, unless it was written already
+func (e *parquetEncoder) begin() error {
+	if e.offset > 0 {
+		return nil
+	}
+	return e.write([]byte(parquetMagic))
+}
+
+// write writes to the output and keeps track of the file offset
+func (e *parquetEncoder) write(p []byte) error {
+	n, err := e.out.Write(p)
+	e.offset += int64(n)
+	return err
+}
+
+// parquetTypes returns the physical and converted (-1 for none) Parquet types of a column type
+func parquetTypes(columnType ColumnType) (int32, int32) {
+	switch columnType {
+	case Int:
+		return parquetInt64, -1
+	case Float:
+		return parquetDouble, -1
+	case Bool:
+		return parquetBoolean, -1
+	case Date:
+		return parquetInt32, parquetDate
+	case Timestamp:
+		return parquetInt64, parquetTimestampMillis
+	}
+	return parquetByteArray, parquetUTF8
+}
+
+// encodeDefinitionLevels encodes the levels with the RLE/bit-packing hybrid encoding, as RLE runs of bit width 1
+func encodeDefinitionLevels(defined []bool) []byte {
+	var encoded []byte
+	for start := 0; start < len(defined); {
+		end := start + 1
+		for end < len(defined) && defined[end] == defined[start] {
+			end++
+		}
+
+		level := byte(0)
+		if defined[start] {
+			level = 1
+		}
+		encoded = binary.AppendUvarint(encoded, uint64(end-start)<<1)
+		encoded = append(encoded, level)
+		start = end
+	}
+	return encoded
+}
+
+// packBools encodes booleans with PLAIN encoding: one bit per value, least significant bit first
+func packBools(values []bool) []byte {
+	packed := make([]byte, (len(values)+7)/8)
+	for i, value := range values {
+		if value {
+			packed[i/8] |= 1 << (i % 8)
+		}
+	}
+	return packed
+}
@@ -0,0 +1,296 @@
+package export
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"open_data_service/models"
+	"testing"
+	"time"
+
+	"github.com/golang/snappy"
+)
+
+// parquetTestColumn is a column of the schema read back from a Parquet file
+type parquetTestColumn struct {
+	name          string
+	physicalType  int64
+	convertedType int64 // -1 for none
+	repetition    int64
+}
+
+// parquetTestFile is a Parquet file read back by readParquet
+type parquetTestFile struct {
+	columns       []parquetTestColumn
+	numRows       int64
+	rowGroupSizes []int64
+	rows          [][]interface{}
+}
+
+// readParquet decodes a Parquet file following the format specification: footer, schema, row groups and
+// their PLAIN encoded, snappy compressed data pages with RLE/bit-packed definition levels
+func readParquet(data []byte) (*parquetTestFile, error) {
+	if len(data) < 12 || string(data[:4]) != "PAR1" || string(data[len(data)-4:]) != "PAR1" {
+		return nil, fmt.Errorf("missing PAR1 magic")
+	}
+	footerSize := int(binary.LittleEndian.Uint32(data[len(data)-8:]))
+	footerStart := len(data) - 8 - footerSize
+	if footerStart < 4 {
+		return nil, fmt.Errorf("footer of %d bytes does not fit the file", footerSize)
+	}
+	metadata, n, err := readThriftStruct(data[footerStart : len(data)-8])
+	if err != nil {
+		return nil, err
+	}
+	if n != footerSize {
+		return nil, fmt.Errorf("footer has %d bytes, the metadata took %d", footerSize, n)
+	}
+
+	file := &parquetTestFile{numRows: metadata[3].(int64)}
+
+	schema := metadata[2].([]interface{})
+	root := schema[0].(map[int16]interface{})
+	if root[5].(int64) != int64(len(schema)-1) {
+		return nil, fmt.Errorf("root has %d children, schema has %d columns", root[5], len(schema)-1)
+	}
+	for _, element := range schema[1:] {
+		fields := element.(map[int16]interface{})
+		column := parquetTestColumn{
+			name:          fields[4].(string),
+			physicalType:  fields[1].(int64),
+			convertedType: -1,
+			repetition:    fields[3].(int64),
+		}
+		if convertedType, ok := fields[6]; ok {
+			column.convertedType = convertedType.(int64)
+		}
+		file.columns = append(file.columns, column)
+	}
+
+	for _, rowGroup := range metadata[4].([]interface{}) {
+		rowGroupFields := rowGroup.(map[int16]interface{})
+		numRows := rowGroupFields[3].(int64)
+		file.rowGroupSizes = append(file.rowGroupSizes, numRows)
+
+		chunks := rowGroupFields[1].([]interface{})
+		if len(chunks) != len(file.columns) {
+			return nil, fmt.Errorf("row group has %d column chunks, schema has %d columns", len(chunks), len(file.columns))
+		}
+
+		rows := make([][]interface{}, numRows)
+		for i := range rows {
+			rows[i] = make([]interface{}, len(file.columns))
+		}
+		for i, chunk := range chunks {
+			values, err := readParquetChunk(data, chunk.(map[int16]interface{}), file.columns[i], numRows)
+			if err != nil {
+				return nil, fmt.Errorf("column %s: %w", file.columns[i].name, err)
+			}
+			for row, value := range values {
+				rows[row][i] = value
+			}
+		}
+		file.rows = append(file.rows, rows...)
+	}
+
+	return file, nil
+}
+
+// readParquetChunk reads the single data page of a column chunk
+func readParquetChunk(data []byte, chunk map[int16]interface{}, column parquetTestColumn, numRows int64) ([]interface{}, error) {
+	metadata := chunk[3].(map[int16]interface{})
+	if metadata[4].(int64) != 1 {
+		return nil, fmt.Errorf("codec %d is not snappy", metadata[4])
+	}
+	if metadata[5].(int64) != numRows {
+		return nil, fmt.Errorf("chunk has %d values, row group has %d rows", metadata[5], numRows)
+	}
+
+	offset := metadata[9].(int64)
+	pageHeader, headerSize, err := readThriftStruct(data[offset:])
+	if err != nil {
+		return nil, err
+	}
+	if pageHeader[1].(int64) != 0 {
+		return nil, fmt.Errorf("page type %d is not a data page", pageHeader[1])
+	}
+	compressedSize := pageHeader[3].(int64)
+	if metadata[7].(int64) != int64(headerSize)+compressedSize {
+		return nil, fmt.Errorf("chunk size %d does not match the page size %d", metadata[7], int64(headerSize)+compressedSize)
+	}
+
+	start := offset + int64(headerSize)
+	page, err := snappy.Decode(nil, data[start:start+compressedSize])
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(page)) != pageHeader[2].(int64) {
+		return nil, fmt.Errorf("page has %d bytes, the header says %d", len(page), pageHeader[2])
+	}
+
+	dataPageHeader := pageHeader[5].(map[int16]interface{})
+	if dataPageHeader[1].(int64) != numRows || dataPageHeader[2].(int64) != 0 {
+		return nil, fmt.Errorf("unexpected data page header %v", dataPageHeader)
+	}
+
+	levelsSize := int(binary.LittleEndian.Uint32(page))
+	defined, err := decodeLevels(page[4:4+levelsSize], int(numRows))
+	if err != nil {
+		return nil, err
+	}
+
+	values := page[4+levelsSize:]
+	result := make([]interface{}, numRows)
+	bit := 0
+	for i := range result {
+		if !defined[i] {
+			continue
+		}
+
+		switch column.physicalType {
+		case 0: // BOOLEAN, bit-packed
+			result[i] = values[bit/8]&(1<<(bit%8)) != 0
+			bit++
+			continue
+		case 1: // INT32
+			value := int32(binary.LittleEndian.Uint32(values))
+			values = values[4:]
+			if column.convertedType != 6 {
+				return nil, fmt.Errorf("INT32 column without DATE type")
+			}
+			result[i] = time.Unix(int64(value)*24*60*60, 0).UTC()
+		case 2: // INT64
+			value := int64(binary.LittleEndian.Uint64(values))
+			values = values[8:]
+			if column.convertedType == 9 {
+				result[i] = time.UnixMilli(value).UTC()
+			} else {
+				result[i] = int(value)
+			}
+		case 5: // DOUBLE
+			result[i] = math.Float64frombits(binary.LittleEndian.Uint64(values))
+			values = values[8:]
+		case 6: // BYTE_ARRAY
+			size := binary.LittleEndian.Uint32(values)
+			result[i] = string(values[4 : 4+size])
+			values = values[4+size:]
+		default:
+			return nil, fmt.Errorf("unsupported physical type %d", column.physicalType)
+		}
+	}
+
+	if column.physicalType == 0 {
+		values = values[(bit+7)/8:]
+	}
+	if len(values) != 0 {
+		return nil, fmt.Errorf("%d bytes left after the values", len(values))
+	}
+	return result, nil
+}
+
+// decodeLevels decodes definition levels of bit width 1 written with the RLE/bit-packing hybrid encoding
+func decodeLevels(data []byte, count int) ([]bool, error) {
+	levels := make([]bool, 0, count)
+	for len(data) > 0 {
+		header, n := binary.Uvarint(data)
+		if n <= 0 {
+			return nil, fmt.Errorf("invalid run header")
+		}
+		data = data[n:]
+
+		if header&1 == 0 {
+			// RLE run: the value in one byte
+			for i := uint64(0); i < header>>1; i++ {
+				levels = append(levels, data[0] == 1)
+			}
+			data = data[1:]
+		} else {
+			// Bit-packed run: groups of 8 values, one byte per group
+			groups := int(header >> 1)
+			for i := 0; i < groups*8; i++ {
+				levels = append(levels, data[i/8]&(1<<(i%8)) != 0)
+			}
+			data = data[groups:]
+		}
+	}
+
+	if len(levels) < count {
+		return nil, fmt.Errorf("got %d levels, want %d", len(levels), count)
+	}
+	return levels[:count], nil
+}
+
+func TestParquetRoundTrip(t *testing.T) {
+	file, err := readParquet(encode(t, models.ExportFormatParquet, "test", testColumns, testRows))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wantColumns := []parquetTestColumn{
+		{name: "ime", physicalType: 6, convertedType: 0, repetition: 1},
+		{name: "broj", physicalType: 2, convertedType: -1, repetition: 1},
+		{name: "prosek", physicalType: 5, convertedType: -1, repetition: 1},
+		{name: "aktivna", physicalType: 0, convertedType: -1, repetition: 1},
+		{name: "datum", physicalType: 1, convertedType: 6, repetition: 1},
+		{name: "vreme", physicalType: 2, convertedType: 9, repetition: 1},
+	}
+	if len(file.columns) != len(wantColumns) {
+		t.Fatalf("got %d columns, want %d", len(file.columns), len(wantColumns))
+	}
+	for i, column := range wantColumns {
+		if file.columns[i] != column {
+			t.Errorf("column %d: got %+v, want %+v", i, file.columns[i], column)
+		}
+	}
+
+	if file.numRows != int64(len(testRows)) {
+		t.Errorf("got %d rows in the metadata, want %d", file.numRows, len(testRows))
+	}
+	compareRows(t, file.rows, expectedRows(testColumns, testRows, time.Millisecond))
+}
+
+func TestParquetRowGroups(t *testing.T) {
+	columns := []Column{
+		{Key: "broj", Title: "Broj", Type: Int},
+		{Key: "aktivna", Title: "Aktivna", Type: Bool},
+	}
+	rows := make([][]interface{}, parquetRowGroupRows+3)
+	for i := range rows {
+		rows[i] = []interface{}{i, i%3 == 0}
+		if i%7 == 0 {
+			rows[i][1] = nil
+		}
+	}
+
+	file, err := readParquet(encode(t, models.ExportFormatParquet, "test", columns, rows))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(file.rowGroupSizes) != 2 || file.rowGroupSizes[0] != parquetRowGroupRows || file.rowGroupSizes[1] != 3 {
+		t.Errorf("got row groups of %v rows, want [%d 3]", file.rowGroupSizes, parquetRowGroupRows)
+	}
+	if file.numRows != int64(len(rows)) {
+		t.Errorf("got %d rows in the metadata, want %d", file.numRows, len(rows))
+	}
+	compareRows(t, file.rows, rows)
+}
+
+func TestParquetEmptyDataset(t *testing.T) {
+	data := encode(t, models.ExportFormatParquet, "test", testColumns, nil)
+	if !bytes.HasPrefix(data, []byte("PAR1")) {
+		t.Fatal("empty file does not start with PAR1")
+	}
+
+	file, err := readParquet(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(file.columns) != len(testColumns) {
+		t.Errorf("got %d columns, want %d", len(file.columns), len(testColumns))
+	}
+	if file.numRows != 0 || len(file.rowGroupSizes) != 0 || len(file.rows) != 0 {
+		t.Errorf("got %d rows in %d row groups, want none", file.numRows, len(file.rowGroupSizes))
+	}
+}
@@ -0,0 +1,106 @@
+package export
+
+import "encoding/binary"
+
+// Types of the Thrift compact protocol
+const (
+	thriftI32    = 5
+	thriftI64    = 6
+	thriftBinary = 8
+	thriftList   = 9
+	thriftStruct = 12
+)
+
+// thriftWriter encodes Thrift structs with the compact protocol, the encoding of the Parquet metadata
+// Fields of a struct must be written in ascending order of their IDs
+type thriftWriter struct {
+	buf     []byte
+	lastIDs []int16 // ID of the last field written in every open struct
+}
+
+func newThriftWriter() *thriftWriter {
+	return &thriftWriter{lastIDs: []int16{0}}
+}
+
+// field writes a field header; the ID is stored as the difference to the previous field when it is small
+func (t *thriftWriter) field(id int16, fieldType byte) {
+	last := &t.lastIDs[len(t.lastIDs)-1]
+	if delta := id - *last; delta > 0 && delta <= 15 {
+		t.buf = append(t.buf, byte(delta)<<4|fieldType)
+	} else {
+		t.buf = append(t.buf, fieldType)
+		t.buf = binary.AppendUvarint(t.buf, uint64(uint16((id<<1)^(id>>15))))
+	}
+	*last = id
+}
+
+func (t *thriftWriter) i32(value int32) {
+	t.buf = binary.AppendUvarint(t.buf, uint64(uint32((value<<1)^(value>>31))))
+}
+
+func (t *thriftWriter) i64(value int64) {
+	t.buf = binary.AppendUvarint(t.buf, uint64((value<<1)^(value>>63)))
+}
+
+func (t *thriftWriter) binary(value string) {
+	t.buf = binary.AppendUvarint(t.buf, uint64(len(value)))
+	t.buf = append(t.buf, value...)
+}
+
+func (t *thriftWriter) I32Field(id int16, value int32) {
+	t.field(id, thriftI32)
+	t.i32(value)
+}
+
+func (t *thriftWriter) I64Field(id int16, value int64) {
+	t.field(id, thriftI64)
+	t.i64(value)
+}
+
+func (t *thriftWriter) StringField(id int16, value string) {
+	t.field(id, thriftBinary)
+	t.binary(value)
+}
+
+// ListField starts a list field; the caller writes the size elements after it
+func (t *thriftWriter) ListField(id int16, elementType byte, size int) {
+	t.field(id, thriftList)
+	if size < 15 {
+		t.buf = append(t.buf, byte(size)<<4|elementType)
+	} else {
+		t.buf = append(t.buf, 0xf0|elementType)
+		t.buf = binary.AppendUvarint(t.buf, uint64(size))
+	}
+}
+
+func (t *thriftWriter) I32Element(value int32) {
+	t.i32(value)
+}
+
+func (t *thriftWriter) StringElement(value string) {
+	t.binary(value)
+}
+
+// StructField starts a struct field; it is ended with EndStruct
+func (t *thriftWriter) StructField(id int16) {
+	t.field(id, thriftStruct)
+	t.BeginStruct()
+}
+
+// BeginStruct starts a struct that is a list element
+func (t *thriftWriter) BeginStruct() {
+	t.lastIDs = append(t.lastIDs, 0)
+}
+
+// EndStruct ends the innermost open struct, or the top-level struct
+func (t *thriftWriter) EndStruct() {
+	t.buf = append(t.buf, 0)
+	if len(t.lastIDs) > 1 {
+		t.lastIDs = t.lastIDs[:len(t.lastIDs)-1]
+	}
+}
+
+// Bytes returns the encoded struct
+func (t *thriftWriter) Bytes() []byte {
+	return t.buf
+}
@@ -0,0 +1,218 @@
+package export
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"reflect"
+	"testing"
+)
+
+// thriftReader decodes Thrift compact protocol structs into maps from field ID to value
+// It is written from the protocol specification, independently of thriftWriter, so the tests can read what the encoders write
+// Integers are decoded as int64, binaries as string, lists as []interface{} and structs as map[int16]interface{}
+type thriftReader struct {
+	buf []byte
+	pos int
+	err error
+}
+
+func (r *thriftReader) fail(format string, args ...interface{}) {
+	if r.err == nil {
+		r.err = fmt.Errorf("offset %d: %s", r.pos, fmt.Sprintf(format, args...))
+	}
+}
+
+func (r *thriftReader) byte() byte {
+	if r.err != nil {
+		return 0
+	}
+	if r.pos >= len(r.buf) {
+		r.fail("unexpected end of data")
+		return 0
+	}
+	b := r.buf[r.pos]
+	r.pos++
+	return b
+}
+
+func (r *thriftReader) uvarint() uint64 {
+	if r.err != nil {
+		return 0
+	}
+	value, n := binary.Uvarint(r.buf[r.pos:])
+	if n <= 0 {
+		r.fail("invalid varint")
+		return 0
+	}
+	r.pos += n
+	return value
+}
+
+func (r *thriftReader) zigzag() int64 {
+	value := r.uvarint()
+	return int64(value>>1) ^ -int64(value&1)
+}
+
+// Struct reads a struct up to and including its stop field
+func (r *thriftReader) Struct() map[int16]interface{} {
+	fields := map[int16]interface{}{}
+	var last int16
+	for r.err == nil {
+		header := r.byte()
+		if header == 0 {
+			break
+		}
+
+		id := last + int16(header>>4)
+		if header>>4 == 0 {
+			id = int16(r.zigzag())
+		}
+		last = id
+
+		fieldType := header & 0x0f
+		switch fieldType {
+		case 1, 2: // Boolean fields keep their value in the type
+			fields[id] = fieldType == 1
+		default:
+			fields[id] = r.value(fieldType)
+		}
+	}
+	return fields
+}
+
+func (r *thriftReader) value(valueType byte) interface{} {
+	switch valueType {
+	case 1, 2: // Boolean list elements are one byte each
+		return r.byte() == 1
+	case 3:
+		return int64(int8(r.byte()))
+	case 4, 5, 6:
+		return r.zigzag()
+	case 7:
+		if r.pos+8 > len(r.buf) {
+			r.fail("unexpected end of data")
+			return nil
+		}
+		value := math.Float64frombits(binary.LittleEndian.Uint64(r.buf[r.pos:]))
+		r.pos += 8
+		return value
+	case 8:
+		size := int(r.uvarint())
+		if r.err != nil || r.pos+size > len(r.buf) {
+			r.fail("binary of %d bytes exceeds the data", size)
+			return nil
+		}
+		value := string(r.buf[r.pos : r.pos+size])
+		r.pos += size
+		return value
+	case 9, 10:
+		header := r.byte()
+		size := int(header >> 4)
+		if size == 15 {
+			size = int(r.uvarint())
+		}
+		elements := make([]interface{}, 0, size)
+		for i := 0; i < size && r.err == nil; i++ {
+			elements = append(elements, r.value(header&0x0f))
+		}
+		return elements
+	case 12:
+		return r.Struct()
+	}
+
+	r.fail("unsupported type %d", valueType)
+	return nil
+}
+
+// readThriftStruct decodes one struct from the start of data and returns it with the number of bytes it took
+func readThriftStruct(data []byte) (map[int16]interface{}, int, error) {
+	r := &thriftReader{buf: data}
+	fields := r.Struct()
+	return fields, r.pos, r.err
+}
+
+func TestThriftWriterEncoding(t *testing.T) {
+	// Examples from the compact protocol specification
+	tests := []struct {
+		name  string
+		write func(w *thriftWriter)
+		want  []byte
+	}{
+		{"short field header", func(w *thriftWriter) { w.I32Field(1, 1) }, []byte{0x15, 0x02, 0x00}},
+		{"negative zigzag", func(w *thriftWriter) { w.I64Field(2, -1) }, []byte{0x26, 0x01, 0x00}},
+		{"long field header", func(w *thriftWriter) { w.I32Field(17, 0) }, []byte{0x05, 0x22, 0x00, 0x00}},
+		{"binary", func(w *thriftWriter) { w.StringField(1, "ab") }, []byte{0x18, 0x02, 'a', 'b', 0x00}},
+		{"short list", func(w *thriftWriter) {
+			w.ListField(1, thriftI32, 2)
+			w.I32Element(1)
+			w.I32Element(-1)
+		}, []byte{0x19, 0x25, 0x02, 0x01, 0x00}},
+	}
+
+	for _, tt := range tests {
+		w := newThriftWriter()
+		tt.write(w)
+		w.EndStruct()
+		if !bytes.Equal(w.Bytes(), tt.want) {
+			t.Errorf("%s: got % x, want % x", tt.name, w.Bytes(), tt.want)
+		}
+	}
+}
+
+func TestThriftWriterRoundTrip(t *testing.T) {
+	names := make([]interface{}, 20)
+
+	w := newThriftWriter()
+	w.I32Field(1, math.MinInt32)
+	w.I64Field(2, 1<<40)
+	w.StringField(20, "šđč")
+	w.ListField(21, thriftBinary, len(names))
+	for i := range names {
+		names[i] = fmt.Sprintf("column_%d", i)
+		w.StringElement(names[i].(string))
+	}
+	w.StructField(22)
+	w.I32Field(3, 7)
+	w.StructField(40)
+	w.I64Field(1, -5)
+	w.EndStruct()
+	w.EndStruct()
+	// The field after a nested struct is relative to the field before it
+	w.I32Field(23, math.MaxInt32)
+	w.ListField(24, thriftStruct, 2)
+	w.BeginStruct()
+	w.StringField(4, "a")
+	w.EndStruct()
+	w.BeginStruct()
+	w.EndStruct()
+	w.EndStruct()
+
+	got, n, err := readThriftStruct(w.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != len(w.Bytes()) {
+		t.Errorf("read %d bytes of %d", n, len(w.Bytes()))
+	}
+
+	want := map[int16]interface{}{
+		1:  int64(math.MinInt32),
+		2:  int64(1 << 40),
+		20: "šđč",
+		21: names,
+		22: map[int16]interface{}{
+			3:  int64(7),
+			40: map[int16]interface{}{1: int64(-5)},
+		},
+		23: int64(math.MaxInt32),
+		24: []interface{}{
+			map[int16]interface{}{4: "a"},
+			map[int16]interface{}{},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %#v, want %#v", got, want)
+	}
+}
@@ -0,0 +1,237 @@
+package export
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// xlsxMaxRows is the number of rows a worksheet can hold, including the header
+const xlsxMaxRows = 1048576
+
+// Cell styles defined in xlsxStyles
+const (
+	xlsxStyleHeader    = 1
+	xlsxStyleDate      = 2
+	xlsxStyleTimestamp = 3
+	xlsxStyleFloat     = 4
+)
+
+// xlsxEpoch is day 0 of spreadsheet dates (Excel counts 1900 as a leap year, so day 60 is skipped)
+var xlsxEpoch = time.Date(1899, time.December, 30, 0, 0, 0, 0, time.UTC)
+
+const xlsxContentTypes = xml.Header + `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+	`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+	`<Default Extension="xml" ContentType="application/xml"/>` +
+	`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+	`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+	`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>` +
+	`</Types>`
+
+const xlsxRootRelationships = xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+	`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+	`</Relationships>`
+
+const xlsxWorkbookRelationships = xml.Header + `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+	`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+	`<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>` +
+	`</Relationships>`
+
+// xlsxStyles defines the cell styles: bold header, ISO dates and timestamps, and two decimal places
+const xlsxStyles = xml.Header + `<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+	`<numFmts count="2"><numFmt numFmtId="164" formatCode="yyyy\-mm\-dd"/><numFmt numFmtId="165" formatCode="yyyy\-mm\-dd\ hh:mm:ss"/></numFmts>` +
+	`<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts>` +
+	`<fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills>` +
+	`<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>` +
+	`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>` +
+	`<cellXfs count="5">` +
+	`<xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/>` +
+	`<xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/>` +
+	`<xf numFmtId="164" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+	`<xf numFmtId="165" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+	`<xf numFmtId="2" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+	`</cellXfs>` +
+	`<cellStyles count="1"><cellStyle name="Normal" xfId="0" builtinId="0"/></cellStyles>` +
+	`</styleSheet>`
+
+// xlsxEncoder writes a dataset as an Excel workbook with one worksheet
+// Strings are written inline instead of in a shared string table, so rows can be written as they arrive
+type xlsxEncoder struct {
+	zip     *zip.Writer
+	sheet   *bufio.Writer
+	out     io.Writer
+	dataset string
+	columns []Column
+	rows    int
+}
+
+func newXLSXEncoder(w io.Writer, dataset string) Encoder {
+	return &xlsxEncoder{zip: zip.NewWriter(w), out: w, dataset: dataset}
+}
+
+func (e *xlsxEncoder) Columns(columns []Column) error {
+	e.columns = columns
+
+	// Sheet names are limited to 31 characters
+	sheetName := []rune(e.dataset)
+	if len(sheetName) > 31 {
+		sheetName = sheetName[:31]
+	}
+	workbook := xml.Header + `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+		`<sheets><sheet name="` + escapeXML(string(sheetName)) + `" sheetId="1" r:id="rId1"/></sheets></workbook>`
+
+	parts := []struct{ name, content string }{
+		{"[Content_Types].xml", xlsxContentTypes},
+		{"_rels/.rels", xlsxRootRelationships},
+		{"xl/workbook.xml", workbook},
+		{"xl/_rels/workbook.xml.rels", xlsxWorkbookRelationships},
+		{"xl/styles.xml", xlsxStyles},
+	}
+	for _, part := range parts {
+		if err := writeZipFile(e.zip, part.name, part.content); err != nil {
+			return err
+		}
+	}
+
+	sheet, err := e.zip.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return err
+	}
+	e.sheet = bufio.NewWriter(sheet)
+
+	// The header row stays visible while scrolling
+	e.sheet.WriteString(xml.Header + `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+		`<sheetViews><sheetView workbookViewId="0"><pane ySplit="1" topLeftCell="A2" activePane="bottomLeft" state="frozen"/></sheetView></sheetViews>` +
+		`<sheetData><row r="1">`)
+	for i, column := range columns {
+		e.writeString(i, column.Title, xlsxStyleHeader)
+	}
+	_, err = e.sheet.WriteString("</row>")
+	return err
+}
+
+func (e *xlsxEncoder) Row(values []interface{}) error {
+	if err := checkRow(e.columns, values); err != nil {
+		return err
+	}
+	if e.rows+2 > xlsxMaxRows {
+		return fmt.Errorf("dataset has more than %d rows, the limit of an XLSX worksheet", xlsxMaxRows-1)
+	}
+
+	e.rows++
+	e.sheet.WriteString(`<row r="` + strconv.Itoa(e.rows+1) + `">`)
+	for i, value := range values {
+		if value == nil {
+			continue
+		}
+
+		switch e.columns[i].Type {
+		case Int:
+			e.writeNumber(i, strconv.Itoa(value.(int)), 0)
+		case Float:
+			e.writeNumber(i, strconv.FormatFloat(value.(float64), 'f', -1, 64), xlsxStyleFloat)
+		case Bool:
+			boolean := "0"
+			if value.(bool) {
+				boolean = "1"
+			}
+			e.sheet.WriteString(`<c r="` + e.cellRef(i) + `" t="b"><v>` + boolean + `</v></c>`)
+		case Date:
+			day := value.(time.Time).UTC().Truncate(24 * time.Hour)
+			e.writeNumber(i, strconv.FormatFloat(xlsxSerial(day), 'f', -1, 64), xlsxStyleDate)
+		case Timestamp:
+			e.writeNumber(i, strconv.FormatFloat(xlsxSerial(value.(time.Time)), 'f', -1, 64), xlsxStyleTimestamp)
+		default:
+			e.writeString(i, value.(string), 0)
+		}
+	}
+	if _, err := e.sheet.WriteString("</row>"); err != nil {
+		return err
+	}
+
+	if e.rows%flushRows == 0 {
+		return e.flush()
+	}
+	return nil
+}
+
+func (e *xlsxEncoder) Close() error {
+	if _, err := e.sheet.WriteString("</sheetData></worksheet>"); err != nil {
+		return err
+	}
+	if err := e.sheet.Flush(); err != nil {
+		return err
+	}
+	if err := e.zip.Close(); err != nil {
+		return err
+	}
+	return flushOutput(e.out)
+}
+
+func (e *xlsxEncoder) flush() error {
+	if err := e.sheet.Flush(); err != nil {
+		return err
+	}
+	if err := e.zip.Flush(); err != nil {
+		return err
+	}
+	return flushOutput(e.out)
+}
+
+// writeString writes an inline string cell
+func (e *xlsxEncoder) writeString(column int, value string, style int) {
+	e.sheet.WriteString(`<c r="` + e.cellRef(column) + `" t="inlineStr"`)
+	if style != 0 {
+		e.sheet.WriteString(` s="` + strconv.Itoa(style) + `"`)
+	}
+	e.sheet.WriteString(`><is><t xml:space="preserve">`)
+	xml.EscapeText(e.sheet, []byte(value))
+	e.sheet.WriteString(`</t></is></c>`)
+}
+
+// writeNumber writes a numeric cell; dates are numbers with a date style
+func (e *xlsxEncoder) writeNumber(column int, value string, style int) {
+	e.sheet.WriteString(`<c r="` + e.cellRef(column) + `"`)
+	if style != 0 {
+		e.sheet.WriteString(` s="` + strconv.Itoa(style) + `"`)
+	}
+	e.sheet.WriteString(`><v>` + value + `</v></c>`)
+}
+
+// cellRef returns the reference of a cell in the current row, e.g. "C12"
+func (e *xlsxEncoder) cellRef(column int) string {
+	name := ""
+	for column++; column > 0; column = (column - 1) / 26 {
+		name = string(rune('A'+(column-1)%26)) + name
+	}
+	return name + strconv.Itoa(e.rows+1)
+}
+
+// xlsxSerial converts a time to a spreadsheet date: days since xlsxEpoch, the time of day as the fraction
+// Seconds are counted with Unix times, since a Duration cannot span more than 292 years
+func xlsxSerial(t time.Time) float64 {
+	seconds := float64(t.Unix()-xlsxEpoch.Unix()) + float64(t.Nanosecond())/1e9
+	return seconds / (24 * 60 * 60)
+}
+
+// writeZipFile adds a compressed file with the given content to the archive
+func writeZipFile(archive *zip.Writer, name, content string) error {
+	file, err := archive.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(file, content)
+	return err
+}
+
+// escapeXML escapes text for use in XML content or attribute values
+func escapeXML(text string) string {
+	var escaped strings.Builder
+	xml.EscapeText(&escaped, []byte(text))
+	return escaped.String()
+}
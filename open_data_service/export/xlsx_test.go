@@ -0,0 +1,205 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"open_data_service/models"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// xlsxTestWorksheet is the part of xl/worksheets/sheet1.xml read by the tests
+type xlsxTestWorksheet struct {
+	Rows []struct {
+		R     int            `xml:"r,attr"`
+		Cells []xlsxTestCell `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+type xlsxTestCell struct {
+	Ref    string `xml:"r,attr"`
+	Type   string `xml:"t,attr"`
+	Style  int    `xml:"s,attr"`
+	Value  string `xml:"v"`
+	Inline string `xml:"is>t"`
+}
+
+// readZipFile returns the content of a file in the archive
+func readZipFile(t *testing.T, archive *zip.Reader, name string) []byte {
+	t.Helper()
+
+	file, err := archive.Open(name)
+	if err != nil {
+		t.Fatalf("%s: %v", name, err)
+	}
+	defer file.Close()
+
+	content, err := io.ReadAll(file)
+	if err != nil {
+		t.Fatalf("%s: %v", name, err)
+	}
+	return content
+}
+
+// readXLSX returns the sheet name, the header titles and the typed values of the data rows
+// Dates and timestamps are converted from spreadsheet serial numbers (days since 1899-12-30) using the cell style
+func readXLSX(t *testing.T, data []byte, columns []Column) (string, []string, [][]interface{}) {
+	t.Helper()
+
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"[Content_Types].xml", "_rels/.rels", "xl/_rels/workbook.xml.rels", "xl/styles.xml"} {
+		if err := xml.Unmarshal(readZipFile(t, archive, name), new(struct{})); err != nil {
+			t.Errorf("%s is not valid XML: %v", name, err)
+		}
+	}
+
+	var workbook struct {
+		Sheets []struct {
+			Name string `xml:"name,attr"`
+		} `xml:"sheets>sheet"`
+	}
+	if err := xml.Unmarshal(readZipFile(t, archive, "xl/workbook.xml"), &workbook); err != nil {
+		t.Fatal(err)
+	}
+	if len(workbook.Sheets) != 1 {
+		t.Fatalf("got %d sheets, want 1", len(workbook.Sheets))
+	}
+
+	var sheet xlsxTestWorksheet
+	if err := xml.Unmarshal(readZipFile(t, archive, "xl/worksheets/sheet1.xml"), &sheet); err != nil {
+		t.Fatal(err)
+	}
+	if len(sheet.Rows) == 0 {
+		t.Fatal("worksheet has no header row")
+	}
+
+	epoch := time.Date(1899, time.December, 30, 0, 0, 0, 0, time.UTC)
+	var header []string
+	var rows [][]interface{}
+	for i, row := range sheet.Rows {
+		if row.R != i+1 {
+			t.Fatalf("row %d has number %d", i+1, row.R)
+		}
+
+		values := make([]interface{}, len(columns))
+		for _, cell := range row.Cells {
+			column := 0
+			for _, letter := range cell.Ref[:len(cell.Ref)-len(strconv.Itoa(row.R))] {
+				column = column*26 + int(letter-'A'+1)
+			}
+			column--
+			if cell.Ref != cellName(column, row.R) || column >= len(columns) {
+				t.Fatalf("unexpected cell %s in row %d", cell.Ref, row.R)
+			}
+
+			switch {
+			case i == 0:
+				if cell.Type != "inlineStr" || cell.Style != xlsxStyleHeader {
+					t.Errorf("header cell %s: type %q, style %d", cell.Ref, cell.Type, cell.Style)
+				}
+				values[column] = cell.Inline
+			case cell.Type == "inlineStr":
+				values[column] = cell.Inline
+			case cell.Type == "b":
+				values[column] = cell.Value == "1"
+			case cell.Type == "":
+				number, err := strconv.ParseFloat(cell.Value, 64)
+				if err != nil {
+					t.Fatalf("cell %s: %v", cell.Ref, err)
+				}
+				switch cell.Style {
+				case 0:
+					values[column] = int(number)
+				case xlsxStyleFloat:
+					values[column] = number
+				case xlsxStyleDate, xlsxStyleTimestamp:
+					millis := math.Round(number * 24 * 60 * 60 * 1000)
+					values[column] = epoch.Add(time.Duration(millis) * time.Millisecond)
+				default:
+					t.Fatalf("cell %s: unexpected style %d", cell.Ref, cell.Style)
+				}
+			default:
+				t.Fatalf("cell %s: unexpected type %q", cell.Ref, cell.Type)
+			}
+		}
+
+		if i == 0 {
+			for _, title := range values {
+				header = append(header, fmt.Sprint(title))
+			}
+			continue
+		}
+		rows = append(rows, values)
+	}
+
+	return workbook.Sheets[0].Name, header, rows
+}
+
+// cellName returns the reference of a cell, e.g. "C12"
+func cellName(column, row int) string {
+	name := ""
+	for column++; column > 0; column = (column - 1) / 26 {
+		name = string(rune('A'+(column-1)%26)) + name
+	}
+	return name + strconv.Itoa(row)
+}
+
+func TestXLSXRoundTrip(t *testing.T) {
+	sheetName, header, rows := readXLSX(t, encode(t, models.ExportFormatXLSX, "accepted-applications", testColumns, testRows), testColumns)
+
+	if sheetName != "accepted-applications" {
+		t.Errorf("got sheet name %q", sheetName)
+	}
+	for i, column := range testColumns {
+		if header[i] != column.Title {
+			t.Errorf("header %d: got %q, want %q", i, header[i], column.Title)
+		}
+	}
+	compareRows(t, rows, expectedRows(testColumns, testRows, time.Millisecond))
+}
+
+func TestXLSXSerialDates(t *testing.T) {
+	// Serial numbers as shown by spreadsheet applications
+	tests := []struct {
+		value time.Time
+		want  float64
+	}{
+		{time.Date(1970, time.January, 1, 0, 0, 0, 0, time.UTC), 25569},
+		{time.Date(2024, time.March, 15, 0, 0, 0, 0, time.UTC), 45366},
+		{time.Date(2024, time.March, 15, 18, 0, 0, 0, time.UTC), 45366.75},
+		{time.Date(1969, time.December, 31, 12, 0, 0, 0, time.UTC), 25568.5},
+	}
+
+	for _, tt := range tests {
+		if got := xlsxSerial(tt.value); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("xlsxSerial(%s) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestXLSXLongSheetName(t *testing.T) {
+	dataset := "a-dataset-name-longer-than-thirty-one-characters"
+	sheetName, _, _ := readXLSX(t, encode(t, models.ExportFormatXLSX, dataset, testColumns, nil), testColumns)
+	if sheetName != dataset[:31] {
+		t.Errorf("got sheet name %q, want %q", sheetName, dataset[:31])
+	}
+}
+
+func TestXLSXEmptyDataset(t *testing.T) {
+	_, header, rows := readXLSX(t, encode(t, models.ExportFormatXLSX, "test", testColumns, nil), testColumns)
+
+	if len(header) != len(testColumns) {
+		t.Errorf("got %d header cells, want %d", len(header), len(testColumns))
+	}
+	if len(rows) != 0 {
+		t.Errorf("got %d data rows, want none", len(rows))
+	}
+}
@@ -0,0 +1,111 @@
+package export
+
+import (
+	"bufio"
+	"encoding/xml"
+	"io"
+	"strconv"
+	"time"
+)
+
+// xmlSchemaTypes are the XML Schema types of the column types, listed in the <columns> element
+var xmlSchemaTypes = map[ColumnType]string{
+	String:    "xs:string",
+	Int:       "xs:integer",
+	Float:     "xs:decimal",
+	Bool:      "xs:boolean",
+	Date:      "xs:date",
+	Timestamp: "xs:dateTime",
+}
+
+// xmlEncoder writes a dataset as XML: a <columns> element with the type of every column,
+// then one <row> element per row with one element per non-empty value
+type xmlEncoder struct {
+	writer  *bufio.Writer
+	out     io.Writer
+	dataset string
+	columns []Column
+	rows    int
+}
+
+func newXMLEncoder(w io.Writer, dataset string) Encoder {
+	return &xmlEncoder{writer: bufio.NewWriter(w), out: w, dataset: dataset}
+}
+
+func (e *xmlEncoder) Columns(columns []Column) error {
+	e.columns = columns
+
+	e.writer.WriteString(xml.Header)
+	e.writer.WriteString(`<dataset xmlns:xs="http://www.w3.org/2001/XMLSchema" id="`)
+	xml.EscapeText(e.writer, []byte(e.dataset))
+	e.writer.WriteString("\">\n  <columns>\n")
+	for _, column := range columns {
+		e.writer.WriteString(`    <column key="`)
+		xml.EscapeText(e.writer, []byte(column.Key))
+		e.writer.WriteString(`" title="`)
+		xml.EscapeText(e.writer, []byte(column.Title))
+		e.writer.WriteString(`" type="` + xmlSchemaTypes[column.Type] + "\"/>\n")
+	}
+	_, err := e.writer.WriteString("  </columns>\n  <rows>\n")
+	return err
+}
+
+func (e *xmlEncoder) Row(values []interface{}) error {
+	if err := checkRow(e.columns, values); err != nil {
+		return err
+	}
+
+	e.writer.WriteString("    <row>")
+	for i, value := range values {
+		if value == nil {
+			continue
+		}
+
+		key := e.columns[i].Key
+		e.writer.WriteString("<" + key + ">")
+		if err := xml.EscapeText(e.writer, []byte(xmlValue(e.columns[i].Type, value))); err != nil {
+			return err
+		}
+		e.writer.WriteString("</" + key + ">")
+	}
+	if _, err := e.writer.WriteString("</row>\n"); err != nil {
+		return err
+	}
+
+	e.rows++
+	if e.rows%flushRows == 0 {
+		return e.flush()
+	}
+	return nil
+}
+
+func (e *xmlEncoder) Close() error {
+	if _, err := e.writer.WriteString("  </rows>\n</dataset>\n"); err != nil {
+		return err
+	}
+	return e.flush()
+}
+
+func (e *xmlEncoder) flush() error {
+	if err := e.writer.Flush(); err != nil {
+		return err
+	}
+	return flushOutput(e.out)
+}
+
+// xmlValue formats a value in the lexical form of its XML Schema type
+func xmlValue(columnType ColumnType, value interface{}) string {
+	switch columnType {
+	case Int:
+		return strconv.Itoa(value.(int))
+	case Float:
+		return strconv.FormatFloat(value.(float64), 'f', -1, 64)
+	case Bool:
+		return strconv.FormatBool(value.(bool))
+	case Date:
+		return value.(time.Time).UTC().Format("2006-01-02")
+	case Timestamp:
+		return value.(time.Time).UTC().Format(time.RFC3339)
+	}
+	return value.(string)
+}
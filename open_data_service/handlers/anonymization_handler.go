@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"open_data_service/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// policyPath is the address of the anonymization policies
+const policyPath = "/api/v1/open-data/export/policies"
+
+// AnonymizationHandler serves the anonymization policies of the export datasets
+type AnonymizationHandler struct {
+	anonymizationService *services.AnonymizationService
+}
+
+// NewAnonymizationHandler creates a new AnonymizationHandler
+func NewAnonymizationHandler(anonymizationService *services.AnonymizationService) *AnonymizationHandler {
+	return &AnonymizationHandler{
+		anonymizationService: anonymizationService,
+	}
+}
+
+// GetPolicies returns the anonymization policy of every dataset
+// GET /api/v1/open-data/export/policies
+func (h *AnonymizationHandler) GetPolicies(c *gin.Context) {
+	policies := h.anonymizationService.GetPolicies()
+
+	c.JSON(http.StatusOK, gin.H{
+		"policies": policies,
+		"total":    len(policies),
+	})
+}
+
+// GetPolicy returns the anonymization policy of one dataset
+// GET /api/v1/open-data/export/policies/:dataset
+func (h *AnonymizationHandler) GetPolicy(c *gin.Context) {
+	policy, err := h.anonymizationService.GetPolicy(services.CanonicalDatasetID(c.Param("dataset")))
+	if err != nil {
+		if errors.Is(err, services.ErrDatasetNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"policy": policy,
+	})
+}
+
+// setPolicyLink points the response to the anonymization policy of the dataset it contains
+func setPolicyLink(c *gin.Context, dataset string) {
+	c.Header("Link", "<"+policyPath+"/"+services.CanonicalDatasetID(dataset)+`>; rel="describedby"`)
+}
@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"net/http"
+	"open_data_service/middleware"
+	"open_data_service/services"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxUsageDays is the longest period a usage report can cover
+const maxUsageDays = 90
+
+// APIKeyHandler handles API key usage requests
+type APIKeyHandler struct {
+	apiKeyService *services.APIKeyService
+}
+
+// NewAPIKeyHandler creates a new APIKeyHandler
+func NewAPIKeyHandler(apiKeyService *services.APIKeyService) *APIKeyHandler {
+	return &APIKeyHandler{
+		apiKeyService: apiKeyService,
+	}
+}
+
+// GetMyUsage returns the usage of the API key sent with the request
+// GET /api/v1/open-data/usage
+// Query params: days (default 7, max 90)
+func (h *APIKeyHandler) GetMyUsage(c *gin.Context) {
+	apiKey := middleware.GetAPIKey(c)
+	if apiKey == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "API key required, send it in the " + middleware.APIKeyHeader + " header"})
+		return
+	}
+
+	h.respondWithUsage(c, apiKey.KeyID, 7)
+}
+
+// GetKeyUsage returns the usage of any API key, used by sso_service to show usage to the key owner
+// GET /api/v1/internal/api-keys/:keyId/usage
+// Query params: days (default 30, max 90)
+func (h *APIKeyHandler) GetKeyUsage(c *gin.Context) {
+	h.respondWithUsage(c, c.Param("keyId"), 30)
+}
+
+// respondWithUsage writes the usage report of the key for the requested number of days
+func (h *APIKeyHandler) respondWithUsage(c *gin.Context, keyID string, defaultDays int) {
+	days := defaultDays
+	if value := c.Query("days"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > maxUsageDays {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "days must be between 1 and 90"})
+			return
+		}
+		days = parsed
+	}
+
+	report, err := h.apiKeyService.GetUsage(c.Request.Context(), keyID, days)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"open_data_service/models"
+	"open_data_service/services"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CatalogHandler serves the DCAT-AP dataset catalog
+type CatalogHandler struct {
+	catalogService *services.CatalogService
+}
+
+// NewCatalogHandler creates a new CatalogHandler
+func NewCatalogHandler(catalogService *services.CatalogService) *CatalogHandler {
+	return &CatalogHandler{
+		catalogService: catalogService,
+	}
+}
+
+// GetCatalog returns the DCAT-AP catalog of all datasets
+// GET /api/v1/open-data/catalog
+// Query params: format (jsonld or rdf); without it the Accept header decides, JSON-LD is the default
+func (h *CatalogHandler) GetCatalog(c *gin.Context) {
+	catalog, err := h.catalogService.GetCatalog()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	h.respondWithCatalog(c, catalog)
+}
+
+// GetDataset returns the DCAT-AP description of one dataset (the dataset URI in the catalog)
+// GET /api/v1/open-data/catalog/datasets/:datasetId
+// Query params: format (jsonld or rdf)
+func (h *CatalogHandler) GetDataset(c *gin.Context) {
+	catalog, err := h.catalogService.GetDatasetCatalog(c.Param("datasetId"))
+	if err != nil {
+		if errors.Is(err, services.ErrDatasetNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	h.respondWithCatalog(c, catalog)
+}
+
+// respondWithCatalog writes the catalog as JSON-LD or RDF/XML
+func (h *CatalogHandler) respondWithCatalog(c *gin.Context, catalog *models.DatasetCatalog) {
+	format := c.Query("format")
+	if format == "" {
+		format = "jsonld"
+		if strings.Contains(c.GetHeader("Accept"), "application/rdf+xml") {
+			format = "rdf"
+		}
+	}
+
+	switch format {
+	case "jsonld":
+		c.Header("Content-Type", "application/ld+json; charset=utf-8")
+		c.JSON(http.StatusOK, services.FormatDCATJSONLD(catalog))
+	case "rdf":
+		document, err := services.FormatDCATRDFXML(catalog)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		c.Data(http.StatusOK, "application/rdf+xml; charset=utf-8", document)
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be 'jsonld' or 'rdf'"})
+	}
+}
@@ -0,0 +1,207 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"open_data_service/models"
+	"open_data_service/services"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxCKANRows is the largest page package_search returns, as in CKAN
+const maxCKANRows = 1000
+
+// CKANHandler handles the read-only CKAN Action API used by national open data portal harvesters
+// Actions accept parameters in the query string (GET) or in a JSON body (POST), like CKAN
+type CKANHandler struct {
+	ckanService *services.CKANService
+}
+
+// NewCKANHandler creates a new CKANHandler
+func NewCKANHandler(ckanService *services.CKANService) *CKANHandler {
+	return &CKANHandler{
+		ckanService: ckanService,
+	}
+}
+
+// PackageList returns the names of all datasets
+// GET /api/3/action/package_list
+// Params: limit, offset
+func (h *CKANHandler) PackageList(c *gin.Context) {
+	params := ckanParams(c)
+
+	limit, err := ckanIntParam(params, "limit", 0)
+	if err != nil {
+		respondCKANError(c, err)
+		return
+	}
+	offset, err := ckanIntParam(params, "offset", 0)
+	if err != nil {
+		respondCKANError(c, err)
+		return
+	}
+
+	names, err := h.ckanService.PackageList(limit, offset)
+	if err != nil {
+		respondCKANError(c, err)
+		return
+	}
+
+	respondCKAN(c, names)
+}
+
+// PackageShow returns one dataset with its resources
+// GET /api/3/action/package_show
+// Params: id (dataset ID or name)
+func (h *CKANHandler) PackageShow(c *gin.Context) {
+	params := ckanParams(c)
+	if params["id"] == "" {
+		respondCKANError(c, errMissingCKANParam("id"))
+		return
+	}
+
+	pkg, err := h.ckanService.PackageShow(params["id"])
+	if err != nil {
+		respondCKANError(c, err)
+		return
+	}
+
+	respondCKAN(c, pkg)
+}
+
+// PackageSearch searches datasets
+// GET /api/3/action/package_search
+// Params: q, fq, rows (default 10, max 1000), start
+func (h *CKANHandler) PackageSearch(c *gin.Context) {
+	params := ckanParams(c)
+
+	rows, err := ckanIntParam(params, "rows", 10)
+	if err != nil {
+		respondCKANError(c, err)
+		return
+	}
+	if rows > maxCKANRows {
+		rows = maxCKANRows
+	}
+	start, err := ckanIntParam(params, "start", 0)
+	if err != nil {
+		respondCKANError(c, err)
+		return
+	}
+
+	result, err := h.ckanService.PackageSearch(models.CKANSearchQuery{
+		Q:     params["q"],
+		Fq:    params["fq"],
+		Rows:  rows,
+		Start: start,
+	})
+	if err != nil {
+		respondCKANError(c, err)
+		return
+	}
+
+	respondCKAN(c, result)
+}
+
+// ResourceShow returns one resource (a dataset in one export format)
+// GET /api/3/action/resource_show
+// Params: id (e.g. "dorms-csv")
+func (h *CKANHandler) ResourceShow(c *gin.Context) {
+	params := ckanParams(c)
+	if params["id"] == "" {
+		respondCKANError(c, errMissingCKANParam("id"))
+		return
+	}
+
+	resource, err := h.ckanService.ResourceShow(params["id"])
+	if err != nil {
+		respondCKANError(c, err)
+		return
+	}
+
+	respondCKAN(c, resource)
+}
+
+// ckanValidationError is an invalid action parameter
+type ckanValidationError struct {
+	message string
+}
+
+func (e *ckanValidationError) Error() string {
+	return e.message
+}
+
+// errMissingCKANParam is the error for a missing required parameter
+func errMissingCKANParam(name string) error {
+	return &ckanValidationError{message: "Missing value: " + name}
+}
+
+// ckanParams collects the action parameters from the query string and, for POST, the JSON body
+func ckanParams(c *gin.Context) map[string]string {
+	params := make(map[string]string)
+	for key, values := range c.Request.URL.Query() {
+		params[key] = values[0]
+	}
+
+	if c.Request.Method == http.MethodPost {
+		var body map[string]interface{}
+		if err := c.ShouldBindJSON(&body); err == nil {
+			for key, value := range body {
+				params[key] = fmt.Sprint(value)
+			}
+		}
+	}
+
+	return params
+}
+
+// ckanIntParam parses a non-negative integer parameter
+func ckanIntParam(params map[string]string, name string, fallback int) (int, error) {
+	value, exists := params[name]
+	if !exists || value == "" {
+		return fallback, nil
+	}
+
+	parsed, err := strconv.Atoi(value)
+	if err != nil || parsed < 0 {
+		return 0, &ckanValidationError{message: name + " must be a non-negative integer"}
+	}
+	return parsed, nil
+}
+
+// respondCKAN writes a successful action response
+func respondCKAN(c *gin.Context, result interface{}) {
+	c.JSON(http.StatusOK, models.CKANResponse{
+		Help:    ckanHelpURL(c),
+		Success: true,
+		Result:  result,
+	})
+}
+
+// respondCKANError writes a failed action response with the CKAN error type and status
+func respondCKANError(c *gin.Context, err error) {
+	status := http.StatusInternalServerError
+	errorType := "Internal Server Error"
+
+	var validationErr *ckanValidationError
+	switch {
+	case errors.Is(err, services.ErrCKANNotFound):
+		status, errorType = http.StatusNotFound, "Not Found Error"
+	case errors.Is(err, services.ErrCKANInvalidFilter), errors.As(err, &validationErr):
+		status, errorType = http.StatusConflict, "Validation Error"
+	}
+
+	c.JSON(status, models.CKANResponse{
+		Help:    ckanHelpURL(c),
+		Success: false,
+		Error:   &models.CKANError{Type: errorType, Message: err.Error()},
+	})
+}
+
+// ckanHelpURL returns the address of the called action, CKAN puts its documentation link here
+func ckanHelpURL(c *gin.Context) string {
+	return c.Request.URL.Path
+}
@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"math"
+	"net/http"
+	"open_data_service/models"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Limits of the nearest dorm search
+const (
+	defaultNearestDorms = 5
+	maxNearestDorms     = 50
+)
+
+// GetDormsGeoJSON returns the dorms with coordinates and their occupancy as GeoJSON, ready to be drawn on a map
+// GET /api/v1/open-data/dorms.geojson
+func (h *OpenDataHandler) GetDormsGeoJSON(c *gin.Context) {
+	collection, err := h.openDataService.GetDormLocations()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("Content-Type", "application/geo+json; charset=utf-8")
+	c.JSON(http.StatusOK, collection)
+}
+
+// FindNearestDorms returns the dorms closest to a point, nearest first
+// GET /api/v1/open-data/dorms/nearest
+// Query params:
+//   - lat, lng: the point (WGS 84), required
+//   - limit: number of dorms, default 5, max 50
+//   - available_only: true to return only dorms with free spots
+//   - max_distance_km: optional distance limit
+func (h *OpenDataHandler) FindNearestDorms(c *gin.Context) {
+	latitude, err := parseCoordinate(c.Query("lat"), 90)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "lat is required and must be between -90 and 90"})
+		return
+	}
+	longitude, err := parseCoordinate(c.Query("lng"), 180)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "lng is required and must be between -180 and 180"})
+		return
+	}
+
+	query := models.NearestDormsQuery{
+		Latitude:      latitude,
+		Longitude:     longitude,
+		Limit:         defaultNearestDorms,
+		AvailableOnly: c.Query("available_only") == "true",
+	}
+
+	if value := c.Query("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > maxNearestDorms {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and 50"})
+			return
+		}
+		query.Limit = parsed
+	}
+
+	if value := c.Query("max_distance_km"); value != "" {
+		parsed, err := strconv.ParseFloat(value, 64)
+		if err != nil || math.IsNaN(parsed) || parsed <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "max_distance_km must be a positive number"})
+			return
+		}
+		query.MaxDistanceKm = &parsed
+	}
+
+	dorms, err := h.openDataService.FindNearestDorms(query)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"dorms": dorms,
+		"count": len(dorms),
+	})
+}
+
+// parseCoordinate parses a latitude or longitude and checks that it is within ±limit degrees
+func parseCoordinate(value string, limit float64) (float64, error) {
+	coordinate, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, err
+	}
+	if math.IsNaN(coordinate) || coordinate < -limit || coordinate > limit {
+		return 0, strconv.ErrRange
+	}
+	return coordinate, nil
+}
@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"compress/gzip"
+	"log"
+	"net/http"
+	"open_data_service/export"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// exportResponse writes a streamed export to the client, compressed with gzip if the client accepts it
+// and the format is not compressed already
+// Headers are sent with the first byte, so an export that fails before writing anything
+// can still be answered with an error response
+type exportResponse struct {
+	c       *gin.Context
+	dataset string
+	format  export.Format
+	gzip    bool
+	gzipper *gzip.Writer
+	started bool
+}
+
+// newExportResponse creates an exportResponse for the request
+func newExportResponse(c *gin.Context, dataset string, format export.Format) *exportResponse {
+	return &exportResponse{
+		c:       c,
+		dataset: dataset,
+		format:  format,
+		gzip:    !format.Compressed && acceptsGzip(c.GetHeader("Accept-Encoding")),
+	}
+}
+
+// Write sends the headers on the first call and then writes the (compressed) data
+func (r *exportResponse) Write(p []byte) (int, error) {
+	if !r.started {
+		r.start()
+	}
+	if r.gzipper != nil {
+		return r.gzipper.Write(p)
+	}
+	return r.c.Writer.Write(p)
+}
+
+// Flush sends everything written so far to the client
+func (r *exportResponse) Flush() error {
+	if r.gzipper != nil {
+		if err := r.gzipper.Flush(); err != nil {
+			return err
+		}
+	}
+	r.c.Writer.Flush()
+	return nil
+}
+
+// Started reports whether the headers have been sent
+func (r *exportResponse) Started() bool {
+	return r.started
+}
+
+// Finish ends a successful export
+func (r *exportResponse) Finish() error {
+	if r.gzipper != nil {
+		return r.gzipper.Close()
+	}
+	return nil
+}
+
+// Abort closes the connection of an export that failed after it started,
+// so the client sees an incomplete response instead of a complete but truncated file
+func (r *exportResponse) Abort(err error) {
+	log.Printf("Export of %s failed after it started: %v", r.dataset, err)
+
+	hijacker, ok := r.c.Writer.(http.Hijacker)
+	if !ok {
+		return
+	}
+	conn, _, hijackErr := hijacker.Hijack()
+	if hijackErr != nil {
+		return
+	}
+	conn.Close()
+}
+
+// start sends the response headers
+func (r *exportResponse) start() {
+	r.started = true
+
+	r.c.Header("Content-Type", r.format.ContentType)
+	if r.format.Attachment {
+		r.c.Header("Content-Disposition", "attachment; filename="+r.dataset+"."+r.format.Extension)
+	}
+	setPolicyLink(r.c, r.dataset)
+	r.c.Header("Vary", "Accept-Encoding")
+	// Lets the gateway pass the rows on as they are written instead of buffering the whole export
+	r.c.Header("X-Accel-Buffering", "no")
+	if r.gzip {
+		r.c.Header("Content-Encoding", "gzip")
+	}
+	r.c.Status(http.StatusOK)
+
+	if r.gzip {
+		r.gzipper = gzip.NewWriter(r.c.Writer)
+	}
+}
+
+// acceptsGzip reports whether an Accept-Encoding header allows a gzip response
+// An explicit gzip entry takes precedence over "*"; a quality of 0 means not acceptable
+func acceptsGzip(acceptEncoding string) bool {
+	gzipQuality, wildcardQuality := -1.0, -1.0
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		coding, params, _ := strings.Cut(part, ";")
+		quality := 1.0
+		if value, found := strings.CutPrefix(strings.TrimSpace(params), "q="); found {
+			parsed, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+			if err != nil {
+				continue
+			}
+			quality = parsed
+		}
+
+		switch strings.ToLower(strings.TrimSpace(coding)) {
+		case "gzip":
+			gzipQuality = quality
+		case "*":
+			wildcardQuality = quality
+		}
+	}
+
+	if gzipQuality >= 0 {
+		return gzipQuality > 0
+	}
+	return wildcardQuality > 0
+}
@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"net/http"
+	"open_data_service/models"
+	"open_data_service/services"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// maxOccupancyHistoryDays is the longest date range a time series request can cover
+const maxOccupancyHistoryDays = 3 * 366
+
+// OccupancyHistoryHandler serves the recorded occupancy history
+type OccupancyHistoryHandler struct {
+	occupancyHistoryService *services.OccupancyHistoryService
+}
+
+// NewOccupancyHistoryHandler creates a new OccupancyHistoryHandler
+func NewOccupancyHistoryHandler(occupancyHistoryService *services.OccupancyHistoryService) *OccupancyHistoryHandler {
+	return &OccupancyHistoryHandler{
+		occupancyHistoryService: occupancyHistoryService,
+	}
+}
+
+// GetOccupancyHistory returns occupancy, capacity and active applications over a date range
+// GET /api/v1/open-data/occupancy/history
+// Query params:
+//   - from, to: dates (YYYY-MM-DD), default the last 30 days
+//   - granularity: day (default), week or month
+//   - level: total, dorm (default) or room; room is the default when room_id is set
+//   - dorm_id, room_id: optional filters
+func (h *OccupancyHistoryHandler) GetOccupancyHistory(c *gin.Context) {
+	query := models.OccupancyTimeSeriesQuery{
+		Granularity: c.DefaultQuery("granularity", models.GranularityDay),
+		Level:       c.DefaultQuery("level", models.OccupancyLevelDorm),
+	}
+
+	switch query.Granularity {
+	case models.GranularityDay, models.GranularityWeek, models.GranularityMonth:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "granularity must be 'day', 'week' or 'month'"})
+		return
+	}
+
+	if dormID := c.Query("dorm_id"); dormID != "" {
+		objectID, err := primitive.ObjectIDFromHex(dormID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid dorm ID"})
+			return
+		}
+		query.DormID = objectID
+	}
+	if roomID := c.Query("room_id"); roomID != "" {
+		objectID, err := primitive.ObjectIDFromHex(roomID)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid room ID"})
+			return
+		}
+		query.RoomID = objectID
+		if c.Query("level") == "" {
+			query.Level = models.OccupancyLevelRoom
+		}
+	}
+
+	switch query.Level {
+	case models.OccupancyLevelTotal, models.OccupancyLevelDorm, models.OccupancyLevelRoom:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "level must be 'total', 'dorm' or 'room'"})
+		return
+	}
+	if !query.RoomID.IsZero() && query.Level != models.OccupancyLevelRoom {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "room_id can only be used with level 'room'"})
+		return
+	}
+
+	now := time.Now().UTC()
+	query.To = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	if value := c.Query("to"); value != "" {
+		parsed, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "to must be a date in YYYY-MM-DD format"})
+			return
+		}
+		query.To = parsed
+	}
+
+	query.From = query.To.AddDate(0, 0, -29)
+	if value := c.Query("from"); value != "" {
+		parsed, err := time.Parse("2006-01-02", value)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "from must be a date in YYYY-MM-DD format"})
+			return
+		}
+		query.From = parsed
+	}
+
+	if query.From.After(query.To) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "from must not be after to"})
+		return
+	}
+	if query.To.Sub(query.From) > maxOccupancyHistoryDays*24*time.Hour {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "date range can cover at most 3 years"})
+		return
+	}
+
+	series, err := h.occupancyHistoryService.GetTimeSeries(query)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"history": series,
+	})
+}
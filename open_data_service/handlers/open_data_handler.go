@@ -1,11 +1,14 @@
 package handlers
 
 import (
+	"context"
 	"fmt"
 	"net/http"
+	"open_data_service/export"
 	"open_data_service/models"
 	"open_data_service/services"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
@@ -13,12 +16,15 @@ import (
 // OpenDataHandler handles all open data API requests
 type OpenDataHandler struct {
 	openDataService *services.OpenDataService
+	exportTimeout   time.Duration
 }
 
 // NewOpenDataHandler creates a new OpenDataHandler
-func NewOpenDataHandler(openDataService *services.OpenDataService) *OpenDataHandler {
+// exportTimeout is the longest a streamed export may take, including the time a slow client needs to read it
+func NewOpenDataHandler(openDataService *services.OpenDataService, exportTimeout time.Duration) *OpenDataHandler {
 	return &OpenDataHandler{
 		openDataService: openDataService,
+		exportTimeout:   exportTimeout,
 	}
 }
 
@@ -173,16 +179,16 @@ func (h *OpenDataHandler) GetOccupancyHeatmap(c *gin.Context) {
 }
 
 // ====================
-// 6. Open Data Export (CSV/JSON)
+// 6. Open Data Export
 // ====================
 
-// ExportData exports data in CSV or JSON format
+// ExportData streams data in CSV, JSON, NDJSON, XML, XLSX, ODS or Parquet format, compressed with gzip if the client accepts it
 // GET /api/v1/open-data/export
-// Query params: dataset (dorms, rooms, dorm-statistics, application-list, accepted-applications, dorm-trends, amenities-report, occupancy-report, room-types), format (csv, json)
+// Query params: dataset (dorms, rooms, dorm-statistics, application-list, accepted-applications, dorm-trends, amenities-report, occupancy-report, room-types), format (csv, json, ndjson, xml, xlsx, ods, parquet)
 func (h *OpenDataHandler) ExportData(c *gin.Context) {
 	dataset := c.Query("dataset")
 	if dataset == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "dataset parameter is required (dorms, rooms, dorm-statistics, application-list, accepted-applications, dorm-trends, amenities-report, occupancy-report, or room-types)"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": "dataset parameter is required (dorms, rooms, dorm-statistics, application-list, accepted-applications, dorm-trends, amenities-report, occupancy-report, occupancy-history, or room-types)"})
 		return
 	}
 
@@ -191,42 +197,29 @@ func (h *OpenDataHandler) ExportData(c *gin.Context) {
 		formatStr = "json"
 	}
 
-	var format models.ExportFormat
-	if formatStr == "csv" {
-		format = models.ExportFormatCSV
-	} else if formatStr == "json" {
-		format = models.ExportFormatJSON
-	} else {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be 'csv' or 'json'"})
+	format := models.ExportFormat(formatStr)
+	exportFormat, exists := export.Lookup(format)
+	if !exists {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be 'csv', 'json', 'ndjson', 'xml', 'xlsx', 'ods' or 'parquet'"})
 		return
 	}
 
-	data, err := h.openDataService.ExportData(dataset, format)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
-		return
-	}
-
-	if format == models.ExportFormatCSV {
-		csvData, ok := data.([][]string)
-		if !ok {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to convert data to CSV format"})
-			return
-		}
+	// The request context stops the export when the client disconnects
+	ctx, cancel := context.WithTimeout(c.Request.Context(), h.exportTimeout)
+	defer cancel()
 
-		csvString, err := services.FormatCSV(csvData)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	response := newExportResponse(c, dataset, exportFormat)
+	if err := h.openDataService.StreamExport(ctx, dataset, format, response); err != nil {
+		if response.Started() {
+			response.Abort(err)
 			return
 		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 
-		c.Header("Content-Type", "text/csv")
-		c.Header("Content-Disposition", "attachment; filename="+dataset+".csv")
-		c.String(http.StatusOK, csvString)
-	} else {
-		c.JSON(http.StatusOK, gin.H{
-			"data": data,
-		})
+	if err := response.Finish(); err != nil {
+		response.Abort(err)
 	}
 }
 
@@ -262,9 +255,9 @@ func (h *OpenDataHandler) GetRoomApplications(c *gin.Context) {
 	})
 }
 
-// GetApplicationsByAcademicYear proxies the request to st_dom_service to get accepted applications by academic year
+// GetApplicationsByAcademicYear returns the accepted applications of an academic year
 // GET /api/v1/open-data/applications/academic-year
-// This is now a public endpoint (no authentication required)
+// Public endpoint - rows are anonymized following the accepted-applications policy
 func (h *OpenDataHandler) GetApplicationsByAcademicYear(c *gin.Context) {
 	academicYear := c.Query("academic_year")
 	if academicYear == "" {
@@ -272,15 +265,18 @@ func (h *OpenDataHandler) GetApplicationsByAcademicYear(c *gin.Context) {
 		return
 	}
 
-	// Call st_dom_service endpoint (no auth required for public data)
-	applications, err := h.openDataService.GetApplicationsByAcademicYearFromStDomService(academicYear)
+	applications, err := h.openDataService.GetAcceptedApplicationsByAcademicYear(academicYear)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
+	setPolicyLink(c, "accepted-applications")
 	c.JSON(http.StatusOK, gin.H{
-		"data": applications,
+		"data": gin.H{
+			"prihvacene_aplikacije": applications,
+			"count":                 len(applications),
+		},
 	})
 }
 
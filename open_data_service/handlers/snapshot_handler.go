@@ -0,0 +1,227 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"open_data_service/models"
+	"open_data_service/services"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxDiffRows is the largest number of added and removed rows a diff lists
+const maxDiffRows = 1000
+
+// SnapshotHandler serves the versioned dataset snapshots
+type SnapshotHandler struct {
+	snapshotService *services.SnapshotService
+	baseURL         string
+}
+
+// NewSnapshotHandler creates a new SnapshotHandler
+// baseURL is the public address of the API, used in the download links
+func NewSnapshotHandler(snapshotService *services.SnapshotService, baseURL string) *SnapshotHandler {
+	return &SnapshotHandler{
+		snapshotService: snapshotService,
+		baseURL:         baseURL,
+	}
+}
+
+// ListSnapshots returns the latest version of every dataset
+// GET /api/v1/open-data/snapshots
+func (h *SnapshotHandler) ListSnapshots(c *gin.Context) {
+	snapshots, err := h.snapshotService.ListLatest()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	for i := range snapshots {
+		h.setFileURLs(&snapshots[i])
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"snapshots": snapshots,
+		"total":     len(snapshots),
+	})
+}
+
+// ListVersions returns all versions of a dataset, newest first
+// GET /api/v1/open-data/snapshots/:dataset
+func (h *SnapshotHandler) ListVersions(c *gin.Context) {
+	dataset := c.Param("dataset")
+
+	versions, err := h.snapshotService.ListVersions(dataset)
+	if err != nil {
+		respondSnapshotError(c, err)
+		return
+	}
+
+	for i := range versions {
+		h.setFileURLs(&versions[i])
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"dataset":    dataset,
+		"latest_url": h.latestURL(dataset, models.ExportFormatCSV),
+		"versions":   versions,
+		"total":      len(versions),
+	})
+}
+
+// DownloadLatest returns the file of the newest version; the URL never changes
+// GET /api/v1/open-data/snapshots/:dataset/latest
+// Query params: format (csv or json, default csv)
+func (h *SnapshotHandler) DownloadLatest(c *gin.Context) {
+	format, ok := snapshotFormat(c)
+	if !ok {
+		return
+	}
+
+	snapshot, err := h.snapshotService.GetLatest(c.Param("dataset"))
+	if err != nil {
+		respondSnapshotError(c, err)
+		return
+	}
+
+	// The content behind this URL changes with every new version, so clients must revalidate
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Content-Location", h.versionURL(snapshot, format))
+	h.serveFile(c, snapshot, format)
+}
+
+// DownloadVersion returns the file of one version
+// GET /api/v1/open-data/snapshots/:dataset/versions/:version
+// Query params: format (csv or json, default csv)
+func (h *SnapshotHandler) DownloadVersion(c *gin.Context) {
+	format, ok := snapshotFormat(c)
+	if !ok {
+		return
+	}
+
+	version, err := strconv.Atoi(c.Param("version"))
+	if err != nil || version < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "version must be a positive integer"})
+		return
+	}
+
+	snapshot, err := h.snapshotService.GetVersion(c.Param("dataset"), version)
+	if err != nil {
+		respondSnapshotError(c, err)
+		return
+	}
+
+	// Versions are never modified, so they can be cached forever
+	c.Header("Cache-Control", "public, max-age=31536000, immutable")
+	h.serveFile(c, snapshot, format)
+}
+
+// DiffVersions returns the rows added and removed between two versions
+// GET /api/v1/open-data/snapshots/:dataset/diff
+// Query params: from, to (required), limit (default 100, max 1000)
+func (h *SnapshotHandler) DiffVersions(c *gin.Context) {
+	from, errFrom := strconv.Atoi(c.Query("from"))
+	to, errTo := strconv.Atoi(c.Query("to"))
+	if errFrom != nil || errTo != nil || from < 1 || to < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "from and to must be positive version numbers"})
+		return
+	}
+
+	limit := 100
+	if value := c.Query("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 0 || parsed > maxDiffRows {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 0 and 1000"})
+			return
+		}
+		limit = parsed
+	}
+
+	diff, err := h.snapshotService.Diff(c.Param("dataset"), from, to, limit)
+	if err != nil {
+		respondSnapshotError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, diff)
+}
+
+// serveFile streams the stored file of the snapshot with its version and checksum headers
+func (h *SnapshotHandler) serveFile(c *gin.Context, snapshot *models.DatasetSnapshot, format models.ExportFormat) {
+	file := snapshot.File(format)
+	if file == nil {
+		respondSnapshotError(c, services.ErrSnapshotFormatNotFound)
+		return
+	}
+
+	etag := `"` + file.SHA256 + `"`
+	c.Header("ETag", etag)
+	c.Header("X-Snapshot-Version", strconv.Itoa(snapshot.Version))
+	c.Header("X-Snapshot-Created-At", snapshot.CreatedAt.UTC().Format(http.TimeFormat))
+	c.Header("X-Checksum-SHA256", file.SHA256)
+	if c.GetHeader("If-None-Match") == etag {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	content, _, err := h.snapshotService.OpenFile(c.Request.Context(), snapshot, format)
+	if err != nil {
+		respondSnapshotError(c, err)
+		return
+	}
+	defer content.Close()
+
+	contentType := "application/json"
+	if format == models.ExportFormatCSV {
+		contentType = "text/csv"
+	}
+	filename := fmt.Sprintf("%s-v%d.%s", snapshot.Dataset, snapshot.Version, format)
+
+	c.DataFromReader(http.StatusOK, file.Size, contentType, content, map[string]string{
+		"Content-Disposition": "attachment; filename=" + filename,
+	})
+}
+
+// setFileURLs fills in the download URL of every file of the snapshot
+func (h *SnapshotHandler) setFileURLs(snapshot *models.DatasetSnapshot) {
+	for i := range snapshot.Files {
+		snapshot.Files[i].URL = h.versionURL(snapshot, snapshot.Files[i].Format)
+	}
+}
+
+// versionURL returns the permanent download URL of one version
+func (h *SnapshotHandler) versionURL(snapshot *models.DatasetSnapshot, format models.ExportFormat) string {
+	return fmt.Sprintf("%s/api/v1/open-data/snapshots/%s/versions/%d?format=%s", h.baseURL, snapshot.Dataset, snapshot.Version, format)
+}
+
+// latestURL returns the stable download URL of the newest version
+func (h *SnapshotHandler) latestURL(dataset string, format models.ExportFormat) string {
+	return fmt.Sprintf("%s/api/v1/open-data/snapshots/%s/latest?format=%s", h.baseURL, dataset, format)
+}
+
+// snapshotFormat reads the format query parameter, writing a 400 response if it is invalid
+func snapshotFormat(c *gin.Context) (models.ExportFormat, bool) {
+	switch c.DefaultQuery("format", "csv") {
+	case "csv":
+		return models.ExportFormatCSV, true
+	case "json":
+		return models.ExportFormatJSON, true
+	}
+
+	c.JSON(http.StatusBadRequest, gin.H{"error": "format must be 'csv' or 'json'"})
+	return "", false
+}
+
+// respondSnapshotError writes the status matching a snapshot service error
+func respondSnapshotError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrDatasetNotFound),
+		errors.Is(err, services.ErrSnapshotNotFound),
+		errors.Is(err, services.ErrSnapshotFormatNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
@@ -5,8 +5,12 @@ import (
 	"open_data_service/config"
 	"open_data_service/database"
 	"open_data_service/handlers"
+	"open_data_service/middleware"
 	"open_data_service/routes"
 	"open_data_service/services"
+	"open_data_service/storage"
+	"open_data_service/utils"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
@@ -34,25 +38,117 @@ func main() {
 	aplikacijeCollection := db.GetCollection("aplikacije")
 	prihvaceneAplikacijeCollection := db.GetCollection("prihvacene_aplikacije")
 	repairsCollection := db.GetCollection("repairs")
+	apiKeyUsageCollection := db.GetCollection("api_key_usage")
+	snapshotsCollection := db.GetCollection("dataset_snapshots")
+	occupancyHistoryCollection := db.GetCollection("occupancy_history")
+
+	// Service tokens for calls to st_dom_service and sso_service
+	serviceTokens := utils.NewServiceTokenSource(cfg.SSOServiceURL, cfg.ServiceClientID, cfg.ServiceClientSecret)
 
 	// Create services
+	anonymizationService := services.NewAnonymizationService(services.AnonymizationConfig{
+		PseudonymKey:     cfg.AnonymizationPseudonymKey,
+		MinGroupSize:     cfg.AnonymizationMinGroupSize,
+		GradeBucketWidth: cfg.GradeBucketWidth,
+	})
+
+	occupancyHistoryService := services.NewOccupancyHistoryService(
+		occupancyHistoryCollection,
+		stDomsCollection,
+		sobasCollection,
+		aplikacijeCollection,
+		prihvaceneAplikacijeCollection,
+	)
+	if err := occupancyHistoryService.EnsureIndexes(); err != nil {
+		log.Println("Failed to create occupancy history indexes:", err)
+	}
+	if cfg.OccupancyHistoryEnabled {
+		occupancyHistoryService.StartScheduler(cfg.OccupancyHistoryPeriod)
+		log.Printf("Occupancy history recorded every %s", cfg.OccupancyHistoryPeriod)
+	}
+
 	openDataService := services.NewOpenDataService(
 		stDomsCollection,
 		sobasCollection,
 		aplikacijeCollection,
 		prihvaceneAplikacijeCollection,
 		repairsCollection,
+		occupancyHistoryService,
+		anonymizationService,
+		serviceTokens,
+	)
+	catalogService := services.NewCatalogService(
+		stDomsCollection,
+		sobasCollection,
+		aplikacijeCollection,
+		prihvaceneAplikacijeCollection,
+		repairsCollection,
+		occupancyHistoryCollection,
+		services.CatalogConfig{
+			BaseURL:      cfg.PublicBaseURL,
+			Publisher:    cfg.CatalogPublisher,
+			ContactEmail: cfg.CatalogContactEmail,
+			License:      cfg.CatalogLicense,
+		},
 	)
+	ckanService := services.NewCKANService(catalogService)
+	apiKeyService := services.NewAPIKeyService(apiKeyUsageCollection, cfg.SSOServiceURL, serviceTokens, cfg.APIKeyCacheTTL, cfg.APIKeyUsageRetention)
+	if err := apiKeyService.EnsureIndexes(); err != nil {
+		log.Println("Failed to create API key usage indexes:", err)
+	}
+
+	// Dataset snapshots - files on disk, metadata in MongoDB
+	snapshotStore, err := storage.NewLocalBlobStore(cfg.SnapshotStorageDir)
+	if err != nil {
+		log.Fatal("Failed to create snapshot storage:", err)
+	}
+	snapshotService := services.NewSnapshotService(snapshotsCollection, openDataService, anonymizationService, snapshotStore)
+	if err := snapshotService.EnsureIndexes(); err != nil {
+		log.Println("Failed to create dataset snapshot indexes:", err)
+	}
+	if err := snapshotService.WithdrawUnanonymizedSnapshots(); err != nil {
+		log.Fatal("Failed to withdraw unanonymized dataset snapshots:", err)
+	}
+	if cfg.SnapshotEnabled {
+		snapshotService.StartScheduler(cfg.SnapshotPeriod)
+		log.Printf("Dataset snapshots scheduled every %s", cfg.SnapshotPeriod)
+	}
+
+	// Public keys for verifying service tokens on internal routes
+	jwksCache := utils.NewJWKSCache(cfg.SSOServiceURL + "/.well-known/jwks.json")
+	jwksCache.Start(cfg.JWKSRefresh)
+
+	// Per-minute limits; API keys additionally have daily quotas
+	rateLimit := middleware.RateLimit(apiKeyService, utils.NewRateLimiter(time.Minute), middleware.RateLimitConfig{
+		AnonymousPerMinute: cfg.AnonymousRateLimit,
+		RouteCosts: map[string]int{
+			"/api/v1/open-data/export":                               cfg.ExportRequestCost,
+			"/api/v1/open-data/snapshots/:dataset/latest":            cfg.ExportRequestCost,
+			"/api/v1/open-data/snapshots/:dataset/versions/:version": cfg.ExportRequestCost,
+		},
+	})
 
 	// Create handlers
-	openDataHandler := handlers.NewOpenDataHandler(openDataService)
+	openDataHandler := handlers.NewOpenDataHandler(openDataService, cfg.ExportTimeout)
+	catalogHandler := handlers.NewCatalogHandler(catalogService)
+	ckanHandler := handlers.NewCKANHandler(ckanService)
+	apiKeyHandler := handlers.NewAPIKeyHandler(apiKeyService)
+	snapshotHandler := handlers.NewSnapshotHandler(snapshotService, cfg.PublicBaseURL)
+	occupancyHistoryHandler := handlers.NewOccupancyHistoryHandler(occupancyHistoryService)
+	anonymizationHandler := handlers.NewAnonymizationHandler(anonymizationService)
 	healthHandler := handlers.NewHealthHandler()
 
 	// Create router
 	router := gin.Default()
+	// nginx sets X-Real-IP to the client address; X-Forwarded-For can be set by the client
+	// and would let anyone bypass the per-IP rate limit
+	router.RemoteIPHeaders = []string{"X-Real-IP"}
+	if err := router.SetTrustedProxies(cfg.TrustedProxies); err != nil {
+		log.Fatal("Invalid TRUSTED_PROXIES:", err)
+	}
 
 	// Setup routes
-	routes.SetupRoutes(router, openDataHandler, healthHandler)
+	routes.SetupRoutes(router, openDataHandler, catalogHandler, ckanHandler, apiKeyHandler, snapshotHandler, occupancyHistoryHandler, anonymizationHandler, healthHandler, rateLimit, jwksCache)
 
 	log.Printf("Open Data Service starting on port %s", cfg.Port)
 	log.Println("Available endpoints:")
@@ -64,8 +160,24 @@ func main() {
 	log.Println("  GET /api/v1/open-data/dorms/list")
 	log.Println("  GET /api/v1/open-data/trends/applications")
 	log.Println("  GET /api/v1/open-data/occupancy/heatmap")
+	log.Println("  GET /api/v1/open-data/occupancy/history")
 	log.Println("  GET /api/v1/open-data/export")
+	log.Println("  GET /api/v1/open-data/export/policies")
+	log.Println("  GET /api/v1/open-data/export/policies/:dataset")
+	log.Println("  GET /api/v1/open-data/catalog")
+	log.Println("  GET /api/v1/open-data/catalog/datasets/:datasetId")
+	log.Println("  GET /api/v1/open-data/snapshots")
+	log.Println("  GET /api/v1/open-data/snapshots/:dataset")
+	log.Println("  GET /api/v1/open-data/snapshots/:dataset/latest")
+	log.Println("  GET /api/v1/open-data/snapshots/:dataset/versions/:version")
+	log.Println("  GET /api/v1/open-data/snapshots/:dataset/diff")
 	log.Println("  GET /api/v1/open-data/amenities")
+	log.Println("  GET /api/v1/open-data/usage")
+	log.Println("  GET /api/3/action/package_list")
+	log.Println("  GET /api/3/action/package_show")
+	log.Println("  GET /api/3/action/package_search")
+	log.Println("  GET /api/3/action/resource_show")
+	log.Println("  GET /api/v1/internal/api-keys/:keyId/usage")
 
 	if err := router.Run(":" + cfg.Port); err != nil {
 		log.Fatal("Failed to start server:", err)
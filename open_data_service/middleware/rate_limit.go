@@ -0,0 +1,131 @@
+package middleware
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"open_data_service/models"
+	"open_data_service/services"
+	"open_data_service/utils"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// APIKeyHeader is the request header carrying an open data API key
+const APIKeyHeader = "X-API-Key"
+
+// apiKeyContextKey is the gin context key of the verified API key
+const apiKeyContextKey = "api_key"
+
+// RateLimitConfig holds the limits applied to open data requests
+type RateLimitConfig struct {
+	// Requests per minute per IP address for requests without an API key
+	AnonymousPerMinute int
+	// Units charged per request by route path; routes not listed cost one unit
+	RouteCosts map[string]int
+}
+
+// RateLimit enforces per-minute rate limits and, for API keys, daily quotas.
+// Requests without an API key are limited per client IP; requests with a key
+// use the key's own limit and quota and are recorded in the key's usage.
+// A key that is not cached yet costs an sso_service call to verify, so it is also charged
+// one unit of the anonymous per-IP limit; otherwise random keys would bypass every limit.
+func RateLimit(apiKeyService *services.APIKeyService, limiter *utils.RateLimiter, cfg RateLimitConfig) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		endpoint := c.FullPath()
+		cost := 1
+		if routeCost, exists := cfg.RouteCosts[endpoint]; exists {
+			cost = routeCost
+		}
+
+		key := c.GetHeader(APIKeyHeader)
+		if key == "" {
+			allowed, remaining, reset := limiter.Allow("ip:"+c.ClientIP(), cfg.AnonymousPerMinute, cost)
+			setRateLimitHeaders(c, cfg.AnonymousPerMinute, remaining, reset)
+			if !allowed {
+				rejectRequest(c, reset, "Rate limit exceeded, use an API key for higher limits")
+				return
+			}
+			c.Next()
+			return
+		}
+
+		if !apiKeyService.IsCached(key) {
+			allowed, remaining, reset := limiter.Allow("ip:"+c.ClientIP(), cfg.AnonymousPerMinute, 1)
+			if !allowed {
+				setRateLimitHeaders(c, cfg.AnonymousPerMinute, remaining, reset)
+				rejectRequest(c, reset, "Rate limit exceeded, too many API key verifications from this address")
+				return
+			}
+		}
+
+		apiKey, err := apiKeyService.Verify(c.Request.Context(), key)
+		if err != nil {
+			if errors.Is(err, services.ErrInvalidAPIKey) {
+				c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			} else {
+				c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+			}
+			c.Abort()
+			return
+		}
+		c.Set(apiKeyContextKey, apiKey)
+
+		allowed, remaining, reset := limiter.Allow("key:"+apiKey.KeyID, apiKey.RateLimitPerMinute, cost)
+		setRateLimitHeaders(c, apiKey.RateLimitPerMinute, remaining, reset)
+		if !allowed {
+			if err := apiKeyService.RecordRejected(c.Request.Context(), apiKey); err != nil {
+				log.Printf("Failed to record rejected request of API key %s: %v", apiKey.KeyID, err)
+			}
+			rejectRequest(c, reset, "Rate limit exceeded for this API key")
+			return
+		}
+
+		used, allowed, err := apiKeyService.ConsumeQuota(c.Request.Context(), apiKey, strings.TrimPrefix(endpoint, "/api/v1/open-data"), cost)
+		if err != nil {
+			// Usage tracking must not take the API down, the per-minute limit still applies
+			log.Printf("Failed to record usage of API key %s: %v", apiKey.KeyID, err)
+			c.Next()
+			return
+		}
+
+		c.Header("X-Quota-Limit", strconv.Itoa(apiKey.DailyQuota))
+		c.Header("X-Quota-Remaining", strconv.FormatInt(max(int64(apiKey.DailyQuota)-used, 0), 10))
+		if !allowed {
+			now := time.Now().UTC()
+			midnight := time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, time.UTC)
+			rejectRequest(c, midnight, "Daily quota exceeded for this API key")
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// GetAPIKey returns the API key verified by RateLimit, or nil for anonymous requests
+func GetAPIKey(c *gin.Context) *models.APIKeyInfo {
+	if value, exists := c.Get(apiKeyContextKey); exists {
+		if apiKey, ok := value.(*models.APIKeyInfo); ok {
+			return apiKey
+		}
+	}
+	return nil
+}
+
+// setRateLimitHeaders sets the standard rate limit headers of the current window
+func setRateLimitHeaders(c *gin.Context, limit, remaining int, reset time.Time) {
+	c.Header("X-RateLimit-Limit", strconv.Itoa(limit))
+	c.Header("X-RateLimit-Remaining", strconv.Itoa(max(remaining, 0)))
+	c.Header("X-RateLimit-Reset", strconv.FormatInt(reset.Unix(), 10))
+}
+
+// rejectRequest aborts with 429 and tells the client when to retry
+func rejectRequest(c *gin.Context, retryAt time.Time, message string) {
+	retryAfter := int(time.Until(retryAt).Seconds()) + 1
+	c.Header("Retry-After", strconv.Itoa(retryAfter))
+	c.JSON(http.StatusTooManyRequests, gin.H{"error": message})
+	c.Abort()
+}
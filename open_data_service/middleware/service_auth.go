@@ -0,0 +1,32 @@
+package middleware
+
+import (
+	"net/http"
+	"open_data_service/utils"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ServiceAuth protects internal routes: only service tokens issued by sso_service are accepted
+func ServiceAuth(keys *utils.JWKSCache) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		authHeader := c.GetHeader("Authorization")
+		if !strings.HasPrefix(authHeader, "Bearer ") {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Service token required"})
+			c.Abort()
+			return
+		}
+
+		claims, err := utils.ValidateServiceJWT(strings.TrimPrefix(authHeader, "Bearer "), keys)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid service token"})
+			c.Abort()
+			return
+		}
+
+		c.Set("service_client", claims.Subject)
+
+		c.Next()
+	}
+}
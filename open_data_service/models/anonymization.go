@@ -0,0 +1,44 @@
+package models
+
+// Anonymization techniques applied to export datasets
+const (
+	TechniquePseudonymization = "pseudonymization"
+	TechniqueGeneralization   = "generalization"
+	TechniqueKAnonymity       = "k-anonymity"
+	TechniqueSuppression      = "suppression"
+	TechniqueRemoval          = "removal"
+)
+
+// AnonymizationPolicy - Documents what was done to a dataset to protect the students it describes
+type AnonymizationPolicy struct {
+	Dataset      string                   `bson:"dataset" json:"dataset"`
+	PersonalData bool                     `bson:"personal_data" json:"personal_data"` // Whether the source data describes individual students
+	Summary      string                   `bson:"summary" json:"summary"`
+	Techniques   []AnonymizationTechnique `bson:"techniques" json:"techniques"`
+}
+
+// AnonymizationTechnique - One technique applied to a dataset
+type AnonymizationTechnique struct {
+	Technique   string   `bson:"technique" json:"technique"`
+	Fields      []string `bson:"fields" json:"fields"`
+	Description string   `bson:"description" json:"description"`
+}
+
+// AnonymizedAcceptedApplication - Published row of the accepted-applications dataset
+type AnonymizedAcceptedApplication struct {
+	Pseudonym    string `json:"pseudonim"`
+	GradeRange   string `json:"raspon_proseka"`
+	DormName     string `json:"ime_doma"`
+	AcademicYear string `json:"academic_year"`
+}
+
+// AnonymizedApplication - Published row of the application-list dataset
+// Note: "Aktivna" means whether the application is still pending (true) or has been processed/closed (false)
+type AnonymizedApplication struct {
+	Pseudonym    string `json:"pseudonim"`
+	DormName     string `json:"ime_doma"`
+	RoomCapacity int    `json:"kapacitet_sobe"`
+	GradeRange   string `json:"raspon_proseka"`
+	IsActive     bool   `json:"aktivna"`
+	Year         int    `json:"godina_prijave"`
+}
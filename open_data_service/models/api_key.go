@@ -0,0 +1,36 @@
+package models
+
+import "time"
+
+// APIKeyInfo is a valid API key as verified by sso_service, with the limits applied to it
+type APIKeyInfo struct {
+	KeyID              string `json:"key_id"`
+	UserID             string `json:"user_id"`
+	RateLimitPerMinute int    `json:"rate_limit_per_minute"`
+	DailyQuota         int    `json:"daily_quota"`
+}
+
+// APIKeyUsage is the usage of one API key on one day (UTC)
+// Units count towards the daily quota; heavy endpoints such as /export cost more than one unit
+type APIKeyUsage struct {
+	KeyID     string           `bson:"key_id" json:"-"`
+	UserID    string           `bson:"user_id" json:"-"`
+	Date      string           `bson:"date" json:"date"` // Format: "2006-01-02"
+	Requests  int64            `bson:"requests" json:"requests"`
+	Units     int64            `bson:"units" json:"units"`
+	Rejected  int64            `bson:"rejected" json:"rejected"`
+	Endpoints map[string]int64 `bson:"endpoints" json:"endpoints"`
+	UpdatedAt time.Time        `bson:"updated_at" json:"updated_at"`
+	ExpiresAt time.Time        `bson:"expires_at" json:"-"`
+}
+
+// APIKeyUsageReport is the usage of an API key over the last days, newest day first
+type APIKeyUsageReport struct {
+	KeyID    string        `json:"key_id"`
+	From     string        `json:"from"`
+	To       string        `json:"to"`
+	Requests int64         `json:"requests"`
+	Units    int64         `json:"units"`
+	Rejected int64         `json:"rejected"`
+	Days     []APIKeyUsage `json:"days"`
+}
@@ -0,0 +1,44 @@
+package models
+
+import "time"
+
+// LocalizedText is a text in Serbian (Latin script) and English
+type LocalizedText struct {
+	SR string `json:"sr"`
+	EN string `json:"en"`
+}
+
+// DatasetCatalog describes all published open datasets, rendered as DCAT-AP for open data portals
+type DatasetCatalog struct {
+	URI         string           `json:"uri"`
+	Title       LocalizedText    `json:"title"`
+	Description LocalizedText    `json:"description"`
+	Publisher   string           `json:"publisher"`
+	HomepageURL string           `json:"homepage_url"`
+	License     string           `json:"license"` // License URI, applies to every distribution
+	Modified    time.Time        `json:"modified"`
+	Datasets    []CatalogDataset `json:"datasets"`
+}
+
+// CatalogDataset describes one dataset of the /export endpoint
+type CatalogDataset struct {
+	ID                 string                `json:"id"` // Value of the export "dataset" parameter
+	URI                string                `json:"uri"`
+	Title              LocalizedText         `json:"title"`
+	Description        LocalizedText         `json:"description"`
+	Keywords           []LocalizedText       `json:"keywords"`
+	Theme              string                `json:"theme"`                    // EU data theme URI
+	AccrualPeriodicity string                `json:"accrual_periodicity"`      // EU frequency URI
+	TemporalStart      *time.Time            `json:"temporal_start,omitempty"` // Coverage is open-ended, datasets are kept up to date
+	ContactEmail       string                `json:"contact_email"`
+	Distributions      []CatalogDistribution `json:"distributions"`
+}
+
+// CatalogDistribution is one downloadable format of a dataset
+type CatalogDistribution struct {
+	URI         string       `json:"uri"`
+	Format      ExportFormat `json:"format"`
+	MediaType   string       `json:"media_type"` // IANA media type, e.g. "text/csv"
+	FileType    string       `json:"file_type"`  // EU file type URI
+	DownloadURL string       `json:"download_url"`
+}
@@ -0,0 +1,104 @@
+package models
+
+// ====================
+// CKAN Action API Models
+// ====================
+// Read-only subset of the CKAN API (https://docs.ckan.org/en/latest/api/) used by CKAN harvesters.
+// Packages are the export datasets, resources are their distributions (one per export format).
+
+// CKANResponse - Envelope of every CKAN action response
+type CKANResponse struct {
+	Help    string      `json:"help"`
+	Success bool        `json:"success"`
+	Result  interface{} `json:"result,omitempty"`
+	Error   *CKANError  `json:"error,omitempty"`
+}
+
+// CKANError - Error of a failed action
+type CKANError struct {
+	Type    string `json:"__type"` // "Not Found Error" or "Validation Error"
+	Message string `json:"message"`
+}
+
+// CKANPackage - A dataset in CKAN
+type CKANPackage struct {
+	ID               string             `json:"id"`
+	Name             string             `json:"name"`
+	Title            string             `json:"title"`
+	Notes            string             `json:"notes"`
+	URL              string             `json:"url"`
+	Type             string             `json:"type"`
+	State            string             `json:"state"`
+	Private          bool               `json:"private"`
+	LicenseID        string             `json:"license_id"`
+	LicenseTitle     string             `json:"license_title"`
+	LicenseURL       string             `json:"license_url"`
+	Maintainer       string             `json:"maintainer"`
+	MaintainerEmail  string             `json:"maintainer_email"`
+	MetadataCreated  string             `json:"metadata_created"`
+	MetadataModified string             `json:"metadata_modified"`
+	Organization     CKANOrganization   `json:"organization"`
+	OwnerOrg         string             `json:"owner_org"`
+	Tags             []CKANTag          `json:"tags"`
+	NumTags          int                `json:"num_tags"`
+	Groups           []CKANOrganization `json:"groups"`
+	Extras           []CKANExtra        `json:"extras"`
+	Resources        []CKANResource     `json:"resources"`
+	NumResources     int                `json:"num_resources"`
+}
+
+// CKANResource - A downloadable file of a package
+type CKANResource struct {
+	ID           string `json:"id"`
+	PackageID    string `json:"package_id"`
+	Name         string `json:"name"`
+	Description  string `json:"description"`
+	URL          string `json:"url"`
+	Format       string `json:"format"`
+	Mimetype     string `json:"mimetype"`
+	State        string `json:"state"`
+	Position     int    `json:"position"`
+	Created      string `json:"created"`
+	LastModified string `json:"last_modified"`
+}
+
+// CKANOrganization - The publishing organization (also used for groups)
+type CKANOrganization struct {
+	ID             string `json:"id"`
+	Name           string `json:"name"`
+	Title          string `json:"title"`
+	Type           string `json:"type"`
+	State          string `json:"state"`
+	IsOrganization bool   `json:"is_organization"`
+}
+
+// CKANTag - A package keyword
+type CKANTag struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	DisplayName string `json:"display_name"`
+	State       string `json:"state"`
+}
+
+// CKANExtra - Additional package metadata as a key/value pair
+type CKANExtra struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// CKANSearchResult - Result of package_search
+type CKANSearchResult struct {
+	Count        int                    `json:"count"`
+	Sort         string                 `json:"sort"`
+	Results      []CKANPackage          `json:"results"`
+	Facets       map[string]interface{} `json:"facets"`
+	SearchFacets map[string]interface{} `json:"search_facets"`
+}
+
+// CKANSearchQuery - Parameters of package_search
+type CKANSearchQuery struct {
+	Q     string // Free text, matched against names, titles, descriptions and tags in both languages
+	Fq    string // Filter query, e.g. "tags:soba res_format:CSV"
+	Rows  int
+	Start int
+}
@@ -0,0 +1,56 @@
+package models
+
+// GeoJSON object types (RFC 7946)
+const (
+	GeoJSONTypeFeatureCollection = "FeatureCollection"
+	GeoJSONTypeFeature           = "Feature"
+	GeoJSONTypePoint             = "Point"
+)
+
+// GeoJSONFeatureCollection - Dorms as a GeoJSON document that map libraries can draw directly
+type GeoJSONFeatureCollection struct {
+	Type     string           `json:"type"`
+	Features []GeoJSONFeature `json:"features"`
+}
+
+// GeoJSONFeature - One dorm on the map
+type GeoJSONFeature struct {
+	Type       string                 `json:"type"`
+	ID         string                 `json:"id"`
+	Geometry   GeoJSONPoint           `json:"geometry"`
+	Properties DormLocationProperties `json:"properties"`
+}
+
+// GeoJSONPoint - Location of a dorm
+// GeoJSON puts the longitude first: [longitude, latitude]
+type GeoJSONPoint struct {
+	Type        string     `json:"type"`
+	Coordinates [2]float64 `json:"coordinates"`
+}
+
+// DormLocationProperties - Occupancy and availability of a dorm shown on the map
+type DormLocationProperties struct {
+	DormName          string  `json:"dorm_name"`
+	Address           string  `json:"address"`
+	TotalCapacity     int     `json:"total_capacity"`
+	OccupiedSpots     int     `json:"occupied_spots"`
+	AvailableSpots    int     `json:"available_spots"`
+	OccupancyRate     float64 `json:"occupancy_rate"`
+	Status            string  `json:"status"` // "high", "medium", "low"
+	HasAvailableSpots bool    `json:"has_available_spots"`
+}
+
+// NearestDormsQuery - Point to search from and filters for the nearest dorms
+type NearestDormsQuery struct {
+	Latitude      float64
+	Longitude     float64
+	Limit         int
+	AvailableOnly bool     // Only dorms with at least one free spot
+	MaxDistanceKm *float64 // No distance limit if nil
+}
+
+// NearestDorm - A dorm with its distance from the searched point
+type NearestDorm struct {
+	DormOccupancyPoint
+	DistanceKm float64 `json:"distance_km"`
+}
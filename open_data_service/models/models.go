@@ -13,6 +13,8 @@ type StDom struct {
 	Address         string             `bson:"address" json:"address"`
 	TelephoneNumber string             `bson:"telephone_number" json:"telephone_number"`
 	Email           string             `bson:"email" json:"email"`
+	Latitude        *float64           `bson:"latitude,omitempty" json:"latitude,omitempty"`   // WGS 84, nil if the dorm has no location yet
+	Longitude       *float64           `bson:"longitude,omitempty" json:"longitude,omitempty"` // WGS 84, nil if the dorm has no location yet
 	CreatedAt       time.Time          `bson:"created_at" json:"created_at"`
 	UpdatedAt       time.Time          `bson:"updated_at" json:"updated_at"`
 }
@@ -201,6 +203,8 @@ type DormOccupancyPoint struct {
 	TotalCapacity   int                `json:"total_capacity"`
 	OccupiedSpots   int                `json:"occupied_spots"`
 	AvailableSpots  int                `json:"available_spots"`
+	Latitude        *float64           `json:"latitude,omitempty"`
+	Longitude       *float64           `json:"longitude,omitempty"`
 }
 
 // OccupancySummary - Summary of occupancy across all dorms
@@ -216,8 +220,13 @@ type OccupancySummary struct {
 type ExportFormat string
 
 const (
-	ExportFormatJSON ExportFormat = "json"
-	ExportFormatCSV  ExportFormat = "csv"
+	ExportFormatJSON    ExportFormat = "json"
+	ExportFormatCSV     ExportFormat = "csv"
+	ExportFormatNDJSON  ExportFormat = "ndjson" // Newline-delimited JSON, one record per line
+	ExportFormatXML     ExportFormat = "xml"
+	ExportFormatXLSX    ExportFormat = "xlsx"    // Office Open XML spreadsheet (Excel)
+	ExportFormatODS     ExportFormat = "ods"     // OpenDocument spreadsheet (LibreOffice)
+	ExportFormatParquet ExportFormat = "parquet" // Apache Parquet, columnar
 )
 
 // ExportDataRequest - Request for data export
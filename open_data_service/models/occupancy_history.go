@@ -0,0 +1,81 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Time series granularities and levels
+const (
+	GranularityDay   = "day"
+	GranularityWeek  = "week"
+	GranularityMonth = "month"
+
+	OccupancyLevelTotal = "total"
+	OccupancyLevelDorm  = "dorm"
+	OccupancyLevelRoom  = "room"
+)
+
+// OccupancyRecord - Occupancy of one dorm and its rooms on one day, stored by the daily occupancy job
+// Running the job again on the same day overwrites the values, so the record holds the last values of the day
+type OccupancyRecord struct {
+	ID                 primitive.ObjectID    `bson:"_id,omitempty" json:"-"`
+	Date               time.Time             `bson:"date" json:"date"` // Midnight UTC of the day
+	DormID             primitive.ObjectID    `bson:"dorm_id" json:"dorm_id"`
+	DormName           string                `bson:"dorm_name" json:"dorm_name"`
+	Capacity           int                   `bson:"capacity" json:"capacity"`
+	Occupied           int                   `bson:"occupied" json:"occupied"`
+	ActiveApplications int                   `bson:"active_applications" json:"active_applications"`
+	Rooms              []RoomOccupancyRecord `bson:"rooms" json:"rooms"`
+	CreatedAt          time.Time             `bson:"created_at" json:"created_at"`
+	UpdatedAt          time.Time             `bson:"updated_at" json:"updated_at"`
+}
+
+// RoomOccupancyRecord - Occupancy of one room on one day
+type RoomOccupancyRecord struct {
+	RoomID             primitive.ObjectID `bson:"room_id" json:"room_id"`
+	Capacity           int                `bson:"capacity" json:"capacity"`
+	Occupied           int                `bson:"occupied" json:"occupied"`
+	ActiveApplications int                `bson:"active_applications" json:"active_applications"`
+}
+
+// OccupancyTimeSeriesQuery - Parameters of an occupancy time series request
+type OccupancyTimeSeriesQuery struct {
+	From        time.Time // First day, inclusive
+	To          time.Time // Last day, inclusive
+	Granularity string    // "day", "week" (starting on Monday) or "month"
+	Level       string    // "total", "dorm" or "room"
+	DormID      primitive.ObjectID
+	RoomID      primitive.ObjectID
+}
+
+// OccupancyTimeSeries - Occupancy over a date range, one series per dorm or room
+type OccupancyTimeSeries struct {
+	From        string            `json:"from"`
+	To          string            `json:"to"`
+	Granularity string            `json:"granularity"`
+	Level       string            `json:"level"`
+	Series      []OccupancySeries `json:"series"`
+}
+
+// OccupancySeries - Occupancy of all dorms, one dorm or one room over time
+type OccupancySeries struct {
+	DormID   string               `json:"dorm_id,omitempty"`
+	DormName string               `json:"dorm_name,omitempty"`
+	RoomID   string               `json:"room_id,omitempty"`
+	Points   []OccupancyTimePoint `json:"points"`
+}
+
+// OccupancyTimePoint - Occupancy in one period
+// For week and month granularity the values are averages over the recorded days of the period
+type OccupancyTimePoint struct {
+	PeriodStart        string  `json:"period_start"` // YYYY-MM-DD
+	Days               int     `json:"days"`         // Recorded days in the period
+	Capacity           float64 `json:"capacity"`
+	Occupied           float64 `json:"occupied"`
+	Available          float64 `json:"available"`
+	ActiveApplications float64 `json:"active_applications"`
+	OccupancyRate      float64 `json:"occupancy_rate"`
+	PeakOccupied       int     `json:"peak_occupied"`
+}
@@ -0,0 +1,58 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// DatasetSnapshot is an immutable, versioned copy of an export dataset
+// Versions are numbered per dataset starting at 1; a new version is stored only when the data changed
+type DatasetSnapshot struct {
+	ID           primitive.ObjectID `bson:"_id,omitempty" json:"-"`
+	Dataset      string             `bson:"dataset" json:"dataset"`
+	Version      int                `bson:"version" json:"version"`
+	CreatedAt    time.Time          `bson:"created_at" json:"created_at"`
+	Rows         int                `bson:"rows" json:"rows"`                   // Data rows, without the CSV header
+	DataChecksum string             `bson:"data_checksum" json:"data_checksum"` // SHA-256 of the CSV rows in sorted order, used to detect changes
+	Files        []SnapshotFile     `bson:"files" json:"files"`
+	// Anonymization applied when the snapshot was taken; versions of personal datasets without it are never served
+	Anonymization *AnonymizationPolicy `bson:"anonymization,omitempty" json:"anonymization,omitempty"`
+}
+
+// SnapshotFile is one stored format of a snapshot
+type SnapshotFile struct {
+	Format  ExportFormat `bson:"format" json:"format"`
+	BlobKey string       `bson:"blob_key" json:"-"`
+	Size    int64        `bson:"size" json:"size"`
+	SHA256  string       `bson:"sha256" json:"sha256"`
+	URL     string       `bson:"-" json:"url"`
+}
+
+// File returns the stored file of the given format, or nil
+func (s *DatasetSnapshot) File(format ExportFormat) *SnapshotFile {
+	for i := range s.Files {
+		if s.Files[i].Format == format {
+			return &s.Files[i]
+		}
+	}
+	return nil
+}
+
+// SnapshotDiff - Row-level difference between two versions of a dataset
+// Rows have no stable key, so a changed row appears once as removed and once as added
+type SnapshotDiff struct {
+	Dataset        string     `json:"dataset"`
+	From           int        `json:"from"`
+	To             int        `json:"to"`
+	FromCreatedAt  time.Time  `json:"from_created_at"`
+	ToCreatedAt    time.Time  `json:"to_created_at"`
+	HeaderChanged  bool       `json:"header_changed"`
+	Header         []string   `json:"header"`
+	AddedCount     int        `json:"added_count"`
+	RemovedCount   int        `json:"removed_count"`
+	UnchangedCount int        `json:"unchanged_count"`
+	Added          [][]string `json:"added"`
+	Removed        [][]string `json:"removed"`
+	Truncated      bool       `json:"truncated"` // Added/Removed hold at most the requested number of rows
+}
@@ -3,6 +3,7 @@ package routes
 import (
 	"open_data_service/handlers"
 	"open_data_service/middleware"
+	"open_data_service/utils"
 
 	"github.com/gin-gonic/gin"
 )
@@ -11,7 +12,15 @@ import (
 func SetupRoutes(
 	router *gin.Engine,
 	openDataHandler *handlers.OpenDataHandler,
+	catalogHandler *handlers.CatalogHandler,
+	ckanHandler *handlers.CKANHandler,
+	apiKeyHandler *handlers.APIKeyHandler,
+	snapshotHandler *handlers.SnapshotHandler,
+	occupancyHistoryHandler *handlers.OccupancyHistoryHandler,
+	anonymizationHandler *handlers.AnonymizationHandler,
 	healthHandler *handlers.HealthHandler,
+	rateLimit gin.HandlerFunc,
+	jwksCache *utils.JWKSCache,
 ) {
 	// Apply CORS middleware
 	router.Use(middleware.CORS())
@@ -19,11 +28,29 @@ func SetupRoutes(
 	// Health check endpoint
 	router.GET("/health", healthHandler.HealthCheck)
 
+	// CKAN Action API (read-only) for CKAN harvesters, also under the unversioned /api/action path
+	for _, prefix := range []string{"/api/3/action", "/api/action"} {
+		ckan := router.Group(prefix)
+		ckan.Use(rateLimit)
+		{
+			ckan.GET("/package_list", ckanHandler.PackageList)
+			ckan.GET("/package_show", ckanHandler.PackageShow)
+			ckan.GET("/package_search", ckanHandler.PackageSearch)
+			ckan.GET("/resource_show", ckanHandler.ResourceShow)
+			ckan.POST("/package_list", ckanHandler.PackageList)
+			ckan.POST("/package_show", ckanHandler.PackageShow)
+			ckan.POST("/package_search", ckanHandler.PackageSearch)
+			ckan.POST("/resource_show", ckanHandler.ResourceShow)
+		}
+	}
+
 	// API v1 routes
 	v1 := router.Group("/api/v1")
 	{
 		// Open Data routes group
+		// Rate limited per IP address, or per API key sent in the X-API-Key header
 		openData := v1.Group("/open-data")
+		openData.Use(rateLimit)
 		{
 			// 1. Public Statistics Dashboard
 			openData.GET("/statistics", openDataHandler.GetPublicStatistics)
@@ -46,15 +73,43 @@ func SetupRoutes(
 
 			// 7. Real-time Occupancy Heatmap
 			openData.GET("/occupancy/heatmap", openDataHandler.GetOccupancyHeatmap)
+			openData.GET("/occupancy/history", occupancyHistoryHandler.GetOccupancyHistory)
+			// Dorm locations with occupancy for maps (GeoJSON) and the nearest dorms to a point
+			openData.GET("/dorms.geojson", openDataHandler.GetDormsGeoJSON)
+			openData.GET("/dorms/nearest", openDataHandler.FindNearestDorms)
 
 			// 8. Open Data Export (CSV/JSON)
 			openData.GET("/export", openDataHandler.ExportData)
+			// What was done to protect students in each dataset (pseudonyms, grade ranges, k-anonymity)
+			openData.GET("/export/policies", anonymizationHandler.GetPolicies)
+			openData.GET("/export/policies/:dataset", anonymizationHandler.GetPolicy)
+
+			// DCAT-AP catalog of the export datasets (JSON-LD or RDF/XML) for open data portals
+			openData.GET("/catalog", catalogHandler.GetCatalog)
+			openData.GET("/catalog/datasets/:datasetId", catalogHandler.GetDataset)
+
+			// Versioned, immutable snapshots of the export datasets; /latest always points to the newest version
+			openData.GET("/snapshots", snapshotHandler.ListSnapshots)
+			openData.GET("/snapshots/:dataset", snapshotHandler.ListVersions)
+			openData.GET("/snapshots/:dataset/latest", snapshotHandler.DownloadLatest)
+			openData.GET("/snapshots/:dataset/versions/:version", snapshotHandler.DownloadVersion)
+			openData.GET("/snapshots/:dataset/diff", snapshotHandler.DiffVersions)
 
 			// Helper endpoints
 			openData.GET("/amenities", openDataHandler.GetAvailableAmenities)
 			
 			// 9. Repairs (proxies to st_dom_service)
 			openData.GET("/repairs/active", openDataHandler.GetActiveRepairs)
+
+			// 10. API key usage (requires X-API-Key)
+			openData.GET("/usage", apiKeyHandler.GetMyUsage)
+		}
+
+		// Internal routes - only for other services with a service token
+		internal := v1.Group("/internal")
+		internal.Use(middleware.ServiceAuth(jwksCache))
+		{
+			internal.GET("/api-keys/:keyId/usage", apiKeyHandler.GetKeyUsage)
 		}
 	}
 }
@@ -0,0 +1,246 @@
+package services
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"open_data_service/models"
+	"sort"
+)
+
+// AnonymizationConfig holds the settings of the anonymization applied to published student data
+type AnonymizationConfig struct {
+	// Key of the HMAC used for pseudonyms; changing it changes every published pseudonym
+	PseudonymKey string
+	// Smallest group of students that can be published (k in k-anonymity)
+	MinGroupSize int
+	// Number of grades in one grade range, e.g. 2 gives 6-7, 8-9 and 10
+	GradeBucketWidth int
+}
+
+// AnonymizationService pseudonymizes, generalizes and suppresses student data before it is published
+type AnonymizationService struct {
+	config AnonymizationConfig
+}
+
+// NewAnonymizationService creates a new AnonymizationService
+func NewAnonymizationService(config AnonymizationConfig) *AnonymizationService {
+	if config.MinGroupSize < 1 {
+		config.MinGroupSize = 1
+	}
+	if config.GradeBucketWidth < 1 {
+		config.GradeBucketWidth = 1
+	}
+	return &AnonymizationService{config: config}
+}
+
+// Pseudonym returns a stable pseudonym for the identifier within one dataset
+// The dataset is part of the HMAC input, so the same student cannot be linked across datasets
+func (s *AnonymizationService) Pseudonym(dataset, identifier string) string {
+	mac := hmac.New(sha256.New, []byte(s.config.PseudonymKey))
+	mac.Write([]byte(dataset + "\x00" + identifier))
+	return hex.EncodeToString(mac.Sum(nil))[:16]
+}
+
+// GradeBucket returns the grade range containing the grade, e.g. "8-9"
+// Grades are 6 to 10, so ranges start at 6
+func (s *AnonymizationService) GradeBucket(grade int) string {
+	width := s.config.GradeBucketWidth
+	if width == 1 || grade < 6 {
+		return fmt.Sprintf("%d", grade)
+	}
+
+	low := 6 + (grade-6)/width*width
+	high := min(low+width-1, 10)
+	if low == high {
+		return fmt.Sprintf("%d", low)
+	}
+	return fmt.Sprintf("%d-%d", low, high)
+}
+
+// IsSmallGroup reports whether a group is too small to publish values derived from its grades
+func (s *AnonymizationService) IsSmallGroup(size int) bool {
+	return size < s.config.MinGroupSize
+}
+
+// kAnonymousGroups receives rows sorted by their quasi-identifiers and passes on the groups of at least k rows
+// Only the current group is kept in memory; within a group the rows are passed on in the order given by less
+type kAnonymousGroups[T any] struct {
+	k                int
+	quasiIdentifiers func(T) string
+	less             func(a, b T) bool
+	write            func(T) error
+	group            []T
+	groupKey         string
+}
+
+// newKAnonymousGroups creates a kAnonymousGroups that passes the rows it keeps to write
+func newKAnonymousGroups[T any](k int, quasiIdentifiers func(T) string, less func(a, b T) bool, write func(T) error) *kAnonymousGroups[T] {
+	return &kAnonymousGroups[T]{
+		k:                k,
+		quasiIdentifiers: quasiIdentifiers,
+		less:             less,
+		write:            write,
+	}
+}
+
+// Add adds a row; the previous group is complete when a row with other quasi-identifiers arrives
+func (g *kAnonymousGroups[T]) Add(row T) error {
+	key := g.quasiIdentifiers(row)
+	if len(g.group) > 0 && key != g.groupKey {
+		if err := g.flush(); err != nil {
+			return err
+		}
+	}
+
+	g.groupKey = key
+	g.group = append(g.group, row)
+	return nil
+}
+
+// Close passes on the last group
+func (g *kAnonymousGroups[T]) Close() error {
+	return g.flush()
+}
+
+// flush passes on the current group if it is large enough and starts a new one
+func (g *kAnonymousGroups[T]) flush() error {
+	defer func() {
+		g.group = g.group[:0]
+	}()
+
+	if len(g.group) < g.k {
+		return nil
+	}
+
+	sort.Slice(g.group, func(i, j int) bool {
+		return g.less(g.group[i], g.group[j])
+	})
+	for _, row := range g.group {
+		if err := g.write(row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetPolicies returns the anonymization policy of every dataset in the catalog
+func (s *AnonymizationService) GetPolicies() []models.AnonymizationPolicy {
+	policies := make([]models.AnonymizationPolicy, 0, len(datasetDescriptors))
+	for _, descriptor := range datasetDescriptors {
+		policies = append(policies, s.policy(descriptor.id))
+	}
+	return policies
+}
+
+// GetPolicy returns the anonymization policy of one dataset
+func (s *AnonymizationService) GetPolicy(dataset string) (*models.AnonymizationPolicy, error) {
+	if !isCatalogDataset(dataset) {
+		return nil, ErrDatasetNotFound
+	}
+
+	policy := s.policy(dataset)
+	return &policy, nil
+}
+
+// policy describes what the export of the dataset does to protect students
+func (s *AnonymizationService) policy(dataset string) models.AnonymizationPolicy {
+	k := s.config.MinGroupSize
+	pseudonymization := models.AnonymizationTechnique{
+		Technique:   models.TechniquePseudonymization,
+		Fields:      []string{"pseudonim"},
+		Description: "The student index number is replaced by a keyed HMAC-SHA256 pseudonym. A student keeps the same pseudonym in every export of this dataset, but has a different one in other datasets. The index number cannot be recovered from the pseudonym.",
+	}
+	gradeGeneralization := models.AnonymizationTechnique{
+		Technique:   models.TechniqueGeneralization,
+		Fields:      []string{"raspon_proseka"},
+		Description: fmt.Sprintf("The grade average is published as a range of %d grades instead of the exact value.", s.config.GradeBucketWidth),
+	}
+
+	switch dataset {
+	case "accepted-applications":
+		return models.AnonymizationPolicy{
+			Dataset:      dataset,
+			PersonalData: true,
+			Summary:      "One row per accepted application. Rows are pseudonymized, grades are generalized and rows of small groups are suppressed.",
+			Techniques: []models.AnonymizationTechnique{
+				{
+					Technique:   models.TechniqueRemoval,
+					Fields:      []string{"broj_indexa", "user_id", "soba_id", "created_at"},
+					Description: "Index numbers, database IDs, rooms and acceptance timestamps are not published. The academic year is the only time information.",
+				},
+				pseudonymization,
+				gradeGeneralization,
+				{
+					Technique:   models.TechniqueKAnonymity,
+					Fields:      []string{"ime_doma", "academic_year", "raspon_proseka"},
+					Description: fmt.Sprintf("A row is published only if at least %d rows share the same dorm, academic year and grade range. Other rows are left out.", k),
+				},
+			},
+		}
+	case "application-list":
+		return models.AnonymizationPolicy{
+			Dataset:      dataset,
+			PersonalData: true,
+			Summary:      "One row per room application. Rows are pseudonymized, grades and dates are generalized and rows of small groups are suppressed.",
+			Techniques: []models.AnonymizationTechnique{
+				{
+					Technique:   models.TechniqueRemoval,
+					Fields:      []string{"broj_indexa", "user_id", "soba_id"},
+					Description: "Index numbers, database IDs and rooms are not published.",
+				},
+				pseudonymization,
+				gradeGeneralization,
+				{
+					Technique:   models.TechniqueGeneralization,
+					Fields:      []string{"godina_prijave"},
+					Description: "The application timestamp is published as the year only.",
+				},
+				{
+					Technique:   models.TechniqueKAnonymity,
+					Fields:      []string{"ime_doma", "kapacitet_sobe", "raspon_proseka", "aktivna", "godina_prijave"},
+					Description: fmt.Sprintf("A row is published only if at least %d rows share the same dorm, room capacity, grade range, status and year. Other rows are left out.", k),
+				},
+			},
+		}
+	case "dorm-statistics":
+		return models.AnonymizationPolicy{
+			Dataset:      dataset,
+			PersonalData: false,
+			Summary:      "Aggregated statistics per dorm. The average grade of a small group of students is not published.",
+			Techniques: []models.AnonymizationTechnique{
+				{
+					Technique:   models.TechniqueSuppression,
+					Fields:      []string{"prosecan_prosek"},
+					Description: fmt.Sprintf("The average grade of a dorm is left empty if fewer than %d accepted students live in it.", k),
+				},
+			},
+		}
+	case "yearly-trends":
+		return models.AnonymizationPolicy{
+			Dataset:      dataset,
+			PersonalData: false,
+			Summary:      "Aggregated statistics per academic year. The average grade of a small group of students is not published.",
+			Techniques: []models.AnonymizationTechnique{
+				{
+					Technique:   models.TechniqueRemoval,
+					Fields:      []string{"min_grade", "max_grade"},
+					Description: "The lowest and highest grades of a year are not published, since each belongs to a single student.",
+				},
+				{
+					Technique:   models.TechniqueSuppression,
+					Fields:      []string{"prosecan_prosek"},
+					Description: fmt.Sprintf("The average grade of an academic year is left empty if fewer than %d applications were accepted.", k),
+				},
+			},
+		}
+	}
+
+	return models.AnonymizationPolicy{
+		Dataset:      dataset,
+		PersonalData: false,
+		Summary:      "Contains only data about dorms, rooms and repairs or counts without grades, so no anonymization is applied.",
+		Techniques:   []models.AnonymizationTechnique{},
+	}
+}
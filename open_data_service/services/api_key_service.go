@@ -0,0 +1,294 @@
+package services
+
+import (
+	"bytes"
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"open_data_service/models"
+	"open_data_service/utils"
+	"strings"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// usageDateFormat is the format of the day a usage record belongs to (UTC)
+const usageDateFormat = "2006-01-02"
+
+// maxCachedAPIKeys is the number of cached verification results; the least recently used one is evicted first
+const maxCachedAPIKeys = 1000
+
+var (
+	ErrInvalidAPIKey             = errors.New("invalid or revoked API key")
+	ErrAPIKeyVerificationFailure = errors.New("API key verification is temporarily unavailable")
+)
+
+// APIKeyService verifies API keys with sso_service and enforces and reports their daily quotas
+// Keys are issued and revoked in sso_service; verification results are cached for cacheTTL,
+// so a revoked key stops working at most cacheTTL later
+type APIKeyService struct {
+	usageCollection *mongo.Collection
+	verifyURL       string
+	serviceTokens   *utils.ServiceTokenSource
+	httpClient      *http.Client
+	cacheTTL        time.Duration
+	usageRetention  time.Duration
+
+	mu         sync.Mutex
+	cache      map[string]*list.Element
+	cacheOrder *list.List // Most recently used first
+}
+
+// cachedAPIKey is a cached verification result; a nil info means the key was rejected
+type cachedAPIKey struct {
+	hash      string
+	info      *models.APIKeyInfo
+	expiresAt time.Time
+}
+
+// NewAPIKeyService creates a new APIKeyService
+// usageRetention is how long daily usage records are kept
+func NewAPIKeyService(usageCollection *mongo.Collection, ssoServiceURL string, serviceTokens *utils.ServiceTokenSource, cacheTTL, usageRetention time.Duration) *APIKeyService {
+	return &APIKeyService{
+		usageCollection: usageCollection,
+		verifyURL:       ssoServiceURL + "/api/v1/internal/api-keys/verify",
+		serviceTokens:   serviceTokens,
+		httpClient:      &http.Client{Timeout: 10 * time.Second},
+		cacheTTL:        cacheTTL,
+		usageRetention:  usageRetention,
+		cache:           make(map[string]*list.Element),
+		cacheOrder:      list.New(),
+	}
+}
+
+// EnsureIndexes creates the unique per-key-per-day index and the TTL index for old usage records
+func (s *APIKeyService) EnsureIndexes() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err := s.usageCollection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "key_id", Value: 1}, {Key: "date", Value: -1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "expires_at", Value: 1}}, Options: options.Index().SetExpireAfterSeconds(0)},
+	})
+	return err
+}
+
+// Verify returns the limits of a valid API key
+// Returns ErrInvalidAPIKey for unknown or revoked keys and ErrAPIKeyVerificationFailure when sso_service cannot be reached
+func (s *APIKeyService) Verify(ctx context.Context, key string) (*models.APIKeyInfo, error) {
+	hash := apiKeyHash(key)
+
+	if cached, exists := s.cached(hash); exists {
+		if cached.info == nil {
+			return nil, ErrInvalidAPIKey
+		}
+		return cached.info, nil
+	}
+
+	info, err := s.verifyWithSSO(ctx, key)
+	if err != nil && !errors.Is(err, ErrInvalidAPIKey) {
+		return nil, err
+	}
+
+	s.store(cachedAPIKey{hash: hash, info: info, expiresAt: time.Now().Add(s.cacheTTL)})
+
+	if info == nil {
+		return nil, ErrInvalidAPIKey
+	}
+	return info, nil
+}
+
+// IsCached reports whether a verification result of the key is cached, so Verify does not call sso_service
+func (s *APIKeyService) IsCached(key string) bool {
+	_, exists := s.cached(apiKeyHash(key))
+	return exists
+}
+
+// cached returns the unexpired verification result of a key hash and marks it as recently used
+func (s *APIKeyService) cached(hash string) (cachedAPIKey, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	element, exists := s.cache[hash]
+	if !exists {
+		return cachedAPIKey{}, false
+	}
+
+	entry := element.Value.(cachedAPIKey)
+	if !time.Now().Before(entry.expiresAt) {
+		s.cacheOrder.Remove(element)
+		delete(s.cache, hash)
+		return cachedAPIKey{}, false
+	}
+
+	s.cacheOrder.MoveToFront(element)
+	return entry, true
+}
+
+// store caches a verification result and evicts the least recently used results above maxCachedAPIKeys
+func (s *APIKeyService) store(entry cachedAPIKey) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if element, exists := s.cache[entry.hash]; exists {
+		element.Value = entry
+		s.cacheOrder.MoveToFront(element)
+		return
+	}
+
+	s.cache[entry.hash] = s.cacheOrder.PushFront(entry)
+	for s.cacheOrder.Len() > maxCachedAPIKeys {
+		oldest := s.cacheOrder.Back()
+		s.cacheOrder.Remove(oldest)
+		delete(s.cache, oldest.Value.(cachedAPIKey).hash)
+	}
+}
+
+// apiKeyHash is the cache key of an API key, so plain keys are not kept in memory
+func apiKeyHash(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// verifyWithSSO asks sso_service whether the key is valid
+func (s *APIKeyService) verifyWithSSO(ctx context.Context, key string) (*models.APIKeyInfo, error) {
+	body, err := json.Marshal(map[string]string{"key": key})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.verifyURL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	if err := s.serviceTokens.Authorize(req); err != nil {
+		return nil, ErrAPIKeyVerificationFailure
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, ErrAPIKeyVerificationFailure
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusUnauthorized:
+		return nil, ErrInvalidAPIKey
+	default:
+		return nil, ErrAPIKeyVerificationFailure
+	}
+
+	var info models.APIKeyInfo
+	if err := json.NewDecoder(resp.Body).Decode(&info); err != nil {
+		return nil, ErrAPIKeyVerificationFailure
+	}
+
+	return &info, nil
+}
+
+// ConsumeQuota records a request of the key and charges cost units to today's quota
+// Returns the units used today and whether the request fits in the quota;
+// a request over the quota is counted as rejected and charges nothing
+func (s *APIKeyService) ConsumeQuota(ctx context.Context, key *models.APIKeyInfo, endpoint string, cost int) (int64, bool, error) {
+	now := time.Now()
+	filter := bson.M{"key_id": key.KeyID, "date": now.UTC().Format(usageDateFormat)}
+	endpointField := "endpoints." + usageEndpointName(endpoint)
+
+	update := bson.M{
+		"$inc":         bson.M{"requests": 1, "units": cost, endpointField: 1},
+		"$set":         bson.M{"updated_at": now},
+		"$setOnInsert": bson.M{"user_id": key.UserID, "rejected": 0, "expires_at": now.Add(s.usageRetention)},
+	}
+	updateOptions := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+
+	var usage models.APIKeyUsage
+	err := s.usageCollection.FindOneAndUpdate(ctx, filter, update, updateOptions).Decode(&usage)
+	if mongo.IsDuplicateKeyError(err) {
+		// Two first requests of the day raced on the upsert, the record exists now
+		err = s.usageCollection.FindOneAndUpdate(ctx, filter, update, updateOptions).Decode(&usage)
+	}
+	if err != nil {
+		return 0, false, err
+	}
+
+	if usage.Units <= int64(key.DailyQuota) {
+		return usage.Units, true, nil
+	}
+
+	_, err = s.usageCollection.UpdateOne(ctx, filter, bson.M{
+		"$inc": bson.M{"requests": -1, "units": -cost, endpointField: -1, "rejected": 1},
+	})
+	if err != nil {
+		return 0, false, err
+	}
+
+	return usage.Units - int64(cost), false, nil
+}
+
+// RecordRejected counts a request of the key that was rejected by the per-minute rate limit
+func (s *APIKeyService) RecordRejected(ctx context.Context, key *models.APIKeyInfo) error {
+	now := time.Now()
+
+	_, err := s.usageCollection.UpdateOne(ctx,
+		bson.M{"key_id": key.KeyID, "date": now.UTC().Format(usageDateFormat)},
+		bson.M{
+			"$inc":         bson.M{"rejected": 1},
+			"$set":         bson.M{"updated_at": now},
+			"$setOnInsert": bson.M{"user_id": key.UserID, "requests": 0, "units": 0, "endpoints": bson.M{}, "expires_at": now.Add(s.usageRetention)},
+		},
+		options.Update().SetUpsert(true),
+	)
+	return err
+}
+
+// GetUsage returns the usage of the key over the last days (including today), newest day first
+// Days without requests are left out
+func (s *APIKeyService) GetUsage(ctx context.Context, keyID string, days int) (*models.APIKeyUsageReport, error) {
+	today := time.Now().UTC()
+	from := today.AddDate(0, 0, -(days - 1)).Format(usageDateFormat)
+
+	findOptions := options.Find().SetSort(bson.D{{Key: "date", Value: -1}})
+	cursor, err := s.usageCollection.Find(ctx, bson.M{"key_id": keyID, "date": bson.M{"$gte": from}}, findOptions)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	report := &models.APIKeyUsageReport{
+		KeyID: keyID,
+		From:  from,
+		To:    today.Format(usageDateFormat),
+		Days:  []models.APIKeyUsage{},
+	}
+	if err := cursor.All(ctx, &report.Days); err != nil {
+		return nil, err
+	}
+
+	for _, day := range report.Days {
+		report.Requests += day.Requests
+		report.Units += day.Units
+		report.Rejected += day.Rejected
+	}
+
+	return report, nil
+}
+
+// usageEndpointName turns a route path into a usage field name
+// Dots and dollar signs are not allowed in MongoDB field names
+func usageEndpointName(endpoint string) string {
+	if endpoint == "" {
+		return "other"
+	}
+	return strings.NewReplacer(".", "_", "$", "_").Replace(endpoint)
+}
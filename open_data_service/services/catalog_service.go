@@ -0,0 +1,357 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"open_data_service/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// ErrDatasetNotFound is returned for dataset IDs that are not in the catalog
+var ErrDatasetNotFound = errors.New("dataset not found")
+
+// EU vocabularies used by DCAT-AP
+const (
+	euFrequency = "http://publications.europa.eu/resource/authority/frequency/"
+	euFileType  = "http://publications.europa.eu/resource/authority/file-type/"
+	euDataTheme = "http://publications.europa.eu/resource/authority/data-theme/"
+)
+
+// CatalogConfig holds the publisher details shown in the dataset catalog
+type CatalogConfig struct {
+	// Public address of the API (through the gateway), used to build dataset and download URIs
+	BaseURL      string
+	Publisher    string
+	ContactEmail string
+	License      string
+}
+
+// exportDistribution describes an export format offered for every dataset
+type exportDistribution struct {
+	format    models.ExportFormat
+	mediaType string
+	fileType  string
+}
+
+// exportDistributions are the formats of the /export endpoint offered in the catalog
+// fileType is the code of the format in the EU file type vocabulary
+var exportDistributions = []exportDistribution{
+	{format: models.ExportFormatCSV, mediaType: "text/csv", fileType: "CSV"},
+	{format: models.ExportFormatJSON, mediaType: "application/json", fileType: "JSON"},
+	{format: models.ExportFormatXML, mediaType: "application/xml", fileType: "XML"},
+	{format: models.ExportFormatXLSX, mediaType: "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", fileType: "XLSX"},
+	{format: models.ExportFormatODS, mediaType: "application/vnd.oasis.opendocument.spreadsheet", fileType: "ODS"},
+	{format: models.ExportFormatParquet, mediaType: "application/vnd.apache.parquet", fileType: "PARQUET"},
+}
+
+// temporalSource names the collection whose created_at dates give the temporal coverage of a dataset
+type temporalSource string
+
+const (
+	temporalDorms               temporalSource = "st_doms"
+	temporalRooms               temporalSource = "sobas"
+	temporalApplications        temporalSource = "aplikacije"
+	temporalAcceptedApplication temporalSource = "prihvacene_aplikacije"
+	temporalRepairs             temporalSource = "repairs"
+	temporalOccupancyHistory    temporalSource = "occupancy_history"
+	temporalCurrentYear         temporalSource = "current-year"
+)
+
+// datasetDescriptor is the catalog metadata of one dataset of the /export endpoint
+type datasetDescriptor struct {
+	id          string
+	title       models.LocalizedText
+	description models.LocalizedText
+	keywords    []models.LocalizedText
+	theme       string
+	frequency   string
+	temporal    temporalSource
+}
+
+// datasetDescriptors lists every dataset of the /export endpoint under its primary name
+var datasetDescriptors = []datasetDescriptor{
+	{
+		id:          "dorms",
+		title:       models.LocalizedText{SR: "Studentski domovi", EN: "Student dormitories"},
+		description: models.LocalizedText{SR: "Spisak studentskih domova sa adresom, geografskim koordinatama i kontakt podacima.", EN: "List of student dormitories with address, geographic coordinates and contact details."},
+		keywords:    []models.LocalizedText{{SR: "studentski dom", EN: "student dormitory"}, {SR: "smeštaj", EN: "accommodation"}},
+		theme:       "EDUC",
+		frequency:   "IRREG",
+		temporal:    temporalDorms,
+	},
+	{
+		id:          "rooms",
+		title:       models.LocalizedText{SR: "Sobe i raspoloživost", EN: "Rooms and availability"},
+		description: models.LocalizedText{SR: "Sobe u studentskim domovima sa kapacitetom, brojem zauzetih i slobodnih mesta i pogodnostima.", EN: "Rooms in student dormitories with capacity, occupied and free places and amenities."},
+		keywords:    []models.LocalizedText{{SR: "soba", EN: "room"}, {SR: "raspoloživost", EN: "availability"}},
+		theme:       "EDUC",
+		frequency:   "UPDATE_CONT",
+		temporal:    temporalRooms,
+	},
+	{
+		id:          "dorm-statistics",
+		title:       models.LocalizedText{SR: "Statistika po domovima", EN: "Dormitory statistics"},
+		description: models.LocalizedText{SR: "Broj soba, kapacitet, popunjenost i prosečan prosek primljenih studenata za svaki dom.", EN: "Number of rooms, capacity, occupancy and average grade of admitted students for each dormitory."},
+		keywords:    []models.LocalizedText{{SR: "statistika", EN: "statistics"}, {SR: "popunjenost", EN: "occupancy"}},
+		theme:       "EDUC",
+		frequency:   "UPDATE_CONT",
+		temporal:    temporalRooms,
+	},
+	{
+		id:          "application-list",
+		title:       models.LocalizedText{SR: "Prijave za sobe", EN: "Room applications"},
+		description: models.LocalizedText{SR: "Sve prijave za sobe sa prosekom, kapacitetom sobe i statusom prijave.", EN: "All room applications with grade average, room capacity and application status."},
+		keywords:    []models.LocalizedText{{SR: "prijava", EN: "application"}, {SR: "upis", EN: "admission"}},
+		theme:       "EDUC",
+		frequency:   "UPDATE_CONT",
+		temporal:    temporalApplications,
+	},
+	{
+		id:          "accepted-applications",
+		title:       models.LocalizedText{SR: "Prihvaćene prijave", EN: "Accepted applications"},
+		description: models.LocalizedText{SR: "Prihvaćene prijave sa prosekom, domom i akademskom godinom.", EN: "Accepted applications with grade average, dormitory and academic year."},
+		keywords:    []models.LocalizedText{{SR: "prihvaćena prijava", EN: "accepted application"}, {SR: "akademska godina", EN: "academic year"}},
+		theme:       "EDUC",
+		frequency:   "UPDATE_CONT",
+		temporal:    temporalAcceptedApplication,
+	},
+	{
+		id:          "yearly-trends",
+		title:       models.LocalizedText{SR: "Godišnja kretanja prijava", EN: "Yearly application trends"},
+		description: models.LocalizedText{SR: "Broj prijava, prihvaćenih prijava, stopa prihvatanja i prosečan prosek po školskoj godini.", EN: "Number of applications, accepted applications, acceptance rate and average grade per academic year."},
+		keywords:    []models.LocalizedText{{SR: "trend", EN: "trend"}, {SR: "školska godina", EN: "academic year"}},
+		theme:       "EDUC",
+		frequency:   "ANNUAL",
+		temporal:    temporalApplications,
+	},
+	{
+		id:          "dorm-trends",
+		title:       models.LocalizedText{SR: "Kretanja prijava po domovima", EN: "Application trends by dormitory"},
+		description: models.LocalizedText{SR: "Ukupan broj prijava, prihvaćenih prijava i stopa prihvatanja za svaki dom.", EN: "Total applications, accepted applications and acceptance rate for each dormitory."},
+		keywords:    []models.LocalizedText{{SR: "trend", EN: "trend"}, {SR: "studentski dom", EN: "student dormitory"}},
+		theme:       "EDUC",
+		frequency:   "ANNUAL",
+		temporal:    temporalApplications,
+	},
+	{
+		id:          "amenities-report",
+		title:       models.LocalizedText{SR: "Pogodnosti u sobama", EN: "Room amenities"},
+		description: models.LocalizedText{SR: "Zastupljenost pogodnosti u sobama: broj i udeo soba koje imaju svaku pogodnost.", EN: "Distribution of room amenities: number and share of rooms offering each amenity."},
+		keywords:    []models.LocalizedText{{SR: "pogodnosti", EN: "amenities"}, {SR: "soba", EN: "room"}},
+		theme:       "EDUC",
+		frequency:   "IRREG",
+		temporal:    temporalRooms,
+	},
+	{
+		id:          "occupancy-report",
+		title:       models.LocalizedText{SR: "Izveštaj o popunjenosti", EN: "Occupancy report"},
+		description: models.LocalizedText{SR: "Kapacitet, zauzeta i slobodna mesta, stopa popunjenosti i status svakog doma.", EN: "Capacity, occupied and free places, occupancy rate and status of each dormitory."},
+		keywords:    []models.LocalizedText{{SR: "popunjenost", EN: "occupancy"}, {SR: "kapacitet", EN: "capacity"}},
+		theme:       "EDUC",
+		frequency:   "UPDATE_CONT",
+		temporal:    temporalRooms,
+	},
+	{
+		id:          "occupancy-history",
+		title:       models.LocalizedText{SR: "Istorija popunjenosti", EN: "Occupancy history"},
+		description: models.LocalizedText{SR: "Dnevni kapacitet, zauzeta i slobodna mesta, stopa popunjenosti i broj aktivnih prijava za svaki dom.", EN: "Daily capacity, occupied and free places, occupancy rate and number of active applications for each dormitory."},
+		keywords:    []models.LocalizedText{{SR: "popunjenost", EN: "occupancy"}, {SR: "vremenska serija", EN: "time series"}},
+		theme:       "EDUC",
+		frequency:   "DAILY",
+		temporal:    temporalOccupancyHistory,
+	},
+	{
+		id:          "room-types",
+		title:       models.LocalizedText{SR: "Tipovi soba", EN: "Room types"},
+		description: models.LocalizedText{SR: "Broj soba, kapacitet i popunjenost po broju kreveta u sobi.", EN: "Number of rooms, capacity and occupancy by number of beds per room."},
+		keywords:    []models.LocalizedText{{SR: "tip sobe", EN: "room type"}, {SR: "kapacitet", EN: "capacity"}},
+		theme:       "EDUC",
+		frequency:   "IRREG",
+		temporal:    temporalRooms,
+	},
+	{
+		id:          "active-repairs",
+		title:       models.LocalizedText{SR: "Aktivni kvarovi", EN: "Active repairs"},
+		description: models.LocalizedText{SR: "Prijavljeni kvarovi koji čekaju na popravku ili se trenutno popravljaju, sa podacima o sobi.", EN: "Reported repairs that are pending or in progress, with room details."},
+		keywords:    []models.LocalizedText{{SR: "kvar", EN: "repair"}, {SR: "održavanje", EN: "maintenance"}},
+		theme:       "EDUC",
+		frequency:   "UPDATE_CONT",
+		temporal:    temporalRepairs,
+	},
+	{
+		id:          "completed-repairs",
+		title:       models.LocalizedText{SR: "Završene popravke u tekućoj godini", EN: "Completed repairs this year"},
+		description: models.LocalizedText{SR: "Popravke završene od početka tekuće godine, sa podacima o sobi.", EN: "Repairs completed since the start of the current year, with room details."},
+		keywords:    []models.LocalizedText{{SR: "kvar", EN: "repair"}, {SR: "održavanje", EN: "maintenance"}},
+		theme:       "EDUC",
+		frequency:   "UPDATE_CONT",
+		temporal:    temporalCurrentYear,
+	},
+}
+
+// datasetAliases maps the old dataset names still accepted by the /export endpoint to their primary names
+var datasetAliases = map[string]string{
+	"statistics":            "dorm-statistics",
+	"application-analytics": "application-list",
+	"godisnja-kretanja":     "yearly-trends",
+}
+
+// CanonicalDatasetID returns the primary name of a dataset
+func CanonicalDatasetID(dataset string) string {
+	if primary, exists := datasetAliases[dataset]; exists {
+		return primary
+	}
+	return dataset
+}
+
+// CatalogService builds the DCAT-AP catalog of the open datasets
+type CatalogService struct {
+	collections map[temporalSource]*mongo.Collection
+	config      CatalogConfig
+}
+
+// NewCatalogService creates a new CatalogService
+// The collections are used only to find the temporal coverage of the datasets
+func NewCatalogService(
+	stDomsCollection *mongo.Collection,
+	sobasCollection *mongo.Collection,
+	aplikacijeCollection *mongo.Collection,
+	prihvaceneAplikacijeCollection *mongo.Collection,
+	repairsCollection *mongo.Collection,
+	occupancyHistoryCollection *mongo.Collection,
+	config CatalogConfig,
+) *CatalogService {
+	return &CatalogService{
+		collections: map[temporalSource]*mongo.Collection{
+			temporalDorms:               stDomsCollection,
+			temporalRooms:               sobasCollection,
+			temporalApplications:        aplikacijeCollection,
+			temporalAcceptedApplication: prihvaceneAplikacijeCollection,
+			temporalRepairs:             repairsCollection,
+			temporalOccupancyHistory:    occupancyHistoryCollection,
+		},
+		config: config,
+	}
+}
+
+// GetCatalog returns the catalog of all datasets with their distributions
+func (s *CatalogService) GetCatalog() (*models.DatasetCatalog, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	now := time.Now().UTC()
+	catalog := &models.DatasetCatalog{
+		URI: s.config.BaseURL + "/api/v1/open-data/catalog",
+		Title: models.LocalizedText{
+			SR: "Otvoreni podaci studentskih domova",
+			EN: "Student dormitories open data",
+		},
+		Description: models.LocalizedText{
+			SR: "Podaci o studentskim domovima, sobama, prijavama za smeštaj, popunjenosti i održavanju.",
+			EN: "Data on student dormitories, rooms, accommodation applications, occupancy and maintenance.",
+		},
+		Publisher:   s.config.Publisher,
+		HomepageURL: s.config.BaseURL,
+		License:     s.config.License,
+		Modified:    now,
+		Datasets:    make([]models.CatalogDataset, 0, len(datasetDescriptors)),
+	}
+
+	// Several datasets share a source collection, look each one up only once
+	coverage := make(map[temporalSource]*time.Time)
+
+	for _, descriptor := range datasetDescriptors {
+		start, looked := coverage[descriptor.temporal]
+		if !looked {
+			var err error
+			start, err = s.temporalStart(ctx, descriptor.temporal, now)
+			if err != nil {
+				return nil, err
+			}
+			coverage[descriptor.temporal] = start
+		}
+
+		catalog.Datasets = append(catalog.Datasets, s.buildDataset(descriptor, start))
+	}
+
+	return catalog, nil
+}
+
+// GetDatasetCatalog returns the catalog with only the given dataset, served at the dataset URI
+func (s *CatalogService) GetDatasetCatalog(datasetID string) (*models.DatasetCatalog, error) {
+	catalog, err := s.GetCatalog()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, dataset := range catalog.Datasets {
+		if dataset.ID == datasetID {
+			catalog.Datasets = []models.CatalogDataset{dataset}
+			return catalog, nil
+		}
+	}
+
+	return nil, ErrDatasetNotFound
+}
+
+// buildDataset creates the catalog entry of a dataset with one distribution per export format
+func (s *CatalogService) buildDataset(descriptor datasetDescriptor, temporalStart *time.Time) models.CatalogDataset {
+	datasetURI := s.config.BaseURL + "/api/v1/open-data/catalog/datasets/" + descriptor.id
+
+	dataset := models.CatalogDataset{
+		ID:                 descriptor.id,
+		URI:                datasetURI,
+		Title:              descriptor.title,
+		Description:        descriptor.description,
+		Keywords:           descriptor.keywords,
+		Theme:              euDataTheme + descriptor.theme,
+		AccrualPeriodicity: euFrequency + descriptor.frequency,
+		TemporalStart:      temporalStart,
+		ContactEmail:       s.config.ContactEmail,
+		Distributions:      make([]models.CatalogDistribution, 0, len(exportDistributions)),
+	}
+
+	for _, distribution := range exportDistributions {
+		dataset.Distributions = append(dataset.Distributions, models.CatalogDistribution{
+			URI:         datasetURI + "#" + string(distribution.format),
+			Format:      distribution.format,
+			MediaType:   distribution.mediaType,
+			FileType:    euFileType + distribution.fileType,
+			DownloadURL: s.config.BaseURL + "/api/v1/open-data/export?dataset=" + descriptor.id + "&format=" + string(distribution.format),
+		})
+	}
+
+	return dataset
+}
+
+// temporalStart returns the date of the oldest record the dataset is built from, or nil when there is no data
+func (s *CatalogService) temporalStart(ctx context.Context, source temporalSource, now time.Time) (*time.Time, error) {
+	if source == temporalCurrentYear {
+		start := time.Date(now.Year(), time.January, 1, 0, 0, 0, 0, time.UTC)
+		return &start, nil
+	}
+
+	cursor, err := s.collections[source].Aggregate(ctx, []bson.M{
+		{"$group": bson.M{"_id": nil, "start": bson.M{"$min": "$created_at"}}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var results []struct {
+		Start *time.Time `bson:"start"`
+	}
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+
+	if len(results) == 0 || results[0].Start == nil {
+		return nil, nil
+	}
+	start := results[0].Start.UTC()
+	return &start, nil
+}
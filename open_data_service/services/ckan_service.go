@@ -0,0 +1,343 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"open_data_service/models"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ckanTimeFormat is the timestamp format used by CKAN (UTC, without a zone)
+const ckanTimeFormat = "2006-01-02T15:04:05.000000"
+
+var (
+	ErrCKANNotFound      = errors.New("Not found")
+	ErrCKANInvalidFilter = errors.New("unsupported filter query, supported fields are name, id, tags, res_format, organization and metadata_modified")
+)
+
+// ckanLicenses maps license URIs to CKAN license IDs and titles
+var ckanLicenses = map[string]struct{ id, title string }{
+	"http://creativecommons.org/licenses/by/4.0/":       {"cc-by", "Creative Commons Attribution 4.0"},
+	"http://creativecommons.org/licenses/by-sa/4.0/":    {"cc-by-sa", "Creative Commons Attribution Share-Alike 4.0"},
+	"http://creativecommons.org/publicdomain/zero/1.0/": {"cc-zero", "Creative Commons CCZero"},
+	"http://opendatacommons.org/licenses/by/1.0/":       {"odc-by", "Open Data Commons Attribution License"},
+}
+
+// ckanFilterTerm matches one "field:value" term of a filter query; values can be quoted or a [from TO to] range
+var ckanFilterTerm = regexp.MustCompile(`([A-Za-z_]+):("[^"]*"|\[[^\]]*\]|\S+)`)
+
+// ckanSlugInvalid matches the characters not allowed in CKAN identifiers
+var ckanSlugInvalid = regexp.MustCompile(`[^a-z0-9]+`)
+
+// CKANService maps the dataset catalog onto the read-only CKAN Action API
+type CKANService struct {
+	catalogService *CatalogService
+}
+
+// NewCKANService creates a new CKANService
+func NewCKANService(catalogService *CatalogService) *CKANService {
+	return &CKANService{
+		catalogService: catalogService,
+	}
+}
+
+// PackageList returns the names of all packages, sorted by name
+func (s *CKANService) PackageList(limit, offset int) ([]string, error) {
+	packages, err := s.packages()
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(packages))
+	for _, pkg := range packages {
+		names = append(names, pkg.Name)
+	}
+
+	return paginate(names, limit, offset), nil
+}
+
+// PackageShow returns one package by ID or name
+func (s *CKANService) PackageShow(id string) (*models.CKANPackage, error) {
+	packages, err := s.packages()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, pkg := range packages {
+		if pkg.ID == id || pkg.Name == id {
+			return &pkg, nil
+		}
+	}
+
+	return nil, ErrCKANNotFound
+}
+
+// PackageSearch returns the packages matching the free text query and the filter query
+func (s *CKANService) PackageSearch(query models.CKANSearchQuery) (*models.CKANSearchResult, error) {
+	packages, err := s.packages()
+	if err != nil {
+		return nil, err
+	}
+
+	// Solr style "field:value" terms in q are treated as filters
+	filter := query.Fq
+	text := strings.TrimSpace(query.Q)
+	if text == "*:*" {
+		text = ""
+	} else if ckanFilterTerm.MatchString(text) {
+		filter = strings.TrimSpace(filter + " " + text)
+		text = ""
+	}
+
+	terms := ckanFilterTerm.FindAllStringSubmatch(filter, -1)
+	for _, term := range terms {
+		if !isSupportedCKANFilter(term[1]) {
+			return nil, ErrCKANInvalidFilter
+		}
+	}
+
+	matches := []models.CKANPackage{}
+	for _, pkg := range packages {
+		if matchesCKANText(pkg, text) && matchesCKANFilters(pkg, terms) {
+			matches = append(matches, pkg)
+		}
+	}
+
+	return &models.CKANSearchResult{
+		Count:        len(matches),
+		Sort:         "name asc",
+		Results:      paginate(matches, query.Rows, query.Start),
+		Facets:       map[string]interface{}{},
+		SearchFacets: map[string]interface{}{},
+	}, nil
+}
+
+// ResourceShow returns one resource by ID
+func (s *CKANService) ResourceShow(id string) (*models.CKANResource, error) {
+	packages, err := s.packages()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, pkg := range packages {
+		for _, resource := range pkg.Resources {
+			if resource.ID == id {
+				return &resource, nil
+			}
+		}
+	}
+
+	return nil, ErrCKANNotFound
+}
+
+// packages converts every catalog dataset to a CKAN package, sorted by name
+func (s *CKANService) packages() ([]models.CKANPackage, error) {
+	catalog, err := s.catalogService.GetCatalog()
+	if err != nil {
+		return nil, err
+	}
+
+	organization := models.CKANOrganization{
+		ID:             ckanSlug(catalog.Publisher),
+		Name:           ckanSlug(catalog.Publisher),
+		Title:          catalog.Publisher,
+		Type:           "organization",
+		State:          "active",
+		IsOrganization: true,
+	}
+
+	packages := make([]models.CKANPackage, 0, len(catalog.Datasets))
+	for _, dataset := range catalog.Datasets {
+		packages = append(packages, toCKANPackage(dataset, catalog, organization))
+	}
+
+	sort.Slice(packages, func(i, j int) bool {
+		return packages[i].Name < packages[j].Name
+	})
+
+	return packages, nil
+}
+
+// toCKANPackage converts a catalog dataset to a CKAN package
+// CKAN has a single title and description, so the Serbian texts are used and the English ones go to extras
+func toCKANPackage(dataset models.CatalogDataset, catalog *models.DatasetCatalog, organization models.CKANOrganization) models.CKANPackage {
+	modified := catalog.Modified.UTC().Format(ckanTimeFormat)
+	created := modified
+	if dataset.TemporalStart != nil {
+		created = dataset.TemporalStart.UTC().Format(ckanTimeFormat)
+	}
+
+	license, known := ckanLicenses[catalog.License]
+	if !known {
+		license.id, license.title = "other-open", "Other (Open)"
+	}
+
+	pkg := models.CKANPackage{
+		ID:               dataset.ID,
+		Name:             dataset.ID,
+		Title:            dataset.Title.SR,
+		Notes:            dataset.Description.SR,
+		URL:              dataset.URI,
+		Type:             "dataset",
+		State:            "active",
+		LicenseID:        license.id,
+		LicenseTitle:     license.title,
+		LicenseURL:       catalog.License,
+		Maintainer:       catalog.Publisher,
+		MaintainerEmail:  dataset.ContactEmail,
+		MetadataCreated:  created,
+		MetadataModified: modified,
+		Organization:     organization,
+		OwnerOrg:         organization.ID,
+		Tags:             []models.CKANTag{},
+		Groups:           []models.CKANOrganization{},
+		Extras: []models.CKANExtra{
+			{Key: "title_en", Value: dataset.Title.EN},
+			{Key: "notes_en", Value: dataset.Description.EN},
+			{Key: "theme", Value: dataset.Theme},
+			{Key: "frequency", Value: dataset.AccrualPeriodicity},
+			{Key: "language", Value: "sr,en"},
+		},
+		Resources: make([]models.CKANResource, 0, len(dataset.Distributions)),
+	}
+	if dataset.TemporalStart != nil {
+		pkg.Extras = append(pkg.Extras, models.CKANExtra{Key: "temporal_start", Value: dataset.TemporalStart.Format("2006-01-02")})
+	}
+
+	seen := make(map[string]bool)
+	for _, keyword := range dataset.Keywords {
+		for _, name := range []string{keyword.SR, keyword.EN} {
+			if seen[name] {
+				continue
+			}
+			seen[name] = true
+			pkg.Tags = append(pkg.Tags, models.CKANTag{ID: name, Name: name, DisplayName: name, State: "active"})
+		}
+	}
+	pkg.NumTags = len(pkg.Tags)
+
+	for i, distribution := range dataset.Distributions {
+		format := strings.ToUpper(string(distribution.Format))
+		pkg.Resources = append(pkg.Resources, models.CKANResource{
+			ID:           dataset.ID + "-" + string(distribution.Format),
+			PackageID:    dataset.ID,
+			Name:         fmt.Sprintf("%s (%s)", dataset.Title.SR, format),
+			Description:  dataset.Description.SR,
+			URL:          distribution.DownloadURL,
+			Format:       format,
+			Mimetype:     distribution.MediaType,
+			State:        "active",
+			Position:     i,
+			Created:      created,
+			LastModified: modified,
+		})
+	}
+	pkg.NumResources = len(pkg.Resources)
+
+	return pkg
+}
+
+// isSupportedCKANFilter reports whether package_search can filter on the field
+func isSupportedCKANFilter(field string) bool {
+	switch field {
+	case "name", "id", "tags", "res_format", "organization", "metadata_modified":
+		return true
+	}
+	return false
+}
+
+// matchesCKANText reports whether the free text appears in the package names, texts or tags (case-insensitive)
+func matchesCKANText(pkg models.CKANPackage, text string) bool {
+	if text == "" {
+		return true
+	}
+
+	fields := []string{pkg.Name, pkg.Title, pkg.Notes}
+	for _, extra := range pkg.Extras {
+		if extra.Key == "title_en" || extra.Key == "notes_en" {
+			fields = append(fields, extra.Value)
+		}
+	}
+	for _, tag := range pkg.Tags {
+		fields = append(fields, tag.Name)
+	}
+
+	text = strings.ToLower(text)
+	for _, field := range fields {
+		if strings.Contains(strings.ToLower(field), text) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesCKANFilters reports whether the package matches every filter term
+func matchesCKANFilters(pkg models.CKANPackage, terms [][]string) bool {
+	for _, term := range terms {
+		field, value := term[1], strings.Trim(term[2], `"`)
+
+		matched := false
+		switch field {
+		case "name", "id":
+			matched = pkg.Name == value
+		case "organization":
+			matched = pkg.Organization.Name == value
+		case "tags":
+			for _, tag := range pkg.Tags {
+				matched = matched || strings.EqualFold(tag.Name, value)
+			}
+		case "res_format":
+			for _, resource := range pkg.Resources {
+				matched = matched || strings.EqualFold(resource.Format, value)
+			}
+		case "metadata_modified":
+			// Package metadata is generated on every request, so every package was modified just now
+			matched = matchesCKANDateRange(time.Now().UTC(), value)
+		}
+
+		if !matched {
+			return false
+		}
+	}
+	return true
+}
+
+// matchesCKANDateRange checks a Solr date range such as "[2024-01-01T00:00:00Z TO *]"
+// Unparseable bounds are treated as open
+func matchesCKANDateRange(t time.Time, value string) bool {
+	bounds := strings.SplitN(strings.Trim(value, "[]"), " TO ", 2)
+	if len(bounds) != 2 {
+		return true
+	}
+
+	if from, err := time.Parse(time.RFC3339, strings.TrimSpace(bounds[0])); err == nil && t.Before(from) {
+		return false
+	}
+	if to, err := time.Parse(time.RFC3339, strings.TrimSpace(bounds[1])); err == nil && t.After(to) {
+		return false
+	}
+	return true
+}
+
+// ckanSlug turns a name into a CKAN identifier (lowercase letters, digits and dashes)
+func ckanSlug(name string) string {
+	replacer := strings.NewReplacer("č", "c", "ć", "c", "š", "s", "ž", "z", "đ", "dj")
+	slug := replacer.Replace(strings.ToLower(name))
+	slug = ckanSlugInvalid.ReplaceAllString(slug, "-")
+	return strings.Trim(slug, "-")
+}
+
+// paginate returns at most limit items starting at offset; a limit of 0 means no limit
+func paginate[T any](items []T, limit, offset int) []T {
+	if offset >= len(items) {
+		return []T{}
+	}
+	items = items[offset:]
+	if limit > 0 && limit < len(items) {
+		items = items[:limit]
+	}
+	return items
+}
@@ -0,0 +1,305 @@
+package services
+
+import (
+	"encoding/xml"
+	"open_data_service/models"
+	"time"
+)
+
+// RDF namespaces used by DCAT-AP
+const (
+	nsRDF   = "http://www.w3.org/1999/02/22-rdf-syntax-ns#"
+	nsDCAT  = "http://www.w3.org/ns/dcat#"
+	nsDCT   = "http://purl.org/dc/terms/"
+	nsFOAF  = "http://xmlns.com/foaf/0.1/"
+	nsVCard = "http://www.w3.org/2006/vcard/ns#"
+	nsXSD   = "http://www.w3.org/2001/XMLSchema#"
+
+	euLanguage = "http://publications.europa.eu/resource/authority/language/"
+	ianaMedia  = "http://www.iana.org/assignments/media-types/"
+)
+
+// catalogLanguages are the EU codes of the catalog text languages (Serbian and English)
+var catalogLanguages = []string{"SRP", "ENG"}
+
+// ====================
+// JSON-LD
+// ====================
+
+// FormatDCATJSONLD renders the catalog as a DCAT-AP JSON-LD document
+func FormatDCATJSONLD(catalog *models.DatasetCatalog) map[string]interface{} {
+	publisher := map[string]interface{}{
+		"@id":       catalog.URI + "#publisher",
+		"@type":     "foaf:Agent",
+		"foaf:name": catalog.Publisher,
+	}
+
+	languages := make([]map[string]string, 0, len(catalogLanguages))
+	for _, language := range catalogLanguages {
+		languages = append(languages, jsonLDRef(euLanguage+language))
+	}
+
+	datasets := make([]map[string]interface{}, 0, len(catalog.Datasets))
+	for _, dataset := range catalog.Datasets {
+		datasets = append(datasets, jsonLDDataset(dataset, catalog))
+	}
+
+	return map[string]interface{}{
+		"@context": map[string]string{
+			"rdf":   nsRDF,
+			"dcat":  nsDCAT,
+			"dct":   nsDCT,
+			"foaf":  nsFOAF,
+			"vcard": nsVCard,
+			"xsd":   nsXSD,
+		},
+		"@id":             catalog.URI,
+		"@type":           "dcat:Catalog",
+		"dct:title":       jsonLDText(catalog.Title),
+		"dct:description": jsonLDText(catalog.Description),
+		"dct:publisher":   publisher,
+		"dct:language":    languages,
+		"dct:license":     jsonLDRef(catalog.License),
+		"dct:modified":    jsonLDDateTime(catalog.Modified),
+		"foaf:homepage":   jsonLDRef(catalog.HomepageURL),
+		"dcat:dataset":    datasets,
+	}
+}
+
+// jsonLDDataset renders one dataset with its distributions
+func jsonLDDataset(dataset models.CatalogDataset, catalog *models.DatasetCatalog) map[string]interface{} {
+	keywords := make([]map[string]string, 0, 2*len(dataset.Keywords))
+	for _, keyword := range dataset.Keywords {
+		keywords = append(keywords, jsonLDText(keyword)...)
+	}
+
+	distributions := make([]map[string]interface{}, 0, len(dataset.Distributions))
+	for _, distribution := range dataset.Distributions {
+		distributions = append(distributions, map[string]interface{}{
+			"@id":              distribution.URI,
+			"@type":            "dcat:Distribution",
+			"dct:title":        string(distribution.Format),
+			"dcat:accessURL":   jsonLDRef(distribution.DownloadURL),
+			"dcat:downloadURL": jsonLDRef(distribution.DownloadURL),
+			"dcat:mediaType":   jsonLDRef(ianaMedia + distribution.MediaType),
+			"dct:format":       jsonLDRef(distribution.FileType),
+			"dct:license":      jsonLDRef(catalog.License),
+		})
+	}
+
+	result := map[string]interface{}{
+		"@id":                    dataset.URI,
+		"@type":                  "dcat:Dataset",
+		"dct:identifier":         dataset.ID,
+		"dct:title":              jsonLDText(dataset.Title),
+		"dct:description":        jsonLDText(dataset.Description),
+		"dcat:keyword":           keywords,
+		"dcat:theme":             jsonLDRef(dataset.Theme),
+		"dct:accrualPeriodicity": jsonLDRef(dataset.AccrualPeriodicity),
+		"dct:publisher":          jsonLDRef(catalog.URI + "#publisher"),
+		"dcat:contactPoint": map[string]interface{}{
+			"@type":          "vcard:Organization",
+			"vcard:fn":       catalog.Publisher,
+			"vcard:hasEmail": jsonLDRef("mailto:" + dataset.ContactEmail),
+		},
+		"dcat:distribution": distributions,
+	}
+
+	if dataset.TemporalStart != nil {
+		result["dct:temporal"] = map[string]interface{}{
+			"@type":          "dct:PeriodOfTime",
+			"dcat:startDate": map[string]string{"@value": dataset.TemporalStart.Format("2006-01-02"), "@type": "xsd:date"},
+		}
+	}
+
+	return result
+}
+
+// jsonLDText renders a text as language-tagged literals
+func jsonLDText(text models.LocalizedText) []map[string]string {
+	return []map[string]string{
+		{"@value": text.SR, "@language": "sr"},
+		{"@value": text.EN, "@language": "en"},
+	}
+}
+
+// jsonLDRef renders a reference to another resource
+func jsonLDRef(uri string) map[string]string {
+	return map[string]string{"@id": uri}
+}
+
+// jsonLDDateTime renders a typed xsd:dateTime literal
+func jsonLDDateTime(t time.Time) map[string]string {
+	return map[string]string{"@value": t.Format(time.RFC3339), "@type": "xsd:dateTime"}
+}
+
+// ====================
+// RDF/XML
+// ====================
+
+// rdfDocument is the root rdf:RDF element
+type rdfDocument struct {
+	XMLName    xml.Name   `xml:"rdf:RDF"`
+	XmlnsRDF   string     `xml:"xmlns:rdf,attr"`
+	XmlnsDCAT  string     `xml:"xmlns:dcat,attr"`
+	XmlnsDCT   string     `xml:"xmlns:dct,attr"`
+	XmlnsFOAF  string     `xml:"xmlns:foaf,attr"`
+	XmlnsVCard string     `xml:"xmlns:vcard,attr"`
+	Catalog    rdfCatalog `xml:"dcat:Catalog"`
+}
+
+type rdfCatalog struct {
+	About       string           `xml:"rdf:about,attr"`
+	Title       []rdfLiteral     `xml:"dct:title"`
+	Description []rdfLiteral     `xml:"dct:description"`
+	Publisher   rdfPublisher     `xml:"dct:publisher"`
+	Language    []rdfResource    `xml:"dct:language"`
+	License     rdfResource      `xml:"dct:license"`
+	Modified    rdfLiteral       `xml:"dct:modified"`
+	Homepage    rdfResource      `xml:"foaf:homepage"`
+	Datasets    []rdfDatasetNode `xml:"dcat:dataset"`
+}
+
+type rdfPublisher struct {
+	Agent struct {
+		About string `xml:"rdf:about,attr"`
+		Name  string `xml:"foaf:name"`
+	} `xml:"foaf:Agent"`
+}
+
+type rdfDatasetNode struct {
+	Dataset rdfDataset `xml:"dcat:Dataset"`
+}
+
+type rdfDataset struct {
+	About              string                `xml:"rdf:about,attr"`
+	Identifier         string                `xml:"dct:identifier"`
+	Title              []rdfLiteral          `xml:"dct:title"`
+	Description        []rdfLiteral          `xml:"dct:description"`
+	Keywords           []rdfLiteral          `xml:"dcat:keyword"`
+	Theme              rdfResource           `xml:"dcat:theme"`
+	AccrualPeriodicity rdfResource           `xml:"dct:accrualPeriodicity"`
+	Temporal           *rdfTemporal          `xml:"dct:temporal,omitempty"`
+	Publisher          rdfResource           `xml:"dct:publisher"`
+	ContactPoint       rdfContactPoint       `xml:"dcat:contactPoint"`
+	Distributions      []rdfDistributionNode `xml:"dcat:distribution"`
+}
+
+type rdfTemporal struct {
+	Period struct {
+		StartDate rdfLiteral `xml:"dcat:startDate"`
+	} `xml:"dct:PeriodOfTime"`
+}
+
+type rdfContactPoint struct {
+	Organization struct {
+		Name  string      `xml:"vcard:fn"`
+		Email rdfResource `xml:"vcard:hasEmail"`
+	} `xml:"vcard:Organization"`
+}
+
+type rdfDistributionNode struct {
+	Distribution struct {
+		About       string      `xml:"rdf:about,attr"`
+		Title       string      `xml:"dct:title"`
+		AccessURL   rdfResource `xml:"dcat:accessURL"`
+		DownloadURL rdfResource `xml:"dcat:downloadURL"`
+		MediaType   rdfResource `xml:"dcat:mediaType"`
+		Format      rdfResource `xml:"dct:format"`
+		License     rdfResource `xml:"dct:license"`
+	} `xml:"dcat:Distribution"`
+}
+
+// rdfLiteral is a literal with an optional language tag or datatype
+type rdfLiteral struct {
+	Lang     string `xml:"xml:lang,attr,omitempty"`
+	Datatype string `xml:"rdf:datatype,attr,omitempty"`
+	Value    string `xml:",chardata"`
+}
+
+// rdfResource is a reference to another resource
+type rdfResource struct {
+	Resource string `xml:"rdf:resource,attr"`
+}
+
+// FormatDCATRDFXML renders the catalog as a DCAT-AP RDF/XML document
+func FormatDCATRDFXML(catalog *models.DatasetCatalog) ([]byte, error) {
+	document := rdfDocument{
+		XmlnsRDF:   nsRDF,
+		XmlnsDCAT:  nsDCAT,
+		XmlnsDCT:   nsDCT,
+		XmlnsFOAF:  nsFOAF,
+		XmlnsVCard: nsVCard,
+	}
+
+	rdfCatalog := &document.Catalog
+	rdfCatalog.About = catalog.URI
+	rdfCatalog.Title = rdfText(catalog.Title)
+	rdfCatalog.Description = rdfText(catalog.Description)
+	rdfCatalog.Publisher.Agent.About = catalog.URI + "#publisher"
+	rdfCatalog.Publisher.Agent.Name = catalog.Publisher
+	for _, language := range catalogLanguages {
+		rdfCatalog.Language = append(rdfCatalog.Language, rdfResource{Resource: euLanguage + language})
+	}
+	rdfCatalog.License = rdfResource{Resource: catalog.License}
+	rdfCatalog.Modified = rdfLiteral{Datatype: nsXSD + "dateTime", Value: catalog.Modified.Format(time.RFC3339)}
+	rdfCatalog.Homepage = rdfResource{Resource: catalog.HomepageURL}
+
+	for _, dataset := range catalog.Datasets {
+		rdfCatalog.Datasets = append(rdfCatalog.Datasets, rdfDatasetNode{Dataset: rdfDatasetFrom(dataset, catalog)})
+	}
+
+	output, err := xml.MarshalIndent(document, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	return append([]byte(xml.Header), output...), nil
+}
+
+// rdfDatasetFrom renders one dataset with its distributions
+func rdfDatasetFrom(dataset models.CatalogDataset, catalog *models.DatasetCatalog) rdfDataset {
+	result := rdfDataset{
+		About:              dataset.URI,
+		Identifier:         dataset.ID,
+		Title:              rdfText(dataset.Title),
+		Description:        rdfText(dataset.Description),
+		Theme:              rdfResource{Resource: dataset.Theme},
+		AccrualPeriodicity: rdfResource{Resource: dataset.AccrualPeriodicity},
+		Publisher:          rdfResource{Resource: catalog.URI + "#publisher"},
+	}
+
+	for _, keyword := range dataset.Keywords {
+		result.Keywords = append(result.Keywords, rdfText(keyword)...)
+	}
+
+	if dataset.TemporalStart != nil {
+		result.Temporal = &rdfTemporal{}
+		result.Temporal.Period.StartDate = rdfLiteral{Datatype: nsXSD + "date", Value: dataset.TemporalStart.Format("2006-01-02")}
+	}
+
+	result.ContactPoint.Organization.Name = catalog.Publisher
+	result.ContactPoint.Organization.Email = rdfResource{Resource: "mailto:" + dataset.ContactEmail}
+
+	for _, distribution := range dataset.Distributions {
+		var node rdfDistributionNode
+		node.Distribution.About = distribution.URI
+		node.Distribution.Title = string(distribution.Format)
+		node.Distribution.AccessURL = rdfResource{Resource: distribution.DownloadURL}
+		node.Distribution.DownloadURL = rdfResource{Resource: distribution.DownloadURL}
+		node.Distribution.MediaType = rdfResource{Resource: ianaMedia + distribution.MediaType}
+		node.Distribution.Format = rdfResource{Resource: distribution.FileType}
+		node.Distribution.License = rdfResource{Resource: catalog.License}
+		result.Distributions = append(result.Distributions, node)
+	}
+
+	return result
+}
+
+// rdfText renders a text as language-tagged literals
+func rdfText(text models.LocalizedText) []rdfLiteral {
+	return []rdfLiteral{
+		{Lang: "sr", Value: text.SR},
+		{Lang: "en", Value: text.EN},
+	}
+}
@@ -0,0 +1,100 @@
+package services
+
+import (
+	"math"
+	"open_data_service/models"
+	"sort"
+)
+
+// earthRadiusKm is the mean radius of the Earth used for distances between dorms and a point
+const earthRadiusKm = 6371.0088
+
+// GetDormLocations returns the dorms with coordinates as a GeoJSON feature collection with their occupancy
+// Dorms without coordinates are left out, because they cannot be placed on a map
+func (s *OpenDataService) GetDormLocations() (*models.GeoJSONFeatureCollection, error) {
+	heatmap, err := s.GetOccupancyHeatmap()
+	if err != nil {
+		return nil, err
+	}
+
+	collection := &models.GeoJSONFeatureCollection{
+		Type:     models.GeoJSONTypeFeatureCollection,
+		Features: []models.GeoJSONFeature{},
+	}
+	for _, point := range heatmap.Dorms {
+		if point.Latitude == nil || point.Longitude == nil {
+			continue
+		}
+
+		collection.Features = append(collection.Features, models.GeoJSONFeature{
+			Type: models.GeoJSONTypeFeature,
+			ID:   point.DormID.Hex(),
+			Geometry: models.GeoJSONPoint{
+				Type:        models.GeoJSONTypePoint,
+				Coordinates: [2]float64{*point.Longitude, *point.Latitude},
+			},
+			Properties: models.DormLocationProperties{
+				DormName:          point.DormName,
+				Address:           point.Address,
+				TotalCapacity:     point.TotalCapacity,
+				OccupiedSpots:     point.OccupiedSpots,
+				AvailableSpots:    point.AvailableSpots,
+				OccupancyRate:     point.OccupancyRate,
+				Status:            point.Status,
+				HasAvailableSpots: point.AvailableSpots > 0,
+			},
+		})
+	}
+
+	return collection, nil
+}
+
+// FindNearestDorms returns the dorms closest to a point, nearest first
+// Dorms without coordinates are skipped
+func (s *OpenDataService) FindNearestDorms(query models.NearestDormsQuery) ([]models.NearestDorm, error) {
+	heatmap, err := s.GetOccupancyHeatmap()
+	if err != nil {
+		return nil, err
+	}
+
+	nearest := []models.NearestDorm{}
+	for _, point := range heatmap.Dorms {
+		if point.Latitude == nil || point.Longitude == nil {
+			continue
+		}
+		if query.AvailableOnly && point.AvailableSpots <= 0 {
+			continue
+		}
+
+		distance := haversineKm(query.Latitude, query.Longitude, *point.Latitude, *point.Longitude)
+		if query.MaxDistanceKm != nil && distance > *query.MaxDistanceKm {
+			continue
+		}
+
+		nearest = append(nearest, models.NearestDorm{
+			DormOccupancyPoint: point,
+			DistanceKm:         math.Round(distance*1000) / 1000,
+		})
+	}
+
+	sort.SliceStable(nearest, func(i, j int) bool {
+		return nearest[i].DistanceKm < nearest[j].DistanceKm
+	})
+	if query.Limit > 0 && len(nearest) > query.Limit {
+		nearest = nearest[:query.Limit]
+	}
+
+	return nearest, nil
+}
+
+// haversineKm returns the great-circle distance in kilometers between two WGS 84 points
+func haversineKm(lat1, lng1, lat2, lng2 float64) float64 {
+	toRadians := func(degrees float64) float64 { return degrees * math.Pi / 180 }
+
+	dLat := toRadians(lat2 - lat1)
+	dLng := toRadians(lng2 - lng1)
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(toRadians(lat1))*math.Cos(toRadians(lat2))*math.Sin(dLng/2)*math.Sin(dLng/2)
+
+	return 2 * earthRadiusKm * math.Asin(math.Min(1, math.Sqrt(a)))
+}
@@ -0,0 +1,66 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"open_data_service/export"
+	"open_data_service/models"
+)
+
+// exportBatchSize is the number of documents a streamed export reads from MongoDB at a time
+const exportBatchSize = 500
+
+// StreamExport writes a dataset to w in one of the export formats
+// Every dataset writes its typed rows once and the encoder of the format decides how they are written
+// Datasets that grow every year (applications and occupancy history) are written row by row
+// straight from the MongoDB cursor; the others are small aggregates that are built in memory first
+// Encoders buffer the rows and only flush them to w every few hundred rows, so a failed query
+// returns its error before anything is written and it can still be reported to the client
+func (s *OpenDataService) StreamExport(ctx context.Context, dataset string, format models.ExportFormat, w io.Writer) error {
+	exportFormat, exists := export.Lookup(format)
+	if !exists {
+		return fmt.Errorf("unsupported export format: %s", format)
+	}
+
+	encoder := exportFormat.NewEncoder(w, CanonicalDatasetID(dataset))
+	if err := s.exportDataset(ctx, dataset, encoder); err != nil {
+		return err
+	}
+
+	return encoder.Close()
+}
+
+// exportDataset writes the columns and then the rows of a dataset
+func (s *OpenDataService) exportDataset(ctx context.Context, dataset string, rows export.RowWriter) error {
+	switch CanonicalDatasetID(dataset) {
+	case "dorms":
+		return s.exportDorms(ctx, rows)
+	case "rooms":
+		return s.exportRooms(ctx, rows)
+	case "dorm-statistics":
+		return s.exportStatistics(ctx, rows)
+	case "application-list":
+		return s.exportApplicationList(ctx, rows)
+	case "accepted-applications":
+		return s.exportAcceptedApplications(ctx, rows)
+	case "yearly-trends":
+		return s.exportYearlyTrends(ctx, rows)
+	case "dorm-trends":
+		return s.exportDormTrends(ctx, rows)
+	case "amenities-report":
+		return s.exportAmenitiesReport(ctx, rows)
+	case "occupancy-report":
+		return s.exportOccupancyReport(ctx, rows)
+	case "occupancy-history":
+		return s.occupancyHistoryService.ExportHistory(ctx, rows)
+	case "room-types":
+		return s.exportRoomTypes(ctx, rows)
+	case "active-repairs":
+		return s.exportActiveRepairs(rows)
+	case "completed-repairs":
+		return s.exportCompletedRepairs(rows)
+	default:
+		return fmt.Errorf("unknown dataset: %s", dataset)
+	}
+}
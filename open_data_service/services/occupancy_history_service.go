@@ -0,0 +1,418 @@
+package services
+
+import (
+	"context"
+	"log"
+	"math"
+	"open_data_service/export"
+	"open_data_service/models"
+	"sort"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// OccupancyHistoryService records daily occupancy of every dorm and room and serves it as a time series
+// The heatmap and statistics endpoints only show the current moment; this keeps the history
+type OccupancyHistoryService struct {
+	historyCollection              *mongo.Collection
+	stDomsCollection               *mongo.Collection
+	sobasCollection                *mongo.Collection
+	aplikacijeCollection           *mongo.Collection
+	prihvaceneAplikacijeCollection *mongo.Collection
+}
+
+// NewOccupancyHistoryService creates a new OccupancyHistoryService
+func NewOccupancyHistoryService(
+	historyCollection *mongo.Collection,
+	stDomsCollection *mongo.Collection,
+	sobasCollection *mongo.Collection,
+	aplikacijeCollection *mongo.Collection,
+	prihvaceneAplikacijeCollection *mongo.Collection,
+) *OccupancyHistoryService {
+	return &OccupancyHistoryService{
+		historyCollection:              historyCollection,
+		stDomsCollection:               stDomsCollection,
+		sobasCollection:                sobasCollection,
+		aplikacijeCollection:           aplikacijeCollection,
+		prihvaceneAplikacijeCollection: prihvaceneAplikacijeCollection,
+	}
+}
+
+// EnsureIndexes creates the unique index on day and dorm
+func (s *OccupancyHistoryService) EnsureIndexes() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err := s.historyCollection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "date", Value: 1}, {Key: "dorm_id", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	return err
+}
+
+// RecordOccupancy stores today's occupancy, capacity and active applications of every dorm and room
+// Occupancy is counted from accepted applications, as in the heatmap
+// Returns the number of dorms recorded
+func (s *OccupancyHistoryService) RecordOccupancy() (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	now := time.Now().UTC()
+	day := truncateToDay(now)
+
+	var dorms []models.StDom
+	cursor, err := s.stDomsCollection.Find(ctx, bson.M{})
+	if err != nil {
+		return 0, err
+	}
+	if err = cursor.All(ctx, &dorms); err != nil {
+		return 0, err
+	}
+
+	var rooms []models.Soba
+	cursor, err = s.sobasCollection.Find(ctx, bson.M{})
+	if err != nil {
+		return 0, err
+	}
+	if err = cursor.All(ctx, &rooms); err != nil {
+		return 0, err
+	}
+
+	occupied, err := countByRoom(ctx, s.prihvaceneAplikacijeCollection, bson.M{})
+	if err != nil {
+		return 0, err
+	}
+	activeApplications, err := countByRoom(ctx, s.aplikacijeCollection, bson.M{"is_active": true})
+	if err != nil {
+		return 0, err
+	}
+
+	records := make(map[primitive.ObjectID]*models.OccupancyRecord, len(dorms))
+	for _, dorm := range dorms {
+		records[dorm.ID] = &models.OccupancyRecord{
+			DormID:   dorm.ID,
+			DormName: dorm.Ime,
+			Rooms:    []models.RoomOccupancyRecord{},
+		}
+	}
+
+	for _, room := range rooms {
+		record, exists := records[room.StDomID]
+		if !exists {
+			continue
+		}
+
+		roomRecord := models.RoomOccupancyRecord{
+			RoomID:             room.ID,
+			Capacity:           room.Krevetnost,
+			Occupied:           occupied[room.ID],
+			ActiveApplications: activeApplications[room.ID],
+		}
+		record.Rooms = append(record.Rooms, roomRecord)
+		record.Capacity += roomRecord.Capacity
+		record.Occupied += roomRecord.Occupied
+		record.ActiveApplications += roomRecord.ActiveApplications
+	}
+
+	if len(records) == 0 {
+		return 0, nil
+	}
+
+	// One record per dorm and day; a second run on the same day overwrites the values
+	writes := make([]mongo.WriteModel, 0, len(records))
+	for _, record := range records {
+		writes = append(writes, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"date": day, "dorm_id": record.DormID}).
+			SetUpdate(bson.M{
+				"$set": bson.M{
+					"dorm_name":           record.DormName,
+					"capacity":            record.Capacity,
+					"occupied":            record.Occupied,
+					"active_applications": record.ActiveApplications,
+					"rooms":               record.Rooms,
+					"updated_at":          now,
+				},
+				"$setOnInsert": bson.M{"created_at": now},
+			}).
+			SetUpsert(true))
+	}
+
+	if _, err := s.historyCollection.BulkWrite(ctx, writes, options.BulkWrite().SetOrdered(false)); err != nil {
+		return 0, err
+	}
+
+	return len(records), nil
+}
+
+// StartScheduler records occupancy in the background, now and then on every period
+func (s *OccupancyHistoryService) StartScheduler(period time.Duration) {
+	go func() {
+		s.record()
+
+		ticker := time.NewTicker(period)
+		defer ticker.Stop()
+		for range ticker.C {
+			s.record()
+		}
+	}()
+}
+
+// record runs RecordOccupancy and logs the result
+func (s *OccupancyHistoryService) record() {
+	recorded, err := s.RecordOccupancy()
+	if err != nil {
+		log.Printf("Failed to record occupancy history: %v", err)
+		return
+	}
+	log.Printf("Recorded occupancy of %d dorms", recorded)
+}
+
+// occupancySample is the occupancy of one series on one day
+type occupancySample struct {
+	capacity           int
+	occupied           int
+	activeApplications int
+}
+
+// GetTimeSeries returns the recorded occupancy over the date range, one series per dorm or room
+// or a single series for all dorms, grouped by day, week or month
+func (s *OccupancyHistoryService) GetTimeSeries(query models.OccupancyTimeSeriesQuery) (*models.OccupancyTimeSeries, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	from, to := truncateToDay(query.From), truncateToDay(query.To)
+
+	filter := bson.M{"date": bson.M{"$gte": from, "$lte": to}}
+	if !query.DormID.IsZero() {
+		filter["dorm_id"] = query.DormID
+	}
+	if !query.RoomID.IsZero() {
+		filter["rooms.room_id"] = query.RoomID
+	}
+
+	cursor, err := s.historyCollection.Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "date", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var records []models.OccupancyRecord
+	if err := cursor.All(ctx, &records); err != nil {
+		return nil, err
+	}
+
+	series := make(map[string]*models.OccupancySeries)
+	samples := make(map[string]map[time.Time]*occupancySample)
+	add := func(key string, info models.OccupancySeries, date time.Time, capacity, occupied, activeApplications int) {
+		if _, exists := series[key]; !exists {
+			samples[key] = make(map[time.Time]*occupancySample)
+		}
+		// Records are sorted by date, so the newest dorm name wins
+		series[key] = &info
+
+		sample, exists := samples[key][date]
+		if !exists {
+			sample = &occupancySample{}
+			samples[key][date] = sample
+		}
+		sample.capacity += capacity
+		sample.occupied += occupied
+		sample.activeApplications += activeApplications
+	}
+
+	for _, record := range records {
+		date := truncateToDay(record.Date)
+		switch query.Level {
+		case models.OccupancyLevelTotal:
+			add(models.OccupancyLevelTotal, models.OccupancySeries{}, date, record.Capacity, record.Occupied, record.ActiveApplications)
+		case models.OccupancyLevelDorm:
+			add(record.DormID.Hex(), models.OccupancySeries{DormID: record.DormID.Hex(), DormName: record.DormName}, date, record.Capacity, record.Occupied, record.ActiveApplications)
+		case models.OccupancyLevelRoom:
+			for _, room := range record.Rooms {
+				if !query.RoomID.IsZero() && room.RoomID != query.RoomID {
+					continue
+				}
+				info := models.OccupancySeries{DormID: record.DormID.Hex(), DormName: record.DormName, RoomID: room.RoomID.Hex()}
+				add(room.RoomID.Hex(), info, date, room.Capacity, room.Occupied, room.ActiveApplications)
+			}
+		}
+	}
+
+	result := &models.OccupancyTimeSeries{
+		From:        from.Format("2006-01-02"),
+		To:          to.Format("2006-01-02"),
+		Granularity: query.Granularity,
+		Level:       query.Level,
+		Series:      make([]models.OccupancySeries, 0, len(series)),
+	}
+	for key, info := range series {
+		info.Points = buildOccupancyPoints(samples[key], query.Granularity)
+		result.Series = append(result.Series, *info)
+	}
+
+	sort.Slice(result.Series, func(i, j int) bool {
+		if result.Series[i].DormName != result.Series[j].DormName {
+			return result.Series[i].DormName < result.Series[j].DormName
+		}
+		return result.Series[i].RoomID < result.Series[j].RoomID
+	})
+
+	return result, nil
+}
+
+// ExportHistory exports the daily occupancy of every dorm (the occupancy-history dataset) row by row from the cursor
+func (s *OccupancyHistoryService) ExportHistory(ctx context.Context, rows export.RowWriter) error {
+	cursor, err := s.historyCollection.Find(ctx, bson.M{}, options.Find().
+		SetSort(bson.D{{Key: "date", Value: 1}, {Key: "dorm_name", Value: 1}}).
+		SetProjection(bson.M{"rooms": 0}).
+		SetBatchSize(exportBatchSize))
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	err = rows.Columns([]export.Column{
+		{Key: "date", Title: "Date", Type: export.Date},
+		{Key: "dorm_id", Title: "Dorm ID", Type: export.String},
+		{Key: "dorm_name", Title: "Dorm Name", Type: export.String},
+		{Key: "capacity", Title: "Capacity", Type: export.Int},
+		{Key: "occupied", Title: "Occupied", Type: export.Int},
+		{Key: "available", Title: "Available", Type: export.Int},
+		{Key: "occupancy_rate", Title: "Occupancy Rate (%)", Type: export.Float},
+		{Key: "active_applications", Title: "Active Applications", Type: export.Int},
+	})
+	if err != nil {
+		return err
+	}
+
+	for cursor.Next(ctx) {
+		var record models.OccupancyRecord
+		if err := cursor.Decode(&record); err != nil {
+			return err
+		}
+
+		err := rows.Row([]interface{}{
+			record.Date,
+			record.DormID.Hex(),
+			record.DormName,
+			record.Capacity,
+			record.Occupied,
+			record.Capacity - record.Occupied,
+			occupancyRate(float64(record.Occupied), float64(record.Capacity)),
+			record.ActiveApplications,
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return cursor.Err()
+}
+
+// buildOccupancyPoints groups the daily samples into periods and averages them
+func buildOccupancyPoints(samples map[time.Time]*occupancySample, granularity string) []models.OccupancyTimePoint {
+	days := make([]time.Time, 0, len(samples))
+	for day := range samples {
+		days = append(days, day)
+	}
+	sort.Slice(days, func(i, j int) bool { return days[i].Before(days[j]) })
+
+	points := []models.OccupancyTimePoint{}
+	var current *models.OccupancyTimePoint
+	var sum occupancySample
+
+	flush := func() {
+		if current == nil {
+			return
+		}
+		n := float64(current.Days)
+		current.Capacity = round2(float64(sum.capacity) / n)
+		current.Occupied = round2(float64(sum.occupied) / n)
+		current.Available = round2(float64(sum.capacity-sum.occupied) / n)
+		current.ActiveApplications = round2(float64(sum.activeApplications) / n)
+		current.OccupancyRate = occupancyRate(float64(sum.occupied), float64(sum.capacity))
+		points = append(points, *current)
+	}
+
+	for _, day := range days {
+		periodStart := periodStart(day, granularity).Format("2006-01-02")
+		if current == nil || current.PeriodStart != periodStart {
+			flush()
+			current = &models.OccupancyTimePoint{PeriodStart: periodStart}
+			sum = occupancySample{}
+		}
+
+		sample := samples[day]
+		current.Days++
+		current.PeakOccupied = max(current.PeakOccupied, sample.occupied)
+		sum.capacity += sample.capacity
+		sum.occupied += sample.occupied
+		sum.activeApplications += sample.activeApplications
+	}
+	flush()
+
+	return points
+}
+
+// periodStart returns the first day of the period containing the day; weeks start on Monday
+func periodStart(day time.Time, granularity string) time.Time {
+	switch granularity {
+	case models.GranularityWeek:
+		return day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+	case models.GranularityMonth:
+		return time.Date(day.Year(), day.Month(), 1, 0, 0, 0, 0, time.UTC)
+	}
+	return day
+}
+
+// countByRoom counts the documents matching the filter for every room (soba_id)
+func countByRoom(ctx context.Context, collection *mongo.Collection, match bson.M) (map[primitive.ObjectID]int, error) {
+	cursor, err := collection.Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: match}},
+		{{Key: "$group", Value: bson.D{
+			{Key: "_id", Value: "$soba_id"},
+			{Key: "count", Value: bson.D{{Key: "$sum", Value: 1}}},
+		}}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var results []struct {
+		RoomID primitive.ObjectID `bson:"_id"`
+		Count  int                `bson:"count"`
+	}
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+
+	counts := make(map[primitive.ObjectID]int, len(results))
+	for _, result := range results {
+		counts[result.RoomID] = result.Count
+	}
+	return counts, nil
+}
+
+// occupancyRate returns occupied as a percentage of capacity, rounded to two decimals
+func occupancyRate(occupied, capacity float64) float64 {
+	if capacity <= 0 {
+		return 0
+	}
+	return round2(occupied / capacity * 100)
+}
+
+// round2 rounds to two decimals
+func round2(value float64) float64 {
+	return math.Round(value*100) / 100
+}
+
+// truncateToDay returns midnight UTC of the day
+func truncateToDay(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
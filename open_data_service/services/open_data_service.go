@@ -8,7 +8,9 @@ import (
 	"io"
 	"math"
 	"net/http"
+	"open_data_service/export"
 	"open_data_service/models"
+	"open_data_service/utils"
 	"os"
 	"sort"
 	"strings"
@@ -27,15 +29,24 @@ type OpenDataService struct {
 	aplikacijeCollection           *mongo.Collection
 	prihvaceneAplikacijeCollection *mongo.Collection
 	repairsCollection              *mongo.Collection
+	occupancyHistoryService        *OccupancyHistoryService
+	anonymizationService           *AnonymizationService
+	serviceTokens                  *utils.ServiceTokenSource
 }
 
 // NewOpenDataService creates a new OpenDataService
+// serviceTokens identifies this service on calls to st_dom_service that are not made on behalf of a user
+// occupancyHistoryService provides the occupancy-history export dataset
+// anonymizationService protects students in the datasets that contain their applications
 func NewOpenDataService(
 	stDomsCollection *mongo.Collection,
 	sobasCollection *mongo.Collection,
 	aplikacijeCollection *mongo.Collection,
 	prihvaceneAplikacijeCollection *mongo.Collection,
 	repairsCollection *mongo.Collection,
+	occupancyHistoryService *OccupancyHistoryService,
+	anonymizationService *AnonymizationService,
+	serviceTokens *utils.ServiceTokenSource,
 ) *OpenDataService {
 	return &OpenDataService{
 		stDomsCollection:               stDomsCollection,
@@ -43,6 +54,9 @@ func NewOpenDataService(
 		aplikacijeCollection:           aplikacijeCollection,
 		prihvaceneAplikacijeCollection: prihvaceneAplikacijeCollection,
 		repairsCollection:              repairsCollection,
+		occupancyHistoryService:        occupancyHistoryService,
+		anonymizationService:           anonymizationService,
+		serviceTokens:                  serviceTokens,
 	}
 }
 
@@ -921,9 +935,11 @@ func (s *OpenDataService) GetOccupancyHeatmap() (*models.OccupancyHeatmap, error
 
 	for _, dorm := range dorms {
 		point := models.DormOccupancyPoint{
-			DormID:   dorm.ID,
-			DormName: dorm.Ime,
-			Address:  dorm.Address,
+			DormID:    dorm.ID,
+			DormName:  dorm.Ime,
+			Address:   dorm.Address,
+			Latitude:  dorm.Latitude,
+			Longitude: dorm.Longitude,
 		}
 
 		// Get total capacity
@@ -1049,263 +1065,252 @@ func (s *OpenDataService) calculateOccupancySummary(points []models.DormOccupanc
 }
 
 // ====================
-// 6. Open Data Export (CSV/JSON)
+// 6. Open Data Export
 // ====================
 
-// ExportData exports data in CSV or JSON format
-func (s *OpenDataService) ExportData(dataset string, format models.ExportFormat) (interface{}, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
-
-	switch dataset {
-	case "dorms":
-		return s.exportDorms(ctx, format)
-	case "rooms":
-		return s.exportRooms(ctx, format)
-	case "statistics", "dorm-statistics": // Support both old and new names
-		return s.exportStatistics(ctx, format)
-	case "application-analytics", "application-list": // Support both old and new names
-		return s.exportApplicationAnalytics(ctx, format)
-	case "accepted-applications":
-		return s.exportAcceptedApplications(ctx, format)
-	case "yearly-trends", "godisnja-kretanja":
-		return s.exportYearlyTrends(ctx, format)
-	case "dorm-trends":
-		return s.exportDormTrends(ctx, format)
-	case "amenities-report":
-		return s.exportAmenitiesReport(ctx, format)
-	case "occupancy-report":
-		return s.exportOccupancyReport(ctx, format)
-	case "room-types":
-		return s.exportRoomTypes(ctx, format)
-	case "active-repairs":
-		return s.exportActiveRepairs(format)
-	case "completed-repairs":
-		return s.exportCompletedRepairs(format)
-	default:
-		return nil, fmt.Errorf("unknown dataset: %s", dataset)
-	}
-}
-
 // exportDorms exports dorm data (without database IDs, in Serbocroatian)
-func (s *OpenDataService) exportDorms(ctx context.Context, format models.ExportFormat) (interface{}, error) {
+func (s *OpenDataService) exportDorms(ctx context.Context, rows export.RowWriter) error {
 	cursor, err := s.stDomsCollection.Find(ctx, bson.M{})
 	if err != nil {
-		return nil, err
+		return err
 	}
 	defer cursor.Close(ctx)
 
 	var dorms []models.StDom
 	if err = cursor.All(ctx, &dorms); err != nil {
-		return nil, err
+		return err
 	}
 
-	if format == models.ExportFormatJSON {
-		// Create a custom structure without ID for JSON
-		type DormExport struct {
-			Ime             string    `json:"ime"`
-			Adresa          string    `json:"adresa"`
-			BrojTelefona    string    `json:"broj_telefona"`
-			Email           string    `json:"email"`
-			DatumKreiranja  time.Time `json:"datum_kreiranja"`
-		}
-		
-		var exports []DormExport
-		for _, dorm := range dorms {
-			exports = append(exports, DormExport{
-				Ime:            dorm.Ime,
-				Adresa:         dorm.Address,
-				BrojTelefona:   dorm.TelephoneNumber,
-				Email:          dorm.Email,
-				DatumKreiranja: dorm.CreatedAt,
-			})
-		}
-		return exports, nil
+	err = rows.Columns([]export.Column{
+		{Key: "ime", Title: "Ime", Type: export.String},
+		{Key: "adresa", Title: "Adresa", Type: export.String},
+		{Key: "broj_telefona", Title: "Broj Telefona", Type: export.String},
+		{Key: "email", Title: "Email", Type: export.String},
+		{Key: "geografska_sirina", Title: "Geografska Sirina", Type: export.Float},
+		{Key: "geografska_duzina", Title: "Geografska Duzina", Type: export.Float},
+		{Key: "datum_kreiranja", Title: "Datum Kreiranja", Type: export.Timestamp},
+	})
+	if err != nil {
+		return err
 	}
 
-	// CSV format with Serbocroatian headers
-	var csvData [][]string
-	csvData = append(csvData, []string{"Ime", "Adresa", "Broj Telefona", "Email", "Datum Kreiranja"})
-
 	for _, dorm := range dorms {
-		csvData = append(csvData, []string{
+		err := rows.Row([]interface{}{
 			dorm.Ime,
 			dorm.Address,
 			dorm.TelephoneNumber,
 			dorm.Email,
-			dorm.CreatedAt.Format("2006-01-02 15:04:05"),
+			optionalFloat(dorm.Latitude),
+			optionalFloat(dorm.Longitude),
+			optionalTime(dorm.CreatedAt),
 		})
+		if err != nil {
+			return err
+		}
 	}
-
-	return csvData, nil
+	return nil
 }
 
-// exportRooms exports room data with availability
-func (s *OpenDataService) exportRooms(ctx context.Context, format models.ExportFormat) (interface{}, error) {
+// exportRooms exports room data with availability (without room and dorm IDs)
+func (s *OpenDataService) exportRooms(ctx context.Context, rows export.RowWriter) error {
 	// Get rooms with availability data
 	rooms, err := s.SearchAvailableRooms(models.RoomSearchFilters{Limit: 1000})
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	if format == models.ExportFormatJSON {
-		// Create a filtered version without room_id and dorm_id
-		var filteredRooms []map[string]interface{}
-		for _, room := range rooms {
-			filteredRooms = append(filteredRooms, map[string]interface{}{
-				"dorm_name":        room.DormName,
-				"dorm_address":     room.DormAddress,
-				"capacity":         room.Capacity,
-				"occupied":         room.Occupied,
-				"available_spots":  room.AvailableSpots,
-				"amenities":        room.Amenities,
-				"is_available":     room.IsAvailable,
-			})
-		}
-		return filteredRooms, nil
+	err = rows.Columns([]export.Column{
+		{Key: "dorm_name", Title: "Ime Doma", Type: export.String},
+		{Key: "dorm_address", Title: "Adresa", Type: export.String},
+		{Key: "capacity", Title: "Kapacitet", Type: export.Int},
+		{Key: "occupied", Title: "Popunjeno", Type: export.Int},
+		{Key: "available_spots", Title: "Slobodno", Type: export.Int},
+		{Key: "amenities", Title: "Pogodnosti", Type: export.String},
+		{Key: "is_available", Title: "Dostupna", Type: export.Bool},
+	})
+	if err != nil {
+		return err
 	}
 
-	// CSV format with Serbocroatian headers (without IDs)
-	var csvData [][]string
-	csvData = append(csvData, []string{"Ime Doma", "Adresa", "Kapacitet", "Popunjeno", "Slobodno", "Pogodnosti", "Dostupna"})
-
 	for _, room := range rooms {
-		availableStr := "Ne"
-		if room.IsAvailable {
-			availableStr = "Da"
-		}
-		
-		csvData = append(csvData, []string{
+		err := rows.Row([]interface{}{
 			room.DormName,
 			room.DormAddress,
-			fmt.Sprintf("%d", room.Capacity),
-			fmt.Sprintf("%d", room.Occupied),
-			fmt.Sprintf("%d", room.AvailableSpots),
+			room.Capacity,
+			room.Occupied,
+			room.AvailableSpots,
 			strings.Join(room.Amenities, ", "),
-			availableStr,
+			room.IsAvailable,
 		})
+		if err != nil {
+			return err
+		}
 	}
-
-	return csvData, nil
+	return nil
 }
 
-// exportStatistics exports statistical data (now called dorm-statistics)
-func (s *OpenDataService) exportStatistics(ctx context.Context, format models.ExportFormat) (interface{}, error) {
+// exportStatistics exports the statistics of every dorm (the dorm-statistics dataset)
+func (s *OpenDataService) exportStatistics(ctx context.Context, rows export.RowWriter) error {
 	stats, err := s.GetPublicStatistics()
 	if err != nil {
-		return nil, err
-	}
-
-	if format == models.ExportFormatJSON {
-		return stats, nil
+		return err
+	}
+
+	err = rows.Columns([]export.Column{
+		{Key: "ime_doma", Title: "Ime Doma", Type: export.String},
+		{Key: "adresa", Title: "Adresa", Type: export.String},
+		{Key: "ukupno_soba", Title: "Ukupno Soba", Type: export.Int},
+		{Key: "ukupan_kapacitet", Title: "Ukupan Kapacitet", Type: export.Int},
+		{Key: "popunjeno", Title: "Popunjeno", Type: export.Int},
+		{Key: "dostupno", Title: "Dostupno", Type: export.Int},
+		{Key: "stopa_popunjenosti", Title: "Stopa Popunjenosti (%)", Type: export.Float},
+		{Key: "prosecan_prosek", Title: "Prosečan Prosek", Type: export.Float},
+	})
+	if err != nil {
+		return err
 	}
 
-	// CSV format - export dorm statistics with Serbocroatian headers
-	var csvData [][]string
-	csvData = append(csvData, []string{"Ime Doma", "Adresa", "Ukupno Soba", "Ukupan Kapacitet", "Popunjeno", "Dostupno", "Stopa Popunjenosti (%)", "Prosečan Prosek"})
-
 	for _, dormStat := range stats.DormStatistics {
-		csvData = append(csvData, []string{
+		var averageProsek interface{} = dormStat.AverageProsek
+		// Average grades of small groups could reveal the grades of individual students
+		if s.anonymizationService.IsSmallGroup(dormStat.OccupiedSpots) {
+			averageProsek = nil
+		}
+
+		err := rows.Row([]interface{}{
 			dormStat.DormName,
 			dormStat.Address,
-			fmt.Sprintf("%d", dormStat.TotalRooms),
-			fmt.Sprintf("%d", dormStat.TotalCapacity),
-			fmt.Sprintf("%d", dormStat.OccupiedSpots),
-			fmt.Sprintf("%d", dormStat.AvailableSpots),
-			fmt.Sprintf("%.2f", dormStat.OccupancyRate),
-			fmt.Sprintf("%.2f", dormStat.AverageProsek),
+			dormStat.TotalRooms,
+			dormStat.TotalCapacity,
+			dormStat.OccupiedSpots,
+			dormStat.AvailableSpots,
+			dormStat.OccupancyRate,
+			averageProsek,
 		})
+		if err != nil {
+			return err
+		}
 	}
-
-	return csvData, nil
+	return nil
 }
 
-// exportYearlyTrends exports yearly trends data
-func (s *OpenDataService) exportYearlyTrends(ctx context.Context, format models.ExportFormat) (interface{}, error) {
+// exportYearlyTrends exports yearly trends data (without min/max grades)
+func (s *OpenDataService) exportYearlyTrends(ctx context.Context, rows export.RowWriter) error {
 	trends, err := s.GetApplicationTrends()
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	if format == models.ExportFormatJSON {
-		// Create filtered version without min/max grades
-		var filteredTrends []map[string]interface{}
-		for _, year := range trends.YearlyTrends {
-			filteredTrends = append(filteredTrends, map[string]interface{}{
-				"akademska_godina":    year.AcademicYear,
-				"prijave":             year.TotalApplications,
-				"prihvaceno":          year.AcceptedApplications,
-				"stopa_prihvatanja":   year.AcceptanceRate,
-				"prosecan_prosek":     year.AverageGrade,
-			})
-		}
-		return map[string]interface{}{
-			"godisnji_kretanja": filteredTrends,
-			"generisano":        time.Now(),
-		}, nil
+	err = rows.Columns([]export.Column{
+		{Key: "akademska_godina", Title: "Školska Godina", Type: export.String},
+		{Key: "prijave", Title: "Prijave", Type: export.Int},
+		{Key: "prihvaceno", Title: "Prihvaćeno", Type: export.Int},
+		{Key: "stopa_prihvatanja", Title: "Stopa Prihvatanja (%)", Type: export.Float},
+		{Key: "prosecan_prosek", Title: "Prosečan Prosek", Type: export.Float},
+	})
+	if err != nil {
+		return err
 	}
 
-	// CSV format with Serbocroatian headers (without Min. Prosek and Maks. Prosek)
-	var csvData [][]string
-	csvData = append(csvData, []string{"Školska Godina", "Prijave", "Prihvaćeno", "Stopa Prihvatanja (%)", "Prosečan Prosek"})
-
 	for _, year := range trends.YearlyTrends {
-		csvData = append(csvData, []string{
+		var averageGrade interface{} = year.AverageGrade
+		if s.anonymizationService.IsSmallGroup(year.AcceptedApplications) {
+			averageGrade = nil // Suppressed, see the anonymization policy
+		}
+
+		err := rows.Row([]interface{}{
 			year.AcademicYear,
-			fmt.Sprintf("%d", year.TotalApplications),
-			fmt.Sprintf("%d", year.AcceptedApplications),
-			fmt.Sprintf("%.2f", year.AcceptanceRate),
-			fmt.Sprintf("%.2f", year.AverageGrade),
+			year.TotalApplications,
+			year.AcceptedApplications,
+			year.AcceptanceRate,
+			averageGrade,
 		})
+		if err != nil {
+			return err
+		}
 	}
-
-	return csvData, nil
+	return nil
 }
 
-// exportDormTrends exports dorm application trends data
-func (s *OpenDataService) exportDormTrends(ctx context.Context, format models.ExportFormat) (interface{}, error) {
+// exportDormTrends exports dorm application trends data (without IDs)
+func (s *OpenDataService) exportDormTrends(ctx context.Context, rows export.RowWriter) error {
 	trends, err := s.GetApplicationTrends()
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	if format == models.ExportFormatJSON {
-		// Create filtered version without IDs
-		var filteredTrends []map[string]interface{}
-		for _, dorm := range trends.DormTrends {
-			filteredTrends = append(filteredTrends, map[string]interface{}{
-				"ime_doma":          dorm.DormName,
-				"ukupno_prijava":    dorm.TotalApplications,
-				"prihvaceno":        dorm.AcceptedApplications,
-				"stopa_prihvatanja": dorm.AcceptanceRate,
-			})
-		}
-		return map[string]interface{}{
-			"kretanja_po_domovima": filteredTrends,
-			"generisano":           time.Now(),
-		}, nil
+	err = rows.Columns([]export.Column{
+		{Key: "ime_doma", Title: "Ime Doma", Type: export.String},
+		{Key: "ukupno_prijava", Title: "Ukupno Prijava", Type: export.Int},
+		{Key: "prihvaceno", Title: "Prihvaćeno", Type: export.Int},
+		{Key: "stopa_prihvatanja", Title: "Stopa Prihvatanja (%)", Type: export.Float},
+	})
+	if err != nil {
+		return err
 	}
 
-	// CSV format with Serbocroatian headers
-	var csvData [][]string
-	csvData = append(csvData, []string{"Ime Doma", "Ukupno Prijava", "Prihvaćeno", "Stopa Prihvatanja (%)"})
-
 	for _, dorm := range trends.DormTrends {
-		csvData = append(csvData, []string{
+		err := rows.Row([]interface{}{
 			dorm.DormName,
-			fmt.Sprintf("%d", dorm.TotalApplications),
-			fmt.Sprintf("%d", dorm.AcceptedApplications),
-			fmt.Sprintf("%.2f", dorm.AcceptanceRate),
+			dorm.TotalApplications,
+			dorm.AcceptedApplications,
+			dorm.AcceptanceRate,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// exportAcceptedApplications exports all accepted applications, anonymized following the accepted-applications policy
+func (s *OpenDataService) exportAcceptedApplications(ctx context.Context, rows export.RowWriter) error {
+	err := rows.Columns([]export.Column{
+		{Key: "pseudonim", Title: "Pseudonim", Type: export.String},
+		{Key: "raspon_proseka", Title: "Raspon Proseka", Type: export.String},
+		{Key: "ime_doma", Title: "Ime Doma", Type: export.String},
+		{Key: "academic_year", Title: "Akademska Godina", Type: export.String},
+	})
+	if err != nil {
+		return err
+	}
+
+	return s.eachAnonymizedAcceptedApplication(ctx, bson.M{}, func(app models.AnonymizedAcceptedApplication) error {
+		return rows.Row([]interface{}{
+			app.Pseudonym,
+			app.GradeRange,
+			app.DormName,
+			app.AcademicYear,
 		})
+	})
+}
+
+// GetAcceptedApplicationsByAcademicYear returns the accepted applications of one academic year,
+// anonymized exactly like the accepted-applications dataset
+func (s *OpenDataService) GetAcceptedApplicationsByAcademicYear(academicYear string) ([]models.AnonymizedAcceptedApplication, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	applications := make([]models.AnonymizedAcceptedApplication, 0)
+	err := s.eachAnonymizedAcceptedApplication(ctx, bson.M{"academic_year": academicYear}, func(app models.AnonymizedAcceptedApplication) error {
+		applications = append(applications, app)
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	return csvData, nil
+	return applications, nil
 }
 
-// exportAcceptedApplications exports all accepted applications (without database IDs, in Serbocroatian)
-func (s *OpenDataService) exportAcceptedApplications(ctx context.Context, format models.ExportFormat) (interface{}, error) {
-	// Get all accepted applications with room and dorm information via aggregation
+// eachAnonymizedAcceptedApplication reads the accepted applications matching the filter with their dorm
+// and calls fn for every row that can be published
+// Index numbers are pseudonymized, grades are bucketed and rows of groups smaller than k are suppressed
+// Academic year is one of the quasi-identifiers, so filtering by year gives the same rows as the full dataset
+// MongoDB sorts the applications by the quasi-identifiers, so only one group is held in memory at a time
+func (s *OpenDataService) eachAnonymizedAcceptedApplication(ctx context.Context, filter bson.M, fn func(models.AnonymizedAcceptedApplication) error) error {
 	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: filter}},
 		{{Key: "$lookup", Value: bson.D{
 			{Key: "from", Value: "sobas"},
 			{Key: "localField", Value: "soba_id"},
@@ -1326,112 +1331,75 @@ func (s *OpenDataService) exportAcceptedApplications(ctx context.Context, format
 			{Key: "path", Value: "$dorm"},
 			{Key: "preserveNullAndEmptyArrays", Value: true},
 		}}},
+		{{Key: "$project", Value: bson.D{
+			{Key: "_id", Value: 0},
+			{Key: "broj_indexa", Value: 1},
+			{Key: "prosek", Value: 1},
+			{Key: "academic_year", Value: 1},
+			{Key: "dorm_name", Value: "$dorm.ime"},
+		}}},
+		// Grade ranges are contiguous, so sorting by grade keeps every group together
+		{{Key: "$sort", Value: bson.D{
+			{Key: "academic_year", Value: 1},
+			{Key: "dorm_name", Value: 1},
+			{Key: "prosek", Value: 1},
+		}}},
 	}
 
-	cursor, err := s.prihvaceneAplikacijeCollection.Aggregate(ctx, pipeline)
+	cursor, err := s.prihvaceneAplikacijeCollection.Aggregate(ctx, pipeline, options.Aggregate().
+		SetAllowDiskUse(true).
+		SetBatchSize(exportBatchSize))
 	if err != nil {
-		return nil, err
+		return err
 	}
 	defer cursor.Close(ctx)
 
-	var results []bson.M
-	if err = cursor.All(ctx, &results); err != nil {
-		return nil, err
-	}
-
-	if format == models.ExportFormatJSON {
-		// Create filtered version without database IDs
-		var filteredApps []map[string]interface{}
-		for _, result := range results {
-			brojIndexa, _ := result["broj_indexa"].(string)
-			prosek := result["prosek"]
-			academicYear, _ := result["academic_year"].(string)
-			createdAt, _ := result["created_at"].(primitive.DateTime)
-			
-			// Get dorm name
-			dormName := "N/A"
-			if dorm, ok := result["dorm"].(bson.M); ok {
-				if ime, ok := dorm["ime"].(string); ok {
-					dormName = ime
-				}
-			}
-			
-			// Convert prosek to float64
-			var prosekFloat float64
-			switch v := prosek.(type) {
-			case float64:
-				prosekFloat = v
-			case float32:
-				prosekFloat = float64(v)
-			case int:
-				prosekFloat = float64(v)
-			case int32:
-				prosekFloat = float64(v)
-			case int64:
-				prosekFloat = float64(v)
-			}
+	const dataset = "accepted-applications"
+	groups := newKAnonymousGroups(s.anonymizationService.config.MinGroupSize, func(app models.AnonymizedAcceptedApplication) string {
+		return app.DormName + "\x00" + app.AcademicYear + "\x00" + app.GradeRange
+	}, func(a, b models.AnonymizedAcceptedApplication) bool {
+		// Sorted so the row order does not reveal when an application was accepted or its exact grade
+		return a.Pseudonym < b.Pseudonym
+	}, fn)
 
-			filteredApps = append(filteredApps, map[string]interface{}{
-				"broj_indexa":    brojIndexa,
-				"prosek":         prosekFloat,
-				"ime_doma":       dormName,
-				"academic_year":  academicYear,
-				"kreirana":       createdAt.Time(),
-			})
+	for cursor.Next(ctx) {
+		var result struct {
+			BrojIndexa   string  `bson:"broj_indexa"`
+			Prosek       int     `bson:"prosek"`
+			AcademicYear string  `bson:"academic_year"`
+			DormName     *string `bson:"dorm_name"`
+		}
+		if err := cursor.Decode(&result); err != nil {
+			return err
 		}
-		return filteredApps, nil
-	}
-
-	// CSV format with Serbocroatian headers (without IDs, without Datum Ažuriranja)
-	var csvData [][]string
-	csvData = append(csvData, []string{"Broj Indexa", "Prosek", "Ime Doma", "Akademska Godina", "Datum Kreiranja"})
 
-	for _, result := range results {
-		brojIndexa, _ := result["broj_indexa"].(string)
-		prosek := result["prosek"]
-		academicYear, _ := result["academic_year"].(string)
-		createdAt, _ := result["created_at"].(primitive.DateTime)
-		
-		// Get dorm name
 		dormName := "N/A"
-		if dorm, ok := result["dorm"].(bson.M); ok {
-			if ime, ok := dorm["ime"].(string); ok {
-				dormName = ime
-			}
-		}
-		
-		// Convert prosek to float64 and format properly
-		var prosekStr string
-		switch v := prosek.(type) {
-		case float64:
-			prosekStr = fmt.Sprintf("%.2f", v)
-		case float32:
-			prosekStr = fmt.Sprintf("%.2f", v)
-		case int:
-			prosekStr = fmt.Sprintf("%.2f", float64(v))
-		case int32:
-			prosekStr = fmt.Sprintf("%.2f", float64(v))
-		case int64:
-			prosekStr = fmt.Sprintf("%.2f", float64(v))
-		default:
-			prosekStr = "0.00"
+		if result.DormName != nil {
+			dormName = *result.DormName
 		}
 
-		csvData = append(csvData, []string{
-			brojIndexa,
-			prosekStr,
-			dormName,
-			academicYear,
-			createdAt.Time().Format("2006-01-02 15:04:05"),
+		err := groups.Add(models.AnonymizedAcceptedApplication{
+			Pseudonym:    s.anonymizationService.Pseudonym(dataset, result.BrojIndexa),
+			GradeRange:   s.anonymizationService.GradeBucket(result.Prosek),
+			DormName:     dormName,
+			AcademicYear: result.AcademicYear,
 		})
+		if err != nil {
+			return err
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return err
 	}
 
-	return csvData, nil
+	return groups.Close()
 }
 
-// exportApplicationAnalytics exports detailed application list
-// Note: "Aktivna" field means whether the application is still pending (true) or has been processed/closed (false)
-func (s *OpenDataService) exportApplicationAnalytics(ctx context.Context, format models.ExportFormat) (interface{}, error) {
+// exportApplicationList exports the application list, anonymized following the application-list policy
+// Index numbers are pseudonymized, grades are bucketed, dates are reduced to the year
+// and rows of groups smaller than k are suppressed
+// MongoDB sorts the applications by the quasi-identifiers, so only one group is held in memory at a time
+func (s *OpenDataService) exportApplicationList(ctx context.Context, rows export.RowWriter) error {
 	// Get all applications with room and dorm information
 	pipeline := mongo.Pipeline{
 		{{Key: "$lookup", Value: bson.D{
@@ -1454,235 +1422,195 @@ func (s *OpenDataService) exportApplicationAnalytics(ctx context.Context, format
 			{Key: "path", Value: "$dorm"},
 			{Key: "preserveNullAndEmptyArrays", Value: true},
 		}}},
+		{{Key: "$project", Value: bson.D{
+			{Key: "_id", Value: 0},
+			{Key: "broj_indexa", Value: 1},
+			{Key: "prosek", Value: 1},
+			{Key: "is_active", Value: 1},
+			{Key: "year", Value: bson.D{{Key: "$year", Value: "$created_at"}}},
+			{Key: "dorm_name", Value: "$dorm.ime"},
+			{Key: "krevetnost", Value: "$room.krevetnost"},
+		}}},
+		// Grade ranges are contiguous, so sorting by grade keeps every group together
+		{{Key: "$sort", Value: bson.D{
+			{Key: "year", Value: 1},
+			{Key: "dorm_name", Value: 1},
+			{Key: "krevetnost", Value: 1},
+			{Key: "is_active", Value: 1},
+			{Key: "prosek", Value: 1},
+		}}},
 	}
 
-	cursor, err := s.aplikacijeCollection.Aggregate(ctx, pipeline)
+	cursor, err := s.aplikacijeCollection.Aggregate(ctx, pipeline, options.Aggregate().
+		SetAllowDiskUse(true).
+		SetBatchSize(exportBatchSize))
 	if err != nil {
-		return nil, err
+		return err
 	}
 	defer cursor.Close(ctx)
 
-	type ApplicationAnalytic struct {
-		BrojIndexa   string    `bson:"broj_indexa" json:"broj_indexa"`
-		DormName     string    `json:"ime_doma"`
-		RoomCapacity int       `json:"kapacitet_sobe"`
-		Grade        int       `bson:"prosek" json:"prosek"`
-		IsActive     bool      `bson:"is_active" json:"aktivna"`
-		CreatedAt    time.Time `bson:"created_at" json:"datum_kreiranja"`
-	}
-
-	var results []struct {
-		BrojIndexa   string                 `bson:"broj_indexa"`
-		Grade        int                    `bson:"prosek"`
-		IsActive     bool                   `bson:"is_active"`
-		CreatedAt    time.Time              `bson:"created_at"`
-		Room         map[string]interface{} `bson:"room"`
-		Dorm         map[string]interface{} `bson:"dorm"`
-	}
-	
-	if err = cursor.All(ctx, &results); err != nil {
-		return nil, err
-	}
+	err = rows.Columns([]export.Column{
+		{Key: "pseudonim", Title: "Pseudonim", Type: export.String},
+		{Key: "ime_doma", Title: "Ime Doma", Type: export.String},
+		{Key: "kapacitet_sobe", Title: "Kapacitet Sobe", Type: export.Int},
+		{Key: "raspon_proseka", Title: "Raspon Proseka", Type: export.String},
+		{Key: "aktivna", Title: "Aktivna", Type: export.Bool},
+		{Key: "godina_prijave", Title: "Godina Prijave", Type: export.Int},
+	})
+	if err != nil {
+		return err
+	}
+
+	const dataset = "application-list"
+	groups := newKAnonymousGroups(s.anonymizationService.config.MinGroupSize, func(app models.AnonymizedApplication) string {
+		return fmt.Sprintf("%s\x00%d\x00%s\x00%t\x00%d", app.DormName, app.RoomCapacity, app.GradeRange, app.IsActive, app.Year)
+	}, func(a, b models.AnonymizedApplication) bool {
+		// Sorted so the row order does not reveal when an application was submitted or its exact grade
+		return a.Pseudonym < b.Pseudonym
+	}, func(app models.AnonymizedApplication) error {
+		return rows.Row([]interface{}{
+			app.Pseudonym,
+			app.DormName,
+			app.RoomCapacity,
+			app.GradeRange,
+			app.IsActive,
+			app.Year,
+		})
+	})
 
-	var analytics []ApplicationAnalytic
-	for _, result := range results {
-		analytic := ApplicationAnalytic{
-			BrojIndexa: result.BrojIndexa,
-			Grade:      result.Grade,
-			IsActive:   result.IsActive,
-			CreatedAt:  result.CreatedAt,
+	for cursor.Next(ctx) {
+		var result struct {
+			BrojIndexa string  `bson:"broj_indexa"`
+			Grade      int     `bson:"prosek"`
+			IsActive   bool    `bson:"is_active"`
+			Year       int     `bson:"year"`
+			DormName   *string `bson:"dorm_name"`
+			Krevetnost int     `bson:"krevetnost"`
 		}
-
-		// Extract dorm name from nested document
-		if result.Dorm != nil {
-			if dormName, ok := result.Dorm["ime"].(string); ok {
-				analytic.DormName = dormName
-			}
+		if err := cursor.Decode(&result); err != nil {
+			return err
 		}
 
-		// Extract room capacity from nested document
-		if result.Room != nil {
-			if krevetnost, ok := result.Room["krevetnost"].(int32); ok {
-				analytic.RoomCapacity = int(krevetnost)
-			} else if krevetnost, ok := result.Room["krevetnost"].(int64); ok {
-				analytic.RoomCapacity = int(krevetnost)
-			} else if krevetnost, ok := result.Room["krevetnost"].(int); ok {
-				analytic.RoomCapacity = krevetnost
-			}
+		application := models.AnonymizedApplication{
+			Pseudonym:    s.anonymizationService.Pseudonym(dataset, result.BrojIndexa),
+			RoomCapacity: result.Krevetnost,
+			GradeRange:   s.anonymizationService.GradeBucket(result.Grade),
+			IsActive:     result.IsActive,
+			Year:         result.Year,
+		}
+		if result.DormName != nil {
+			application.DormName = *result.DormName
 		}
 
-		analytics = append(analytics, analytic)
-	}
-
-	if format == models.ExportFormatJSON {
-		return analytics, nil
-	}
-
-	// CSV format
-	var csvData [][]string
-	csvData = append(csvData, []string{"Broj Indexa", "Ime Doma", "Kapacitet Sobe", "Prosek", "Aktivna (da/ne)", "Datum Kreiranja"})
-
-	for _, app := range analytics {
-		isActiveStr := "ne"
-		if app.IsActive {
-			isActiveStr = "da"
+		if err := groups.Add(application); err != nil {
+			return err
 		}
-		
-		csvData = append(csvData, []string{
-			app.BrojIndexa,
-			app.DormName,
-			fmt.Sprintf("%d", app.RoomCapacity),
-			fmt.Sprintf("%d", app.Grade),
-			isActiveStr,
-			app.CreatedAt.Format("2006-01-02 15:04:05"),
-		})
+	}
+	if err := cursor.Err(); err != nil {
+		return err
 	}
 
-	return csvData, nil
+	return groups.Close()
 }
 
-// exportAmenitiesReport exports amenities distribution report
-func (s *OpenDataService) exportAmenitiesReport(ctx context.Context, format models.ExportFormat) (interface{}, error) {
+// exportAmenitiesReport exports amenities distribution report, sorted by the number of rooms
+func (s *OpenDataService) exportAmenitiesReport(ctx context.Context, rows export.RowWriter) error {
 	stats, err := s.GetPublicStatistics()
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	type AmenityReport struct {
-		Amenity      string `json:"amenity"`
-		TotalRooms   int    `json:"total_rooms"`
-		Percentage   float64 `json:"percentage"`
+	amenities := make([]string, 0, len(stats.AmenitiesDistribution))
+	for amenity := range stats.AmenitiesDistribution {
+		amenities = append(amenities, amenity)
 	}
-
-	var amenityReports []AmenityReport
-	totalRooms := stats.TotalRooms
-
-	for amenity, count := range stats.AmenitiesDistribution {
-		percentage := 0.0
-		if totalRooms > 0 {
-			percentage = float64(count) / float64(totalRooms) * 100
-			percentage = math.Round(percentage*100) / 100
+	sort.Slice(amenities, func(i, j int) bool {
+		a, b := amenities[i], amenities[j]
+		if stats.AmenitiesDistribution[a] != stats.AmenitiesDistribution[b] {
+			return stats.AmenitiesDistribution[a] > stats.AmenitiesDistribution[b]
 		}
-
-		amenityReports = append(amenityReports, AmenityReport{
-			Amenity:    amenity,
-			TotalRooms: count,
-			Percentage: percentage,
-		})
-	}
-
-	// Sort by total rooms descending
-	sort.Slice(amenityReports, func(i, j int) bool {
-		return amenityReports[i].TotalRooms > amenityReports[j].TotalRooms
+		return a < b
 	})
 
-	if format == models.ExportFormatJSON {
-		return map[string]interface{}{
-			"amenities":   amenityReports,
-			"total_rooms": totalRooms,
-			"generated_at": time.Now(),
-		}, nil
+	err = rows.Columns([]export.Column{
+		{Key: "amenity", Title: "Amenity", Type: export.String},
+		{Key: "total_rooms", Title: "Rooms with Amenity", Type: export.Int},
+		{Key: "percentage", Title: "Percentage of Total Rooms (%)", Type: export.Float},
+	})
+	if err != nil {
+		return err
 	}
 
-	// CSV format
-	var csvData [][]string
-	csvData = append(csvData, []string{"Amenity", "Rooms with Amenity", "Percentage of Total Rooms"})
+	for _, amenity := range amenities {
+		count := stats.AmenitiesDistribution[amenity]
+		percentage := 0.0
+		if stats.TotalRooms > 0 {
+			percentage = float64(count) / float64(stats.TotalRooms) * 100
+			percentage = math.Round(percentage*100) / 100
+		}
 
-	for _, report := range amenityReports {
-		csvData = append(csvData, []string{
-			report.Amenity,
-			fmt.Sprintf("%d", report.TotalRooms),
-			fmt.Sprintf("%.2f%%", report.Percentage),
-		})
+		if err := rows.Row([]interface{}{amenity, count, percentage}); err != nil {
+			return err
+		}
 	}
-
-	return csvData, nil
+	return nil
 }
 
 // exportOccupancyReport exports detailed occupancy analysis
-func (s *OpenDataService) exportOccupancyReport(ctx context.Context, format models.ExportFormat) (interface{}, error) {
+func (s *OpenDataService) exportOccupancyReport(ctx context.Context, rows export.RowWriter) error {
 	heatmap, err := s.GetOccupancyHeatmap()
 	if err != nil {
-		return nil, err
-	}
-
-	type OccupancyDetail struct {
-		DormID          string  `json:"dorm_id"`
-		DormName        string  `json:"dorm_name"`
-		Address         string  `json:"address"`
-		TotalCapacity   int     `json:"total_capacity"`
-		OccupiedSpots   int     `json:"occupied_spots"`
-		AvailableSpots  int     `json:"available_spots"`
-		OccupancyRate   float64 `json:"occupancy_rate"`
-		Status          string  `json:"status"`
+		return err
+	}
+
+	err = rows.Columns([]export.Column{
+		{Key: "dorm_id", Title: "Dorm ID", Type: export.String},
+		{Key: "dorm_name", Title: "Dorm Name", Type: export.String},
+		{Key: "address", Title: "Address", Type: export.String},
+		{Key: "total_capacity", Title: "Total Capacity", Type: export.Int},
+		{Key: "occupied_spots", Title: "Occupied", Type: export.Int},
+		{Key: "available_spots", Title: "Available", Type: export.Int},
+		{Key: "occupancy_rate", Title: "Occupancy Rate (%)", Type: export.Float},
+		{Key: "status", Title: "Status", Type: export.String},
+	})
+	if err != nil {
+		return err
 	}
 
-	var occupancyDetails []OccupancyDetail
-
 	for _, dorm := range heatmap.Dorms {
-		occupancyDetails = append(occupancyDetails, OccupancyDetail{
-			DormID:         dorm.DormID.Hex(),
-			DormName:       dorm.DormName,
-			Address:        dorm.Address,
-			TotalCapacity:  dorm.TotalCapacity,
-			OccupiedSpots:  dorm.OccupiedSpots,
-			AvailableSpots: dorm.AvailableSpots,
-			OccupancyRate:  dorm.OccupancyRate,
-			Status:         dorm.Status,
-		})
-	}
-
-	if format == models.ExportFormatJSON {
-		return map[string]interface{}{
-			"dorms":      occupancyDetails,
-			"summary":    heatmap.Summary,
-			"generated_at": time.Now(),
-		}, nil
-	}
-
-	// CSV format
-	var csvData [][]string
-	csvData = append(csvData, []string{"Dorm ID", "Dorm Name", "Address", "Total Capacity", "Occupied", "Available", "Occupancy Rate", "Status"})
-
-	for _, detail := range occupancyDetails {
-		csvData = append(csvData, []string{
-			detail.DormID,
-			detail.DormName,
-			detail.Address,
-			fmt.Sprintf("%d", detail.TotalCapacity),
-			fmt.Sprintf("%d", detail.OccupiedSpots),
-			fmt.Sprintf("%d", detail.AvailableSpots),
-			fmt.Sprintf("%.2f%%", detail.OccupancyRate),
-			detail.Status,
+		err := rows.Row([]interface{}{
+			dorm.DormID.Hex(),
+			dorm.DormName,
+			dorm.Address,
+			dorm.TotalCapacity,
+			dorm.OccupiedSpots,
+			dorm.AvailableSpots,
+			dorm.OccupancyRate,
+			dorm.Status,
 		})
+		if err != nil {
+			return err
+		}
 	}
-
-	return csvData, nil
+	return nil
 }
 
 // exportRoomTypes exports room types and availability breakdown
-func (s *OpenDataService) exportRoomTypes(ctx context.Context, format models.ExportFormat) (interface{}, error) {
+func (s *OpenDataService) exportRoomTypes(ctx context.Context, rows export.RowWriter) error {
 	stats, err := s.GetPublicStatistics()
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	type RoomTypeDetail struct {
-		Capacity          int     `json:"kapacitet"`
-		TotalRooms        int     `json:"ukupno_soba"`
-		TotalCapacity     int     `json:"ukupan_kapacitet"`
-		OccupiedSpots     int     `json:"popunjeno_mesta"`
-		AvailableSpots    int     `json:"dostupno_mesta"`
-		OccupancyRate     float64 `json:"stopa_popunjenosti"`
+	capacities := make([]int, 0, len(stats.RoomTypeDistribution))
+	for capacity := range stats.RoomTypeDistribution {
+		capacities = append(capacities, capacity)
 	}
+	sort.Ints(capacities)
 
-	var roomTypeDetails []RoomTypeDetail
-
-	// Get room type statistics with occupancy
-	for capacity, roomCount := range stats.RoomTypeDistribution {
-		totalCap := capacity * roomCount
-
-		// Count occupied spots for this room type
+	// Count occupied spots for every room type before anything is written
+	occupiedSpots := make(map[int]int, len(capacities))
+	for _, capacity := range capacities {
 		pipeline := mongo.Pipeline{
 			{{Key: "$lookup", Value: bson.D{
 				{Key: "from", Value: "sobas"},
@@ -1699,7 +1627,7 @@ func (s *OpenDataService) exportRoomTypes(ctx context.Context, format models.Exp
 
 		cursor, err := s.prihvaceneAplikacijeCollection.Aggregate(ctx, pipeline)
 		if err != nil {
-			return nil, err
+			return err
 		}
 
 		var result []struct {
@@ -1707,183 +1635,124 @@ func (s *OpenDataService) exportRoomTypes(ctx context.Context, format models.Exp
 		}
 		if err = cursor.All(ctx, &result); err != nil {
 			cursor.Close(ctx)
-			return nil, err
+			return err
 		}
 		cursor.Close(ctx)
 
-		occupied := 0
 		if len(result) > 0 {
-			occupied = result[0].Total
+			occupiedSpots[capacity] = result[0].Total
 		}
+	}
+
+	err = rows.Columns([]export.Column{
+		{Key: "kapacitet", Title: "Kapacitet Sobe", Type: export.Int},
+		{Key: "ukupno_soba", Title: "Ukupno Soba", Type: export.Int},
+		{Key: "ukupan_kapacitet", Title: "Ukupan Kapacitet", Type: export.Int},
+		{Key: "popunjeno_mesta", Title: "Popunjeno Mesta", Type: export.Int},
+		{Key: "dostupno_mesta", Title: "Dostupno Mesta", Type: export.Int},
+		{Key: "stopa_popunjenosti", Title: "Stopa Popunjenosti (%)", Type: export.Float},
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, capacity := range capacities {
+		roomCount := stats.RoomTypeDistribution[capacity]
+		totalCap := capacity * roomCount
+		occupied := occupiedSpots[capacity]
 
-		available := totalCap - occupied
 		occupancyRate := 0.0
 		if totalCap > 0 {
 			occupancyRate = float64(occupied) / float64(totalCap) * 100
 			occupancyRate = math.Round(occupancyRate*100) / 100
 		}
 
-		roomTypeDetails = append(roomTypeDetails, RoomTypeDetail{
-			Capacity:       capacity,
-			TotalRooms:     roomCount,
-			TotalCapacity:  totalCap,
-			OccupiedSpots:  occupied,
-			AvailableSpots: available,
-			OccupancyRate:  occupancyRate,
-		})
-	}
-
-	// Sort by capacity
-	sort.Slice(roomTypeDetails, func(i, j int) bool {
-		return roomTypeDetails[i].Capacity < roomTypeDetails[j].Capacity
-	})
-
-	if format == models.ExportFormatJSON {
-		return map[string]interface{}{
-			"tipovi_soba":  roomTypeDetails,
-			"generisano": time.Now(),
-		}, nil
-	}
-
-	// CSV format with Serbocroatian headers
-	var csvData [][]string
-	csvData = append(csvData, []string{"Kapacitet Sobe", "Ukupno Soba", "Ukupan Kapacitet", "Popunjeno Mesta", "Dostupno Mesta", "Stopa Popunjenosti (%)"})
-
-	for _, detail := range roomTypeDetails {
-		csvData = append(csvData, []string{
-			fmt.Sprintf("Soba za %d osoba", detail.Capacity),
-			fmt.Sprintf("%d", detail.TotalRooms),
-			fmt.Sprintf("%d", detail.TotalCapacity),
-			fmt.Sprintf("%d", detail.OccupiedSpots),
-			fmt.Sprintf("%d", detail.AvailableSpots),
-			fmt.Sprintf("%.2f", detail.OccupancyRate),
+		err := rows.Row([]interface{}{
+			capacity,
+			roomCount,
+			totalCap,
+			occupied,
+			totalCap - occupied,
+			occupancyRate,
 		})
+		if err != nil {
+			return err
+		}
 	}
+	return nil
+}
 
-	return csvData, nil
+// repairColumns are the columns that begin every row of the repair datasets, filled by repairValues
+var repairColumns = []export.Column{
+	{Key: "id_sobe", Title: "ID Sobe", Type: export.String},
+	{Key: "ime_doma", Title: "Ime Doma", Type: export.String},
+	{Key: "kapacitet_sobe", Title: "Kapacitet Sobe", Type: export.Int},
+	{Key: "opis", Title: "Opis", Type: export.String},
+	{Key: "predvideni_datum_zavrsetka", Title: "Predviđeni Datum Završetka", Type: export.Date},
 }
 
-// exportActiveRepairs exports all pending and in-progress repairs with room info
-func (s *OpenDataService) exportActiveRepairs(format models.ExportFormat) (interface{}, error) {
-	repairs, err := s.GetActiveRepairsFromStDomService()
-	if err != nil {
-		return nil, err
+// repairValues extracts the values of repairColumns from a repair joined with its room and dorm
+func repairValues(repair map[string]interface{}) []interface{} {
+	// Extract room ID
+	roomID := ""
+	if val, ok := repair["soba_id"].(primitive.ObjectID); ok {
+		roomID = val.Hex()
 	}
 
-	if format == models.ExportFormatJSON {
-		// Format for JSON export
-		var jsonData []map[string]interface{}
-		for _, repair := range repairs {
-			// Extract room ID
-			roomID := ""
-			if val, ok := repair["soba_id"].(primitive.ObjectID); ok {
-				roomID = val.Hex()
-			}
-
-			// Extract dorm name
-			dormName := ""
-			if dorm, ok := repair["dorm"].(primitive.M); ok {
-				if name, ok := dorm["ime"].(string); ok {
-					dormName = name
-				}
-			}
-
-			// Extract room capacity (krevetnost)
-			roomCapacity := ""
-			if room, ok := repair["room"].(primitive.M); ok {
-				if krevetnost, ok := room["krevetnost"].(int32); ok {
-					roomCapacity = fmt.Sprintf("%d", krevetnost)
-				} else if krevetnost, ok := room["krevetnost"].(int64); ok {
-					roomCapacity = fmt.Sprintf("%d", krevetnost)
-				} else if krevetnost, ok := room["krevetnost"].(int); ok {
-					roomCapacity = fmt.Sprintf("%d", krevetnost)
-				}
-			}
-
-			// Format dates
-			estimatedDate := ""
-			if val, ok := repair["estimated_completion_date"].(primitive.DateTime); ok {
-				estimatedDate = val.Time().Format("2006-01-02")
-			}
-			createdAt := ""
-			if val, ok := repair["created_at"].(primitive.DateTime); ok {
-				createdAt = val.Time().Format("2006-01-02 15:04:05")
-			}
-
-			// Translate status
-			status := ""
-			if val, ok := repair["status"].(string); ok {
-				switch val {
-				case "scheduled":
-					status = "Zakazano"
-				case "in_progress":
-					status = "U Toku"
-				default:
-					status = val
-				}
-			}
-
-			jsonData = append(jsonData, map[string]interface{}{
-				"ID Sobe":                     roomID,
-				"Ime Doma":                    dormName,
-				"Kapacitet Sobe":              roomCapacity,
-				"Opis":                        repair["description"],
-				"Predviđeni Datum Završetka": estimatedDate,
-				"Status":                      status,
-				"Datum Kreiranja":             createdAt,
-			})
+	// Extract dorm name
+	dormName := ""
+	if dorm, ok := repair["dorm"].(primitive.M); ok {
+		if name, ok := dorm["ime"].(string); ok {
+			dormName = name
 		}
-		return jsonData, nil
 	}
 
-	// CSV format
-	csvData := [][]string{
-		{"ID Sobe", "Ime Doma", "Kapacitet Sobe", "Opis", "Predviđeni Datum Završetka", "Status", "Datum Kreiranja"},
+	// Extract room capacity (krevetnost)
+	var roomCapacity interface{}
+	if room, ok := repair["room"].(primitive.M); ok {
+		switch krevetnost := room["krevetnost"].(type) {
+		case int32:
+			roomCapacity = int(krevetnost)
+		case int64:
+			roomCapacity = int(krevetnost)
+		case int:
+			roomCapacity = krevetnost
+		}
 	}
 
-	for _, repair := range repairs {
-		// Extract room ID
-		roomID := ""
-		if val, ok := repair["soba_id"].(primitive.ObjectID); ok {
-			roomID = val.Hex()
-		}
+	// Extract description
+	var description interface{}
+	if val, ok := repair["description"].(string); ok {
+		description = val
+	}
 
-		// Extract dorm name
-		dormName := ""
-		if dorm, ok := repair["dorm"].(primitive.M); ok {
-			if name, ok := dorm["ime"].(string); ok {
-				dormName = name
-			}
-		}
+	return []interface{}{roomID, dormName, roomCapacity, description, repairTime(repair, "estimated_completion_date")}
+}
 
-		// Extract room capacity (krevetnost)
-		roomCapacity := ""
-		if room, ok := repair["room"].(primitive.M); ok {
-			if krevetnost, ok := room["krevetnost"].(int32); ok {
-				roomCapacity = fmt.Sprintf("%d", krevetnost)
-			} else if krevetnost, ok := room["krevetnost"].(int64); ok {
-				roomCapacity = fmt.Sprintf("%d", krevetnost)
-			} else if krevetnost, ok := room["krevetnost"].(int); ok {
-				roomCapacity = fmt.Sprintf("%d", krevetnost)
-			}
-		}
+// repairTime returns a date field of a repair, or nil if it is not set
+func repairTime(repair map[string]interface{}, field string) interface{} {
+	if val, ok := repair[field].(primitive.DateTime); ok {
+		return optionalTime(val.Time())
+	}
+	return nil
+}
 
-		// Extract description
-		description := ""
-		if val, ok := repair["description"].(string); ok {
-			description = val
-		}
+// exportActiveRepairs exports all pending and in-progress repairs with room info
+func (s *OpenDataService) exportActiveRepairs(rows export.RowWriter) error {
+	repairs, err := s.GetActiveRepairsFromStDomService()
+	if err != nil {
+		return err
+	}
 
-		// Format dates
-		estimatedDate := ""
-		if val, ok := repair["estimated_completion_date"].(primitive.DateTime); ok {
-			estimatedDate = val.Time().Format("2006-01-02")
-		}
-		createdAt := ""
-		if val, ok := repair["created_at"].(primitive.DateTime); ok {
-			createdAt = val.Time().Format("2006-01-02 15:04:05")
-		}
+	columns := append(repairColumns[:len(repairColumns):len(repairColumns)],
+		export.Column{Key: "status", Title: "Status", Type: export.String},
+		export.Column{Key: "datum_kreiranja", Title: "Datum Kreiranja", Type: export.Timestamp},
+	)
+	if err := rows.Columns(columns); err != nil {
+		return err
+	}
 
+	for _, repair := range repairs {
 		// Translate status
 		status := ""
 		if val, ok := repair["status"].(string); ok {
@@ -1897,132 +1766,52 @@ func (s *OpenDataService) exportActiveRepairs(format models.ExportFormat) (inter
 			}
 		}
 
-		csvData = append(csvData, []string{roomID, dormName, roomCapacity, description, estimatedDate, status, createdAt})
+		values := append(repairValues(repair), status, repairTime(repair, "created_at"))
+		if err := rows.Row(values); err != nil {
+			return err
+		}
 	}
-
-	return csvData, nil
+	return nil
 }
 
 // exportCompletedRepairs exports all completed repairs from this year with room info
-func (s *OpenDataService) exportCompletedRepairs(format models.ExportFormat) (interface{}, error) {
+func (s *OpenDataService) exportCompletedRepairs(rows export.RowWriter) error {
 	repairs, err := s.GetCompletedRepairsThisYearFromStDomService()
 	if err != nil {
-		return nil, err
-	}
-
-	if format == models.ExportFormatJSON {
-		// Format for JSON export
-		var jsonData []map[string]interface{}
-		for _, repair := range repairs {
-			// Extract room ID
-			roomID := ""
-			if val, ok := repair["soba_id"].(primitive.ObjectID); ok {
-				roomID = val.Hex()
-			}
-
-			// Extract dorm name
-			dormName := ""
-			if dorm, ok := repair["dorm"].(primitive.M); ok {
-				if name, ok := dorm["ime"].(string); ok {
-					dormName = name
-				}
-			}
-
-			// Extract room capacity (krevetnost)
-			roomCapacity := ""
-			if room, ok := repair["room"].(primitive.M); ok {
-				if krevetnost, ok := room["krevetnost"].(int32); ok {
-					roomCapacity = fmt.Sprintf("%d", krevetnost)
-				} else if krevetnost, ok := room["krevetnost"].(int64); ok {
-					roomCapacity = fmt.Sprintf("%d", krevetnost)
-				} else if krevetnost, ok := room["krevetnost"].(int); ok {
-					roomCapacity = fmt.Sprintf("%d", krevetnost)
-				}
-			}
-
-			// Format dates
-			estimatedDate := ""
-			if val, ok := repair["estimated_completion_date"].(primitive.DateTime); ok {
-				estimatedDate = val.Time().Format("2006-01-02")
-			}
-			createdAt := ""
-			if val, ok := repair["created_at"].(primitive.DateTime); ok {
-				createdAt = val.Time().Format("2006-01-02 15:04:05")
-			}
-			updatedAt := ""
-			if val, ok := repair["updated_at"].(primitive.DateTime); ok {
-				updatedAt = val.Time().Format("2006-01-02 15:04:05")
-			}
-
-			jsonData = append(jsonData, map[string]interface{}{
-				"ID Sobe":                     roomID,
-				"Ime Doma":                    dormName,
-				"Kapacitet Sobe":              roomCapacity,
-				"Opis":                        repair["description"],
-				"Predviđeni Datum Završetka": estimatedDate,
-				"Datum Kreiranja":             createdAt,
-				"Datum Završavanja":           updatedAt,
-			})
-		}
-		return jsonData, nil
+		return err
 	}
 
-	// CSV format
-	csvData := [][]string{
-		{"ID Sobe", "Ime Doma", "Kapacitet Sobe", "Opis", "Predviđeni Datum Završetka", "Datum Kreiranja", "Datum Završavanja"},
+	columns := append(repairColumns[:len(repairColumns):len(repairColumns)],
+		export.Column{Key: "datum_kreiranja", Title: "Datum Kreiranja", Type: export.Timestamp},
+		export.Column{Key: "datum_zavrsavanja", Title: "Datum Završavanja", Type: export.Timestamp},
+	)
+	if err := rows.Columns(columns); err != nil {
+		return err
 	}
 
 	for _, repair := range repairs {
-		// Extract room ID
-		roomID := ""
-		if val, ok := repair["soba_id"].(primitive.ObjectID); ok {
-			roomID = val.Hex()
-		}
-
-		// Extract dorm name
-		dormName := ""
-		if dorm, ok := repair["dorm"].(primitive.M); ok {
-			if name, ok := dorm["ime"].(string); ok {
-				dormName = name
-			}
-		}
-
-		// Extract room capacity (krevetnost)
-		roomCapacity := ""
-		if room, ok := repair["room"].(primitive.M); ok {
-			if krevetnost, ok := room["krevetnost"].(int32); ok {
-				roomCapacity = fmt.Sprintf("%d", krevetnost)
-			} else if krevetnost, ok := room["krevetnost"].(int64); ok {
-				roomCapacity = fmt.Sprintf("%d", krevetnost)
-			} else if krevetnost, ok := room["krevetnost"].(int); ok {
-				roomCapacity = fmt.Sprintf("%d", krevetnost)
-			}
-		}
-
-		// Extract description
-		description := ""
-		if val, ok := repair["description"].(string); ok {
-			description = val
-		}
-
-		// Format dates
-		estimatedDate := ""
-		if val, ok := repair["estimated_completion_date"].(primitive.DateTime); ok {
-			estimatedDate = val.Time().Format("2006-01-02")
-		}
-		createdAt := ""
-		if val, ok := repair["created_at"].(primitive.DateTime); ok {
-			createdAt = val.Time().Format("2006-01-02 15:04:05")
-		}
-		updatedAt := ""
-		if val, ok := repair["updated_at"].(primitive.DateTime); ok {
-			updatedAt = val.Time().Format("2006-01-02 15:04:05")
+		values := append(repairValues(repair), repairTime(repair, "created_at"), repairTime(repair, "updated_at"))
+		if err := rows.Row(values); err != nil {
+			return err
 		}
+	}
+	return nil
+}
 
-		csvData = append(csvData, []string{roomID, dormName, roomCapacity, description, estimatedDate, createdAt, updatedAt})
+// optionalTime returns the time, or nil (an empty value) if it is not set
+func optionalTime(t time.Time) interface{} {
+	if t.IsZero() {
+		return nil
 	}
+	return t
+}
 
-	return csvData, nil
+// optionalFloat returns the number, or nil (an empty value) if it is not set
+func optionalFloat(f *float64) interface{} {
+	if f == nil {
+		return nil
+	}
+	return *f
 }
 
 // GetActiveRepairsFromStDomService fetches all pending and in-progress repairs from MongoDB with room and dorm info
@@ -2223,52 +2012,6 @@ func (s *OpenDataService) GetRoomApplicationsFromStDomService(roomID string, aut
 	return result, nil
 }
 
-// GetApplicationsByAcademicYearFromStDomService fetches accepted applications by academic year from st_dom_service
-// Now accessible without authentication for public open data (proxies to st_dom_service)
-func (s *OpenDataService) GetApplicationsByAcademicYearFromStDomService(academicYear string) (interface{}, error) {
-	// Get ST_DOM_SERVICE_URL from environment variable
-	stDomServiceURL := os.Getenv("ST_DOM_SERVICE_URL")
-	if stDomServiceURL == "" {
-		stDomServiceURL = "http://st_dom_service:8081" // default for Docker
-	}
-
-	// Construct the endpoint URL with query parameter
-	url := fmt.Sprintf("%s/api/v1/prihvacene_aplikacije/academic_year?academic_year=%s", stDomServiceURL, academicYear)
-
-	// Create HTTP request (no auth required - public endpoint)
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %v", err)
-	}
-
-	// Make the request
-	client := &http.Client{Timeout: 10 * time.Second}
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to call st_dom_service: %v", err)
-	}
-	defer resp.Body.Close()
-
-	// Read response body
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read response: %v", err)
-	}
-
-	// Check status code
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("st_dom_service returned status %d: %s", resp.StatusCode, string(body))
-	}
-
-	// Parse JSON response
-	var result map[string]interface{}
-	if err := json.Unmarshal(body, &result); err != nil {
-		return nil, fmt.Errorf("failed to parse response: %v", err)
-	}
-
-	return result, nil
-}
-
 // FormatJSON formats data to JSON string
 func FormatJSON(data interface{}) (string, error) {
 	jsonData, err := json.MarshalIndent(data, "", "  ")
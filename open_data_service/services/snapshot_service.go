@@ -0,0 +1,490 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"open_data_service/models"
+	"open_data_service/storage"
+	"sort"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var (
+	ErrSnapshotNotFound       = errors.New("snapshot not found")
+	ErrSnapshotFormatNotFound = errors.New("snapshot is not available in this format")
+	ErrDatasetNotAnonymized   = errors.New("dataset contains personal data and has no anonymization policy")
+)
+
+// SnapshotService stores scheduled, immutable snapshots of the export datasets so downloads can be cited and reproduced
+// Snapshot metadata is kept in MongoDB and the files in the blob store; neither is modified after it is written,
+// except that the files of personal dataset versions taken without anonymization are withdrawn
+type SnapshotService struct {
+	collection           *mongo.Collection
+	openDataService      *OpenDataService
+	anonymizationService *AnonymizationService
+	store                storage.BlobStore
+}
+
+// NewSnapshotService creates a new SnapshotService
+func NewSnapshotService(collection *mongo.Collection, openDataService *OpenDataService, anonymizationService *AnonymizationService, store storage.BlobStore) *SnapshotService {
+	return &SnapshotService{
+		collection:           collection,
+		openDataService:      openDataService,
+		anonymizationService: anonymizationService,
+		store:                store,
+	}
+}
+
+// EnsureIndexes creates the unique index on dataset and version
+func (s *SnapshotService) EnsureIndexes() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err := s.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "dataset", Value: 1}, {Key: "version", Value: -1}},
+		Options: options.Index().SetUnique(true),
+	})
+	return err
+}
+
+// WithdrawUnanonymizedSnapshots deletes the files of personal dataset versions stored without anonymization metadata
+// Such versions were taken before the dataset was anonymized and contain raw index numbers and grades
+// The version documents are kept without files and checksum, so their version numbers are never reused
+func (s *SnapshotService) WithdrawUnanonymizedSnapshots() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Minute)
+	defer cancel()
+
+	personal := []string{}
+	for _, descriptor := range datasetDescriptors {
+		if s.anonymizationService.policy(descriptor.id).PersonalData {
+			personal = append(personal, descriptor.id)
+		}
+	}
+
+	cursor, err := s.collection.Find(ctx, bson.M{
+		"dataset":       bson.M{"$in": personal},
+		"anonymization": bson.M{"$exists": false},
+		"files.0":       bson.M{"$exists": true},
+	})
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	snapshots := []models.DatasetSnapshot{}
+	if err := cursor.All(ctx, &snapshots); err != nil {
+		return err
+	}
+
+	for i := range snapshots {
+		snapshot := &snapshots[i]
+		for _, file := range snapshot.Files {
+			if err := s.store.Delete(ctx, file.BlobKey); err != nil {
+				return fmt.Errorf("failed to delete snapshot file %s: %w", file.BlobKey, err)
+			}
+		}
+
+		_, err := s.collection.UpdateOne(ctx, bson.M{"_id": snapshot.ID}, bson.M{
+			"$set": bson.M{"files": []models.SnapshotFile{}, "rows": 0, "data_checksum": ""},
+		})
+		if err != nil {
+			return err
+		}
+		log.Printf("Withdrew unanonymized snapshot %s v%d", snapshot.Dataset, snapshot.Version)
+	}
+
+	return nil
+}
+
+// SnapshotAll takes a snapshot of every dataset in the catalog
+// A failing dataset is logged and skipped so the others are still snapshotted
+func (s *SnapshotService) SnapshotAll() {
+	for _, descriptor := range datasetDescriptors {
+		snapshot, created, err := s.Snapshot(descriptor.id)
+		if err != nil {
+			log.Printf("Snapshot of dataset %s failed: %v", descriptor.id, err)
+			continue
+		}
+		if created {
+			log.Printf("Stored snapshot %s v%d (%d rows)", snapshot.Dataset, snapshot.Version, snapshot.Rows)
+		}
+	}
+}
+
+// Snapshot stores a new version of the dataset if its data changed since the latest version
+// Returns the latest version and whether it was created by this call
+// Personal datasets are snapshotted only once they have an anonymization policy
+func (s *SnapshotService) Snapshot(dataset string) (*models.DatasetSnapshot, bool, error) {
+	policy := s.anonymizationService.policy(dataset)
+	if policy.PersonalData && len(policy.Techniques) == 0 {
+		return nil, false, ErrDatasetNotAnonymized
+	}
+
+	files, rows, dataChecksum, err := s.renderDataset(dataset)
+	if err != nil {
+		return nil, false, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Minute)
+	defer cancel()
+
+	// Withdrawn versions count here, so their version numbers are not reused
+	latest, err := s.findLatest(ctx, bson.M{"dataset": dataset})
+	if err != nil && !errors.Is(err, ErrSnapshotNotFound) {
+		return nil, false, err
+	}
+	if latest != nil && latest.DataChecksum == dataChecksum && latest.Anonymization != nil {
+		return latest, false, nil
+	}
+
+	snapshot := &models.DatasetSnapshot{
+		ID:            primitive.NewObjectID(),
+		Dataset:       dataset,
+		Version:       1,
+		CreatedAt:     time.Now().UTC(),
+		Rows:          rows,
+		DataChecksum:  dataChecksum,
+		Anonymization: &policy,
+	}
+	if latest != nil {
+		snapshot.Version = latest.Version + 1
+	}
+
+	// Blob keys use the snapshot ID, so two instances racing for the same version never overwrite each other's files
+	for _, file := range files {
+		file.BlobKey = fmt.Sprintf("%s/%s.%s", dataset, snapshot.ID.Hex(), file.Format)
+		if _, err := s.store.Put(ctx, file.BlobKey, bytes.NewReader(file.content)); err != nil {
+			s.deleteFiles(ctx, snapshot)
+			return nil, false, err
+		}
+		snapshot.Files = append(snapshot.Files, file.SnapshotFile)
+	}
+
+	if _, err := s.collection.InsertOne(ctx, snapshot); err != nil {
+		s.deleteFiles(ctx, snapshot)
+		if mongo.IsDuplicateKeyError(err) {
+			// Another instance stored this version first
+			latest, err := s.GetLatest(dataset)
+			return latest, false, err
+		}
+		return nil, false, err
+	}
+
+	return snapshot, true, nil
+}
+
+// ListVersions returns all versions of the dataset, newest first
+func (s *SnapshotService) ListVersions(dataset string) ([]models.DatasetSnapshot, error) {
+	if !isCatalogDataset(dataset) {
+		return nil, ErrDatasetNotFound
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	cursor, err := s.collection.Find(ctx, s.servableFilter(dataset), options.Find().SetSort(bson.D{{Key: "version", Value: -1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	snapshots := []models.DatasetSnapshot{}
+	if err := cursor.All(ctx, &snapshots); err != nil {
+		return nil, err
+	}
+
+	return snapshots, nil
+}
+
+// ListLatest returns the latest version of every dataset that has snapshots
+func (s *SnapshotService) ListLatest() ([]models.DatasetSnapshot, error) {
+	snapshots := []models.DatasetSnapshot{}
+	for _, descriptor := range datasetDescriptors {
+		latest, err := s.GetLatest(descriptor.id)
+		if err != nil {
+			if errors.Is(err, ErrSnapshotNotFound) {
+				continue
+			}
+			return nil, err
+		}
+		snapshots = append(snapshots, *latest)
+	}
+
+	return snapshots, nil
+}
+
+// GetLatest returns the newest version of the dataset
+func (s *SnapshotService) GetLatest(dataset string) (*models.DatasetSnapshot, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	return s.findLatest(ctx, s.servableFilter(dataset))
+}
+
+// findLatest returns the snapshot with the highest version among those matching the filter
+func (s *SnapshotService) findLatest(ctx context.Context, filter bson.M) (*models.DatasetSnapshot, error) {
+	var snapshot models.DatasetSnapshot
+	err := s.collection.FindOne(ctx, filter, options.FindOne().SetSort(bson.D{{Key: "version", Value: -1}})).Decode(&snapshot)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, ErrSnapshotNotFound
+		}
+		return nil, err
+	}
+
+	return &snapshot, nil
+}
+
+// GetVersion returns one version of the dataset
+func (s *SnapshotService) GetVersion(dataset string, version int) (*models.DatasetSnapshot, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	filter := s.servableFilter(dataset)
+	filter["version"] = version
+
+	var snapshot models.DatasetSnapshot
+	err := s.collection.FindOne(ctx, filter).Decode(&snapshot)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, ErrSnapshotNotFound
+		}
+		return nil, err
+	}
+
+	return &snapshot, nil
+}
+
+// OpenFile opens the stored file of the snapshot in the given format
+// The caller must close the returned reader
+func (s *SnapshotService) OpenFile(ctx context.Context, snapshot *models.DatasetSnapshot, format models.ExportFormat) (io.ReadCloser, *models.SnapshotFile, error) {
+	if !s.isServable(snapshot) {
+		return nil, nil, ErrSnapshotNotFound
+	}
+
+	file := snapshot.File(format)
+	if file == nil {
+		return nil, nil, ErrSnapshotFormatNotFound
+	}
+
+	content, err := s.store.Get(ctx, file.BlobKey)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return content, file, nil
+}
+
+// Diff compares the CSV rows of two versions of the dataset
+// At most limit added and removed rows are listed; the counts always cover all rows
+func (s *SnapshotService) Diff(dataset string, from, to, limit int) (*models.SnapshotDiff, error) {
+	fromSnapshot, err := s.GetVersion(dataset, from)
+	if err != nil {
+		return nil, err
+	}
+	toSnapshot, err := s.GetVersion(dataset, to)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	fromRows, err := s.readCSV(ctx, fromSnapshot)
+	if err != nil {
+		return nil, err
+	}
+	toRows, err := s.readCSV(ctx, toSnapshot)
+	if err != nil {
+		return nil, err
+	}
+
+	diff := &models.SnapshotDiff{
+		Dataset:       dataset,
+		From:          from,
+		To:            to,
+		FromCreatedAt: fromSnapshot.CreatedAt,
+		ToCreatedAt:   toSnapshot.CreatedAt,
+		Added:         [][]string{},
+		Removed:       [][]string{},
+	}
+
+	var fromHeader, toHeader []string
+	if len(fromRows) > 0 {
+		fromHeader, fromRows = fromRows[0], fromRows[1:]
+	}
+	if len(toRows) > 0 {
+		toHeader, toRows = toRows[0], toRows[1:]
+	}
+	diff.Header = toHeader
+	diff.HeaderChanged = rowKey(fromHeader) != rowKey(toHeader)
+
+	// Rows are compared as a multiset: each old row cancels out one identical new row
+	remaining := make(map[string]int)
+	for _, row := range fromRows {
+		remaining[rowKey(row)]++
+	}
+
+	for _, row := range toRows {
+		key := rowKey(row)
+		if remaining[key] > 0 {
+			remaining[key]--
+			diff.UnchangedCount++
+			continue
+		}
+		diff.AddedCount++
+		if len(diff.Added) < limit {
+			diff.Added = append(diff.Added, row)
+		}
+	}
+
+	for _, row := range fromRows {
+		key := rowKey(row)
+		if remaining[key] == 0 {
+			continue
+		}
+		remaining[key]--
+		diff.RemovedCount++
+		if len(diff.Removed) < limit {
+			diff.Removed = append(diff.Removed, row)
+		}
+	}
+
+	diff.Truncated = diff.AddedCount > len(diff.Added) || diff.RemovedCount > len(diff.Removed)
+
+	return diff, nil
+}
+
+// StartScheduler takes snapshots of all datasets in the background, now and then on every period
+func (s *SnapshotService) StartScheduler(period time.Duration) {
+	go func() {
+		s.SnapshotAll()
+
+		ticker := time.NewTicker(period)
+		defer ticker.Stop()
+		for range ticker.C {
+			s.SnapshotAll()
+		}
+	}()
+}
+
+// servableFilter matches the versions of the dataset that may be served
+// Versions of personal datasets must carry the anonymization that was applied to them
+func (s *SnapshotService) servableFilter(dataset string) bson.M {
+	filter := bson.M{"dataset": dataset}
+	if s.anonymizationService.policy(dataset).PersonalData {
+		filter["anonymization"] = bson.M{"$exists": true}
+	}
+	return filter
+}
+
+// isServable reports whether the snapshot may be served, see servableFilter
+func (s *SnapshotService) isServable(snapshot *models.DatasetSnapshot) bool {
+	return snapshot.Anonymization != nil || !s.anonymizationService.policy(snapshot.Dataset).PersonalData
+}
+
+// renderedFile is a snapshot file with its content, before it is stored
+type renderedFile struct {
+	models.SnapshotFile
+	content []byte
+}
+
+// renderDataset exports the dataset in every snapshot format exactly as the /export endpoint returns it
+// Returns the files, the number of data rows and the data checksum
+// Row order is not guaranteed, so changes are detected on the sorted CSV rows instead of the file checksums
+func (s *SnapshotService) renderDataset(dataset string) ([]renderedFile, int, string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Minute)
+	defer cancel()
+
+	var csvContent, jsonContent bytes.Buffer
+	if err := s.openDataService.StreamExport(ctx, dataset, models.ExportFormatCSV, &csvContent); err != nil {
+		return nil, 0, "", err
+	}
+	if err := s.openDataService.StreamExport(ctx, dataset, models.ExportFormatJSON, &jsonContent); err != nil {
+		return nil, 0, "", err
+	}
+
+	rows, err := csv.NewReader(bytes.NewReader(csvContent.Bytes())).ReadAll()
+	if err != nil {
+		return nil, 0, "", err
+	}
+
+	keys := make([]string, 0, len(rows))
+	for _, row := range rows {
+		keys = append(keys, rowKey(row))
+	}
+	if len(keys) > 1 {
+		sort.Strings(keys[1:]) // Keep the header first
+	}
+	sum := sha256.Sum256([]byte(strings.Join(keys, "\n")))
+
+	return []renderedFile{
+		newRenderedFile(models.ExportFormatCSV, csvContent.Bytes()),
+		newRenderedFile(models.ExportFormatJSON, jsonContent.Bytes()),
+	}, max(len(rows)-1, 0), hex.EncodeToString(sum[:]), nil
+}
+
+// newRenderedFile computes the size and checksum of the content
+func newRenderedFile(format models.ExportFormat, content []byte) renderedFile {
+	sum := sha256.Sum256(content)
+	return renderedFile{
+		SnapshotFile: models.SnapshotFile{
+			Format: format,
+			Size:   int64(len(content)),
+			SHA256: hex.EncodeToString(sum[:]),
+		},
+		content: content,
+	}
+}
+
+// readCSV reads and parses the CSV file of the snapshot
+func (s *SnapshotService) readCSV(ctx context.Context, snapshot *models.DatasetSnapshot) ([][]string, error) {
+	content, _, err := s.OpenFile(ctx, snapshot, models.ExportFormatCSV)
+	if err != nil {
+		return nil, err
+	}
+	defer content.Close()
+
+	reader := csv.NewReader(content)
+	reader.FieldsPerRecord = -1
+	return reader.ReadAll()
+}
+
+// deleteFiles removes the stored files of a snapshot that could not be saved
+func (s *SnapshotService) deleteFiles(ctx context.Context, snapshot *models.DatasetSnapshot) {
+	for _, file := range snapshot.Files {
+		if err := s.store.Delete(ctx, file.BlobKey); err != nil {
+			log.Printf("Failed to delete snapshot file %s: %v", file.BlobKey, err)
+		}
+	}
+}
+
+// isCatalogDataset reports whether the dataset is listed in the catalog
+func isCatalogDataset(dataset string) bool {
+	for _, descriptor := range datasetDescriptors {
+		if descriptor.id == dataset {
+			return true
+		}
+	}
+	return false
+}
+
+// rowKey joins the row values into a comparable key
+func rowKey(row []string) string {
+	key, _ := json.Marshal(row)
+	return string(key)
+}
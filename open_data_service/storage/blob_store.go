@@ -0,0 +1,21 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+// ErrBlobNotFound is returned when a blob does not exist in the store
+var ErrBlobNotFound = errors.New("blob not found")
+
+// BlobStore is an abstraction for storing binary files (dataset snapshots)
+// LocalBlobStore is the default implementation; others (e.g. S3) can be added without changing the services
+type BlobStore interface {
+	// Put stores the content under the given key and returns the number of bytes written
+	Put(ctx context.Context, key string, content io.Reader) (int64, error)
+	// Get opens the content stored under the given key
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete removes the content stored under the given key
+	Delete(ctx context.Context, key string) error
+}
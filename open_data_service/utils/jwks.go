@@ -0,0 +1,179 @@
+package utils
+
+import (
+	"context"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"log"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// jwksMinRefreshInterval is the minimum time between two fetches caused by an unknown kid
+const jwksMinRefreshInterval = 30 * time.Second
+
+// JWKSCache caches the sso_service public keys used to verify service tokens.
+// Keys are refreshed periodically, and a token with an unknown kid (key rotation) triggers a refetch.
+type JWKSCache struct {
+	jwksURL     string
+	httpClient  *http.Client
+	mu          sync.RWMutex
+	keys        map[string]publicKey
+	lastRefresh time.Time
+}
+
+// publicKey is a verification key together with the algorithm tokens are signed with
+type publicKey struct {
+	algorithm string
+	key       crypto.PublicKey
+}
+
+// jwk is a public key in JSON Web Key format as published by sso_service
+type jwk struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Algorithm string `json:"alg"`
+	N         string `json:"n"`
+	E         string `json:"e"`
+	Curve     string `json:"crv"`
+	X         string `json:"x"`
+}
+
+// NewJWKSCache creates an empty key cache for the given JWKS URL
+func NewJWKSCache(jwksURL string) *JWKSCache {
+	return &JWKSCache{
+		jwksURL:    jwksURL,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+		keys:       make(map[string]publicKey),
+	}
+}
+
+// Start refreshes the keys in the background on the given interval
+// If sso_service is unavailable the last fetched keys are kept
+func (j *JWKSCache) Start(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			if err := j.Refresh(context.Background()); err != nil {
+				log.Println("Failed to refresh JWKS:", err)
+			}
+			<-ticker.C
+		}
+	}()
+}
+
+// Refresh fetches the current public keys from sso_service
+func (j *JWKSCache) Refresh(ctx context.Context) error {
+	j.mu.Lock()
+	j.lastRefresh = time.Now()
+	j.mu.Unlock()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, j.jwksURL, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := j.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return errors.New("failed to fetch JWKS")
+	}
+
+	var result struct {
+		Keys []jwk `json:"keys"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return err
+	}
+
+	keys := make(map[string]publicKey, len(result.Keys))
+	for _, key := range result.Keys {
+		parsed, err := parseJWK(key)
+		if err != nil {
+			log.Printf("Skipping JWK %s: %v", key.KeyID, err)
+			continue
+		}
+		keys[key.KeyID] = publicKey{algorithm: key.Algorithm, key: parsed}
+	}
+
+	j.mu.Lock()
+	j.keys = keys
+	j.mu.Unlock()
+
+	return nil
+}
+
+// PublicKey returns the public key and algorithm for the kid from the token header
+// An unknown kid refetches the keys, at most once per jwksMinRefreshInterval
+func (j *JWKSCache) PublicKey(kid string) (crypto.PublicKey, string, error) {
+	if key, exists := j.lookup(kid); exists {
+		return key.key, key.algorithm, nil
+	}
+
+	j.mu.RLock()
+	canRefresh := time.Since(j.lastRefresh) >= jwksMinRefreshInterval
+	j.mu.RUnlock()
+
+	if canRefresh {
+		if err := j.Refresh(context.Background()); err != nil {
+			log.Println("Failed to refresh JWKS:", err)
+		}
+		if key, exists := j.lookup(kid); exists {
+			return key.key, key.algorithm, nil
+		}
+	}
+
+	return nil, "", errors.New("unknown signing key")
+}
+
+// lookup finds a key in the cache
+func (j *JWKSCache) lookup(kid string) (publicKey, bool) {
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+
+	key, exists := j.keys[kid]
+	return key, exists
+}
+
+// parseJWK converts a JWK into an RSA or Ed25519 public key
+func parseJWK(key jwk) (crypto.PublicKey, error) {
+	switch key.KeyType {
+	case "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(key.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := base64.RawURLEncoding.DecodeString(key.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+	case "OKP":
+		if key.Curve != "Ed25519" {
+			return nil, errors.New("unsupported curve")
+		}
+		x, err := base64.RawURLEncoding.DecodeString(key.X)
+		if err != nil {
+			return nil, err
+		}
+		if len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("invalid Ed25519 key size")
+		}
+		return ed25519.PublicKey(x), nil
+	default:
+		return nil, errors.New("unsupported key type")
+	}
+}
@@ -0,0 +1,51 @@
+package utils
+
+import (
+	"errors"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// Service token markers set by sso_service: typ separates them from user tokens, aud limits them to internal routes
+const (
+	ServiceTokenType     = "service"
+	ServiceTokenAudience = "internal"
+)
+
+// ServiceClaims represents the claims of a service-to-service (client credentials) token.
+// The subject is the calling service's client ID.
+type ServiceClaims struct {
+	TokenType string `json:"typ"`
+	jwt.RegisteredClaims
+}
+
+// ValidateServiceJWT validates a service token issued by sso_service and returns its claims
+func ValidateServiceJWT(tokenString string, keys *JWKSCache) (*ServiceClaims, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &ServiceClaims{}, keyFunc(keys))
+	if err != nil {
+		return nil, err
+	}
+
+	claims, ok := token.Claims.(*ServiceClaims)
+	if !ok || !token.Valid || claims.TokenType != ServiceTokenType || claims.Subject == "" ||
+		!claims.VerifyAudience(ServiceTokenAudience, true) {
+		return nil, errors.New("invalid service token")
+	}
+
+	return claims, nil
+}
+
+// keyFunc selects the public key by the kid header and checks the signing algorithm
+func keyFunc(keys *JWKSCache) jwt.Keyfunc {
+	return func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		publicKey, algorithm, err := keys.PublicKey(kid)
+		if err != nil {
+			return nil, err
+		}
+		if token.Method.Alg() != algorithm {
+			return nil, errors.New("unexpected signing method")
+		}
+		return publicKey, nil
+	}
+}
@@ -0,0 +1,69 @@
+package utils
+
+import (
+	"sync"
+	"time"
+)
+
+// RateLimiter counts requests per client in fixed time windows.
+// Counters live in memory, so each service instance enforces the limit on its own.
+type RateLimiter struct {
+	window      time.Duration
+	mu          sync.Mutex
+	counters    map[string]*rateWindow
+	lastCleanup time.Time
+}
+
+// rateWindow is the request count of one client in the current window
+type rateWindow struct {
+	start time.Time
+	used  int
+}
+
+// NewRateLimiter creates a limiter with the given window length (e.g. one minute)
+func NewRateLimiter(window time.Duration) *RateLimiter {
+	return &RateLimiter{
+		window:      window,
+		counters:    make(map[string]*rateWindow),
+		lastCleanup: time.Now(),
+	}
+}
+
+// Allow consumes cost units of the client's limit for the current window.
+// It returns whether the request is allowed, the units left and when the window resets.
+// A rejected request consumes nothing.
+func (l *RateLimiter) Allow(client string, limit, cost int) (bool, int, time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	l.cleanup(now)
+
+	counter, exists := l.counters[client]
+	if !exists || now.Sub(counter.start) >= l.window {
+		counter = &rateWindow{start: now}
+		l.counters[client] = counter
+	}
+	reset := counter.start.Add(l.window)
+
+	if counter.used+cost > limit {
+		return false, limit - counter.used, reset
+	}
+
+	counter.used += cost
+	return true, limit - counter.used, reset
+}
+
+// cleanup drops counters of windows that have ended, at most once per window
+func (l *RateLimiter) cleanup(now time.Time) {
+	if now.Sub(l.lastCleanup) < l.window {
+		return
+	}
+
+	for client, counter := range l.counters {
+		if now.Sub(counter.start) >= l.window {
+			delete(l.counters, client)
+		}
+	}
+	l.lastCleanup = now
+}
@@ -0,0 +1,92 @@
+package utils
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// serviceTokenRenewBefore is how long before expiry a cached service token is renewed
+const serviceTokenRenewBefore = 30 * time.Second
+
+// ServiceTokenSource obtains a service token from sso_service (client credentials) and caches it until it expires.
+// The token identifies open_data_service when it calls other services.
+type ServiceTokenSource struct {
+	tokenURL     string
+	clientID     string
+	clientSecret string
+	httpClient   *http.Client
+	mu           sync.Mutex
+	token        string
+	expiresAt    time.Time
+}
+
+// NewServiceTokenSource creates a token source for the given SSO service and this service's credentials
+func NewServiceTokenSource(ssoServiceURL, clientID, clientSecret string) *ServiceTokenSource {
+	return &ServiceTokenSource{
+		tokenURL:     ssoServiceURL + "/api/v1/oauth/token",
+		clientID:     clientID,
+		clientSecret: clientSecret,
+		httpClient:   &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Token returns a valid service token, fetching a new one only when the cached token is about to expire
+func (s *ServiceTokenSource) Token(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token != "" && time.Now().Add(serviceTokenRenewBefore).Before(s.expiresAt) {
+		return s.token, nil
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "client_credentials")
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth(s.clientID, s.clientSecret)
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", errors.New("failed to obtain service token")
+	}
+
+	var result struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int64  `json:"expires_in"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", err
+	}
+
+	s.token = result.AccessToken
+	s.expiresAt = time.Now().Add(time.Duration(result.ExpiresIn) * time.Second)
+
+	return s.token, nil
+}
+
+// Authorize sets the service token as the bearer token of the request
+func (s *ServiceTokenSource) Authorize(req *http.Request) error {
+	token, err := s.Token(req.Context())
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Authorization", "Bearer "+token)
+	return nil
+}
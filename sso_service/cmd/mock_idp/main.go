@@ -0,0 +1,290 @@
+// mock_idp - lokalni OIDC provajder identiteta za razvoj i testiranje prijave univerzitetskim nalogom
+// ne proverava lozinke: na stranici za prijavu se unose podaci korisnika koji ce biti upisani u ID token
+package main
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"html/template"
+	"log"
+	"math/big"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+const keyID = "mock-idp"
+
+// izdat autorizacioni kod sa podacima korisnika i parametrima zahteva
+type authorizationCode struct {
+	clientID      string
+	redirectURI   string
+	nonce         string
+	codeChallenge string
+	claims        jwt.MapClaims
+	expiresAt     time.Time
+}
+
+// mockIDP - stanje provajdera: kljuc za potpis i izdati kodovi
+type mockIDP struct {
+	issuer       string
+	publicURL    string
+	clientID     string
+	clientSecret string
+	key          *rsa.PrivateKey
+
+	mu    sync.Mutex
+	codes map[string]authorizationCode
+}
+
+var loginPage = template.Must(template.New("login").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>Mock univerzitetski IdP</title></head>
+<body style="font-family: sans-serif; max-width: 420px; margin: 40px auto;">
+  <h2>Mock univerzitetski IdP</h2>
+  <p>Unesite podatke korisnika koji ce biti poslati SSO servisu.</p>
+  <form method="post">
+    {{range $name, $value := .Params}}<input type="hidden" name="{{$name}}" value="{{$value}}">{{end}}
+    <p><label>Subject (sub)<br><input name="sub" value="student-1" required></label></p>
+    <p><label>Email<br><input name="email" value="student1@uns.ac.rs" required></label></p>
+    <p><label><input type="checkbox" name="email_verified" value="true" checked> Email potvrdjen</label></p>
+    <p><label>Ime<br><input name="given_name" value="Petar"></label></p>
+    <p><label>Prezime<br><input name="family_name" value="Petrovic"></label></p>
+    <p><label>Korisnicko ime<br><input name="preferred_username" value="petar.petrovic"></label></p>
+    <p><label>Broj indeksa<br><input name="index_number" value="SW-1-2024"></label></p>
+    <p><label>Fakultet<br><input name="faculty" value="Fakultet tehnickih nauka"></label></p>
+    <button type="submit" name="decision" value="approve">Prijavi se</button>
+    <button type="submit" name="decision" value="deny">Odbij</button>
+  </form>
+</body>
+</html>`))
+
+// pokrece mock provajder
+// MOCK_IDP_ISSUER je adresa na kojoj ga vidi SSO servis, a MOCK_IDP_PUBLIC_URL adresa na kojoj ga vidi browser
+func main() {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		log.Fatal("Failed to generate signing key:", err)
+	}
+
+	issuer := strings.TrimSuffix(getEnv("MOCK_IDP_ISSUER", "http://localhost:9090"), "/")
+	idp := &mockIDP{
+		issuer:       issuer,
+		publicURL:    strings.TrimSuffix(getEnv("MOCK_IDP_PUBLIC_URL", issuer), "/"),
+		clientID:     getEnv("MOCK_IDP_CLIENT_ID", "st_dom_sso"),
+		clientSecret: getEnv("MOCK_IDP_CLIENT_SECRET", "mock-idp-secret"),
+		key:          key,
+		codes:        make(map[string]authorizationCode),
+	}
+
+	http.HandleFunc("/.well-known/openid-configuration", idp.discovery)
+	http.HandleFunc("/jwks", idp.jwks)
+	http.HandleFunc("/authorize", idp.authorize)
+	http.HandleFunc("/token", idp.token)
+
+	port := getEnv("MOCK_IDP_PORT", "9090")
+	log.Printf("Mock identity provider %s listening on port %s", issuer, port)
+	if err := http.ListenAndServe(":"+port, nil); err != nil {
+		log.Fatal("Failed to start server:", err)
+	}
+}
+
+// discovery dokument - stranica za prijavu je na javnoj adresi, ostalo na adresi izdavaoca
+func (p *mockIDP) discovery(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"issuer":                                p.issuer,
+		"authorization_endpoint":                p.publicURL + "/authorize",
+		"token_endpoint":                        p.issuer + "/token",
+		"jwks_uri":                              p.issuer + "/jwks",
+		"response_types_supported":              []string{"code"},
+		"subject_types_supported":               []string{"public"},
+		"id_token_signing_alg_values_supported": []string{"RS256"},
+		"code_challenge_methods_supported":      []string{"S256"},
+	})
+}
+
+// javni kljuc za proveru potpisa ID tokena
+func (p *mockIDP) jwks(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": keyID,
+			"use": "sig",
+			"alg": "RS256",
+			"n":   base64.RawURLEncoding.EncodeToString(p.key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(p.key.E)).Bytes()),
+		}},
+	})
+}
+
+// GET prikazuje formu za unos podataka korisnika, POST izdaje kod i vraca korisnika klijentu
+func (p *mockIDP) authorize(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "invalid request", http.StatusBadRequest)
+		return
+	}
+
+	if r.Form.Get("client_id") != p.clientID || r.Form.Get("redirect_uri") == "" {
+		http.Error(w, "unknown client_id or missing redirect_uri", http.StatusBadRequest)
+		return
+	}
+
+	if r.Method == http.MethodGet {
+		params := map[string]string{}
+		for _, name := range []string{"client_id", "redirect_uri", "state", "nonce", "code_challenge", "code_challenge_method", "scope"} {
+			params[name] = r.Form.Get(name)
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		if err := loginPage.Execute(w, map[string]interface{}{"Params": params}); err != nil {
+			log.Println("Failed to render login page:", err)
+		}
+		return
+	}
+
+	query := url.Values{}
+	if state := r.Form.Get("state"); state != "" {
+		query.Set("state", state)
+	}
+
+	if r.Form.Get("decision") != "approve" {
+		query.Set("error", "access_denied")
+		http.Redirect(w, r, withQuery(r.Form.Get("redirect_uri"), query), http.StatusFound)
+		return
+	}
+
+	claims := jwt.MapClaims{
+		"sub":            r.Form.Get("sub"),
+		"email":          r.Form.Get("email"),
+		"email_verified": r.Form.Get("email_verified") == "true",
+	}
+	for _, name := range []string{"given_name", "family_name", "preferred_username", "index_number", "faculty"} {
+		if value := r.Form.Get(name); value != "" {
+			claims[name] = value
+		}
+	}
+
+	code := randomToken()
+	p.mu.Lock()
+	p.codes[code] = authorizationCode{
+		clientID:      p.clientID,
+		redirectURI:   r.Form.Get("redirect_uri"),
+		nonce:         r.Form.Get("nonce"),
+		codeChallenge: r.Form.Get("code_challenge"),
+		claims:        claims,
+		expiresAt:     time.Now().Add(time.Minute),
+	}
+	p.mu.Unlock()
+
+	query.Set("code", code)
+	http.Redirect(w, r, withQuery(r.Form.Get("redirect_uri"), query), http.StatusFound)
+}
+
+// menja kod za ID token - proverava klijenta, redirect_uri i PKCE
+func (p *mockIDP) token(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost || r.ParseForm() != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_request"})
+		return
+	}
+
+	clientID, clientSecret, ok := r.BasicAuth()
+	if ok {
+		clientID, _ = url.QueryUnescape(clientID)
+		clientSecret, _ = url.QueryUnescape(clientSecret)
+	} else {
+		clientID, clientSecret = r.PostForm.Get("client_id"), r.PostForm.Get("client_secret")
+	}
+	if clientID != p.clientID || clientSecret != p.clientSecret {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid_client"})
+		return
+	}
+
+	p.mu.Lock()
+	code, exists := p.codes[r.PostForm.Get("code")]
+	delete(p.codes, r.PostForm.Get("code"))
+	p.mu.Unlock()
+
+	if !exists || time.Now().After(code.expiresAt) || code.redirectURI != r.PostForm.Get("redirect_uri") {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+		return
+	}
+
+	if code.codeChallenge != "" {
+		sum := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+		if base64.RawURLEncoding.EncodeToString(sum[:]) != code.codeChallenge {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+			return
+		}
+	}
+
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"iss": p.issuer,
+		"aud": code.clientID,
+		"iat": now.Unix(),
+		"exp": now.Add(5 * time.Minute).Unix(),
+	}
+	if code.nonce != "" {
+		claims["nonce"] = code.nonce
+	}
+	for name, value := range code.claims {
+		claims[name] = value
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = keyID
+	idToken, err := token.SignedString(p.key)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "server_error"})
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"access_token": randomToken(),
+		"token_type":   "Bearer",
+		"expires_in":   300,
+		"id_token":     idToken,
+	})
+}
+
+// dodaje query parametre na adresu koja vec moze imati svoje parametre
+func withQuery(rawURL string, query url.Values) string {
+	if strings.Contains(rawURL, "?") {
+		return rawURL + "&" + query.Encode()
+	}
+	return rawURL + "?" + query.Encode()
+}
+
+// generiše nasumican token za kodove
+func randomToken() string {
+	bytes := make([]byte, 32)
+	if _, err := rand.Read(bytes); err != nil {
+		log.Fatal("Failed to generate random token:", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(bytes)
+}
+
+// upisuje JSON odgovor
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		log.Println("Failed to write response:", err)
+	}
+}
+
+// dobija environment varijablu ili vraca default vrednost ako ne postoji
+func getEnv(key, fallback string) string {
+	if value, exists := os.LookupEnv(key); exists {
+		return value
+	}
+	return fallback
+}
@@ -3,6 +3,9 @@ package config
 import (
 	"log"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -11,10 +14,64 @@ import (
 type Config struct {
 	MongoDBURI        string
 	DatabaseName      string
-	JWTSecret         string
+	JWTKeysDir        string
+	JWTActiveKeyID    string
+	JWTKeyAlgorithm   string
 	Port              string
 	GinMode           string
 	StDomServiceURL   string
+	AccessTokenTTL    time.Duration
+	RefreshTokenTTL   time.Duration
+	// frontend adresa na koju vode linkovi iz email poruka
+	AppBaseURL               string
+	RequireEmailVerification bool
+	EmailVerificationTTL     time.Duration
+	PasswordResetTTL         time.Duration
+	// "smtp" za pravo slanje ili "log" za razvoj (poruke se zapisuju u log ili MAIL_LOG_FILE)
+	MailDriver   string
+	MailLogFile  string
+	SMTPHost     string
+	SMTPPort     int
+	SMTPUsername string
+	SMTPPassword string
+	SMTPFrom     string
+	// naziv koji authenticator aplikacije prikazuju uz nalog i trajanje MFA izazova pri prijavi
+	MFAIssuer       string
+	MFAChallengeTTL time.Duration
+	// zastita od pogadjanja lozinke i cuvanje dnevnika prijava
+	LoginMaxAccountFailures int
+	LoginMaxIPFailures      int
+	LoginFailureWindow      time.Duration
+	LoginLockoutDuration    time.Duration
+	LoginAuditRetention     time.Duration
+	// base64 AES-256 kljuc za sifrovanje JMBG-a - bez njega se JMBG ne moze uneti
+	StudentDataKey string
+	// servisi koji mogu dobiti servisni token (SERVICE_CLIENTS="id:tajna,id:tajna") i trajanje tokena
+	ServiceClients  map[string]string
+	ServiceClientID string
+	ServiceTokenTTL time.Duration
+	// javna adresa SSO servisa koja se upisuje u OIDC tokene (iss) i trajanje autorizacionog koda
+	OIDCIssuer  string
+	OIDCCodeTTL time.Duration
+	// spoljni OIDC provajder identiteta (univerzitetski nalozi) - prazan FEDERATION_ISSUER iskljucuje prijavu preko njega
+	FederationName         string
+	FederationIssuer       string
+	FederationClientID     string
+	FederationClientSecret string
+	FederationRedirectURL  string
+	FederationScopes       string
+	FederationIndexClaim   string
+	FederationFacultyClaim string
+	FederationStateTTL     time.Duration
+	// prijava lozinkom i registracija samo za administratore - studenti koriste univerzitetski nalog
+	LocalLoginAdminsOnly bool
+	// API kljucevi za open data servis - podrazumevani limiti novih kljuceva i najveci broj aktivnih kljuceva po korisniku
+	OpenDataServiceURL string
+	APIKeyRateLimit    int
+	APIKeyDailyQuota   int
+	APIKeyMaxPerUser   int
+	// adrese ili mreze (CIDR) proksija kojima se veruje X-Real-IP zaglavlje - adresa klijenta za zakljucavanje prijave i dnevnik
+	TrustedProxies []string
 }
 
 // ucitava konfiguraciju iz environment varijabli ili config.env fajla
@@ -27,10 +84,62 @@ func LoadConfig() *Config {
 	config := &Config{
 		MongoDBURI:      getEnv("MONGODB_URI", "mongodb://localhost:27018"),
 		DatabaseName:    getEnv("DATABASE_NAME", "sso_db"),
-		JWTSecret:       getEnv("JWT_SECRET", "default_jwt_secret_change_in_production"),
+		JWTKeysDir:      getEnv("JWT_KEYS_DIR", "keys"),
+		JWTActiveKeyID:  getEnv("JWT_ACTIVE_KEY_ID", ""),
+		JWTKeyAlgorithm: getEnv("JWT_KEY_ALGORITHM", "RS256"),
 		Port:            getEnv("PORT", "8080"),
 		GinMode:         getEnv("GIN_MODE", "debug"),
 		StDomServiceURL: getEnv("ST_DOM_SERVICE_URL", "http://localhost:8081"),
+		AccessTokenTTL:  getDurationEnv("ACCESS_TOKEN_TTL", 15*time.Minute),
+		RefreshTokenTTL: getDurationEnv("REFRESH_TOKEN_TTL", 7*24*time.Hour),
+
+		AppBaseURL:               getEnv("APP_BASE_URL", "http://localhost:3000"),
+		RequireEmailVerification: getBoolEnv("REQUIRE_EMAIL_VERIFICATION", true),
+		EmailVerificationTTL:     getDurationEnv("EMAIL_VERIFICATION_TTL", 48*time.Hour),
+		PasswordResetTTL:         getDurationEnv("PASSWORD_RESET_TTL", time.Hour),
+
+		MailDriver:   getEnv("MAIL_DRIVER", "log"),
+		MailLogFile:  getEnv("MAIL_LOG_FILE", ""),
+		SMTPHost:     getEnv("SMTP_HOST", "localhost"),
+		SMTPPort:     getIntEnv("SMTP_PORT", 25),
+		SMTPUsername: getEnv("SMTP_USERNAME", ""),
+		SMTPPassword: getEnv("SMTP_PASSWORD", ""),
+		SMTPFrom:     getEnv("SMTP_FROM", "no-reply@stdom.local"),
+
+		MFAIssuer:       getEnv("MFA_ISSUER", "StDom"),
+		MFAChallengeTTL: getDurationEnv("MFA_CHALLENGE_TTL", 5*time.Minute),
+
+		LoginMaxAccountFailures: getIntEnv("LOGIN_MAX_ACCOUNT_FAILURES", 10),
+		LoginMaxIPFailures:      getIntEnv("LOGIN_MAX_IP_FAILURES", 50),
+		LoginFailureWindow:      getDurationEnv("LOGIN_FAILURE_WINDOW", 15*time.Minute),
+		LoginLockoutDuration:    getDurationEnv("LOGIN_LOCKOUT_DURATION", 15*time.Minute),
+		LoginAuditRetention:     getDurationEnv("LOGIN_AUDIT_RETENTION", 90*24*time.Hour),
+
+		StudentDataKey: getEnv("STUDENT_DATA_KEY", ""),
+
+		ServiceClients:  getPairsEnv("SERVICE_CLIENTS"),
+		ServiceClientID: getEnv("SERVICE_CLIENT_ID", "sso_service"),
+		ServiceTokenTTL: getDurationEnv("SERVICE_TOKEN_TTL", 5*time.Minute),
+
+		OIDCIssuer:  getEnv("OIDC_ISSUER", "http://localhost"),
+		OIDCCodeTTL: getDurationEnv("OIDC_CODE_TTL", time.Minute),
+
+		FederationName:         getEnv("FEDERATION_NAME", "Univerzitetski nalog"),
+		FederationIssuer:       getEnv("FEDERATION_ISSUER", ""),
+		FederationClientID:     getEnv("FEDERATION_CLIENT_ID", ""),
+		FederationClientSecret: getEnv("FEDERATION_CLIENT_SECRET", ""),
+		FederationRedirectURL:  getEnv("FEDERATION_REDIRECT_URL", "http://localhost/api/v1/auth/federated/callback"),
+		FederationScopes:       getEnv("FEDERATION_SCOPES", "openid profile email"),
+		FederationIndexClaim:   getEnv("FEDERATION_INDEX_CLAIM", "index_number"),
+		FederationFacultyClaim: getEnv("FEDERATION_FACULTY_CLAIM", "faculty"),
+		FederationStateTTL:     getDurationEnv("FEDERATION_STATE_TTL", 10*time.Minute),
+		LocalLoginAdminsOnly:   getBoolEnv("LOCAL_LOGIN_ADMINS_ONLY", false),
+
+		OpenDataServiceURL: getEnv("OPEN_DATA_SERVICE_URL", "http://localhost:8082"),
+		APIKeyRateLimit:    getIntEnv("API_KEY_RATE_LIMIT", 120),
+		APIKeyDailyQuota:   getIntEnv("API_KEY_DAILY_QUOTA", 10000),
+		APIKeyMaxPerUser:   getIntEnv("API_KEY_MAX_PER_USER", 5),
+		TrustedProxies:     getListEnv("TRUSTED_PROXIES", "127.0.0.1,::1,10.0.0.0/8,172.16.0.0/12,192.168.0.0/16"),
 	}
 
 	return config
@@ -44,3 +153,69 @@ func getEnv(key, fallback string) string {
 	return fallback
 }
 
+
+// dobija trajanje iz environment varijable (npr. "15m", "168h") ili vraca default vrednost
+// ako vrednost nije ispravna ili nije pozitivna
+func getDurationEnv(key string, fallback time.Duration) time.Duration {
+	if value, exists := os.LookupEnv(key); exists {
+		if duration, err := time.ParseDuration(value); err == nil && duration > 0 {
+			return duration
+		}
+		log.Printf("Invalid duration for %s, using default %s", key, fallback)
+	}
+	return fallback
+}
+
+// dobija logicku vrednost iz environment varijable ili vraca default vrednost
+func getBoolEnv(key string, fallback bool) bool {
+	if value, exists := os.LookupEnv(key); exists {
+		if parsed, err := strconv.ParseBool(value); err == nil {
+			return parsed
+		}
+		log.Printf("Invalid boolean for %s, using default %t", key, fallback)
+	}
+	return fallback
+}
+
+// dobija parove kljuc:vrednost razdvojene zarezom iz environment varijable
+// neispravni parovi se preskacu
+func getPairsEnv(key string) map[string]string {
+	pairs := make(map[string]string)
+	value, exists := os.LookupEnv(key)
+	if !exists {
+		return pairs
+	}
+
+	for _, item := range strings.Split(value, ",") {
+		name, secret, ok := strings.Cut(strings.TrimSpace(item), ":")
+		if !ok || name == "" || secret == "" {
+			log.Printf("Invalid entry in %s, skipping", key)
+			continue
+		}
+		pairs[name] = secret
+	}
+	return pairs
+}
+
+// dobija listu vrednosti razdvojenih zarezom iz environment varijable ili iz default vrednosti
+// prazne stavke se preskacu
+func getListEnv(key, fallback string) []string {
+	var items []string
+	for _, item := range strings.Split(getEnv(key, fallback), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// dobija ceo broj iz environment varijable ili vraca default vrednost
+func getIntEnv(key string, fallback int) int {
+	if value, exists := os.LookupEnv(key); exists {
+		if parsed, err := strconv.Atoi(value); err == nil {
+			return parsed
+		}
+		log.Printf("Invalid integer for %s, using default %d", key, fallback)
+	}
+	return fallback
+}
@@ -0,0 +1,273 @@
+package handlers
+
+import (
+	"net/http"
+	"sso_service/models"
+	"sso_service/services"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// AdminHandler - rukuje administratorskim zahtevima za upravljanje korisnicima
+type AdminHandler struct {
+	userService    *services.UserService
+	accountService *services.AccountService
+	mfaService     *services.MFAService
+	loginAttempts  *services.LoginAttemptService
+}
+
+// kreira novi AdminHandler sa servisom za korisnike, servisom za naloge, servisom za drugi faktor i dnevnikom prijava
+func NewAdminHandler(userService *services.UserService, accountService *services.AccountService, mfaService *services.MFAService, loginAttempts *services.LoginAttemptService) *AdminHandler {
+	return &AdminHandler{
+		userService:    userService,
+		accountService: accountService,
+		mfaService:     mfaService,
+		loginAttempts:  loginAttempts,
+	}
+}
+
+// pregled i pretraga korisnika sa stranicenjem
+// query parametri: search, role, status (active/disabled), page (default 1), limit (default 20, najvise 100)
+func (h *AdminHandler) ListUsers(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+
+	filter := models.UserFilter{
+		Search: c.Query("search"),
+		Role:   c.Query("role"),
+		Status: c.Query("status"),
+		Page:   page,
+		Limit:  limit,
+	}
+
+	if filter.Role != "" && !models.IsValidRole(filter.Role) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid role. Valid values: user, admin"})
+		return
+	}
+
+	if filter.Status != "" && filter.Status != "active" && filter.Status != "disabled" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid status. Valid values: active, disabled"})
+		return
+	}
+
+	result, err := h.userService.ListUsers(filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// pregled dnevnika prijava sa stranicenjem
+// query parametri: user_id, email, ip, outcome, page (default 1), limit (default 50, najvise 100)
+func (h *AdminHandler) ListLoginAttempts(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+
+	filter := models.LoginAttemptFilter{
+		Email:     c.Query("email"),
+		IPAddress: c.Query("ip"),
+		Outcome:   c.Query("outcome"),
+		Page:      page,
+		Limit:     limit,
+	}
+
+	if userIDParam := c.Query("user_id"); userIDParam != "" {
+		userID, err := primitive.ObjectIDFromHex(userIDParam)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID format"})
+			return
+		}
+		filter.UserID = &userID
+	}
+
+	result, err := h.loginAttempts.ListAttempts(filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// dobija korisnika po ID-u
+func (h *AdminHandler) GetUser(c *gin.Context) {
+	userID, ok := parseUserID(c)
+	if !ok {
+		return
+	}
+
+	user, err := h.userService.GetUserByID(userID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"user": user,
+	})
+}
+
+// menja ulogu korisnika (user/admin) - postojece sesije korisnika se opozivaju
+func (h *AdminHandler) UpdateRole(c *gin.Context) {
+	userID, ok := parseUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, err := h.userService.UpdateRole(c.MustGet("user_id").(primitive.ObjectID), userID, req.Role)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "User role updated successfully",
+		"user":    user,
+	})
+}
+
+// menja domove kojima administrator upravlja - prazna lista oduzima pristup svim domovima
+// postojece sesije korisnika se opozivaju
+func (h *AdminHandler) UpdateManagedStDoms(c *gin.Context) {
+	userID, ok := parseUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateManagedStDomsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, err := h.userService.UpdateManagedStDoms(c.MustGet("user_id").(primitive.ObjectID), userID, req.StDomIDs)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Managed student dormitories updated successfully",
+		"user":    user,
+	})
+}
+
+// dodeljuje ili oduzima prava globalnog administratora
+// postojece sesije korisnika se opozivaju
+func (h *AdminHandler) UpdateSuperAdmin(c *gin.Context) {
+	userID, ok := parseUserID(c)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateSuperAdminRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, err := h.userService.SetSuperAdmin(c.MustGet("user_id").(primitive.ObjectID), userID, *req.SuperAdmin)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Global administrator access updated successfully",
+		"user":    user,
+	})
+}
+
+// onemogucava nalog - korisnik se ne moze prijaviti, a postojeci tokeni prestaju da vaze
+func (h *AdminHandler) DisableUser(c *gin.Context) {
+	h.setDisabled(c, true)
+}
+
+// ponovo omogucava onemoguceni nalog
+func (h *AdminHandler) EnableUser(c *gin.Context) {
+	h.setDisabled(c, false)
+}
+
+// primorava korisnika da promeni lozinku - opoziva sesije i salje link za reset
+func (h *AdminHandler) ForcePasswordReset(c *gin.Context) {
+	userID, ok := parseUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.accountService.ForcePasswordReset(userID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Password reset required, reset link sent to the user",
+	})
+}
+
+// ponistava dvofaktorsku autentifikaciju korisniku koji je izgubio uredjaj i kodove za oporavak
+// sesije korisnika se opozivaju, a administrator ce pri sledecoj prijavi morati ponovo da podesi TOTP
+func (h *AdminHandler) ResetMFA(c *gin.Context) {
+	userID, ok := parseUserID(c)
+	if !ok {
+		return
+	}
+
+	if userID == c.MustGet("user_id").(primitive.ObjectID) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "You cannot reset your own two-factor authentication"})
+		return
+	}
+
+	if err := h.mfaService.ResetTOTP(userID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Two-factor authentication reset successfully",
+	})
+}
+
+// zajednicka logika za onemogucavanje i omogucavanje naloga
+func (h *AdminHandler) setDisabled(c *gin.Context, disabled bool) {
+	userID, ok := parseUserID(c)
+	if !ok {
+		return
+	}
+
+	user, err := h.userService.SetDisabled(c.MustGet("user_id").(primitive.ObjectID), userID, disabled)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	message := "User enabled successfully"
+	if disabled {
+		message = "User disabled successfully"
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": message,
+		"user":    user,
+	})
+}
+
+// parsira :id parametar i upisuje odgovor ako nije ispravan
+func parseUserID(c *gin.Context) (primitive.ObjectID, bool) {
+	userID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID format"})
+		return primitive.NilObjectID, false
+	}
+	return userID, true
+}
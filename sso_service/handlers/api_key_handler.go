@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"sso_service/models"
+	"sso_service/services"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// najveci broj dana za koji se moze traziti potrosnja kljuca
+const maxAPIKeyUsageDays = 90
+
+// APIKeyHandler - rukuje API kljucevima za open data servis
+type APIKeyHandler struct {
+	apiKeyService *services.APIKeyService
+}
+
+// kreira novi APIKeyHandler sa servisom za API kljuceve
+func NewAPIKeyHandler(apiKeyService *services.APIKeyService) *APIKeyHandler {
+	return &APIKeyHandler{
+		apiKeyService: apiKeyService,
+	}
+}
+
+// vraca API kljuceve trenutno ulogovanog korisnika
+func (h *APIKeyHandler) ListKeys(c *gin.Context) {
+	keys, err := h.apiKeyService.ListKeys(c.MustGet("user_id").(primitive.ObjectID))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"api_keys": keys})
+}
+
+// kreira novi API kljuc - kljuc je u odgovoru samo ovaj put
+func (h *APIKeyHandler) CreateKey(c *gin.Context) {
+	var req models.CreateAPIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	key, err := h.apiKeyService.CreateKey(c.MustGet("user_id").(primitive.ObjectID), req.Name)
+	if err != nil {
+		if errors.Is(err, services.ErrAPIKeyLimitReached) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "API key created. Copy it now, it will not be shown again",
+		"api_key": key,
+	})
+}
+
+// opoziva API kljuc korisnika
+func (h *APIKeyHandler) RevokeKey(c *gin.Context) {
+	keyID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid API key ID format"})
+		return
+	}
+
+	if err := h.apiKeyService.RevokeKey(c.MustGet("user_id").(primitive.ObjectID), keyID); err != nil {
+		if errors.Is(err, services.ErrAPIKeyNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "API key revoked"})
+}
+
+// vraca dnevnu potrosnju kljuca - ?days=N (podrazumevano 30, najvise 90)
+func (h *APIKeyHandler) GetUsage(c *gin.Context) {
+	keyID, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid API key ID format"})
+		return
+	}
+
+	days, err := strconv.Atoi(c.DefaultQuery("days", "30"))
+	if err != nil || days < 1 || days > maxAPIKeyUsageDays {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "days must be between 1 and 90"})
+		return
+	}
+
+	usage, err := h.apiKeyService.GetUsage(c.MustGet("user_id").(primitive.ObjectID), keyID, days)
+	if err != nil {
+		if errors.Is(err, services.ErrAPIKeyNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Data(http.StatusOK, "application/json; charset=utf-8", usage)
+}
+
+// proverava API kljuc - za komunikaciju izmedju servisa
+// koristi se od strane open_data_service pri svakom zahtevu sa kljucem koji nije u njegovom kesu
+func (h *APIKeyHandler) VerifyKey(c *gin.Context) {
+	var req models.VerifyAPIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	verification, err := h.apiKeyService.VerifyKey(req.Key)
+	if err != nil {
+		if errors.Is(err, services.ErrAPIKeyInvalid) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, verification)
+}
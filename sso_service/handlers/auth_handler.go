@@ -1,9 +1,14 @@
 package handlers
 
 import (
+	"errors"
+	"log"
+	"math"
 	"net/http"
 	"sso_service/models"
 	"sso_service/services"
+	"sso_service/utils"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -11,13 +16,22 @@ import (
 
 // AuthHandler - rukuje zahtevima za autentifikaciju
 type AuthHandler struct {
-	userService *services.UserService
+	userService    *services.UserService
+	tokenService   *services.TokenService
+	accountService *services.AccountService
+	loginAttempts  *services.LoginAttemptService
+	keys           *utils.KeySet
 }
 
-// kreira novi AuthHandler sa prosledjenim user servisom
-func NewAuthHandler(userService *services.UserService) *AuthHandler {
+// kreira novi AuthHandler sa prosledjenim user servisom, servisom za tokene, servisom za verifikaciju naloga,
+// dnevnikom prijava i kljucevima za potpisivanje
+func NewAuthHandler(userService *services.UserService, tokenService *services.TokenService, accountService *services.AccountService, loginAttempts *services.LoginAttemptService, keys *utils.KeySet) *AuthHandler {
 	return &AuthHandler{
-		userService: userService,
+		userService:    userService,
+		tokenService:   tokenService,
+		accountService: accountService,
+		loginAttempts:  loginAttempts,
+		keys:           keys,
 	}
 }
 
@@ -32,14 +46,23 @@ func (h *AuthHandler) Register(c *gin.Context) {
 
 	user, err := h.userService.RegisterUser(req)
 	if err != nil {
+		if errors.Is(err, services.ErrLocalLoginDisabled) {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
 		return
 	}
 
 	user.Password = ""
 
+	// nalog je kreiran i ako slanje ne uspe - korisnik moze ponovo zatraziti link
+	if err := h.accountService.SendEmailVerification(user); err != nil {
+		log.Println("Failed to send verification email:", err)
+	}
+
 	c.JSON(http.StatusCreated, gin.H{
-		"message": "User registered successfully",
+		"message": "User registered successfully. Please check your email to verify your account",
 		"user":    user,
 	})
 }
@@ -53,16 +76,187 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		return
 	}
 
-	response, err := h.userService.LoginUser(req)
+	response, err := h.userService.LoginUser(req, clientInfo(c))
 	if err != nil {
+		if respondThrottled(c, err) {
+			return
+		}
+		if errors.Is(err, services.ErrEmailNotVerified) || errors.Is(err, services.ErrAccountDisabled) || errors.Is(err, services.ErrPasswordResetRequired) ||
+			errors.Is(err, services.ErrLocalLoginDisabled) {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
 		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
 		return
 	}
 
+	c.JSON(http.StatusOK, loginBody(response))
+}
+
+// pravi telo odgovora na prijavu - tokeni ili MFA izazov
+// ako je potreban drugi faktor, klijent salje kod na /auth/mfa/verify
+func loginBody(response *models.LoginResponse) gin.H {
+	if response.MFA != nil {
+		message := "Two-factor authentication required"
+		if response.MFA.EnrollmentRequired {
+			message = "Two-factor authentication setup required"
+		}
+		return gin.H{
+			"message":             message,
+			"mfa_required":        true,
+			"mfa_token":           response.MFA.MFAToken,
+			"enrollment_required": response.MFA.EnrollmentRequired,
+			"expires_in":          response.MFA.ExpiresIn,
+		}
+	}
+
+	return gin.H{
+		"message":       "Login successful",
+		"token":         response.AccessToken,
+		"refresh_token": response.RefreshToken,
+		"expires_in":    response.ExpiresIn,
+		"user":          response.User,
+	}
+}
+
+// osvezava tokene - prima refresh token i vraca novi access token i novi refresh token
+// stari refresh token vise ne vazi; ponovna upotreba starog tokena opoziva celu sesiju
+func (h *AuthHandler) Refresh(c *gin.Context) {
+	var req models.RefreshRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tokens, err := h.userService.RefreshTokens(req.RefreshToken, clientInfo(c))
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidRefreshToken) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":       "Token refreshed successfully",
+		"token":         tokens.AccessToken,
+		"refresh_token": tokens.RefreshToken,
+		"expires_in":    tokens.ExpiresIn,
+	})
+}
+
+// odjava korisnika - opoziva trenutnu sesiju i access token
+func (h *AuthHandler) Logout(c *gin.Context) {
+	claims, ok := getClaims(c)
+	if !ok {
+		return
+	}
+
+	if err := h.userService.Logout(claims); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Logged out successfully",
+	})
+}
+
+// odjava sa svih uredjaja - opoziva sve sesije korisnika
+func (h *AuthHandler) LogoutAll(c *gin.Context) {
+	claims, ok := getClaims(c)
+	if !ok {
+		return
+	}
+
+	if err := h.userService.LogoutAllSessions(claims); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Logged out from all sessions successfully",
+	})
+}
+
+// potvrdjuje email adresu pomocu tokena iz poruke poslate pri registraciji
+func (h *AuthHandler) VerifyEmail(c *gin.Context) {
+	var req models.VerifyEmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.accountService.VerifyEmail(req.Token); err != nil {
+		if errors.Is(err, services.ErrInvalidAccountToken) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Email verified successfully",
+	})
+}
+
+// ponovo salje link za potvrdu email adrese
+// odgovor je isti bez obzira da li nalog postoji
+func (h *AuthHandler) ResendVerification(c *gin.Context) {
+	var req models.EmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.accountService.ResendEmailVerification(req.Email); err != nil {
+		log.Println("Failed to resend verification email:", err)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "If the account exists and is not verified, a verification email has been sent",
+	})
+}
+
+// zaboravljena lozinka - salje link za reset lozinke na email
+// odgovor je isti bez obzira da li nalog postoji
+func (h *AuthHandler) ForgotPassword(c *gin.Context) {
+	var req models.EmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.accountService.RequestPasswordReset(req.Email); err != nil {
+		log.Println("Failed to send password reset email:", err)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "If the account exists, a password reset email has been sent",
+	})
+}
+
+// postavlja novu lozinku pomocu tokena iz email-a i odjavljuje korisnika sa svih uredjaja
+func (h *AuthHandler) ResetPassword(c *gin.Context) {
+	var req models.ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.accountService.ResetPassword(req.Token, req.Password); err != nil {
+		if errors.Is(err, services.ErrInvalidAccountToken) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{
-		"message": "Login successful",
-		"token":   response.Token,
-		"user":    response.User,
+		"message": "Password reset successfully",
 	})
 }
 
@@ -81,28 +275,113 @@ func (h *AuthHandler) GetProfile(c *gin.Context) {
 		return
 	}
 
+	// poslednje prijave na nalog - korisnik moze da primeti prijave koje nisu njegove
+	recentSignIns, err := h.loginAttempts.RecentForUser(user.ID, 10)
+	if err != nil {
+		log.Println("Failed to load recent sign-ins:", err)
+		recentSignIns = []models.LoginAttempt{}
+	}
+
 	c.JSON(http.StatusOK, gin.H{
-		"user": user,
+		"user":            user,
+		"recent_sign_ins": recentSignIns,
 	})
 }
 
-// brise nalog korisnika - prvo proverava da li korisnik ima aktivnu sobu
-// ne dozvoljava brisanje ako korisnik ima dodeljenu sobu u domu
-func (h *AuthHandler) DeleteAccount(c *gin.Context) {
-	userID, exists := c.Get("user_id")
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in context"})
+// menja podatke profila - ime i prezime odmah, a email tek posle potvrde nove adrese
+func (h *AuthHandler) UpdateProfile(c *gin.Context) {
+	userID := c.MustGet("user_id").(primitive.ObjectID)
+
+	var req models.UpdateProfileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	err := h.userService.DeleteUser(userID.(primitive.ObjectID))
+	user, err := h.userService.UpdateProfile(userID, req.FirstName, req.LastName)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
+	message := "Profile updated successfully"
+	if req.Email != nil && *req.Email != user.Email {
+		if err := h.accountService.RequestEmailChange(userID, *req.Email); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		message = "Profile updated successfully. Please check your new email address to confirm the change"
+
+		if user, err = h.userService.GetUserByID(userID); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": message,
+		"user":    user,
+	})
+}
+
+// menja lozinku - potrebna je trenutna lozinka, a sve ostale sesije korisnika se opozivaju
+func (h *AuthHandler) ChangePassword(c *gin.Context) {
+	claims, ok := getClaims(c)
+	if !ok {
+		return
+	}
+
+	var req models.ChangePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.accountService.ChangePassword(claims.UserID, claims.SessionID, req.CurrentPassword, req.NewPassword); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{
-		"message": "Account deleted successfully",
+		"message": "Password changed successfully. Other sessions have been signed out",
+	})
+}
+
+// vraca kontakt podatke korisnika - za komunikaciju izmedju servisa
+// koristi se od strane st_dom_service za slanje obavestenja email-om
+func (h *AuthHandler) GetUserContact(c *gin.Context) {
+	userID, err := primitive.ObjectIDFromHex(c.Param("userId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID format"})
+		return
+	}
+
+	user, err := h.userService.GetUserByID(userID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"user_id":    user.ID,
+		"email":      user.Email,
+		"first_name": user.FirstName,
+		"last_name":  user.LastName,
+	})
+}
+
+// vraca opozvane access tokene koji jos nisu istekli - za komunikaciju izmedju servisa
+// ostali servisi periodicno preuzimaju listu da bi odbijali opozvane tokene
+func (h *AuthHandler) GetRevokedTokens(c *gin.Context) {
+	revoked, err := h.tokenService.GetActiveRevokedTokens()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"revoked_tokens": revoked,
+		"count":          len(revoked),
 	})
 }
 
@@ -114,3 +393,50 @@ func (h *AuthHandler) Health(c *gin.Context) {
 	})
 }
 
+// objavljuje javne kljuceve za proveru potpisa tokena (JWKS)
+// ostali servisi ih kesiraju, pa se posle rotacije novi kljuc preuzima po nepoznatom kid-u
+func (h *AuthHandler) JWKS(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, h.keys.JWKS())
+}
+
+// upisuje 429 odgovor sa Retry-After zaglavljem ako je prijava privremeno odbijena zbog neuspelih pokusaja
+func respondThrottled(c *gin.Context, err error) bool {
+	var throttled *services.LoginThrottledError
+	if !errors.As(err, &throttled) {
+		return false
+	}
+
+	retryAfter := int64(math.Ceil(throttled.RetryAfter.Seconds()))
+	c.Header("Retry-After", strconv.FormatInt(retryAfter, 10))
+	c.JSON(http.StatusTooManyRequests, gin.H{
+		"error":       err.Error(),
+		"retry_after": retryAfter,
+	})
+	return true
+}
+
+// izvlaci JWT claims iz konteksta (postavlja ih AuthMiddleware) i upisuje odgovor ako ne postoje
+func getClaims(c *gin.Context) (*utils.JWTClaims, bool) {
+	value, exists := c.Get("claims")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Token claims not found in context"})
+		return nil, false
+	}
+
+	claims, ok := value.(*utils.JWTClaims)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token claims"})
+		return nil, false
+	}
+
+	return claims, true
+}
+
+// vraca IP adresu i user agent klijenta koji se beleze uz sesiju
+func clientInfo(c *gin.Context) models.ClientInfo {
+	return models.ClientInfo{
+		IPAddress: c.ClientIP(),
+		UserAgent: c.Request.UserAgent(),
+	}
+}
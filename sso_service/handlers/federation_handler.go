@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"sso_service/models"
+	"sso_service/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// kolacic koji vezuje prijavu preko spoljnog provajdera za browser koji ju je zapoceo
+// salje se samo rutama za prijavu preko provajdera i nije dostupan JavaScript-u
+const (
+	federationBindingCookie = "federation_binding"
+	federationCookiePath    = "/api/v1/auth/federated"
+)
+
+// FederationHandler - rukuje prijavom preko spoljnog provajdera identiteta (univerzitetski nalog)
+type FederationHandler struct {
+	federationService *services.FederationService
+	// naziv provajdera koji frontend prikazuje na dugmetu za prijavu
+	providerName string
+}
+
+// kreira novi FederationHandler sa servisom za prijavu preko spoljnog provajdera i nazivom provajdera
+func NewFederationHandler(federationService *services.FederationService, providerName string) *FederationHandler {
+	return &FederationHandler{
+		federationService: federationService,
+		providerName:      providerName,
+	}
+}
+
+// vraca da li je prijava preko spoljnog provajdera dostupna - frontend na osnovu toga prikazuje dugme
+func (h *FederationHandler) Status(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"enabled": h.federationService.Enabled(),
+		"name":    h.providerName,
+	})
+}
+
+// preusmerava korisnika na stranicu za prijavu spoljnog provajdera
+func (h *FederationHandler) Login(c *gin.Context) {
+	location, binding, err := h.federationService.LoginURL(c.Query("return_to"))
+	if err != nil {
+		if errors.Is(err, services.ErrFederationDisabled) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+		return
+	}
+
+	setBindingCookie(c, binding, int(h.federationService.BindingTTL().Seconds()))
+	c.Redirect(http.StatusFound, location)
+}
+
+// povratak sa spoljnog provajdera - preusmerava korisnika na frontend sa tiketom ili greskom
+// prihvata se samo u browseru koji je zapoceo prijavu (kolacic), sto sprecava podmetanje tudje prijave
+func (h *FederationHandler) Callback(c *gin.Context) {
+	binding, _ := c.Cookie(federationBindingCookie)
+	location := h.federationService.Callback(c.Query("state"), c.Query("code"), c.Query("error"), binding)
+	c.Redirect(http.StatusFound, location)
+}
+
+// zavrsava prijavu tiketom iz povratnog linka - odgovor je isti kao kod prijave lozinkom
+func (h *FederationHandler) Complete(c *gin.Context) {
+	var req models.FederatedLoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	binding, _ := c.Cookie(federationBindingCookie)
+	response, returnTo, err := h.federationService.Complete(req.Ticket, binding, clientInfo(c))
+	if err != nil {
+		if errors.Is(err, services.ErrAccountDisabled) {
+			c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, services.ErrFederationTicketInvalid) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	// tiket je iskoriscen, kolacic vise nije potreban
+	setBindingCookie(c, "", -1)
+
+	body := loginBody(response)
+	if returnTo != "" {
+		body["return_to"] = returnTo
+	}
+
+	c.JSON(http.StatusOK, body)
+}
+
+// postavlja ili brise (maxAge < 0) kolacic koji vezuje prijavu za browser
+// SameSite=Lax jer se browser sa provajdera vraca preusmerenjem sa drugog sajta
+func setBindingCookie(c *gin.Context, value string, maxAge int) {
+	secure := c.Request.TLS != nil || c.GetHeader("X-Forwarded-Proto") == "https"
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(federationBindingCookie, value, maxAge, federationCookiePath, "", secure, true)
+}
@@ -0,0 +1,161 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"sso_service/models"
+	"sso_service/services"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MFAHandler - rukuje zahtevima za dvofaktorsku autentifikaciju (TOTP i kodovi za oporavak)
+type MFAHandler struct {
+	mfaService *services.MFAService
+}
+
+// kreira novi MFAHandler sa servisom za drugi faktor
+func NewMFAHandler(mfaService *services.MFAService) *MFAHandler {
+	return &MFAHandler{
+		mfaService: mfaService,
+	}
+}
+
+// zavrsava prijavu - prima token izazova i TOTP kod (ili kod za oporavak) i vraca tokene
+// ako je izazov zahtevao podesavanje TOTP-a, odgovor sadrzi i kodove za oporavak koji se prikazuju samo jednom
+func (h *MFAHandler) VerifyChallenge(c *gin.Context) {
+	var req models.MFAVerifyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	response, recoveryCodes, err := h.mfaService.VerifyChallenge(req.MFAToken, req.Code, clientInfo(c))
+	if err != nil {
+		respondMFAError(c, err)
+		return
+	}
+
+	body := gin.H{
+		"message":       "Login successful",
+		"token":         response.AccessToken,
+		"refresh_token": response.RefreshToken,
+		"expires_in":    response.ExpiresIn,
+		"user":          response.User,
+	}
+	if recoveryCodes != nil {
+		body["recovery_codes"] = recoveryCodes
+	}
+
+	c.JSON(http.StatusOK, body)
+}
+
+// zapocinje podesavanje TOTP-a u okviru izazova pri prijavi (administrator koji ga jos nema)
+// vraca tajnu i otpauth URI; podesavanje se zavrsava slanjem prvog koda na /auth/mfa/verify
+func (h *MFAHandler) StartEnrollment(c *gin.Context) {
+	var req models.MFAEnrollRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	setup, err := h.mfaService.StartEnrollment(req.MFAToken)
+	if err != nil {
+		respondMFAError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Scan the secret with an authenticator app and confirm with the first code",
+		"totp":    setup,
+	})
+}
+
+// generiše TOTP tajnu za prijavljenog korisnika
+func (h *MFAHandler) SetupTOTP(c *gin.Context) {
+	setup, err := h.mfaService.SetupTOTP(c.MustGet("user_id").(primitive.ObjectID))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Scan the secret with an authenticator app and confirm with the first code",
+		"totp":    setup,
+	})
+}
+
+// ukljucuje TOTP prvim kodom i vraca kodove za oporavak
+func (h *MFAHandler) ConfirmTOTP(c *gin.Context) {
+	var req models.TOTPCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	recoveryCodes, err := h.mfaService.ConfirmTOTP(c.MustGet("user_id").(primitive.ObjectID), req.Code)
+	if err != nil {
+		respondMFAError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":        "Two-factor authentication enabled",
+		"recovery_codes": recoveryCodes,
+	})
+}
+
+// iskljucuje TOTP - nije dozvoljeno administratorima
+func (h *MFAHandler) DisableTOTP(c *gin.Context) {
+	var req models.DisableTOTPRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if err := h.mfaService.DisableTOTP(c.MustGet("user_id").(primitive.ObjectID), req.Password, req.Code); err != nil {
+		respondMFAError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Two-factor authentication disabled",
+	})
+}
+
+// izdaje nove kodove za oporavak - prethodni prestaju da vaze
+func (h *MFAHandler) RegenerateRecoveryCodes(c *gin.Context) {
+	var req models.TOTPCodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	recoveryCodes, err := h.mfaService.RegenerateRecoveryCodes(c.MustGet("user_id").(primitive.ObjectID), req.Code)
+	if err != nil {
+		respondMFAError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":        "Recovery codes regenerated",
+		"recovery_codes": recoveryCodes,
+	})
+}
+
+// upisuje odgovor za gresku drugog faktora sa odgovarajucim statusom
+func respondMFAError(c *gin.Context, err error) {
+	if respondThrottled(c, err) {
+		return
+	}
+
+	switch {
+	case errors.Is(err, services.ErrInvalidMFAChallenge), errors.Is(err, services.ErrInvalidMFACode):
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrAccountDisabled), errors.Is(err, services.ErrMFARequiredForRole):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	}
+}
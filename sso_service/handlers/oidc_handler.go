@@ -0,0 +1,218 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"sso_service/models"
+	"sso_service/services"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// OIDCHandler - rukuje OpenID Connect zahtevima (discovery, autorizacija, token, userinfo)
+// i administracijom registrovanih klijenata
+type OIDCHandler struct {
+	oidcService        *services.OIDCService
+	serviceAuthHandler *ServiceAuthHandler
+	appBaseURL         string
+}
+
+// kreira novi OIDCHandler sa OIDC servisom, handlerom za servisne tokene i adresom frontenda
+// na kojoj korisnik odobrava pristup aplikaciji
+func NewOIDCHandler(oidcService *services.OIDCService, serviceAuthHandler *ServiceAuthHandler, appBaseURL string) *OIDCHandler {
+	return &OIDCHandler{
+		oidcService:        oidcService,
+		serviceAuthHandler: serviceAuthHandler,
+		appBaseURL:         strings.TrimSuffix(appBaseURL, "/"),
+	}
+}
+
+// vraca OIDC discovery dokument
+func (h *OIDCHandler) Discovery(c *gin.Context) {
+	c.JSON(http.StatusOK, h.oidcService.Discovery())
+}
+
+// preusmerava korisnika na frontend stranicu za prijavu i odobravanje pristupa
+// parametri zahteva se prosledjuju nepromenjeni
+func (h *OIDCHandler) Authorize(c *gin.Context) {
+	c.Redirect(http.StatusFound, h.appBaseURL+"/oauth/authorize?"+c.Request.URL.RawQuery)
+}
+
+// vraca naziv aplikacije i trazene scope-ove za ekran odobravanja pristupa
+func (h *OIDCHandler) AuthorizeDetails(c *gin.Context) {
+	var req models.AuthorizeRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	details, redirectTo, err := h.oidcService.AuthorizeDetails(req)
+	if err != nil {
+		respondAuthorizeError(c, err)
+		return
+	}
+
+	if redirectTo != "" {
+		c.JSON(http.StatusOK, gin.H{"redirect_to": redirectTo})
+		return
+	}
+
+	c.JSON(http.StatusOK, details)
+}
+
+// cuva odluku prijavljenog korisnika i vraca adresu na koju ga frontend preusmerava nazad ka klijentu
+func (h *OIDCHandler) Consent(c *gin.Context) {
+	userID := c.MustGet("user_id").(primitive.ObjectID)
+
+	var req models.AuthorizeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	redirectTo, err := h.oidcService.Authorize(userID, req)
+	if err != nil {
+		respondAuthorizeError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"redirect_to": redirectTo})
+}
+
+// token endpoint - authorization_code za OIDC klijente, client_credentials za servise
+// servisni tokeni se izdaju samo na internoj mrezi, zahtevi koji su prosli kroz API gateway ih ne dobijaju
+func (h *OIDCHandler) Token(c *gin.Context) {
+	switch c.PostForm("grant_type") {
+	case "client_credentials":
+		if viaGateway(c) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "unsupported_grant_type"})
+			return
+		}
+		h.serviceAuthHandler.IssueToken(c)
+		return
+	case "authorization_code":
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "unsupported_grant_type"})
+		return
+	}
+
+	var req models.CodeExchangeRequest
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid_request"})
+		return
+	}
+
+	if clientID, clientSecret, ok := c.Request.BasicAuth(); ok {
+		req.ClientID, req.ClientSecret = clientID, clientSecret
+	}
+
+	tokens, err := h.oidcService.ExchangeCode(req)
+	if err != nil {
+		respondOAuthError(c, err)
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, tokens)
+}
+
+// vraca podatke o korisniku prema scope-ovima access tokena izdatog OIDC klijentu
+func (h *OIDCHandler) UserInfo(c *gin.Context) {
+	authHeader := c.GetHeader("Authorization")
+	tokenString := strings.TrimPrefix(authHeader, "Bearer ")
+	if authHeader == "" || tokenString == authHeader {
+		c.Header("WWW-Authenticate", `Bearer error="invalid_token"`)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid_token"})
+		return
+	}
+
+	claims, err := h.oidcService.UserInfo(tokenString)
+	if err != nil {
+		c.Header("WWW-Authenticate", `Bearer error="invalid_token"`)
+		respondOAuthError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, claims)
+}
+
+// registruje novog OIDC klijenta - tajna se prikazuje samo u ovom odgovoru
+func (h *OIDCHandler) CreateClient(c *gin.Context) {
+	actorID := c.MustGet("user_id").(primitive.ObjectID)
+
+	var req models.CreateOAuthClientRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	client, secret, err := h.oidcService.CreateClient(actorID, req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	response := gin.H{"client": client}
+	if secret != "" {
+		response["client_secret"] = secret
+	}
+
+	c.JSON(http.StatusCreated, response)
+}
+
+// vraca sve registrovane OIDC klijente
+func (h *OIDCHandler) ListClients(c *gin.Context) {
+	clients, err := h.oidcService.ListClients()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"clients": clients})
+}
+
+// brise OIDC klijenta
+func (h *OIDCHandler) DeleteClient(c *gin.Context) {
+	if err := h.oidcService.DeleteClient(c.Param("clientId")); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Client deleted successfully"})
+}
+
+// odgovara na gresku pri autorizaciji - nepoznat klijent ili redirect_uri se nikada ne preusmerava
+func respondAuthorizeError(c *gin.Context, err error) {
+	if errors.Is(err, services.ErrInvalidOAuthClient) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+}
+
+// odgovara greskom u OAuth2 formatu - invalid_client i invalid_token su 401, ostale 400
+func respondOAuthError(c *gin.Context, err error) {
+	var oauthErr *services.OAuthError
+	if !errors.As(err, &oauthErr) {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "server_error"})
+		return
+	}
+
+	status := http.StatusBadRequest
+	if oauthErr.Code == "invalid_client" || oauthErr.Code == "invalid_token" {
+		status = http.StatusUnauthorized
+	}
+
+	c.JSON(status, gin.H{
+		"error":             oauthErr.Code,
+		"error_description": oauthErr.Description,
+	})
+}
+
+// proverava da li je zahtev prosao kroz API gateway - gateway uvek postavlja X-Real-IP i X-Forwarded-For,
+// a servisi na internoj mrezi zovu SSO direktno bez tih zaglavlja
+func viaGateway(c *gin.Context) bool {
+	return c.GetHeader("X-Real-IP") != "" || c.GetHeader("X-Forwarded-For") != ""
+}
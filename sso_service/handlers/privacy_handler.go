@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"sso_service/services"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// PrivacyHandler - rukuje izvozom licnih podataka i brisanjem naloga
+type PrivacyHandler struct {
+	privacyService *services.PrivacyService
+}
+
+// kreira novi PrivacyHandler sa servisom za licne podatke
+func NewPrivacyHandler(privacyService *services.PrivacyService) *PrivacyHandler {
+	return &PrivacyHandler{
+		privacyService: privacyService,
+	}
+}
+
+// preuzimanje svih podataka o korisniku iz SSO i st_dom servisa kao ZIP arhive
+func (h *PrivacyHandler) ExportPersonalData(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in context"})
+		return
+	}
+
+	archive, err := h.privacyService.ExportPersonalData(userID.(primitive.ObjectID))
+	if err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+		return
+	}
+
+	filename := fmt.Sprintf("personal-data-%s.zip", time.Now().Format("2006-01-02"))
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Header("Cache-Control", "no-store")
+	c.Data(http.StatusOK, "application/zip", archive)
+}
+
+// brise nalog korisnika i anonimizuje njegove podatke u st_dom servisu
+// ne dozvoljava brisanje dok korisnik ima dodeljenu sobu u domu ili neplacene uplate
+func (h *PrivacyHandler) DeleteAccount(c *gin.Context) {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in context"})
+		return
+	}
+
+	err := h.privacyService.EraseAccount(userID.(primitive.ObjectID))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Account deleted successfully",
+	})
+}
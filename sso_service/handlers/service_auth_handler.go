@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"sso_service/models"
+	"sso_service/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ServiceAuthHandler - izdaje servisne tokene za pozive izmedju servisa
+type ServiceAuthHandler struct {
+	serviceAuth *services.ServiceAuthService
+}
+
+// kreira novi ServiceAuthHandler sa servisom za servisne tokene
+func NewServiceAuthHandler(serviceAuth *services.ServiceAuthService) *ServiceAuthHandler {
+	return &ServiceAuthHandler{
+		serviceAuth: serviceAuth,
+	}
+}
+
+// izdaje token servisu (OAuth2 client credentials) - kredencijali se salju u telu ili kroz HTTP Basic zaglavlje
+func (h *ServiceAuthHandler) IssueToken(c *gin.Context) {
+	var req models.ServiceTokenRequest
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid_request"})
+		return
+	}
+
+	if req.GrantType != "client_credentials" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "unsupported_grant_type"})
+		return
+	}
+
+	if clientID, clientSecret, ok := c.Request.BasicAuth(); ok {
+		req.ClientID, req.ClientSecret = clientID, clientSecret
+	}
+
+	token, err := h.serviceAuth.IssueToken(req.ClientID, req.ClientSecret)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidClient) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid_client"})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, token)
+}
@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"sso_service/models"
+	"sso_service/services"
+	"sso_service/utils"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// StudentHandler - rukuje zahtevima za podatke o studiranju (broj indeksa, fakultet, JMBG...)
+type StudentHandler struct {
+	studentService *services.StudentService
+}
+
+// kreira novi StudentHandler sa servisom za podatke o studiranju
+func NewStudentHandler(studentService *services.StudentService) *StudentHandler {
+	return &StudentHandler{
+		studentService: studentService,
+	}
+}
+
+// cuva podatke o studiranju prijavljenog korisnika - izmena ponistava raniju potvrdu administratora
+func (h *StudentHandler) UpdateStudentProfile(c *gin.Context) {
+	userID := c.MustGet("user_id").(primitive.ObjectID)
+
+	var req models.UpdateStudentProfileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, err := h.studentService.UpdateStudentProfile(userID, req)
+	if err != nil {
+		respondStudentError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Student profile updated successfully",
+		"user":    user,
+	})
+}
+
+// vraca podatke o studiranju sa desifrovanim JMBG-om - administrator ih koristi pri potvrdi
+func (h *StudentHandler) GetStudentDetails(c *gin.Context) {
+	userID, ok := parseUserID(c)
+	if !ok {
+		return
+	}
+
+	profile, jmbg, err := h.studentService.GetStudentDetails(userID)
+	if err != nil {
+		respondStudentError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"student": profile,
+		"jmbg":    jmbg,
+	})
+}
+
+// potvrdjuje podatke o studiranju korisnika
+func (h *StudentHandler) VerifyStudent(c *gin.Context) {
+	h.setVerified(c, true)
+}
+
+// ponistava potvrdu podataka o studiranju
+func (h *StudentHandler) UnverifyStudent(c *gin.Context) {
+	h.setVerified(c, false)
+}
+
+// vraca podatke o studentu bez JMBG-a - za komunikaciju izmedju servisa
+// st_dom_service ih koristi pri kreiranju aplikacija za sobe
+func (h *StudentHandler) GetStudentIdentity(c *gin.Context) {
+	userID, err := primitive.ObjectIDFromHex(c.Param("userId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID format"})
+		return
+	}
+
+	identity, err := h.studentService.GetStudentIdentity(userID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, identity)
+}
+
+// zajednicka logika za potvrdu i ponistavanje potvrde
+func (h *StudentHandler) setVerified(c *gin.Context, verified bool) {
+	userID, ok := parseUserID(c)
+	if !ok {
+		return
+	}
+
+	user, err := h.studentService.SetVerified(c.MustGet("user_id").(primitive.ObjectID), userID, verified)
+	if err != nil {
+		respondStudentError(c, err)
+		return
+	}
+
+	message := "Student profile verification removed"
+	if verified {
+		message = "Student profile verified successfully"
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": message,
+		"user":    user,
+	})
+}
+
+// upisuje odgovarajuci status za greske servisa za podatke o studiranju
+func respondStudentError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrStudentProfileNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrIndexNumberTaken):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, utils.ErrEncryptionDisabled):
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "JMBG cannot be stored at the moment"})
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	}
+}
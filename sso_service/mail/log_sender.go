@@ -0,0 +1,44 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+// LogSender - ne salje poruke vec ih zapisuje u log ili fajl
+// koristi se u razvoju da bi se linkovi za verifikaciju i reset lozinke mogli procitati lokalno
+type LogSender struct {
+	path string
+	mu   sync.Mutex
+}
+
+// kreira novi LogSender - ako je path prazan, poruke se ispisuju u standardni log
+func NewLogSender(path string) *LogSender {
+	return &LogSender{path: path}
+}
+
+// zapisuje poruku u log ili je dodaje na kraj fajla
+func (s *LogSender) Send(ctx context.Context, message Message) error {
+	entry := fmt.Sprintf("[%s] To: %s\nSubject: %s\n\n%s\n\n", time.Now().Format(time.RFC3339), message.To, message.Subject, message.Body)
+
+	if s.path == "" {
+		log.Print("Outgoing email:\n" + entry)
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	file, err := os.OpenFile(s.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	_, err = file.WriteString(entry)
+	return err
+}
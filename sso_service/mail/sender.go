@@ -0,0 +1,16 @@
+package mail
+
+import "context"
+
+// Message - email poruka koju salje SSO servis
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Sender - nacin slanja email poruka
+// u produkciji se koristi SMTP, a u razvoju poruke se samo zapisuju u log ili fajl
+type Sender interface {
+	Send(ctx context.Context, message Message) error
+}
@@ -0,0 +1,47 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+	"net/smtp"
+	"strings"
+)
+
+// SMTPConfig - podesavanja SMTP servera
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+}
+
+// SMTPSender - salje poruke preko SMTP servera
+type SMTPSender struct {
+	config SMTPConfig
+}
+
+// kreira novi SMTPSender sa prosledjenim podesavanjima
+func NewSMTPSender(config SMTPConfig) *SMTPSender {
+	return &SMTPSender{config: config}
+}
+
+// salje poruku kao obican tekst (UTF-8)
+func (s *SMTPSender) Send(ctx context.Context, message Message) error {
+	var body strings.Builder
+	fmt.Fprintf(&body, "From: %s\r\n", s.config.From)
+	fmt.Fprintf(&body, "To: %s\r\n", message.To)
+	fmt.Fprintf(&body, "Subject: %s\r\n", message.Subject)
+	body.WriteString("MIME-Version: 1.0\r\n")
+	body.WriteString("Content-Type: text/plain; charset=\"UTF-8\"\r\n")
+	body.WriteString("\r\n")
+	body.WriteString(message.Body)
+
+	var auth smtp.Auth
+	if s.config.Username != "" {
+		auth = smtp.PlainAuth("", s.config.Username, s.config.Password, s.config.Host)
+	}
+
+	addr := fmt.Sprintf("%s:%d", s.config.Host, s.config.Port)
+	return smtp.SendMail(addr, auth, s.config.From, []string{message.To}, []byte(body.String()))
+}
@@ -5,8 +5,10 @@ import (
 	"sso_service/config"
 	"sso_service/database"
 	"sso_service/handlers"
+	"sso_service/mail"
 	"sso_service/routes"
 	"sso_service/services"
+	"sso_service/utils"
 
 	"github.com/gin-gonic/gin"
 )
@@ -29,14 +31,140 @@ func main() {
 	}()
 
 	usersCollection := db.GetCollection("users")
+	refreshTokensCollection := db.GetCollection("refresh_tokens")
+	revokedTokensCollection := db.GetCollection("revoked_tokens")
+	accountTokensCollection := db.GetCollection("account_tokens")
+	mfaChallengesCollection := db.GetCollection("mfa_challenges")
+	loginAttemptsCollection := db.GetCollection("login_attempts")
 
-	userService := services.NewUserService(usersCollection, cfg.JWTSecret, cfg.StDomServiceURL)
+	keySet, err := utils.LoadKeySet(cfg.JWTKeysDir, cfg.JWTActiveKeyID, cfg.JWTKeyAlgorithm)
+	if err != nil {
+		log.Fatal("Failed to load JWT signing keys:", err)
+	}
+	log.Printf("Signing tokens with key %s (%s)", keySet.Active().ID, keySet.Active().Algorithm)
 
-	authHandler := handlers.NewAuthHandler(userService)
+	tokenService := services.NewTokenService(refreshTokensCollection, revokedTokensCollection, keySet, cfg.AccessTokenTTL, cfg.RefreshTokenTTL)
+	if err := tokenService.EnsureIndexes(); err != nil {
+		log.Println("Failed to create token indexes:", err)
+	}
+
+	serviceAuthService := services.NewServiceAuthService(keySet, cfg.ServiceClients, cfg.ServiceClientID, cfg.ServiceTokenTTL)
+	if len(cfg.ServiceClients) == 0 {
+		log.Fatal("SERVICE_CLIENTS is not set, other services cannot call internal endpoints")
+	}
+
+	loginAttemptService := services.NewLoginAttemptService(loginAttemptsCollection, services.LoginAttemptConfig{
+		MaxAccountFailures: cfg.LoginMaxAccountFailures,
+		MaxIPFailures:      cfg.LoginMaxIPFailures,
+		FailureWindow:      cfg.LoginFailureWindow,
+		LockoutDuration:    cfg.LoginLockoutDuration,
+		Retention:          cfg.LoginAuditRetention,
+	})
+	if err := loginAttemptService.EnsureIndexes(); err != nil {
+		log.Println("Failed to create login attempt indexes:", err)
+	}
+
+	mfaService := services.NewMFAService(mfaChallengesCollection, usersCollection, tokenService, loginAttemptService, cfg.MFAIssuer, cfg.MFAChallengeTTL)
+	if err := mfaService.EnsureIndexes(); err != nil {
+		log.Println("Failed to create MFA challenge indexes:", err)
+	}
+
+	userService := services.NewUserService(usersCollection, tokenService, mfaService, loginAttemptService, cfg.RequireEmailVerification, cfg.LocalLoginAdminsOnly)
+	if err := userService.MarkLegacyUsersVerified(); err != nil {
+		log.Println("Failed to mark existing users as verified:", err)
+	}
+	if err := userService.MarkLegacySuperAdmins(); err != nil {
+		log.Println("Failed to mark existing global administrators:", err)
+	}
+
+	studentDataKey, err := utils.ParseEncryptionKey(cfg.StudentDataKey)
+	if err != nil {
+		log.Fatal("Invalid STUDENT_DATA_KEY:", err)
+	}
+	if studentDataKey == nil {
+		log.Println("STUDENT_DATA_KEY is not set, JMBG cannot be stored")
+	}
+
+	studentService := services.NewStudentService(usersCollection, studentDataKey)
+	if err := studentService.EnsureIndexes(); err != nil {
+		log.Println("Failed to create student profile indexes:", err)
+	}
+
+	var mailSender mail.Sender
+	if cfg.MailDriver == "smtp" {
+		mailSender = mail.NewSMTPSender(mail.SMTPConfig{
+			Host:     cfg.SMTPHost,
+			Port:     cfg.SMTPPort,
+			Username: cfg.SMTPUsername,
+			Password: cfg.SMTPPassword,
+			From:     cfg.SMTPFrom,
+		})
+	} else {
+		mailSender = mail.NewLogSender(cfg.MailLogFile)
+	}
+
+	oidcService := services.NewOIDCService(db.GetCollection("oauth_clients"), db.GetCollection("authorization_codes"), userService, keySet, cfg.OIDCIssuer, cfg.AccessTokenTTL, cfg.OIDCCodeTTL)
+	if err := oidcService.EnsureIndexes(); err != nil {
+		log.Println("Failed to create OIDC indexes:", err)
+	}
+
+	accountService := services.NewAccountService(accountTokensCollection, userService, tokenService, mailSender, cfg.AppBaseURL, cfg.EmailVerificationTTL, cfg.PasswordResetTTL)
+	if err := accountService.EnsureIndexes(); err != nil {
+		log.Println("Failed to create account token indexes:", err)
+	}
+
+	federationService := services.NewFederationService(db.GetCollection("federation_states"), usersCollection, userService, tokenService, mfaService, loginAttemptService, services.FederationConfig{
+		Issuer:       cfg.FederationIssuer,
+		ClientID:     cfg.FederationClientID,
+		ClientSecret: cfg.FederationClientSecret,
+		RedirectURL:  cfg.FederationRedirectURL,
+		Scopes:       cfg.FederationScopes,
+		IndexClaim:   cfg.FederationIndexClaim,
+		FacultyClaim: cfg.FederationFacultyClaim,
+		StateTTL:     cfg.FederationStateTTL,
+		AppBaseURL:   cfg.AppBaseURL,
+	})
+	if err := federationService.EnsureIndexes(); err != nil {
+		log.Println("Failed to create federation indexes:", err)
+	}
+	if cfg.FederationIssuer != "" && cfg.FederationClientSecret == "" {
+		log.Fatal("FEDERATION_CLIENT_SECRET is not set")
+	}
+	if federationService.Enabled() {
+		log.Printf("Federated login enabled with identity provider %s", cfg.FederationIssuer)
+	}
+
+	apiKeyService := services.NewAPIKeyService(db.GetCollection("api_keys"), userService, serviceAuthService, services.APIKeyConfig{
+		RateLimitPerMinute: cfg.APIKeyRateLimit,
+		DailyQuota:         cfg.APIKeyDailyQuota,
+		MaxKeysPerUser:     cfg.APIKeyMaxPerUser,
+		OpenDataServiceURL: cfg.OpenDataServiceURL,
+	})
+	if err := apiKeyService.EnsureIndexes(); err != nil {
+		log.Println("Failed to create API key indexes:", err)
+	}
+
+	privacyService := services.NewPrivacyService(db.Database, userService, studentService, tokenService, serviceAuthService, cfg.StDomServiceURL)
+
+	authHandler := handlers.NewAuthHandler(userService, tokenService, accountService, loginAttemptService, keySet)
+	adminHandler := handlers.NewAdminHandler(userService, accountService, mfaService, loginAttemptService)
+	mfaHandler := handlers.NewMFAHandler(mfaService)
+	studentHandler := handlers.NewStudentHandler(studentService)
+	serviceAuthHandler := handlers.NewServiceAuthHandler(serviceAuthService)
+	oidcHandler := handlers.NewOIDCHandler(oidcService, serviceAuthHandler, cfg.AppBaseURL)
+	federationHandler := handlers.NewFederationHandler(federationService, cfg.FederationName)
+	privacyHandler := handlers.NewPrivacyHandler(privacyService)
+	apiKeyHandler := handlers.NewAPIKeyHandler(apiKeyService)
 
 	router := gin.Default()
+	// nginx postavlja X-Real-IP na adresu klijenta, a na X-Forwarded-For samo dodaje adresu,
+	// pa bi klijent lazirao IP i zaobisao zakljucavanje prijave po IP adresi
+	router.RemoteIPHeaders = []string{"X-Real-IP"}
+	if err := router.SetTrustedProxies(cfg.TrustedProxies); err != nil {
+		log.Fatal("Invalid TRUSTED_PROXIES:", err)
+	}
 
-	routes.SetupRoutes(router, authHandler, cfg.JWTSecret)
+	routes.SetupRoutes(router, authHandler, adminHandler, mfaHandler, studentHandler, oidcHandler, federationHandler, privacyHandler, apiKeyHandler, keySet, tokenService, cfg.AppBaseURL)
 
 	log.Printf("Server starting on port %s", cfg.Port)
 	if err := router.Run(":" + cfg.Port); err != nil {
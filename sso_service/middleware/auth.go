@@ -8,9 +8,14 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
+// RevocationChecker - proverava da li je access token (jti) opozvan
+type RevocationChecker interface {
+	IsAccessTokenRevoked(jti string) (bool, error)
+}
+
 // middleware za validaciju JWT tokena - proverava Authorization header
-// izvlaci token, validira ga i postavlja korisnicke podatke u kontekst
-func AuthMiddleware(jwtSecret string) gin.HandlerFunc {
+// izvlaci token, validira ga, odbija opozvane tokene i postavlja korisnicke podatke u kontekst
+func AuthMiddleware(keys *utils.KeySet, revocations RevocationChecker) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
@@ -27,17 +32,33 @@ func AuthMiddleware(jwtSecret string) gin.HandlerFunc {
 
 		token := strings.TrimPrefix(authHeader, "Bearer ")
 
-		claims, err := utils.ValidateJWT(token, jwtSecret)
+		claims, err := utils.ValidateJWT(token, keys)
 		if err != nil {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
 			c.Abort()
 			return
 		}
 
+		revoked, err := revocations.IsAccessTokenRevoked(claims.ID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify token"})
+			c.Abort()
+			return
+		}
+
+		if revoked {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Token has been revoked"})
+			c.Abort()
+			return
+		}
+
+		c.Set("claims", claims)
 		c.Set("user_id", claims.UserID)
 		c.Set("username", claims.Username)
 		c.Set("email", claims.Email)
 		c.Set("role", claims.Role)
+		c.Set("st_dom_ids", claims.StDomIDs)
+		c.Set("super_admin", claims.SuperAdmin)
 
 		c.Next()
 	}
@@ -64,3 +85,44 @@ func RoleMiddleware(requiredRole string) gin.HandlerFunc {
 	}
 }
 
+// middleware za proveru globalnog administratora - administrator kome je eksplicitno dodeljen super_admin
+// upravnici pojedinacnih domova i administratori bez domova ne mogu da upravljaju korisnicima
+func GlobalAdminMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userRole, _ := c.Get("role")
+		superAdmin, _ := c.Get("super_admin")
+
+		if userRole != "admin" || superAdmin != true {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Global administrator access required"})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+
+// middleware za interne rute - prihvata samo servisne tokene izdate registrovanim servisima
+// ID servisa koji poziva postavlja se u kontekst kao "service_client"
+func ServiceAuthMiddleware(keys *utils.KeySet) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		authHeader := c.GetHeader("Authorization")
+		if !strings.HasPrefix(authHeader, "Bearer ") {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Service token required"})
+			c.Abort()
+			return
+		}
+
+		claims, err := utils.ValidateServiceJWT(strings.TrimPrefix(authHeader, "Bearer "), keys)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid service token"})
+			c.Abort()
+			return
+		}
+
+		c.Set("service_client", claims.Subject)
+
+		c.Next()
+	}
+}
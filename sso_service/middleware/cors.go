@@ -1,14 +1,23 @@
 package middleware
 
 import (
+	"strings"
+
 	"github.com/gin-gonic/gin"
 )
 
 // middleware za rukovanje CORS zaglavljima - omogucava cross-origin zahteve
 // postavlja potrebna zaglavlja za komunikaciju sa frontend aplikacijom
-func CORSMiddleware() gin.HandlerFunc {
+// samo frontend (appOrigin) moze slati zahteve sa kolacicima, ostali sajtovi dobijaju "*" bez kolacica
+func CORSMiddleware(appOrigin string) gin.HandlerFunc {
+	appOrigin = strings.TrimSuffix(appOrigin, "/")
 	return gin.HandlerFunc(func(c *gin.Context) {
-		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
+		c.Writer.Header().Add("Vary", "Origin")
+		if origin := c.GetHeader("Origin"); origin != "" && origin == appOrigin {
+			c.Writer.Header().Set("Access-Control-Allow-Origin", origin)
+		} else {
+			c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
+		}
 		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
 		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With")
 		c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, PATCH, DELETE")
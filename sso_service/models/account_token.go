@@ -0,0 +1,45 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// AccountTokenPurpose - namena jednokratnog tokena poslatog email-om
+type AccountTokenPurpose string
+
+const (
+	AccountTokenEmailVerification AccountTokenPurpose = "email_verification"
+	AccountTokenPasswordReset     AccountTokenPurpose = "password_reset"
+	AccountTokenEmailChange       AccountTokenPurpose = "email_change"
+)
+
+// AccountToken - jednokratni token za verifikaciju email-a, promenu email-a ili reset lozinke
+// cuva se samo hes tokena; token vazi do ExpiresAt i samo jednom (UsedAt)
+type AccountToken struct {
+	ID        primitive.ObjectID  `bson:"_id,omitempty" json:"id,omitempty"`
+	UserID    primitive.ObjectID  `bson:"user_id" json:"user_id"`
+	Purpose   AccountTokenPurpose `bson:"purpose" json:"purpose"`
+	Email     string              `bson:"email" json:"email"` // adresa na koju je token poslat
+	TokenHash string              `bson:"token_hash" json:"-"`
+	ExpiresAt time.Time           `bson:"expires_at" json:"expires_at"`
+	UsedAt    *time.Time          `bson:"used_at,omitempty" json:"used_at,omitempty"`
+	CreatedAt time.Time           `bson:"created_at" json:"created_at"`
+}
+
+// VerifyEmailRequest - zahtev za potvrdu email adrese
+type VerifyEmailRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// EmailRequest - zahtev koji sadrzi samo email (ponovno slanje verifikacije, zaboravljena lozinka)
+type EmailRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// ResetPasswordRequest - zahtev za postavljanje nove lozinke pomocu tokena iz email-a
+type ResetPasswordRequest struct {
+	Token    string `json:"token" binding:"required"`
+	Password string `json:"password" binding:"required,min=6"`
+}
@@ -0,0 +1,45 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// APIKey - kljuc kojim korisnik pristupa API-ju otvorenih podataka van limita za anonimne korisnike
+// cuva se samo hes kljuca; Prefix je pocetak kljuca po kome ga korisnik prepoznaje u listi
+type APIKey struct {
+	ID                 primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
+	UserID             primitive.ObjectID `bson:"user_id" json:"user_id"`
+	Name               string             `bson:"name" json:"name"`
+	Prefix             string             `bson:"prefix" json:"prefix"`
+	KeyHash            string             `bson:"key_hash" json:"-"`
+	RateLimitPerMinute int                `bson:"rate_limit_per_minute" json:"rate_limit_per_minute"`
+	DailyQuota         int                `bson:"daily_quota" json:"daily_quota"`
+	RevokedAt          *time.Time         `bson:"revoked_at,omitempty" json:"revoked_at,omitempty"`
+	CreatedAt          time.Time          `bson:"created_at" json:"created_at"`
+}
+
+// CreateAPIKeyRequest - zahtev za novi API kljuc
+type CreateAPIKeyRequest struct {
+	Name string `json:"name" binding:"required,max=100"`
+}
+
+// CreatedAPIKey - novi API kljuc; sam kljuc se prikazuje samo jednom, pri kreiranju
+type CreatedAPIKey struct {
+	APIKey
+	Key string `json:"key"`
+}
+
+// VerifyAPIKeyRequest - open data servis proverava kljuc poslat uz zahtev
+type VerifyAPIKeyRequest struct {
+	Key string `json:"key" binding:"required"`
+}
+
+// APIKeyVerification - vazeci kljuc sa limitima koje open data servis primenjuje
+type APIKeyVerification struct {
+	KeyID              primitive.ObjectID `json:"key_id"`
+	UserID             primitive.ObjectID `json:"user_id"`
+	RateLimitPerMinute int                `json:"rate_limit_per_minute"`
+	DailyQuota         int                `json:"daily_quota"`
+}
@@ -0,0 +1,50 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// FederatedIdentity - nalog kod spoljnog provajdera identiteta (univerzitetski nalog) povezan sa lokalnim korisnikom
+type FederatedIdentity struct {
+	Issuer   string    `bson:"issuer" json:"issuer"`
+	Subject  string    `bson:"subject" json:"subject"`
+	LinkedAt time.Time `bson:"linked_at" json:"linked_at"`
+}
+
+// FederationState - prijava preko spoljnog provajdera koja je u toku
+// pre povratka sa provajdera cuva state, nonce i PKCE verifier, a posle njega jednokratni tiket
+// kojim frontend preuzima tokene; cuvaju se samo hesevi state-a i tiketa
+// BindingHash vezuje prijavu za browser koji ju je zapoceo (kolacic) - povratni link i tiket ne vaze u drugom browseru
+type FederationState struct {
+	ID           primitive.ObjectID  `bson:"_id,omitempty"`
+	StateHash    string              `bson:"state_hash"`
+	BindingHash  string              `bson:"binding_hash"`
+	Nonce        string              `bson:"nonce"`
+	CodeVerifier string              `bson:"code_verifier"`
+	ReturnTo     string              `bson:"return_to,omitempty"`
+	TicketHash   string              `bson:"ticket_hash,omitempty"`
+	UserID       *primitive.ObjectID `bson:"user_id,omitempty"`
+	ExpiresAt    time.Time           `bson:"expires_at"`
+	CreatedAt    time.Time           `bson:"created_at"`
+}
+
+// FederatedLoginRequest - zavrsetak prijave preko spoljnog provajdera tiketom iz povratnog linka
+type FederatedLoginRequest struct {
+	Ticket string `json:"ticket" binding:"required"`
+}
+
+// FederatedClaims - podaci o korisniku koje salje spoljni provajder u ID tokenu
+// broj indeksa i fakultet se citaju iz claim-ova cija su imena podesiva
+type FederatedClaims struct {
+	Issuer            string
+	Subject           string
+	Email             string
+	EmailVerified     bool
+	GivenName         string
+	FamilyName        string
+	PreferredUsername string
+	IndexNumber       string
+	Faculty           string
+}
@@ -0,0 +1,50 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ishodi pokusaja prijave koji se beleze u dnevnik
+const (
+	LoginOutcomeSuccess               = "success"
+	LoginOutcomeMFAChallenge          = "mfa_challenge"
+	LoginOutcomeInvalidCredentials    = "invalid_credentials"
+	LoginOutcomeInvalidMFACode        = "invalid_mfa_code"
+	LoginOutcomeLockedOut             = "locked_out"
+	LoginOutcomeAccountDisabled       = "account_disabled"
+	LoginOutcomeEmailNotVerified      = "email_not_verified"
+	LoginOutcomePasswordResetRequired = "password_reset_required"
+	LoginOutcomeLocalLoginDisabled    = "local_login_disabled"
+)
+
+// LoginAttempt - jedan zapis u dnevniku prijava
+// UserID je prazan ako nalog sa datim email-om ne postoji
+type LoginAttempt struct {
+	ID        primitive.ObjectID  `bson:"_id,omitempty" json:"id,omitempty"`
+	UserID    *primitive.ObjectID `bson:"user_id,omitempty" json:"user_id,omitempty"`
+	Email     string              `bson:"email" json:"email"`
+	IPAddress string              `bson:"ip_address" json:"ip_address"`
+	UserAgent string              `bson:"user_agent" json:"user_agent"`
+	Outcome   string              `bson:"outcome" json:"outcome"`
+	CreatedAt time.Time           `bson:"created_at" json:"created_at"`
+}
+
+// LoginAttemptFilter - filter i stranicenje za pregled dnevnika prijava (administratori)
+type LoginAttemptFilter struct {
+	UserID    *primitive.ObjectID
+	Email     string
+	IPAddress string
+	Outcome   string
+	Page      int
+	Limit     int
+}
+
+// LoginAttemptPage - jedna stranica dnevnika prijava
+type LoginAttemptPage struct {
+	Attempts []LoginAttempt `json:"attempts"`
+	Total    int64          `json:"total"`
+	Page     int            `json:"page"`
+	Limit    int            `json:"limit"`
+}
@@ -0,0 +1,55 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MFAChallenge - izazov koji se izdaje posle ispravne lozinke kada je potreban drugi faktor
+// cuva se samo hes tokena izazova; izazov je kratkotrajan i ima ogranicen broj pokusaja
+type MFAChallenge struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
+	UserID    primitive.ObjectID `bson:"user_id" json:"user_id"`
+	TokenHash string             `bson:"token_hash" json:"-"`
+	// korisnik jos nema TOTP (administrator) - mora ga podesiti pre izdavanja tokena
+	EnrollmentRequired bool      `bson:"enrollment_required" json:"enrollment_required"`
+	Attempts           int       `bson:"attempts" json:"attempts"`
+	ExpiresAt          time.Time `bson:"expires_at" json:"expires_at"`
+	CreatedAt          time.Time `bson:"created_at" json:"created_at"`
+}
+
+// MFAChallengeInfo - podaci o izazovu koji se vracaju klijentu umesto tokena
+type MFAChallengeInfo struct {
+	MFAToken           string `json:"mfa_token"`
+	EnrollmentRequired bool   `json:"enrollment_required"`
+	ExpiresIn          int64  `json:"expires_in"` // trajanje izazova u sekundama
+}
+
+// TOTPSetup - tajna za authenticator aplikaciju i otpauth URI za QR kod
+type TOTPSetup struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"otpauth_url"`
+}
+
+// MFAVerifyRequest - zahtev za zavrsetak prijave TOTP kodom ili kodom za oporavak
+type MFAVerifyRequest struct {
+	MFAToken string `json:"mfa_token" binding:"required"`
+	Code     string `json:"code" binding:"required"`
+}
+
+// MFAEnrollRequest - zahtev za podesavanje TOTP-a u okviru izazova pri prijavi
+type MFAEnrollRequest struct {
+	MFAToken string `json:"mfa_token" binding:"required"`
+}
+
+// TOTPCodeRequest - zahtev koji sadrzi samo TOTP kod
+type TOTPCodeRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// DisableTOTPRequest - zahtev za iskljucivanje TOTP-a, potrebni su lozinka i trenutni kod
+type DisableTOTPRequest struct {
+	Password string `json:"password" binding:"required"`
+	Code     string `json:"code" binding:"required"`
+}
@@ -0,0 +1,89 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// scope-ovi koje podrzava OIDC provajder
+const (
+	ScopeOpenID  = "openid"
+	ScopeProfile = "profile"
+	ScopeEmail   = "email"
+)
+
+// OAuthClient - spoljna aplikacija (npr. menza, biblioteka) koja prijavljuje korisnike preko SSO servisa
+// javni klijenti (SPA, mobilne aplikacije) nemaju tajnu i oslanjaju se samo na PKCE
+type OAuthClient struct {
+	ID           primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
+	ClientID     string             `bson:"client_id" json:"client_id"`
+	Name         string             `bson:"name" json:"name"`
+	SecretHash   string             `bson:"secret_hash,omitempty" json:"-"`
+	Public       bool               `bson:"public" json:"public"`
+	RedirectURIs []string           `bson:"redirect_uris" json:"redirect_uris"`
+	CreatedBy    primitive.ObjectID `bson:"created_by" json:"created_by"`
+	CreatedAt    time.Time          `bson:"created_at" json:"created_at"`
+}
+
+// CreateOAuthClientRequest - zahtev administratora za registraciju OIDC klijenta
+type CreateOAuthClientRequest struct {
+	Name         string   `json:"name" binding:"required,max=100"`
+	RedirectURIs []string `json:"redirect_uris" binding:"required,min=1,dive,url"`
+	Public       bool     `json:"public"`
+}
+
+// AuthorizationCode - jednokratni kod koji klijent menja za tokene na /oauth/token
+// cuva se samo hes koda, zajedno sa PKCE izazovom i parametrima zahteva
+type AuthorizationCode struct {
+	ID            primitive.ObjectID `bson:"_id,omitempty"`
+	CodeHash      string             `bson:"code_hash"`
+	ClientID      string             `bson:"client_id"`
+	UserID        primitive.ObjectID `bson:"user_id"`
+	RedirectURI   string             `bson:"redirect_uri"`
+	Scope         string             `bson:"scope"`
+	Nonce         string             `bson:"nonce,omitempty"`
+	CodeChallenge string             `bson:"code_challenge"`
+	ExpiresAt     time.Time          `bson:"expires_at"`
+	UsedAt        *time.Time         `bson:"used_at,omitempty"`
+	CreatedAt     time.Time          `bson:"created_at"`
+}
+
+// AuthorizeRequest - parametri zahteva za autorizaciju (authorization code + PKCE)
+// frontend ih prosledjuje iz URL-a na koji je korisnik preusmeren
+type AuthorizeRequest struct {
+	ResponseType        string `form:"response_type" json:"response_type"`
+	ClientID            string `form:"client_id" json:"client_id"`
+	RedirectURI         string `form:"redirect_uri" json:"redirect_uri"`
+	Scope               string `form:"scope" json:"scope"`
+	State               string `form:"state" json:"state"`
+	Nonce               string `form:"nonce" json:"nonce"`
+	CodeChallenge       string `form:"code_challenge" json:"code_challenge"`
+	CodeChallengeMethod string `form:"code_challenge_method" json:"code_challenge_method"`
+	// korisnik je odobrio ili odbio pristup aplikaciji
+	Approve bool `form:"-" json:"approve"`
+}
+
+// AuthorizeDetails - podaci koje frontend prikazuje korisniku pre odobravanja pristupa
+type AuthorizeDetails struct {
+	ClientName string   `json:"client_name"`
+	Scopes     []string `json:"scopes"`
+}
+
+// CodeExchangeRequest - zamena autorizacionog koda za tokene
+type CodeExchangeRequest struct {
+	Code         string `form:"code"`
+	RedirectURI  string `form:"redirect_uri"`
+	CodeVerifier string `form:"code_verifier"`
+	ClientID     string `form:"client_id"`
+	ClientSecret string `form:"client_secret"`
+}
+
+// OIDCTokenResponse - odgovor token endpointa za authorization code
+type OIDCTokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int64  `json:"expires_in"`
+	IDToken     string `json:"id_token"`
+	Scope       string `json:"scope"`
+}
@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// PersonalDataExport - podaci koje SSO servis cuva o korisniku, deo arhive koju korisnik preuzima
+// tajne (lozinka, TOTP, hesevi tokena) se ne izvoze, a JMBG se izvozi desifrovan
+type PersonalDataExport struct {
+	GeneratedAt   time.Time      `json:"generated_at"`
+	User          *User          `json:"user"`
+	JMBG          string         `json:"jmbg,omitempty"`
+	Sessions      []RefreshToken `json:"sessions"`
+	LoginAttempts []LoginAttempt `json:"login_attempts"`
+	AccountTokens []AccountToken `json:"account_tokens"`
+	APIKeys       []APIKey       `json:"api_keys"`
+}
@@ -0,0 +1,16 @@
+package models
+
+// ServiceTokenRequest - zahtev servisa za token (OAuth2 client credentials)
+// client_id i client_secret mogu stici i kroz HTTP Basic zaglavlje
+type ServiceTokenRequest struct {
+	GrantType    string `form:"grant_type" json:"grant_type" binding:"required"`
+	ClientID     string `form:"client_id" json:"client_id"`
+	ClientSecret string `form:"client_secret" json:"client_secret"`
+}
+
+// ServiceToken - odgovor sa servisnim tokenom u OAuth2 formatu
+type ServiceToken struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int64  `json:"expires_in"` // trajanje tokena u sekundama
+}
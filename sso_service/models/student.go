@@ -0,0 +1,49 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// pol studenta - koristi se pri rasporedjivanju u sobe
+const (
+	GenderMale   = "male"
+	GenderFemale = "female"
+)
+
+// StudentProfile - podaci o studiranju koje st_dom_service preuzima pri apliciranju za sobu
+// podatke unosi student, a administrator ih potvrdjuje; svaka izmena ponistava potvrdu
+type StudentProfile struct {
+	IndexNumber  string `bson:"index_number" json:"index_number"`
+	Faculty      string `bson:"faculty" json:"faculty"`
+	StudyProgram string `bson:"study_program" json:"study_program"`
+	YearOfStudy  int    `bson:"year_of_study" json:"year_of_study"`
+	Gender       string `bson:"gender" json:"gender"`
+	// JMBG je opcion i cuva se iskljucivo sifrovan; u odgovorima se vidi samo da li je unet
+	JMBGEncrypted string              `bson:"jmbg_encrypted,omitempty" json:"-"`
+	HasJMBG       bool                `bson:"has_jmbg" json:"has_jmbg"`
+	Verified      bool                `bson:"verified" json:"verified"`
+	VerifiedAt    *time.Time          `bson:"verified_at,omitempty" json:"verified_at,omitempty"`
+	VerifiedBy    *primitive.ObjectID `bson:"verified_by,omitempty" json:"verified_by,omitempty"`
+	UpdatedAt     time.Time           `bson:"updated_at" json:"updated_at"`
+}
+
+// UpdateStudentProfileRequest - zahtev studenta za unos ili izmenu podataka o studiranju
+// JMBG koji nije poslat ostaje isti, a prazan string ga brise
+type UpdateStudentProfileRequest struct {
+	IndexNumber  string  `json:"index_number" binding:"required,max=32"`
+	Faculty      string  `json:"faculty" binding:"required,max=200"`
+	StudyProgram string  `json:"study_program" binding:"required,max=200"`
+	YearOfStudy  int     `json:"year_of_study" binding:"required,min=1,max=8"`
+	Gender       string  `json:"gender" binding:"required,oneof=male female"`
+	JMBG         *string `json:"jmbg"`
+}
+
+// StudentIdentity - podaci o studentu koje dobijaju ostali servisi (bez JMBG-a)
+type StudentIdentity struct {
+	UserID    primitive.ObjectID `json:"user_id"`
+	FirstName string             `json:"first_name"`
+	LastName  string             `json:"last_name"`
+	Student   StudentProfile     `json:"student"`
+}
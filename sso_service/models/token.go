@@ -0,0 +1,51 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// RefreshToken - jedan refresh token u lancu rotacije jedne sesije
+// cuva se samo hes tokena; svi tokeni jedne prijave dele isti SessionID
+type RefreshToken struct {
+	ID              primitive.ObjectID  `bson:"_id,omitempty" json:"id,omitempty"`
+	UserID          primitive.ObjectID  `bson:"user_id" json:"user_id"`
+	SessionID       string              `bson:"session_id" json:"session_id"`
+	TokenHash       string              `bson:"token_hash" json:"-"`
+	AccessTokenID   string              `bson:"access_token_id" json:"-"` // jti access tokena izdatog uz ovaj refresh token
+	AccessExpiresAt time.Time           `bson:"access_expires_at" json:"-"`
+	IPAddress       string              `bson:"ip_address,omitempty" json:"ip_address,omitempty"`
+	UserAgent       string              `bson:"user_agent,omitempty" json:"user_agent,omitempty"`
+	ExpiresAt       time.Time           `bson:"expires_at" json:"expires_at"`
+	RevokedAt       *time.Time          `bson:"revoked_at,omitempty" json:"revoked_at,omitempty"`
+	ReplacedBy      *primitive.ObjectID `bson:"replaced_by,omitempty" json:"-"`
+	CreatedAt       time.Time           `bson:"created_at" json:"created_at"`
+}
+
+// RevokedToken - opozvani access token (jti) koji se odbija do isteka
+// zapis se automatski brise iz baze kada token istekne (TTL indeks)
+type RevokedToken struct {
+	JTI       string             `bson:"jti" json:"jti"`
+	UserID    primitive.ObjectID `bson:"user_id" json:"-"`
+	ExpiresAt time.Time          `bson:"expires_at" json:"expires_at"`
+	RevokedAt time.Time          `bson:"revoked_at" json:"-"`
+}
+
+// ClientInfo - podaci o klijentu koji se beleze uz sesiju
+type ClientInfo struct {
+	IPAddress string
+	UserAgent string
+}
+
+// TokenPair - access i refresh token izdati pri prijavi ili osvezavanju
+type TokenPair struct {
+	AccessToken  string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int64  `json:"expires_in"` // trajanje access tokena u sekundama
+}
+
+// RefreshRequest - zahtev za osvezavanje tokena
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
@@ -15,8 +15,44 @@ type User struct {
 	FirstName string             `bson:"first_name" json:"first_name" binding:"required"`
 	LastName  string             `bson:"last_name" json:"last_name" binding:"required"`
 	Role      string             `bson:"role" json:"role"`
-	CreatedAt time.Time          `bson:"created_at" json:"created_at"`
-	UpdatedAt time.Time          `bson:"updated_at" json:"updated_at"`
+	// domovi kojima administrator upravlja - administrator bez domova ne upravlja nijednim domom
+	ManagedStDomIDs []string `bson:"managed_st_dom_ids,omitempty" json:"managed_st_dom_ids,omitempty"`
+	// globalni administrator - pristup svim domovima i upravljanje korisnicima, dodeljuje se samo eksplicitno
+	SuperAdmin bool `bson:"super_admin" json:"super_admin"`
+	// email mora biti potvrdjen pre prve prijave
+	EmailVerified   bool       `bson:"email_verified" json:"email_verified"`
+	EmailVerifiedAt *time.Time `bson:"email_verified_at,omitempty" json:"email_verified_at,omitempty"`
+	// nova adresa koja ceka potvrdu - postaje Email tek kada korisnik otvori link poslat na nju
+	PendingEmail string `bson:"pending_email,omitempty" json:"pending_email,omitempty"`
+	// podaci o studiranju - potrebni za apliciranje za sobu u studentskom domu
+	Student *StudentProfile `bson:"student,omitempty" json:"student,omitempty"`
+	// univerzitetski nalog povezan pri prvoj prijavi preko spoljnog provajdera identiteta
+	Federation *FederatedIdentity `bson:"federation,omitempty" json:"federation,omitempty"`
+	// onemoguceni korisnici ne mogu da se prijave niti da osveze tokene
+	Disabled   bool       `bson:"disabled" json:"disabled"`
+	DisabledAt *time.Time `bson:"disabled_at,omitempty" json:"disabled_at,omitempty"`
+	// administrator je zahtevao promenu lozinke - prijava nije moguca dok se lozinka ne promeni
+	PasswordResetRequired bool `bson:"password_reset_required" json:"password_reset_required"`
+	// dvofaktorska autentifikacija (TOTP) - opciona za korisnike, obavezna za administratore
+	TOTPEnabled       bool       `bson:"totp_enabled" json:"totp_enabled"`
+	TOTPEnabledAt     *time.Time `bson:"totp_enabled_at,omitempty" json:"totp_enabled_at,omitempty"`
+	TOTPSecret        string     `bson:"totp_secret,omitempty" json:"-"`
+	TOTPPendingSecret string     `bson:"totp_pending_secret,omitempty" json:"-"` // tajna dok korisnik ne potvrdi prvi kod
+	TOTPLastStep      int64      `bson:"totp_last_step,omitempty" json:"-"`      // poslednji iskorisceni korak - sprecava ponovnu upotrebu koda
+	RecoveryCodes     []string   `bson:"recovery_codes,omitempty" json:"-"`      // hesevi jednokratnih kodova za oporavak
+	CreatedAt         time.Time  `bson:"created_at" json:"created_at"`
+	UpdatedAt         time.Time  `bson:"updated_at" json:"updated_at"`
+}
+
+// uloge korisnika
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+)
+
+// proverava da li je uloga podrzana
+func IsValidRole(role string) bool {
+	return role == RoleUser || role == RoleAdmin
 }
 
 // RegisterRequest - zahtev za registraciju novog korisnika
@@ -34,14 +70,30 @@ type LoginRequest struct {
 	Password string `json:"password" binding:"required"`
 }
 
-// LoginResponse - odgovor za uspesnu prijavu sa tokenom i podacima o korisniku
+// UpdateProfileRequest - zahtev za izmenu profila; polja koja nisu poslata se ne menjaju
+// promena email-a se primenjuje tek posle potvrde nove adrese
+type UpdateProfileRequest struct {
+	FirstName *string `json:"first_name" binding:"omitempty,min=1"`
+	LastName  *string `json:"last_name" binding:"omitempty,min=1"`
+	Email     *string `json:"email" binding:"omitempty,email"`
+}
+
+// ChangePasswordRequest - zahtev za promenu lozinke, potrebna je trenutna lozinka
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" binding:"required"`
+	NewPassword     string `json:"new_password" binding:"required,min=6"`
+}
+
+// LoginResponse - odgovor za uspesnu prijavu sa tokenima i podacima o korisniku
+// ako je potrebna dvofaktorska autentifikacija, tokeni se ne izdaju vec se vraca MFA izazov
 type LoginResponse struct {
-	Token string `json:"token"`
-	User  User   `json:"user"`
+	TokenPair
+	User User              `json:"user"`
+	MFA  *MFAChallengeInfo `json:"mfa,omitempty"`
 }
 
 // kreira novog korisnika sa default vrednostima na osnovu zahteva za registraciju
-// postavlja ulogu na "user" i trenutno vreme za kreiranje i azuriranje
+// postavlja ulogu na "user", email kao nepotvrdjen i trenutno vreme za kreiranje i azuriranje
 func NewUser(req RegisterRequest, hashedPassword string) User {
 	return User{
 		Username:  req.Username,
@@ -49,9 +101,8 @@ func NewUser(req RegisterRequest, hashedPassword string) User {
 		Password:  hashedPassword,
 		FirstName: req.FirstName,
 		LastName:  req.LastName,
-		Role:      "user",
+		Role:      RoleUser,
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
 	}
 }
-
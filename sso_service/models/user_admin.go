@@ -0,0 +1,34 @@
+package models
+
+// UserFilter - filter i stranicenje za pregled korisnika (administratori)
+type UserFilter struct {
+	Search string // deo korisnickog imena, email-a, imena ili prezimena
+	Role   string
+	Status string // "active" ili "disabled"
+	Page   int
+	Limit  int
+}
+
+// UserPage - jedna stranica rezultata pretrage korisnika
+type UserPage struct {
+	Users []User `json:"users"`
+	Total int64  `json:"total"`
+	Page  int    `json:"page"`
+	Limit int    `json:"limit"`
+}
+
+// UpdateRoleRequest - zahtev za promenu uloge korisnika
+type UpdateRoleRequest struct {
+	Role string `json:"role" binding:"required"`
+}
+
+// UpdateManagedStDomsRequest - zahtev za promenu domova kojima administrator upravlja
+// prazna lista oduzima pristup svim domovima - globalni administrator se dodeljuje posebno
+type UpdateManagedStDomsRequest struct {
+	StDomIDs []string `json:"st_dom_ids"`
+}
+
+// UpdateSuperAdminRequest - zahtev za dodelu ili oduzimanje prava globalnog administratora
+type UpdateSuperAdminRequest struct {
+	SuperAdmin *bool `json:"super_admin" binding:"required"`
+}
@@ -3,30 +3,109 @@ package routes
 import (
 	"sso_service/handlers"
 	"sso_service/middleware"
+	"sso_service/utils"
 
 	"github.com/gin-gonic/gin"
 )
 
 // postavlja sve rute za aplikaciju - javne i zasticene
 // javne rute su za registraciju i prijavu, zasticene zahtevaju JWT token
-func SetupRoutes(r *gin.Engine, authHandler *handlers.AuthHandler, jwtSecret string) {
-	r.Use(middleware.CORSMiddleware())
+func SetupRoutes(r *gin.Engine, authHandler *handlers.AuthHandler, adminHandler *handlers.AdminHandler, mfaHandler *handlers.MFAHandler, studentHandler *handlers.StudentHandler, oidcHandler *handlers.OIDCHandler, federationHandler *handlers.FederationHandler, privacyHandler *handlers.PrivacyHandler, apiKeyHandler *handlers.APIKeyHandler, keys *utils.KeySet, revocations middleware.RevocationChecker, appOrigin string) {
+	r.Use(middleware.CORSMiddleware(appOrigin))
 
 	r.GET("/health", authHandler.Health)
 
+	// javni kljucevi za proveru potpisa tokena u ostalim servisima
+	r.GET("/.well-known/jwks.json", authHandler.JWKS)
+	r.GET("/.well-known/openid-configuration", oidcHandler.Discovery)
+
 	v1 := r.Group("/api/v1")
 	{
 		auth := v1.Group("/auth")
 		{
 			auth.POST("/register", authHandler.Register)
 			auth.POST("/login", authHandler.Login)
+			auth.POST("/refresh", authHandler.Refresh)
+			auth.POST("/verify-email", authHandler.VerifyEmail)
+			auth.POST("/resend-verification", authHandler.ResendVerification)
+			auth.POST("/forgot-password", authHandler.ForgotPassword)
+			auth.POST("/reset-password", authHandler.ResetPassword)
+			auth.POST("/mfa/verify", mfaHandler.VerifyChallenge)
+			auth.POST("/mfa/enroll", mfaHandler.StartEnrollment)
+			auth.GET("/federated", federationHandler.Status)
+			auth.GET("/federated/login", federationHandler.Login)
+			auth.GET("/federated/callback", federationHandler.Callback)
+			auth.POST("/federated/complete", federationHandler.Complete)
+			auth.POST("/logout", middleware.AuthMiddleware(keys, revocations), authHandler.Logout)
+			auth.POST("/logout-all", middleware.AuthMiddleware(keys, revocations), authHandler.LogoutAll)
+		}
+
+		// OpenID Connect provajder za druge aplikacije i servisni tokeni (client credentials)
+		oauth := v1.Group("/oauth")
+		{
+			oauth.GET("/authorize", oidcHandler.Authorize)
+			oauth.GET("/authorize/details", middleware.AuthMiddleware(keys, revocations), oidcHandler.AuthorizeDetails)
+			oauth.POST("/authorize", middleware.AuthMiddleware(keys, revocations), oidcHandler.Consent)
+			oauth.POST("/token", oidcHandler.Token)
+			oauth.GET("/userinfo", oidcHandler.UserInfo)
+			oauth.POST("/userinfo", oidcHandler.UserInfo)
+		}
+
+		// registrovani OIDC klijenti - samo globalni administratori
+		clients := v1.Group("/oauth/clients")
+		clients.Use(middleware.AuthMiddleware(keys, revocations), middleware.GlobalAdminMiddleware())
+		{
+			clients.GET("", oidcHandler.ListClients)
+			clients.POST("", oidcHandler.CreateClient)
+			clients.DELETE("/:clientId", oidcHandler.DeleteClient)
+		}
+
+		// komunikacija izmedju servisa - nije izlozeno preko API gateway-a i zahteva servisni token
+		internal := v1.Group("/internal")
+		internal.Use(middleware.ServiceAuthMiddleware(keys))
+		{
+			internal.GET("/users/:userId/contact", authHandler.GetUserContact)
+			internal.GET("/users/:userId/student", studentHandler.GetStudentIdentity)
+			internal.GET("/revoked-tokens", authHandler.GetRevokedTokens)
+			internal.POST("/api-keys/verify", apiKeyHandler.VerifyKey)
 		}
 
 		protected := v1.Group("/")
-		protected.Use(middleware.AuthMiddleware(jwtSecret))
+		protected.Use(middleware.AuthMiddleware(keys, revocations))
 		{
 			protected.GET("/profile", authHandler.GetProfile)
-			protected.DELETE("/account", authHandler.DeleteAccount)
+			protected.PUT("/profile", authHandler.UpdateProfile)
+			protected.POST("/profile/password", authHandler.ChangePassword)
+			protected.PUT("/profile/student", studentHandler.UpdateStudentProfile)
+			protected.DELETE("/account", privacyHandler.DeleteAccount)
+			protected.GET("/account/export", privacyHandler.ExportPersonalData)
+			protected.POST("/mfa/totp/setup", mfaHandler.SetupTOTP)
+			protected.POST("/mfa/totp/confirm", mfaHandler.ConfirmTOTP)
+			protected.POST("/mfa/totp/disable", mfaHandler.DisableTOTP)
+			protected.POST("/mfa/recovery-codes", mfaHandler.RegenerateRecoveryCodes)
+			protected.GET("/api-keys", apiKeyHandler.ListKeys)
+			protected.POST("/api-keys", apiKeyHandler.CreateKey)
+			protected.DELETE("/api-keys/:id", apiKeyHandler.RevokeKey)
+			protected.GET("/api-keys/:id/usage", apiKeyHandler.GetUsage)
+		}
+
+		// upravljanje korisnicima - samo globalni administratori
+		users := v1.Group("/users")
+		users.Use(middleware.AuthMiddleware(keys, revocations), middleware.GlobalAdminMiddleware())
+		{
+			users.GET("", adminHandler.ListUsers)
+			users.GET("/login-attempts", adminHandler.ListLoginAttempts)
+			users.GET("/:id", adminHandler.GetUser)
+			users.PUT("/:id/role", adminHandler.UpdateRole)
+			users.PUT("/:id/st-doms", adminHandler.UpdateManagedStDoms)
+			users.PUT("/:id/super-admin", adminHandler.UpdateSuperAdmin)
+			users.POST("/:id/disable", adminHandler.DisableUser)
+			users.POST("/:id/enable", adminHandler.EnableUser)
+			users.POST("/:id/force-password-reset", adminHandler.ForcePasswordReset)
+			users.POST("/:id/reset-mfa", adminHandler.ResetMFA)
+			users.GET("/:id/student", studentHandler.GetStudentDetails)
+			users.POST("/:id/student/verify", studentHandler.VerifyStudent)
+			users.POST("/:id/student/unverify", studentHandler.UnverifyStudent)
 		}
 	}
 }
\ No newline at end of file
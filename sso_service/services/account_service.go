@@ -0,0 +1,307 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"sso_service/mail"
+	"sso_service/models"
+	"sso_service/utils"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ErrInvalidAccountToken - token iz email-a ne postoji, istekao je ili je vec iskoriscen
+var ErrInvalidAccountToken = errors.New("invalid or expired token")
+
+// AccountService - verifikacija email adrese i reset zaboravljene lozinke
+// oba toka salju jednokratni token email-om; u bazi se cuva samo hes tokena
+type AccountService struct {
+	collection      *mongo.Collection
+	userService     *UserService
+	tokenService    *TokenService
+	sender          mail.Sender
+	appBaseURL      string
+	verificationTTL time.Duration
+	resetTTL        time.Duration
+}
+
+// kreira novi AccountService sa kolekcijom tokena, servisima za korisnike i tokene i nacinom slanja poruka
+func NewAccountService(collection *mongo.Collection, userService *UserService, tokenService *TokenService, sender mail.Sender, appBaseURL string, verificationTTL, resetTTL time.Duration) *AccountService {
+	return &AccountService{
+		collection:      collection,
+		userService:     userService,
+		tokenService:    tokenService,
+		sender:          sender,
+		appBaseURL:      appBaseURL,
+		verificationTTL: verificationTTL,
+		resetTTL:        resetTTL,
+	}
+}
+
+// kreira indekse - jedinstven hes tokena i TTL indeks koji brise istekle tokene
+func (s *AccountService) EnsureIndexes() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err := s.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "token_hash", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "purpose", Value: 1}}},
+		{Keys: bson.D{{Key: "expires_at", Value: 1}}, Options: options.Index().SetExpireAfterSeconds(0)},
+	})
+	return err
+}
+
+// salje korisniku link za potvrdu email adrese
+// prethodno poslati neiskorisceni linkovi prestaju da vaze
+func (s *AccountService) SendEmailVerification(user *models.User) error {
+	token, err := s.createToken(user.ID, user.Email, models.AccountTokenEmailVerification, s.verificationTTL)
+	if err != nil {
+		return err
+	}
+
+	link := s.link("/verify-email", token)
+	body := fmt.Sprintf("Zdravo %s,\n\nPotvrdite svoju email adresu otvaranjem sledeceg linka:\n\n%s\n\nLink vazi %s. Ako niste kreirali nalog, zanemarite ovu poruku.\n",
+		user.FirstName, link, s.verificationTTL)
+
+	return s.send(user.Email, "Potvrda email adrese", body)
+}
+
+// ponovo salje link za potvrdu email adrese
+// ne otkriva da li nalog postoji - za nepostojeci ili vec potvrdjen nalog ne radi nista
+func (s *AccountService) ResendEmailVerification(email string) error {
+	user, err := s.userService.GetUserByEmail(email)
+	if err != nil || user.EmailVerified {
+		return nil
+	}
+
+	return s.SendEmailVerification(user)
+}
+
+// potvrdjuje email adresu korisnika pomocu tokena iz email-a
+// isti link potvrdjuje i novu adresu posle promene email-a na profilu
+func (s *AccountService) VerifyEmail(token string) error {
+	record, err := s.consumeToken(token, models.AccountTokenEmailVerification, models.AccountTokenEmailChange)
+	if err != nil {
+		return err
+	}
+
+	if record.Purpose == models.AccountTokenEmailChange {
+		return s.userService.ConfirmEmailChange(record.UserID, record.Email)
+	}
+
+	if err := s.userService.MarkEmailVerified(record.UserID, record.Email); err != nil {
+		return ErrInvalidAccountToken
+	}
+
+	return nil
+}
+
+// zapocinje promenu email adrese - nova adresa se cuva kao privremena i salje joj se link za potvrdu
+// stara adresa ostaje aktivna dok se nova ne potvrdi, a na nju stize obavestenje o zahtevu
+func (s *AccountService) RequestEmailChange(userID primitive.ObjectID, email string) error {
+	user, err := s.userService.GetUserByID(userID)
+	if err != nil {
+		return err
+	}
+
+	if email == user.Email {
+		return errors.New("new email address is the same as the current one")
+	}
+
+	if err := s.userService.SetPendingEmail(userID, email); err != nil {
+		return err
+	}
+
+	token, err := s.createToken(userID, email, models.AccountTokenEmailChange, s.verificationTTL)
+	if err != nil {
+		return err
+	}
+
+	link := s.link("/verify-email", token)
+	body := fmt.Sprintf("Zdravo %s,\n\nPotvrdite novu email adresu otvaranjem sledeceg linka:\n\n%s\n\nLink vazi %s. Do potvrde se za prijavu koristi dosadasnja adresa.\n",
+		user.FirstName, link, s.verificationTTL)
+
+	if err := s.send(email, "Potvrda nove email adrese", body); err != nil {
+		return err
+	}
+
+	notice := fmt.Sprintf("Zdravo %s,\n\nZatrazena je promena email adrese vaseg naloga na %s. Ako to niste vi, odmah promenite lozinku.\n",
+		user.FirstName, email)
+	if err := s.send(user.Email, "Promena email adrese", notice); err != nil {
+		log.Println("Failed to send email change notice:", err)
+	}
+
+	return nil
+}
+
+// menja lozinku prijavljenog korisnika - potrebna je trenutna lozinka
+// sve ostale sesije se opozivaju, a trenutna (sessionID) ostaje aktivna
+func (s *AccountService) ChangePassword(userID primitive.ObjectID, sessionID, currentPassword, newPassword string) error {
+	if err := s.userService.VerifyPassword(userID, currentPassword); err != nil {
+		return err
+	}
+
+	if err := s.userService.UpdatePassword(userID, newPassword); err != nil {
+		return err
+	}
+
+	// ranije poslati linkovi za reset ne smeju vratiti staru lozinku u igru
+	if err := s.invalidateTokens(userID, models.AccountTokenPasswordReset); err != nil {
+		return err
+	}
+
+	return s.tokenService.RevokeOtherSessions(userID, sessionID)
+}
+
+// salje link za reset lozinke ako nalog sa email adresom postoji
+// ne otkriva da li nalog postoji - odgovor je uvek isti
+func (s *AccountService) RequestPasswordReset(email string) error {
+	user, err := s.userService.GetUserByEmail(email)
+	if err != nil {
+		return nil
+	}
+
+	return s.sendPasswordReset(user)
+}
+
+// primorava korisnika da promeni lozinku - administratorska akcija
+// opoziva sve sesije, blokira prijavu do promene lozinke i salje link za reset
+func (s *AccountService) ForcePasswordReset(userID primitive.ObjectID) error {
+	user, err := s.userService.GetUserByID(userID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.userService.RequirePasswordReset(userID); err != nil {
+		return err
+	}
+
+	return s.sendPasswordReset(user)
+}
+
+// kreira token za reset lozinke i salje link korisniku
+func (s *AccountService) sendPasswordReset(user *models.User) error {
+	token, err := s.createToken(user.ID, user.Email, models.AccountTokenPasswordReset, s.resetTTL)
+	if err != nil {
+		return err
+	}
+
+	link := s.link("/reset-password", token)
+	body := fmt.Sprintf("Zdravo %s,\n\nPrimili smo zahtev za promenu lozinke. Novu lozinku mozete postaviti na sledecem linku:\n\n%s\n\nLink vazi %s i moze se iskoristiti samo jednom. Ako niste trazili promenu lozinke, zanemarite ovu poruku.\n",
+		user.FirstName, link, s.resetTTL)
+
+	return s.send(user.Email, "Promena lozinke", body)
+}
+
+// postavlja novu lozinku pomocu tokena iz email-a
+// posle promene opozivaju se sve sesije korisnika
+func (s *AccountService) ResetPassword(token, password string) error {
+	record, err := s.consumeToken(token, models.AccountTokenPasswordReset)
+	if err != nil {
+		return err
+	}
+
+	if err := s.userService.UpdatePassword(record.UserID, password); err != nil {
+		return err
+	}
+
+	// pristup poslatom linku potvrdjuje i vlasnistvo nad email adresom
+	if err := s.userService.MarkEmailVerified(record.UserID, record.Email); err != nil {
+		log.Println("Failed to mark email as verified after password reset:", err)
+	}
+
+	if err := s.invalidateTokens(record.UserID, models.AccountTokenPasswordReset); err != nil {
+		return err
+	}
+
+	return s.tokenService.RevokeAllSessions(record.UserID)
+}
+
+// kreira novi jednokratni token za adresu email i ponistava prethodne tokene iste namene
+func (s *AccountService) createToken(userID primitive.ObjectID, email string, purpose models.AccountTokenPurpose, ttl time.Duration) (string, error) {
+	if err := s.invalidateTokens(userID, purpose); err != nil {
+		return "", err
+	}
+
+	token, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return "", err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	now := time.Now()
+	record := models.AccountToken{
+		ID:        primitive.NewObjectID(),
+		UserID:    userID,
+		Purpose:   purpose,
+		Email:     email,
+		TokenHash: utils.HashToken(token),
+		ExpiresAt: now.Add(ttl),
+		CreatedAt: now,
+	}
+
+	if _, err := s.collection.InsertOne(ctx, record); err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
+// iskoriscava token jedne od navedenih namena - atomicno ga oznacava kao iskoriscen ako je vazeci
+func (s *AccountService) consumeToken(token string, purposes ...models.AccountTokenPurpose) (*models.AccountToken, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	now := time.Now()
+	filter := bson.M{
+		"token_hash": utils.HashToken(token),
+		"purpose":    bson.M{"$in": purposes},
+		"used_at":    bson.M{"$exists": false},
+		"expires_at": bson.M{"$gt": now},
+	}
+
+	var record models.AccountToken
+	err := s.collection.FindOneAndUpdate(ctx, filter, bson.M{"$set": bson.M{"used_at": now}}).Decode(&record)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, ErrInvalidAccountToken
+		}
+		return nil, err
+	}
+
+	return &record, nil
+}
+
+// ponistava sve neiskoriscene tokene korisnika za datu namenu
+func (s *AccountService) invalidateTokens(userID primitive.ObjectID, purpose models.AccountTokenPurpose) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err := s.collection.UpdateMany(ctx,
+		bson.M{"user_id": userID, "purpose": purpose, "used_at": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"used_at": time.Now()}},
+	)
+	return err
+}
+
+// pravi link ka frontend stranici sa tokenom kao query parametrom
+func (s *AccountService) link(path, token string) string {
+	return s.appBaseURL + path + "?token=" + url.QueryEscape(token)
+}
+
+// salje poruku sa vremenskim ogranicenjem
+func (s *AccountService) send(to, subject, body string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	return s.sender.Send(ctx, mail.Message{To: to, Subject: subject, Body: body})
+}
@@ -0,0 +1,222 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sso_service/models"
+	"sso_service/utils"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// oznaka na pocetku svakog API kljuca - olaksava prepoznavanje kljuceva koji su procureli u kod ili logove
+const apiKeyPrefix = "od_"
+
+// duzina dela kljuca koji se cuva i prikazuje u listi kljuceva
+const apiKeyDisplayLength = len(apiKeyPrefix) + 8
+
+// greske pri radu sa API kljucevima
+var (
+	ErrAPIKeyNotFound     = errors.New("API key not found")
+	ErrAPIKeyInvalid      = errors.New("invalid or revoked API key")
+	ErrAPIKeyLimitReached = errors.New("maximum number of API keys reached, revoke an unused key first")
+)
+
+// APIKeyConfig - podrazumevani limiti novih kljuceva i adresa open data servisa koji ih primenjuje
+type APIKeyConfig struct {
+	RateLimitPerMinute int
+	DailyQuota         int
+	MaxKeysPerUser     int
+	OpenDataServiceURL string
+}
+
+// APIKeyService - izdaje i opoziva API kljuceve za open data servis i proverava ih na njegov zahtev
+// potrosnju kljuceva meri open data servis, a vlasnik je vidi preko ovog servisa
+type APIKeyService struct {
+	collection  *mongo.Collection
+	userService *UserService
+	serviceAuth *ServiceAuthService
+	config      APIKeyConfig
+	httpClient  *http.Client
+}
+
+// kreira novi APIKeyService sa kolekcijom kljuceva, servisom za korisnike, servisnim tokenima
+// za pozive open data servisa i podesavanjima kljuceva
+func NewAPIKeyService(collection *mongo.Collection, userService *UserService, serviceAuth *ServiceAuthService, config APIKeyConfig) *APIKeyService {
+	return &APIKeyService{
+		collection:  collection,
+		userService: userService,
+		serviceAuth: serviceAuth,
+		config:      config,
+		httpClient:  &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// kreira indekse za pretragu po hesu kljuca i po vlasniku
+func (s *APIKeyService) EnsureIndexes() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err := s.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "key_hash", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "created_at", Value: -1}}},
+	})
+	return err
+}
+
+// kreira novi API kljuc sa podrazumevanim limitima
+// kljuc se vraca samo ovde - u bazi ostaju hes i prefiks za prikaz
+func (s *APIKeyService) CreateKey(userID primitive.ObjectID, name string) (*models.CreatedAPIKey, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	active, err := s.collection.CountDocuments(ctx, bson.M{"user_id": userID, "revoked_at": bson.M{"$exists": false}})
+	if err != nil {
+		return nil, err
+	}
+	if int(active) >= s.config.MaxKeysPerUser {
+		return nil, ErrAPIKeyLimitReached
+	}
+
+	token, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+	key := apiKeyPrefix + token
+
+	apiKey := models.APIKey{
+		ID:                 primitive.NewObjectID(),
+		UserID:             userID,
+		Name:               name,
+		Prefix:             key[:apiKeyDisplayLength],
+		KeyHash:            utils.HashToken(key),
+		RateLimitPerMinute: s.config.RateLimitPerMinute,
+		DailyQuota:         s.config.DailyQuota,
+		CreatedAt:          time.Now(),
+	}
+
+	if _, err := s.collection.InsertOne(ctx, apiKey); err != nil {
+		return nil, err
+	}
+
+	return &models.CreatedAPIKey{APIKey: apiKey, Key: key}, nil
+}
+
+// vraca sve kljuceve korisnika, ukljucujuci opozvane, najnoviji prvi
+func (s *APIKeyService) ListKeys(userID primitive.ObjectID) ([]models.APIKey, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	cursor, err := s.collection.Find(ctx, bson.M{"user_id": userID}, options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	keys := []models.APIKey{}
+	if err := cursor.All(ctx, &keys); err != nil {
+		return nil, err
+	}
+
+	return keys, nil
+}
+
+// opoziva kljuc korisnika - open data servis ga odbija cim istekne njegov kes provera
+func (s *APIKeyService) RevokeKey(userID, keyID primitive.ObjectID) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	result, err := s.collection.UpdateOne(ctx,
+		bson.M{"_id": keyID, "user_id": userID, "revoked_at": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"revoked_at": time.Now()}},
+	)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return ErrAPIKeyNotFound
+	}
+
+	return nil
+}
+
+// proverava kljuc poslat open data servisu i vraca njegove limite
+// kljucevi onemogucenih korisnika se ne prihvataju
+func (s *APIKeyService) VerifyKey(key string) (*models.APIKeyVerification, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var apiKey models.APIKey
+	err := s.collection.FindOne(ctx, bson.M{"key_hash": utils.HashToken(key), "revoked_at": bson.M{"$exists": false}}).Decode(&apiKey)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, ErrAPIKeyInvalid
+		}
+		return nil, err
+	}
+
+	user, err := s.userService.GetUserByID(apiKey.UserID)
+	if err != nil || user.Disabled {
+		return nil, ErrAPIKeyInvalid
+	}
+
+	return &models.APIKeyVerification{
+		KeyID:              apiKey.ID,
+		UserID:             apiKey.UserID,
+		RateLimitPerMinute: apiKey.RateLimitPerMinute,
+		DailyQuota:         apiKey.DailyQuota,
+	}, nil
+}
+
+// vraca dnevnu potrosnju kljuca za poslednjih days dana - podatke cuva open data servis
+func (s *APIKeyService) GetUsage(userID, keyID primitive.ObjectID, days int) (json.RawMessage, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	err := s.collection.FindOne(ctx, bson.M{"_id": keyID, "user_id": userID}).Err()
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, ErrAPIKeyNotFound
+		}
+		return nil, err
+	}
+
+	// interne rute open data servisa prihvataju samo servisni token
+	token, err := s.serviceAuth.SelfToken()
+	if err != nil {
+		return nil, err
+	}
+
+	url := fmt.Sprintf("%s/api/v1/internal/api-keys/%s/usage?days=%d", s.config.OpenDataServiceURL, keyID.Hex(), days)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.New("failed to fetch API key usage")
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	return body, nil
+}
@@ -0,0 +1,600 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"sso_service/models"
+	"sso_service/utils"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// trajanje tiketa kojim frontend preuzima tokene posle povratka sa provajdera
+const federationTicketTTL = 2 * time.Minute
+
+// greske pri prijavi preko spoljnog provajdera identiteta
+var (
+	ErrFederationDisabled      = errors.New("federated login is not configured")
+	ErrFederationStateInvalid  = errors.New("login session expired, please try again")
+	ErrFederationTicketInvalid = errors.New("login link is invalid or expired")
+	ErrFederationNoEmail       = errors.New("identity provider did not return an email address")
+	ErrFederationEmailTaken    = errors.New("an account with this email already exists and cannot be linked automatically")
+	ErrFederationFailed        = errors.New("federated login failed")
+)
+
+// FederationConfig - podesavanja spoljnog OIDC provajdera identiteta (univerzitetski nalozi)
+type FederationConfig struct {
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	// adresa /api/v1/auth/federated/callback kako je vidi browser - mora biti registrovana kod provajdera
+	RedirectURL string
+	Scopes      string
+	// imena claim-ova ID tokena iz kojih se citaju broj indeksa i fakultet
+	IndexClaim   string
+	FacultyClaim string
+	StateTTL     time.Duration
+	// frontend adresa na koju se korisnik vraca posle prijave
+	AppBaseURL string
+}
+
+// podaci iz discovery dokumenta provajdera koji su potrebni za prijavu
+type providerMetadata struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// FederationService - prijava preko spoljnog OIDC provajdera identiteta (authorization code + PKCE)
+// pri prvoj prijavi kreira lokalni nalog ili ga povezuje sa postojecim nalogom iste potvrdjene email adrese
+type FederationService struct {
+	states        *mongo.Collection
+	users         *mongo.Collection
+	userService   *UserService
+	tokenService  *TokenService
+	mfaService    *MFAService
+	loginAttempts *LoginAttemptService
+	config        FederationConfig
+	httpClient    *http.Client
+
+	mu       sync.Mutex
+	provider *providerMetadata
+	jwks     *utils.JWKSCache
+}
+
+// kreira novi FederationService sa kolekcijama prijava u toku i korisnika, servisima za korisnike,
+// tokene, drugi faktor i dnevnik prijava i podesavanjima provajdera
+// discovery dokument provajdera se preuzima pri prvoj prijavi
+func NewFederationService(states, users *mongo.Collection, userService *UserService, tokenService *TokenService, mfaService *MFAService, loginAttempts *LoginAttemptService, config FederationConfig) *FederationService {
+	config.Issuer = strings.TrimSuffix(config.Issuer, "/")
+	config.AppBaseURL = strings.TrimSuffix(config.AppBaseURL, "/")
+
+	return &FederationService{
+		states:        states,
+		users:         users,
+		userService:   userService,
+		tokenService:  tokenService,
+		mfaService:    mfaService,
+		loginAttempts: loginAttempts,
+		config:        config,
+		httpClient:    &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// proverava da li je spoljni provajder podesen
+func (s *FederationService) Enabled() bool {
+	return s.config.Issuer != "" && s.config.ClientID != ""
+}
+
+// kreira indekse - jedinstven state i tiket, TTL indeks za istekle prijave
+// i jedinstven univerzitetski nalog po lokalnom korisniku
+func (s *FederationService) EnsureIndexes() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err := s.states.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "state_hash", Value: 1}}, Options: options.Index().SetUnique(true).SetSparse(true)},
+		{Keys: bson.D{{Key: "ticket_hash", Value: 1}}, Options: options.Index().SetUnique(true).SetSparse(true)},
+		{Keys: bson.D{{Key: "expires_at", Value: 1}}, Options: options.Index().SetExpireAfterSeconds(0)},
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = s.users.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "federation.issuer", Value: 1}, {Key: "federation.subject", Value: 1}},
+		Options: options.Index().
+			SetUnique(true).
+			SetPartialFilterExpression(bson.M{"federation.subject": bson.M{"$exists": true}}),
+	})
+	return err
+}
+
+// zapocinje prijavu - cuva state, nonce i PKCE verifier i vraca adresu provajdera na koju se korisnik preusmerava
+// i vrednost kolacica koji vezuje prijavu za browser (hes state-a) - povratak i tiket vaze samo uz taj kolacic
+// returnTo je stranica frontenda na koju se korisnik vraca posle prijave (samo relativna putanja)
+func (s *FederationService) LoginURL(returnTo string) (string, string, error) {
+	if !s.Enabled() {
+		return "", "", ErrFederationDisabled
+	}
+
+	provider, err := s.metadata()
+	if err != nil {
+		return "", "", err
+	}
+
+	state, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return "", "", err
+	}
+	nonce, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return "", "", err
+	}
+	verifier, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return "", "", err
+	}
+	binding := utils.HashToken(state)
+
+	if !strings.HasPrefix(returnTo, "/") || strings.HasPrefix(returnTo, "//") || strings.HasPrefix(returnTo, "/\\") {
+		returnTo = ""
+	}
+
+	now := time.Now()
+	record := models.FederationState{
+		ID:           primitive.NewObjectID(),
+		StateHash:    utils.HashToken(state),
+		BindingHash:  utils.HashToken(binding),
+		Nonce:        nonce,
+		CodeVerifier: verifier,
+		ReturnTo:     returnTo,
+		ExpiresAt:    now.Add(s.config.StateTTL),
+		CreatedAt:    now,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if _, err := s.states.InsertOne(ctx, record); err != nil {
+		return "", "", err
+	}
+
+	params := url.Values{}
+	params.Set("response_type", "code")
+	params.Set("client_id", s.config.ClientID)
+	params.Set("redirect_uri", s.config.RedirectURL)
+	params.Set("scope", s.config.Scopes)
+	params.Set("state", state)
+	params.Set("nonce", nonce)
+	params.Set("code_challenge", utils.PKCEChallenge(verifier))
+	params.Set("code_challenge_method", "S256")
+
+	return appendQuery(provider.AuthorizationEndpoint, params), binding, nil
+}
+
+// koliko dugo vazi kolacic koji vezuje prijavu za browser - do isteka state-a i tiketa
+func (s *FederationService) BindingTTL() time.Duration {
+	return s.config.StateTTL + federationTicketTTL
+}
+
+// obradjuje povratak sa provajdera i vraca adresu frontenda na koju se korisnik preusmerava
+// uspesna prijava nosi jednokratni tiket, a neuspesna opis greske
+// binding je vrednost kolacica iz browsera - povratni link otvoren u drugom browseru se odbija
+func (s *FederationService) Callback(state, code, providerError, binding string) string {
+	ticket, err := s.handleCallback(state, code, providerError, binding)
+	if err != nil {
+		log.Println("Federated login failed:", err)
+
+		message := ErrFederationFailed.Error()
+		for _, known := range []error{ErrFederationDisabled, ErrFederationStateInvalid, ErrFederationNoEmail, ErrFederationEmailTaken} {
+			if errors.Is(err, known) {
+				message = known.Error()
+			}
+		}
+		return s.config.AppBaseURL + "/login?federation_error=" + url.QueryEscape(message)
+	}
+
+	return s.config.AppBaseURL + "/login?ticket=" + url.QueryEscape(ticket)
+}
+
+// zavrsava prijavu tiketom - izdaje tokene ili, kao i kod prijave lozinkom, MFA izazov
+// tiket vazi samo u browseru koji je zapoceo prijavu (binding je vrednost kolacica)
+// vraca i stranicu frontenda na koju se korisnik vraca
+func (s *FederationService) Complete(ticket, binding string, client models.ClientInfo) (*models.LoginResponse, string, error) {
+	if binding == "" {
+		return nil, "", ErrFederationTicketInvalid
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var record models.FederationState
+	err := s.states.FindOneAndDelete(ctx, bson.M{
+		"ticket_hash":  utils.HashToken(ticket),
+		"binding_hash": utils.HashToken(binding),
+		"expires_at":   bson.M{"$gt": time.Now()},
+	}).Decode(&record)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, "", ErrFederationTicketInvalid
+		}
+		return nil, "", err
+	}
+
+	if record.UserID == nil {
+		return nil, "", ErrFederationTicketInvalid
+	}
+
+	user, err := s.userService.GetUserByID(*record.UserID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if user.Disabled {
+		s.loginAttempts.Record(&user.ID, user.Email, client, models.LoginOutcomeAccountDisabled)
+		return nil, "", ErrAccountDisabled
+	}
+
+	if s.mfaService.IsRequired(user) {
+		challenge, err := s.mfaService.CreateChallenge(user)
+		if err != nil {
+			return nil, "", err
+		}
+		s.loginAttempts.Record(&user.ID, user.Email, client, models.LoginOutcomeMFAChallenge)
+		return &models.LoginResponse{MFA: challenge}, record.ReturnTo, nil
+	}
+
+	tokens, err := s.tokenService.IssueTokens(user, client)
+	if err != nil {
+		return nil, "", err
+	}
+
+	s.loginAttempts.Record(&user.ID, user.Email, client, models.LoginOutcomeSuccess)
+
+	return &models.LoginResponse{
+		TokenPair: *tokens,
+		User:      sanitizeUser(*user),
+	}, record.ReturnTo, nil
+}
+
+// trosi state, menja kod za ID token, povezuje lokalni nalog i izdaje tiket
+func (s *FederationService) handleCallback(state, code, providerError, binding string) (string, error) {
+	if !s.Enabled() {
+		return "", ErrFederationDisabled
+	}
+
+	if state == "" || binding == "" {
+		return "", ErrFederationStateInvalid
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var record models.FederationState
+	err := s.states.FindOneAndUpdate(ctx,
+		bson.M{"state_hash": utils.HashToken(state), "binding_hash": utils.HashToken(binding), "expires_at": bson.M{"$gt": time.Now()}},
+		bson.M{"$unset": bson.M{"state_hash": ""}},
+	).Decode(&record)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return "", ErrFederationStateInvalid
+		}
+		return "", err
+	}
+
+	if providerError != "" {
+		return "", fmt.Errorf("identity provider returned %s", providerError)
+	}
+
+	if code == "" {
+		return "", errors.New("identity provider did not return a code")
+	}
+
+	claims, err := s.exchangeCode(code, record.CodeVerifier, record.Nonce)
+	if err != nil {
+		return "", err
+	}
+
+	user, err := s.linkUser(claims)
+	if err != nil {
+		return "", err
+	}
+
+	ticket, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return "", err
+	}
+
+	_, err = s.states.UpdateOne(ctx,
+		bson.M{"_id": record.ID},
+		bson.M{"$set": bson.M{
+			"ticket_hash": utils.HashToken(ticket),
+			"user_id":     user.ID,
+			"expires_at":  time.Now().Add(federationTicketTTL),
+		}},
+	)
+	if err != nil {
+		return "", err
+	}
+
+	return ticket, nil
+}
+
+// menja autorizacioni kod za tokene na token endpointu provajdera i proverava ID token
+func (s *FederationService) exchangeCode(code, verifier, nonce string) (*models.FederatedClaims, error) {
+	provider, err := s.metadata()
+	if err != nil {
+		return nil, err
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", s.config.RedirectURL)
+	form.Set("code_verifier", verifier)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, provider.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	req.SetBasicAuth(url.QueryEscape(s.config.ClientID), url.QueryEscape(s.config.ClientSecret))
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		IDToken string `json:"id_token"`
+		Error   string `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK || result.IDToken == "" {
+		return nil, fmt.Errorf("token endpoint returned %d %s", resp.StatusCode, result.Error)
+	}
+
+	claims, err := utils.ValidateExternalIDToken(result.IDToken, provider.Issuer, s.config.ClientID, nonce, s.jwks)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.mapClaims(claims), nil
+}
+
+// izdvaja podatke o korisniku iz claim-ova ID tokena
+func (s *FederationService) mapClaims(claims jwt.MapClaims) *models.FederatedClaims {
+	text := func(name string) string {
+		switch value := claims[name].(type) {
+		case string:
+			return strings.TrimSpace(value)
+		case float64:
+			return fmt.Sprintf("%.0f", value)
+		}
+		return ""
+	}
+
+	emailVerified, _ := claims["email_verified"].(bool)
+
+	return &models.FederatedClaims{
+		Issuer:            text("iss"),
+		Subject:           text("sub"),
+		Email:             strings.ToLower(text("email")),
+		EmailVerified:     emailVerified,
+		GivenName:         text("given_name"),
+		FamilyName:        text("family_name"),
+		PreferredUsername: text("preferred_username"),
+		IndexNumber:       text(s.config.IndexClaim),
+		Faculty:           text(s.config.FacultyClaim),
+	}
+}
+
+// pronalazi lokalni nalog povezan sa univerzitetskim nalogom
+// ako ga nema, povezuje nalog sa istom email adresom (samo ako je provajder potvrdio adresu) ili kreira novi
+// administratorski nalozi se ne povezuju po email adresi - prijavljuju se lokalnom lozinkom
+func (s *FederationService) linkUser(claims *models.FederatedClaims) (*models.User, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var user models.User
+	err := s.users.FindOne(ctx, bson.M{
+		"federation.issuer":  claims.Issuer,
+		"federation.subject": claims.Subject,
+	}).Decode(&user)
+	if err == nil {
+		s.mapStudentProfile(&user, claims)
+		return &user, nil
+	}
+	if err != mongo.ErrNoDocuments {
+		return nil, err
+	}
+
+	if claims.Email == "" {
+		return nil, ErrFederationNoEmail
+	}
+
+	identity := &models.FederatedIdentity{
+		Issuer:   claims.Issuer,
+		Subject:  claims.Subject,
+		LinkedAt: time.Now(),
+	}
+
+	err = s.users.FindOne(ctx, bson.M{"email": claims.Email}).Decode(&user)
+	if err == nil {
+		if !claims.EmailVerified || user.Federation != nil || user.Role == models.RoleAdmin {
+			return nil, ErrFederationEmailTaken
+		}
+
+		_, err := s.users.UpdateOne(ctx,
+			bson.M{"_id": user.ID},
+			bson.M{"$set": bson.M{"federation": identity, "updated_at": time.Now()}},
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		user.Federation = identity
+		s.mapStudentProfile(&user, claims)
+		return &user, nil
+	}
+	if err != mongo.ErrNoDocuments {
+		return nil, err
+	}
+
+	username, err := s.availableUsername(ctx, claims)
+	if err != nil {
+		return nil, err
+	}
+
+	// univerzitetska adresa se smatra potvrdjenom, a nalog nema lozinku - prijava je moguca samo preko provajdera
+	// dok korisnik ne postavi lozinku kroz "zaboravljena lozinka"
+	now := time.Now()
+	user = models.User{
+		ID:              primitive.NewObjectID(),
+		Username:        username,
+		Email:           claims.Email,
+		FirstName:       claims.GivenName,
+		LastName:        claims.FamilyName,
+		Role:            models.RoleUser,
+		EmailVerified:   true,
+		EmailVerifiedAt: &now,
+		Federation:      identity,
+		CreatedAt:       now,
+		UpdatedAt:       now,
+	}
+
+	if _, err := s.users.InsertOne(ctx, user); err != nil {
+		return nil, err
+	}
+
+	s.mapStudentProfile(&user, claims)
+	return &user, nil
+}
+
+// upisuje broj indeksa i fakultet iz claim-ova ako korisnik jos nema broj indeksa
+// podatke i dalje potvrdjuje administrator; ako je broj indeksa vec vezan za drugi nalog, preskace se
+func (s *FederationService) mapStudentProfile(user *models.User, claims *models.FederatedClaims) {
+	if claims.IndexNumber == "" || (user.Student != nil && user.Student.IndexNumber != "") {
+		return
+	}
+
+	profile := models.StudentProfile{}
+	if user.Student != nil {
+		profile = *user.Student
+	}
+	profile.IndexNumber = claims.IndexNumber
+	if claims.Faculty != "" {
+		profile.Faculty = claims.Faculty
+	}
+	profile.Verified = false
+	profile.VerifiedAt = nil
+	profile.VerifiedBy = nil
+	profile.UpdatedAt = time.Now()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err := s.users.UpdateOne(ctx,
+		bson.M{"_id": user.ID},
+		bson.M{"$set": bson.M{"student": profile, "updated_at": time.Now()}},
+	)
+	if err != nil {
+		log.Printf("Failed to map student profile for user %s: %v", user.ID.Hex(), err)
+		return
+	}
+
+	user.Student = &profile
+}
+
+// bira slobodno korisnicko ime - preferred_username, deo email adrese pre @ ili isto sa brojem na kraju
+func (s *FederationService) availableUsername(ctx context.Context, claims *models.FederatedClaims) (string, error) {
+	base := claims.PreferredUsername
+	if base == "" {
+		base, _, _ = strings.Cut(claims.Email, "@")
+	}
+	if len(base) > 16 {
+		base = base[:16]
+	}
+	for len(base) < 3 {
+		base += "0"
+	}
+
+	candidate := base
+	for i := 2; i < 1000; i++ {
+		count, err := s.users.CountDocuments(ctx, bson.M{"username": candidate})
+		if err != nil {
+			return "", err
+		}
+		if count == 0 {
+			return candidate, nil
+		}
+		candidate = fmt.Sprintf("%s%d", base, i)
+	}
+
+	return "", errors.New("could not find an available username")
+}
+
+// vraca discovery dokument provajdera - preuzima se jednom i cuva dok servis radi
+// izdavalac iz dokumenta mora odgovarati podesenom izdavaocu
+func (s *FederationService) metadata() (*providerMetadata, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.provider != nil {
+		return s.provider, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.config.Issuer+"/.well-known/openid-configuration", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.New("failed to fetch identity provider configuration")
+	}
+
+	var provider providerMetadata
+	if err := json.NewDecoder(resp.Body).Decode(&provider); err != nil {
+		return nil, err
+	}
+
+	if strings.TrimSuffix(provider.Issuer, "/") != s.config.Issuer ||
+		provider.AuthorizationEndpoint == "" || provider.TokenEndpoint == "" || provider.JWKSURI == "" {
+		return nil, errors.New("identity provider configuration is invalid")
+	}
+
+	s.provider = &provider
+	s.jwks = utils.NewJWKSCache(provider.JWKSURI, s.httpClient)
+	return s.provider, nil
+}
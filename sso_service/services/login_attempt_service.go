@@ -0,0 +1,258 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sso_service/models"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	// broj neuspelih pokusaja bez kasnjenja; posle toga se ceka 1s, 2s, 4s... najvise loginMaxDelay
+	loginFreeAttempts = 3
+	loginBaseDelay    = time.Second
+	loginMaxDelay     = 30 * time.Second
+)
+
+// ishodi koji se racunaju kao neuspeli pokusaji (pogresna lozinka ili kod)
+var loginFailureOutcomes = []string{models.LoginOutcomeInvalidCredentials, models.LoginOutcomeInvalidMFACode}
+
+// LoginThrottledError - previse neuspelih pokusaja, prijava je privremeno odbijena
+// Locked oznacava zakljucavanje, inace je u pitanju progresivno kasnjenje izmedju pokusaja
+type LoginThrottledError struct {
+	RetryAfter time.Duration
+	Locked     bool
+}
+
+func (e *LoginThrottledError) Error() string {
+	if e.Locked {
+		return fmt.Sprintf("too many failed login attempts, sign-in is locked for %s", e.RetryAfter.Round(time.Second))
+	}
+	return fmt.Sprintf("too many failed login attempts, try again in %s", e.RetryAfter.Round(time.Second))
+}
+
+// LoginAttemptConfig - pragovi za zastitu od pogadjanja lozinke
+type LoginAttemptConfig struct {
+	MaxAccountFailures int           // neuspelih pokusaja za jedan nalog pre zakljucavanja
+	MaxIPFailures      int           // neuspelih pokusaja sa jedne IP adrese pre zakljucavanja
+	FailureWindow      time.Duration // period u kojem se neuspeli pokusaji sabiraju
+	LockoutDuration    time.Duration // trajanje zakljucavanja od poslednjeg neuspelog pokusaja
+	Retention          time.Duration // koliko dugo se cuvaju zapisi u dnevniku
+}
+
+// LoginAttemptService - dnevnik prijava i zastita od pogadjanja lozinke
+// neuspeli pokusaji se broje iz samog dnevnika, po nalogu (email) i po IP adresi
+type LoginAttemptService struct {
+	collection *mongo.Collection
+	config     LoginAttemptConfig
+}
+
+// kreira novi LoginAttemptService sa kolekcijom dnevnika prijava i pragovima
+func NewLoginAttemptService(collection *mongo.Collection, config LoginAttemptConfig) *LoginAttemptService {
+	return &LoginAttemptService{
+		collection: collection,
+		config:     config,
+	}
+}
+
+// kreira indekse za brojanje pokusaja po nalogu, IP adresi i korisniku i TTL indeks za stare zapise
+func (s *LoginAttemptService) EnsureIndexes() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err := s.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "email", Value: 1}, {Key: "created_at", Value: -1}}},
+		{Keys: bson.D{{Key: "ip_address", Value: 1}, {Key: "created_at", Value: -1}}},
+		{Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "created_at", Value: -1}}},
+		{Keys: bson.D{{Key: "created_at", Value: 1}}, Options: options.Index().SetExpireAfterSeconds(int32(s.config.Retention.Seconds()))},
+	})
+	return err
+}
+
+// proverava da li je prijava za nalog i IP adresu trenutno dozvoljena
+// vraca *LoginThrottledError ako je nalog ili adresa zakljucana ili jos traje kasnjenje
+func (s *LoginAttemptService) Check(email, ipAddress string) error {
+	if err := s.throttle("email", normalizeEmail(email), true, s.config.MaxAccountFailures); err != nil {
+		return err
+	}
+
+	if ipAddress == "" {
+		return nil
+	}
+
+	// uspesna prijava sa adrese ne ponistava brojac - napadac bi mogao da se prijavi na svoj nalog
+	return s.throttle("ip_address", ipAddress, false, s.config.MaxIPFailures)
+}
+
+// belezi pokusaj prijave u dnevnik - greska pri upisu se samo loguje da ne bi blokirala prijavu
+func (s *LoginAttemptService) Record(userID *primitive.ObjectID, email string, client models.ClientInfo, outcome string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	attempt := models.LoginAttempt{
+		UserID:    userID,
+		Email:     normalizeEmail(email),
+		IPAddress: client.IPAddress,
+		UserAgent: client.UserAgent,
+		Outcome:   outcome,
+		CreatedAt: time.Now(),
+	}
+
+	if _, err := s.collection.InsertOne(ctx, attempt); err != nil {
+		log.Println("Failed to record login attempt:", err)
+	}
+}
+
+// pregled dnevnika prijava sa filterima i stranicenjem (administratori)
+func (s *LoginAttemptService) ListAttempts(filter models.LoginAttemptFilter) (*models.LoginAttemptPage, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if filter.Page <= 0 {
+		filter.Page = 1
+	}
+	if filter.Limit <= 0 || filter.Limit > 100 {
+		filter.Limit = 50
+	}
+
+	query := bson.M{}
+	if filter.UserID != nil {
+		query["user_id"] = *filter.UserID
+	}
+	if filter.Email != "" {
+		query["email"] = normalizeEmail(filter.Email)
+	}
+	if filter.IPAddress != "" {
+		query["ip_address"] = filter.IPAddress
+	}
+	if filter.Outcome != "" {
+		query["outcome"] = filter.Outcome
+	}
+
+	total, err := s.collection.CountDocuments(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	findOptions := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}}).
+		SetSkip(int64((filter.Page - 1) * filter.Limit)).
+		SetLimit(int64(filter.Limit))
+
+	attempts, err := s.find(ctx, query, findOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.LoginAttemptPage{
+		Attempts: attempts,
+		Total:    total,
+		Page:     filter.Page,
+		Limit:    filter.Limit,
+	}, nil
+}
+
+// vraca poslednje pokusaje prijave na nalog korisnika - prikazuju se na profilu
+func (s *LoginAttemptService) RecentForUser(userID primitive.ObjectID, limit int) ([]models.LoginAttempt, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	findOptions := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}}).
+		SetLimit(int64(limit))
+
+	return s.find(ctx, bson.M{"user_id": userID}, findOptions)
+}
+
+// racuna zakljucavanje ili kasnjenje za jedan kljuc (email ili IP adresa)
+// neuspeli pokusaji se broje u periodu FailureWindow pre poslednjeg neuspelog pokusaja,
+// a za nalog samo posle poslednje uspesne prijave
+func (s *LoginAttemptService) throttle(field, value string, resetOnSuccess bool, maxFailures int) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	now := time.Now()
+	since := now.Add(-(s.config.FailureWindow + s.config.LockoutDuration))
+
+	if resetOnSuccess {
+		var lastSuccess models.LoginAttempt
+		err := s.collection.FindOne(ctx,
+			bson.M{field: value, "outcome": models.LoginOutcomeSuccess, "created_at": bson.M{"$gt": since}},
+			options.FindOne().SetSort(bson.D{{Key: "created_at", Value: -1}}),
+		).Decode(&lastSuccess)
+		if err == nil {
+			since = lastSuccess.CreatedAt
+		} else if err != mongo.ErrNoDocuments {
+			return err
+		}
+	}
+
+	failureQuery := bson.M{field: value, "outcome": bson.M{"$in": loginFailureOutcomes}, "created_at": bson.M{"$gt": since}}
+
+	var lastFailure models.LoginAttempt
+	err := s.collection.FindOne(ctx, failureQuery, options.FindOne().SetSort(bson.D{{Key: "created_at", Value: -1}})).Decode(&lastFailure)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil
+		}
+		return err
+	}
+
+	windowStart := lastFailure.CreatedAt.Add(-s.config.FailureWindow)
+	if windowStart.Before(since) {
+		windowStart = since
+	}
+	failureQuery["created_at"] = bson.M{"$gt": windowStart}
+
+	failures, err := s.collection.CountDocuments(ctx, failureQuery)
+	if err != nil {
+		return err
+	}
+
+	if int(failures) >= maxFailures {
+		if lockedUntil := lastFailure.CreatedAt.Add(s.config.LockoutDuration); now.Before(lockedUntil) {
+			return &LoginThrottledError{RetryAfter: lockedUntil.Sub(now), Locked: true}
+		}
+		return nil
+	}
+
+	if failures >= loginFreeAttempts {
+		delay := loginBaseDelay << (failures - loginFreeAttempts)
+		if delay > loginMaxDelay || delay <= 0 {
+			delay = loginMaxDelay
+		}
+		if retryAt := lastFailure.CreatedAt.Add(delay); now.Before(retryAt) {
+			return &LoginThrottledError{RetryAfter: retryAt.Sub(now)}
+		}
+	}
+
+	return nil
+}
+
+// izvrsava upit nad dnevnikom i vraca listu pokusaja
+func (s *LoginAttemptService) find(ctx context.Context, query bson.M, findOptions *options.FindOptions) ([]models.LoginAttempt, error) {
+	cursor, err := s.collection.Find(ctx, query, findOptions)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	attempts := []models.LoginAttempt{}
+	if err = cursor.All(ctx, &attempts); err != nil {
+		return nil, err
+	}
+
+	return attempts, nil
+}
+
+// email se u dnevniku cuva malim slovima da varijacije velikih slova ne bi zaobisle brojanje
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
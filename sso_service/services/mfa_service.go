@@ -0,0 +1,474 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sso_service/models"
+	"sso_service/utils"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// greske dvofaktorske autentifikacije
+var (
+	ErrInvalidMFAChallenge = errors.New("invalid or expired MFA challenge")
+	ErrInvalidMFACode      = errors.New("invalid verification code")
+	ErrMFARequiredForRole  = errors.New("two-factor authentication is mandatory for administrators")
+)
+
+const (
+	// broj pogresnih kodova posle kojeg izazov prestaje da vazi i prijava se mora ponoviti
+	mfaMaxAttempts = 5
+	// broj kodova za oporavak koji se izdaju pri ukljucivanju TOTP-a
+	recoveryCodeCount = 10
+)
+
+// MFAService - dvofaktorska autentifikacija TOTP kodovima (RFC 6238) i kodovima za oporavak
+// posle ispravne lozinke izdaje se izazov, a tokeni tek kada korisnik posalje ispravan kod
+type MFAService struct {
+	challenges    *mongo.Collection
+	users         *mongo.Collection
+	tokenService  *TokenService
+	loginAttempts *LoginAttemptService
+	issuer        string
+	challengeTTL  time.Duration
+}
+
+// kreira novi MFAService sa kolekcijom izazova, kolekcijom korisnika, servisom za tokene i dnevnikom prijava
+// issuer je naziv koji authenticator aplikacija prikazuje uz nalog
+func NewMFAService(challenges, users *mongo.Collection, tokenService *TokenService, loginAttempts *LoginAttemptService, issuer string, challengeTTL time.Duration) *MFAService {
+	return &MFAService{
+		challenges:    challenges,
+		users:         users,
+		tokenService:  tokenService,
+		loginAttempts: loginAttempts,
+		issuer:        issuer,
+		challengeTTL:  challengeTTL,
+	}
+}
+
+// kreira indekse - jedinstven hes izazova i TTL indeks koji brise istekle izazove
+func (s *MFAService) EnsureIndexes() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err := s.challenges.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "token_hash", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "expires_at", Value: 1}}, Options: options.Index().SetExpireAfterSeconds(0)},
+	})
+	return err
+}
+
+// proverava da li je korisniku potreban drugi faktor pri prijavi
+// administratori ga moraju imati, ostali korisnici samo ako su ga sami ukljucili
+func (s *MFAService) IsRequired(user *models.User) bool {
+	return user.TOTPEnabled || user.Role == models.RoleAdmin
+}
+
+// izdaje izazov posle ispravne lozinke - administrator bez TOTP-a ga mora podesiti u okviru izazova
+func (s *MFAService) CreateChallenge(user *models.User) (*models.MFAChallengeInfo, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	token, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	challenge := models.MFAChallenge{
+		ID:                 primitive.NewObjectID(),
+		UserID:             user.ID,
+		TokenHash:          utils.HashToken(token),
+		EnrollmentRequired: !user.TOTPEnabled,
+		ExpiresAt:          now.Add(s.challengeTTL),
+		CreatedAt:          now,
+	}
+
+	if _, err := s.challenges.InsertOne(ctx, challenge); err != nil {
+		return nil, err
+	}
+
+	return &models.MFAChallengeInfo{
+		MFAToken:           token,
+		EnrollmentRequired: challenge.EnrollmentRequired,
+		ExpiresIn:          int64(s.challengeTTL.Seconds()),
+	}, nil
+}
+
+// zapocinje podesavanje TOTP-a u okviru izazova (administrator koji ga jos nema)
+// vraca tajnu za authenticator aplikaciju; TOTP se ukljucuje tek kada se izazov potvrdi prvim kodom
+func (s *MFAService) StartEnrollment(mfaToken string) (*models.TOTPSetup, error) {
+	challenge, err := s.findChallenge(mfaToken)
+	if err != nil {
+		return nil, err
+	}
+
+	if !challenge.EnrollmentRequired {
+		return nil, errors.New("two-factor authentication is already enabled")
+	}
+
+	return s.SetupTOTP(challenge.UserID)
+}
+
+// zavrsava prijavu - proverava TOTP kod ili kod za oporavak i izdaje tokene
+// ako je izazov zahtevao podesavanje, prvi ispravan kod ukljucuje TOTP i vracaju se kodovi za oporavak
+func (s *MFAService) VerifyChallenge(mfaToken, code string, client models.ClientInfo) (*models.LoginResponse, []string, error) {
+	challenge, err := s.findChallenge(mfaToken)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	user, err := s.getUser(challenge.UserID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if user.Disabled {
+		s.loginAttempts.Record(&user.ID, user.Email, client, models.LoginOutcomeAccountDisabled)
+		return nil, nil, ErrAccountDisabled
+	}
+
+	// pogresni kodovi se racunaju u isti brojac kao pogresne lozinke
+	if err := s.loginAttempts.Check(user.Email, client.IPAddress); err != nil {
+		s.loginAttempts.Record(&user.ID, user.Email, client, models.LoginOutcomeLockedOut)
+		return nil, nil, err
+	}
+
+	var recoveryCodes []string
+	if challenge.EnrollmentRequired {
+		recoveryCodes, err = s.confirmPending(user, code)
+	} else {
+		err = s.checkCode(user, code, true)
+	}
+
+	if err != nil {
+		if errors.Is(err, ErrInvalidMFACode) {
+			s.recordFailedAttempt(challenge)
+			s.loginAttempts.Record(&user.ID, user.Email, client, models.LoginOutcomeInvalidMFACode)
+		}
+		return nil, nil, err
+	}
+
+	// izazov je jednokratan - ako ga je istovremeni zahtev vec iskoristio, prijava se odbija
+	if !s.consumeChallenge(challenge.ID) {
+		return nil, nil, ErrInvalidMFAChallenge
+	}
+
+	user, err = s.getUser(challenge.UserID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	tokens, err := s.tokenService.IssueTokens(user, client)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	s.loginAttempts.Record(&user.ID, user.Email, client, models.LoginOutcomeSuccess)
+
+	return &models.LoginResponse{
+		TokenPair: *tokens,
+		User:      sanitizeUser(*user),
+	}, recoveryCodes, nil
+}
+
+// generiše novu TOTP tajnu za prijavljenog korisnika
+// tajna se cuva kao privremena dok je korisnik ne potvrdi prvim kodom
+func (s *MFAService) SetupTOTP(userID primitive.ObjectID) (*models.TOTPSetup, error) {
+	user, err := s.getUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.TOTPEnabled {
+		return nil, errors.New("two-factor authentication is already enabled")
+	}
+
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.updateUser(userID, bson.M{"$set": bson.M{"totp_pending_secret": secret}}); err != nil {
+		return nil, err
+	}
+
+	return &models.TOTPSetup{
+		Secret:          secret,
+		ProvisioningURI: utils.TOTPProvisioningURI(s.issuer, user.Email, secret),
+	}, nil
+}
+
+// ukljucuje TOTP prvim ispravnim kodom iz authenticator aplikacije i vraca kodove za oporavak
+func (s *MFAService) ConfirmTOTP(userID primitive.ObjectID, code string) ([]string, error) {
+	user, err := s.getUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.confirmPending(user, code)
+}
+
+// iskljucuje TOTP - potrebni su lozinka i trenutni kod; administratori ga ne mogu iskljuciti
+func (s *MFAService) DisableTOTP(userID primitive.ObjectID, password, code string) error {
+	user, err := s.getUser(userID)
+	if err != nil {
+		return err
+	}
+
+	if user.Role == models.RoleAdmin {
+		return ErrMFARequiredForRole
+	}
+
+	if !user.TOTPEnabled {
+		return errors.New("two-factor authentication is not enabled")
+	}
+
+	if !utils.CheckPasswordHash(password, user.Password) {
+		return errors.New("invalid password")
+	}
+
+	if err := s.checkCode(user, code, false); err != nil {
+		return err
+	}
+
+	return s.clearTOTP(userID)
+}
+
+// izdaje nove kodove za oporavak - stari prestaju da vaze; potreban je trenutni TOTP kod
+func (s *MFAService) RegenerateRecoveryCodes(userID primitive.ObjectID, code string) ([]string, error) {
+	user, err := s.getUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !user.TOTPEnabled {
+		return nil, errors.New("two-factor authentication is not enabled")
+	}
+
+	if err := s.checkCode(user, code, false); err != nil {
+		return nil, err
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.updateUser(userID, bson.M{"$set": bson.M{"recovery_codes": hashes}}); err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+// ponistava TOTP korisniku koji je izgubio uredjaj i kodove za oporavak (administratori)
+// sve sesije se opozivaju; administrator ce pri sledecoj prijavi morati ponovo da podesi TOTP
+func (s *MFAService) ResetTOTP(userID primitive.ObjectID) error {
+	if err := s.clearTOTP(userID); err != nil {
+		return err
+	}
+
+	return s.tokenService.RevokeAllSessions(userID)
+}
+
+// potvrdjuje privremenu tajnu kodom i ukljucuje TOTP
+func (s *MFAService) confirmPending(user *models.User, code string) ([]string, error) {
+	if user.TOTPEnabled {
+		return nil, errors.New("two-factor authentication is already enabled")
+	}
+
+	if user.TOTPPendingSecret == "" {
+		return nil, errors.New("two-factor authentication setup has not been started")
+	}
+
+	step, ok := utils.ValidateTOTP(user.TOTPPendingSecret, code, time.Now())
+	if !ok {
+		return nil, ErrInvalidMFACode
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.updateUser(user.ID, bson.M{
+		"$set": bson.M{
+			"totp_enabled":    true,
+			"totp_enabled_at": time.Now(),
+			"totp_secret":     user.TOTPPendingSecret,
+			"totp_last_step":  step,
+			"recovery_codes":  hashes,
+		},
+		"$unset": bson.M{"totp_pending_secret": ""},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+// proverava TOTP kod; kod koji je vec iskoriscen (isti ili raniji korak) se odbija
+// ako je allowRecovery postavljen, umesto TOTP koda moze se poslati kod za oporavak
+func (s *MFAService) checkCode(user *models.User, code string, allowRecovery bool) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if step, ok := utils.ValidateTOTP(user.TOTPSecret, code, time.Now()); ok {
+		result, err := s.users.UpdateOne(ctx,
+			bson.M{"_id": user.ID, "$or": []bson.M{
+				{"totp_last_step": bson.M{"$lt": step}},
+				{"totp_last_step": bson.M{"$exists": false}},
+			}},
+			bson.M{"$set": bson.M{"totp_last_step": step}},
+		)
+		if err != nil {
+			return err
+		}
+		if result.ModifiedCount == 0 {
+			return ErrInvalidMFACode
+		}
+		return nil
+	}
+
+	if !allowRecovery {
+		return ErrInvalidMFACode
+	}
+
+	// kod za oporavak se uklanja iz liste u istoj operaciji u kojoj se proverava
+	hash := utils.HashToken(utils.NormalizeRecoveryCode(code))
+	result, err := s.users.UpdateOne(ctx,
+		bson.M{"_id": user.ID, "recovery_codes": hash},
+		bson.M{"$pull": bson.M{"recovery_codes": hash}},
+	)
+	if err != nil {
+		return err
+	}
+	if result.ModifiedCount == 0 {
+		return ErrInvalidMFACode
+	}
+
+	return nil
+}
+
+// pronalazi vazeci izazov po tokenu
+func (s *MFAService) findChallenge(mfaToken string) (*models.MFAChallenge, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var challenge models.MFAChallenge
+	err := s.challenges.FindOne(ctx, bson.M{
+		"token_hash": utils.HashToken(mfaToken),
+		"expires_at": bson.M{"$gt": time.Now()},
+		"attempts":   bson.M{"$lt": mfaMaxAttempts},
+	}).Decode(&challenge)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, ErrInvalidMFAChallenge
+		}
+		return nil, err
+	}
+
+	return &challenge, nil
+}
+
+// belezi pogresan kod - posle mfaMaxAttempts pokusaja izazov vise ne vazi
+func (s *MFAService) recordFailedAttempt(challenge *models.MFAChallenge) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	s.challenges.UpdateOne(ctx, bson.M{"_id": challenge.ID}, bson.M{"$inc": bson.M{"attempts": 1}})
+}
+
+// brise izazov i vraca da li je ovaj zahtev bio taj koji ga je iskoristio
+func (s *MFAService) consumeChallenge(id primitive.ObjectID) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	result, err := s.challenges.DeleteOne(ctx, bson.M{"_id": id})
+	return err == nil && result.DeletedCount == 1
+}
+
+// uklanja TOTP tajne i kodove za oporavak korisnika
+func (s *MFAService) clearTOTP(userID primitive.ObjectID) error {
+	return s.updateUser(userID, bson.M{
+		"$set": bson.M{"totp_enabled": false},
+		"$unset": bson.M{
+			"totp_enabled_at":     "",
+			"totp_secret":         "",
+			"totp_pending_secret": "",
+			"totp_last_step":      "",
+			"recovery_codes":      "",
+		},
+	})
+}
+
+// ucitava korisnika sa svim poljima (lozinka i TOTP tajna su potrebni za proveru)
+func (s *MFAService) getUser(userID primitive.ObjectID) (*models.User, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var user models.User
+	if err := s.users.FindOne(ctx, bson.M{"_id": userID}).Decode(&user); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.New("user not found")
+		}
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+// azurira korisnika prosledjenim operatorima i postavlja vreme izmene
+func (s *MFAService) updateUser(userID primitive.ObjectID, update bson.M) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	set, _ := update["$set"].(bson.M)
+	if set == nil {
+		set = bson.M{}
+		update["$set"] = set
+	}
+	set["updated_at"] = time.Now()
+
+	result, err := s.users.UpdateOne(ctx, bson.M{"_id": userID}, update)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return errors.New("user not found")
+	}
+
+	return nil
+}
+
+// generiše kodove za oporavak i njihove heseve - korisniku se kodovi prikazuju samo jednom
+func newRecoveryCodes() ([]string, []string, error) {
+	codes, err := utils.GenerateRecoveryCodes(recoveryCodeCount)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	hashes := make([]string, 0, len(codes))
+	for _, code := range codes {
+		hashes = append(hashes, utils.HashToken(utils.NormalizeRecoveryCode(code)))
+	}
+
+	return codes, hashes, nil
+}
+
+// uklanja lozinku i TOTP podatke pre vracanja korisnika klijentu
+func sanitizeUser(user models.User) models.User {
+	user.Password = ""
+	user.TOTPSecret = ""
+	user.TOTPPendingSecret = ""
+	user.RecoveryCodes = nil
+	return user
+}
@@ -0,0 +1,470 @@
+package services
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"net/url"
+	"sso_service/models"
+	"sso_service/utils"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ErrInvalidOAuthClient - klijent ne postoji ili redirect_uri nije registrovan
+// u tom slucaju se korisnik nikada ne preusmerava nazad ka klijentu
+var ErrInvalidOAuthClient = errors.New("unknown client or unregistered redirect_uri")
+
+// OAuthError - greska u OAuth2/OIDC formatu (RFC 6749, odeljak 5.2)
+type OAuthError struct {
+	Code        string
+	Description string
+}
+
+func (e *OAuthError) Error() string {
+	return e.Code + ": " + e.Description
+}
+
+// scope-ovi koje provajder podrzava, redom kojim se prikazuju korisniku
+var supportedScopes = []string{models.ScopeOpenID, models.ScopeProfile, models.ScopeEmail}
+
+// OIDCService - OpenID Connect provajder: authorization code + PKCE, ID tokeni i userinfo
+// omogucava drugim univerzitetskim aplikacijama da prijavljuju korisnike istim nalozima
+type OIDCService struct {
+	clients     *mongo.Collection
+	codes       *mongo.Collection
+	userService *UserService
+	keys        *utils.KeySet
+	issuer      string
+	accessTTL   time.Duration
+	codeTTL     time.Duration
+}
+
+// kreira novi OIDCService sa kolekcijama klijenata i autorizacionih kodova, servisom za korisnike,
+// kljucevima za potpis, adresom izdavaoca (issuer) i trajanjem tokena i kodova
+func NewOIDCService(clients, codes *mongo.Collection, userService *UserService, keys *utils.KeySet, issuer string, accessTTL, codeTTL time.Duration) *OIDCService {
+	return &OIDCService{
+		clients:     clients,
+		codes:       codes,
+		userService: userService,
+		keys:        keys,
+		issuer:      strings.TrimSuffix(issuer, "/"),
+		accessTTL:   accessTTL,
+		codeTTL:     codeTTL,
+	}
+}
+
+// kreira indekse - jedinstven ID klijenta, jedinstven hes koda i TTL indeks koji brise istekle kodove
+func (s *OIDCService) EnsureIndexes() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if _, err := s.clients.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "client_id", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	}); err != nil {
+		return err
+	}
+
+	_, err := s.codes.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "code_hash", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "expires_at", Value: 1}}, Options: options.Index().SetExpireAfterSeconds(0)},
+	})
+	return err
+}
+
+// vraca discovery dokument (/.well-known/openid-configuration)
+func (s *OIDCService) Discovery() map[string]interface{} {
+	return map[string]interface{}{
+		"issuer":                                s.issuer,
+		"authorization_endpoint":                s.issuer + "/api/v1/oauth/authorize",
+		"token_endpoint":                        s.issuer + "/api/v1/oauth/token",
+		"userinfo_endpoint":                     s.issuer + "/api/v1/oauth/userinfo",
+		"jwks_uri":                              s.issuer + "/.well-known/jwks.json",
+		"response_types_supported":              []string{"code"},
+		"grant_types_supported":                 []string{"authorization_code"},
+		"subject_types_supported":               []string{"public"},
+		"id_token_signing_alg_values_supported": []string{s.keys.Active().Algorithm},
+		"scopes_supported":                      supportedScopes,
+		"token_endpoint_auth_methods_supported": []string{"client_secret_basic", "client_secret_post", "none"},
+		"code_challenge_methods_supported":      []string{"S256"},
+		"claims_supported": []string{
+			"sub", "iss", "aud", "exp", "iat", "nonce",
+			"name", "given_name", "family_name", "preferred_username", "email", "email_verified",
+		},
+	}
+}
+
+// registruje novog OIDC klijenta - tajna se vraca samo jednom, a u bazi se cuva njen hes
+// javni klijenti nemaju tajnu
+func (s *OIDCService) CreateClient(actorID primitive.ObjectID, req models.CreateOAuthClientRequest) (*models.OAuthClient, string, error) {
+	for _, uri := range req.RedirectURIs {
+		parsed, err := url.Parse(uri)
+		if err != nil || (parsed.Scheme != "https" && parsed.Scheme != "http") || parsed.Fragment != "" {
+			return nil, "", errors.New("invalid redirect_uri: " + uri)
+		}
+	}
+
+	clientID, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return nil, "", err
+	}
+
+	client := models.OAuthClient{
+		ID:           primitive.NewObjectID(),
+		ClientID:     clientID,
+		Name:         req.Name,
+		Public:       req.Public,
+		RedirectURIs: req.RedirectURIs,
+		CreatedBy:    actorID,
+		CreatedAt:    time.Now(),
+	}
+
+	secret := ""
+	if !req.Public {
+		if secret, err = utils.GenerateOpaqueToken(); err != nil {
+			return nil, "", err
+		}
+		client.SecretHash = utils.HashToken(secret)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if _, err := s.clients.InsertOne(ctx, client); err != nil {
+		return nil, "", err
+	}
+
+	return &client, secret, nil
+}
+
+// vraca sve registrovane OIDC klijente
+func (s *OIDCService) ListClients() ([]models.OAuthClient, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	cursor, err := s.clients.Find(ctx, bson.M{}, options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}}))
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	clients := []models.OAuthClient{}
+	if err := cursor.All(ctx, &clients); err != nil {
+		return nil, err
+	}
+
+	return clients, nil
+}
+
+// brise OIDC klijenta - neiskorisceni kodovi tog klijenta se vise ne mogu zameniti za tokene
+func (s *OIDCService) DeleteClient(clientID string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	result, err := s.clients.DeleteOne(ctx, bson.M{"client_id": clientID})
+	if err != nil {
+		return err
+	}
+
+	if result.DeletedCount == 0 {
+		return errors.New("client not found")
+	}
+
+	return nil
+}
+
+// vraca podatke za ekran odobravanja pristupa (naziv aplikacije i trazeni scope-ovi)
+// ako parametri zahteva nisu ispravni, vraca adresu na koju se korisnik preusmerava sa greskom
+func (s *OIDCService) AuthorizeDetails(req models.AuthorizeRequest) (*models.AuthorizeDetails, string, error) {
+	client, err := s.findClientForRedirect(req.ClientID, req.RedirectURI)
+	if err != nil {
+		return nil, "", err
+	}
+
+	scopes, oauthErr := validateAuthorizeRequest(req)
+	if oauthErr != nil {
+		return nil, errorRedirect(req.RedirectURI, req.State, oauthErr), nil
+	}
+
+	return &models.AuthorizeDetails{ClientName: client.Name, Scopes: scopes}, "", nil
+}
+
+// obradjuje odluku prijavljenog korisnika i vraca adresu na koju se preusmerava nazad ka klijentu
+// uz odobrenje adresa sadrzi jednokratni autorizacioni kod, a inace gresku access_denied
+func (s *OIDCService) Authorize(userID primitive.ObjectID, req models.AuthorizeRequest) (string, error) {
+	if _, err := s.findClientForRedirect(req.ClientID, req.RedirectURI); err != nil {
+		return "", err
+	}
+
+	scopes, oauthErr := validateAuthorizeRequest(req)
+	if oauthErr != nil {
+		return errorRedirect(req.RedirectURI, req.State, oauthErr), nil
+	}
+
+	if !req.Approve {
+		return errorRedirect(req.RedirectURI, req.State, &OAuthError{"access_denied", "the user denied the request"}), nil
+	}
+
+	code, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	record := models.AuthorizationCode{
+		ID:            primitive.NewObjectID(),
+		CodeHash:      utils.HashToken(code),
+		ClientID:      req.ClientID,
+		UserID:        userID,
+		RedirectURI:   req.RedirectURI,
+		Scope:         strings.Join(scopes, " "),
+		Nonce:         req.Nonce,
+		CodeChallenge: req.CodeChallenge,
+		ExpiresAt:     now.Add(s.codeTTL),
+		CreatedAt:     now,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if _, err := s.codes.InsertOne(ctx, record); err != nil {
+		return "", err
+	}
+
+	params := url.Values{}
+	params.Set("code", code)
+	if req.State != "" {
+		params.Set("state", req.State)
+	}
+
+	return appendQuery(req.RedirectURI, params), nil
+}
+
+// menja autorizacioni kod za access token i ID token
+// kod je jednokratan, vezan za klijenta i redirect_uri, a code_verifier mora odgovarati PKCE izazovu
+func (s *OIDCService) ExchangeCode(req models.CodeExchangeRequest) (*models.OIDCTokenResponse, error) {
+	client, err := s.authenticateClient(req.ClientID, req.ClientSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Code == "" || req.CodeVerifier == "" {
+		return nil, &OAuthError{"invalid_request", "code and code_verifier are required"}
+	}
+
+	record, err := s.consumeCode(req.Code)
+	if err != nil {
+		return nil, err
+	}
+
+	if record.ClientID != client.ClientID || record.RedirectURI != req.RedirectURI {
+		return nil, &OAuthError{"invalid_grant", "authorization code was issued to another client or redirect_uri"}
+	}
+
+	if !utils.VerifyPKCE(req.CodeVerifier, record.CodeChallenge) {
+		return nil, &OAuthError{"invalid_grant", "code_verifier does not match the code challenge"}
+	}
+
+	user, err := s.userService.GetUserByID(record.UserID)
+	if err != nil || user.Disabled {
+		return nil, &OAuthError{"invalid_grant", "user is not available"}
+	}
+
+	accessToken, err := utils.GenerateOIDCAccessToken(s.issuer, client.ClientID, user.ID, record.Scope, s.keys, s.accessTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	claims := userClaims(user, strings.Fields(record.Scope))
+	claims.Nonce = record.Nonce
+	claims.Issuer = s.issuer
+	claims.Audience = jwt.ClaimStrings{client.ClientID}
+	claims.ExpiresAt = jwt.NewNumericDate(now.Add(s.accessTTL))
+	claims.IssuedAt = jwt.NewNumericDate(now)
+
+	idToken, err := utils.GenerateIDToken(claims, s.keys)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.OIDCTokenResponse{
+		AccessToken: accessToken,
+		TokenType:   "Bearer",
+		ExpiresIn:   int64(s.accessTTL.Seconds()),
+		IDToken:     idToken,
+		Scope:       record.Scope,
+	}, nil
+}
+
+// vraca podatke o korisniku za access token izdat OIDC klijentu (userinfo endpoint)
+func (s *OIDCService) UserInfo(accessToken string) (*utils.IDTokenClaims, error) {
+	claims, err := utils.ValidateOIDCAccessToken(accessToken, s.issuer, s.keys)
+	if err != nil {
+		return nil, &OAuthError{"invalid_token", "access token is invalid or expired"}
+	}
+
+	userID, err := primitive.ObjectIDFromHex(claims.Subject)
+	if err != nil {
+		return nil, &OAuthError{"invalid_token", "access token is invalid or expired"}
+	}
+
+	user, err := s.userService.GetUserByID(userID)
+	if err != nil || user.Disabled {
+		return nil, &OAuthError{"invalid_token", "user is not available"}
+	}
+
+	return userClaims(user, strings.Fields(claims.Scope)), nil
+}
+
+// pronalazi klijenta i proverava da je redirect_uri tacno jedna od registrovanih adresa
+func (s *OIDCService) findClientForRedirect(clientID, redirectURI string) (*models.OAuthClient, error) {
+	client, err := s.findClient(clientID)
+	if err != nil {
+		return nil, ErrInvalidOAuthClient
+	}
+
+	for _, uri := range client.RedirectURIs {
+		if uri == redirectURI {
+			return client, nil
+		}
+	}
+
+	return nil, ErrInvalidOAuthClient
+}
+
+// proverava kredencijale klijenta na token endpointu - javni klijenti se identifikuju samo ID-em
+func (s *OIDCService) authenticateClient(clientID, clientSecret string) (*models.OAuthClient, error) {
+	client, err := s.findClient(clientID)
+	if err != nil {
+		return nil, &OAuthError{"invalid_client", "client authentication failed"}
+	}
+
+	if !client.Public {
+		provided := utils.HashToken(clientSecret)
+		if clientSecret == "" || subtle.ConstantTimeCompare([]byte(provided), []byte(client.SecretHash)) != 1 {
+			return nil, &OAuthError{"invalid_client", "client authentication failed"}
+		}
+	}
+
+	return client, nil
+}
+
+// pronalazi klijenta po ID-u
+func (s *OIDCService) findClient(clientID string) (*models.OAuthClient, error) {
+	if clientID == "" {
+		return nil, errors.New("client not found")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var client models.OAuthClient
+	if err := s.clients.FindOne(ctx, bson.M{"client_id": clientID}).Decode(&client); err != nil {
+		return nil, err
+	}
+
+	return &client, nil
+}
+
+// atomicno oznacava kod kao iskoriscen ako je vazeci i vraca ga
+func (s *OIDCService) consumeCode(code string) (*models.AuthorizationCode, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	now := time.Now()
+	filter := bson.M{
+		"code_hash":  utils.HashToken(code),
+		"used_at":    bson.M{"$exists": false},
+		"expires_at": bson.M{"$gt": now},
+	}
+
+	var record models.AuthorizationCode
+	err := s.codes.FindOneAndUpdate(ctx, filter, bson.M{"$set": bson.M{"used_at": now}}).Decode(&record)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, &OAuthError{"invalid_grant", "authorization code is invalid, expired or already used"}
+		}
+		return nil, err
+	}
+
+	return &record, nil
+}
+
+// proverava parametre zahteva za autorizaciju i vraca podrzane scope-ove koje je klijent trazio
+// zahteva se response_type=code, openid scope i PKCE izazov metodom S256
+func validateAuthorizeRequest(req models.AuthorizeRequest) ([]string, *OAuthError) {
+	if req.ResponseType != "code" {
+		return nil, &OAuthError{"unsupported_response_type", "only response_type=code is supported"}
+	}
+
+	if req.CodeChallenge == "" || req.CodeChallengeMethod != "S256" {
+		return nil, &OAuthError{"invalid_request", "PKCE with code_challenge_method=S256 is required"}
+	}
+
+	requested := strings.Fields(req.Scope)
+	scopes := make([]string, 0, len(supportedScopes))
+	for _, scope := range supportedScopes {
+		for _, r := range requested {
+			if r == scope {
+				scopes = append(scopes, scope)
+				break
+			}
+		}
+	}
+
+	if len(scopes) == 0 || scopes[0] != models.ScopeOpenID {
+		return nil, &OAuthError{"invalid_scope", "the openid scope is required"}
+	}
+
+	return scopes, nil
+}
+
+// popunjava podatke o korisniku prema odobrenim scope-ovima - sub se uvek salje
+func userClaims(user *models.User, scopes []string) *utils.IDTokenClaims {
+	claims := &utils.IDTokenClaims{}
+	claims.Subject = user.ID.Hex()
+
+	for _, scope := range scopes {
+		switch scope {
+		case models.ScopeProfile:
+			claims.Name = strings.TrimSpace(user.FirstName + " " + user.LastName)
+			claims.GivenName = user.FirstName
+			claims.FamilyName = user.LastName
+			claims.PreferredUsername = user.Username
+		case models.ScopeEmail:
+			verified := user.EmailVerified
+			claims.Email = user.Email
+			claims.EmailVerified = &verified
+		}
+	}
+
+	return claims
+}
+
+// pravi adresu za preusmeravanje ka klijentu sa OAuth greskom
+func errorRedirect(redirectURI, state string, oauthErr *OAuthError) string {
+	params := url.Values{}
+	params.Set("error", oauthErr.Code)
+	params.Set("error_description", oauthErr.Description)
+	if state != "" {
+		params.Set("state", state)
+	}
+	return appendQuery(redirectURI, params)
+}
+
+// dodaje query parametre na adresu koja vec moze imati svoje parametre
+func appendQuery(rawURL string, params url.Values) string {
+	separator := "?"
+	if strings.Contains(rawURL, "?") {
+		separator = "&"
+	}
+	return rawURL + separator + params.Encode()
+}
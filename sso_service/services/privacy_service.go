@@ -0,0 +1,255 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sso_service/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// PrivacyService - izvoz licnih podataka korisnika i brisanje naloga u svim servisima
+// podatke u st_dom servisu preuzima i anonimizuje preko internih ruta sa servisnim tokenom
+type PrivacyService struct {
+	refreshTokens      *mongo.Collection
+	accountTokens      *mongo.Collection
+	mfaChallenges      *mongo.Collection
+	loginAttempts      *mongo.Collection
+	authorizationCodes *mongo.Collection
+	federationStates   *mongo.Collection
+	apiKeys            *mongo.Collection
+	userService        *UserService
+	studentService     *StudentService
+	tokenService       *TokenService
+	serviceAuth        *ServiceAuthService
+	stDomServiceURL    string
+	httpClient         *http.Client
+}
+
+// kreira novi PrivacyService sa bazom, servisima za korisnike, studente i tokene,
+// servisnim tokenima za pozive st_dom servisa i URL-om st_dom servisa
+func NewPrivacyService(db *mongo.Database, userService *UserService, studentService *StudentService, tokenService *TokenService, serviceAuth *ServiceAuthService, stDomServiceURL string) *PrivacyService {
+	return &PrivacyService{
+		refreshTokens:      db.Collection("refresh_tokens"),
+		accountTokens:      db.Collection("account_tokens"),
+		mfaChallenges:      db.Collection("mfa_challenges"),
+		loginAttempts:      db.Collection("login_attempts"),
+		authorizationCodes: db.Collection("authorization_codes"),
+		federationStates:   db.Collection("federation_states"),
+		apiKeys:            db.Collection("api_keys"),
+		userService:        userService,
+		studentService:     studentService,
+		tokenService:       tokenService,
+		serviceAuth:        serviceAuth,
+		stDomServiceURL:    stDomServiceURL,
+		httpClient:         &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// pravi ZIP arhivu sa svim podacima o korisniku - po jedan JSON fajl za svaki servis
+// izvoz ne uspeva ako st_dom servis nije dostupan, da korisnik ne bi dobio nepotpune podatke
+func (s *PrivacyService) ExportPersonalData(userID primitive.ObjectID) ([]byte, error) {
+	ssoData, err := s.collectPersonalData(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	stDomData, err := s.callStDom(http.MethodGet, userID, "personal-data")
+	if err != nil {
+		return nil, err
+	}
+
+	ssoJSON, err := json.MarshalIndent(ssoData, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	var stDomJSON bytes.Buffer
+	if err := json.Indent(&stDomJSON, stDomData, "", "  "); err != nil {
+		return nil, err
+	}
+
+	var archive bytes.Buffer
+	writer := zip.NewWriter(&archive)
+	files := []struct {
+		name    string
+		content []byte
+	}{
+		{"sso_service.json", ssoJSON},
+		{"st_dom_service.json", stDomJSON.Bytes()},
+	}
+	for _, file := range files {
+		entry, err := writer.CreateHeader(&zip.FileHeader{Name: file.name, Method: zip.Deflate, Modified: ssoData.GeneratedAt})
+		if err != nil {
+			return nil, err
+		}
+		if _, err := entry.Write(file.content); err != nil {
+			return nil, err
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+
+	return archive.Bytes(), nil
+}
+
+// brise nalog korisnika - prvo anonimizuje podatke u st_dom servisu, pa tek onda brise korisnika
+// st_dom servis odbija brisanje dok korisnik ima sobu ili neplacene uplate i tada nalog ostaje netaknut
+// posle brisanja korisnika opozivaju se sesije i brisu ili anonimizuju ostali zapisi vezani za nalog
+func (s *PrivacyService) EraseAccount(userID primitive.ObjectID) error {
+	user, err := s.userService.GetUserByID(userID)
+	if err != nil {
+		return err
+	}
+
+	if _, err := s.callStDom(http.MethodPost, userID, "erase"); err != nil {
+		return err
+	}
+
+	if err := s.userService.DeleteUser(userID); err != nil {
+		return err
+	}
+
+	if err := s.tokenService.RevokeAllSessions(userID); err != nil {
+		log.Printf("Failed to revoke sessions of deleted user %s: %v", userID.Hex(), err)
+	}
+
+	s.removeAccountRecords(userID, user.Email)
+
+	return nil
+}
+
+// skuplja podatke o korisniku iz SSO baze
+func (s *PrivacyService) collectPersonalData(userID primitive.ObjectID) (*models.PersonalDataExport, error) {
+	user, err := s.userService.GetUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	export := &models.PersonalDataExport{
+		GeneratedAt:   time.Now(),
+		User:          user,
+		Sessions:      []models.RefreshToken{},
+		LoginAttempts: []models.LoginAttempt{},
+		AccountTokens: []models.AccountToken{},
+		APIKeys:       []models.APIKey{},
+	}
+
+	if user.Student != nil {
+		if _, export.JMBG, err = s.studentService.GetStudentDetails(userID); err != nil {
+			return nil, err
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	byCreatedAt := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}})
+
+	if err := findAll(ctx, s.refreshTokens, bson.M{"user_id": userID}, byCreatedAt, &export.Sessions); err != nil {
+		return nil, err
+	}
+	if err := findAll(ctx, s.loginAttempts, bson.M{"user_id": userID}, byCreatedAt, &export.LoginAttempts); err != nil {
+		return nil, err
+	}
+	if err := findAll(ctx, s.accountTokens, bson.M{"user_id": userID}, byCreatedAt, &export.AccountTokens); err != nil {
+		return nil, err
+	}
+	if err := findAll(ctx, s.apiKeys, bson.M{"user_id": userID}, byCreatedAt, &export.APIKeys); err != nil {
+		return nil, err
+	}
+
+	return export, nil
+}
+
+// brise tokene, izazove, zapocete prijave i API kljuceve obrisanog korisnika
+// dnevnik prijava se ne brise nego anonimizuje - ishodi i vremena ostaju za statistiku
+// greske se samo loguju jer je korisnik vec obrisan
+func (s *PrivacyService) removeAccountRecords(userID primitive.ObjectID, email string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	for _, collection := range []*mongo.Collection{s.refreshTokens, s.accountTokens, s.mfaChallenges, s.authorizationCodes, s.federationStates, s.apiKeys} {
+		if _, err := collection.DeleteMany(ctx, bson.M{"user_id": userID}); err != nil {
+			log.Printf("Failed to delete %s of deleted user %s: %v", collection.Name(), userID.Hex(), err)
+		}
+	}
+
+	_, err := s.loginAttempts.UpdateMany(ctx,
+		bson.M{"$or": []bson.M{{"user_id": userID}, {"email": email}}},
+		bson.M{
+			"$set":   bson.M{"email": "", "ip_address": "", "user_agent": ""},
+			"$unset": bson.M{"user_id": ""},
+		},
+	)
+	if err != nil {
+		log.Printf("Failed to anonymize login attempts of deleted user %s: %v", userID.Hex(), err)
+	}
+}
+
+// poziva internu rutu st_dom servisa za korisnika i vraca telo odgovora
+// odbijen zahtev (409) vraca poruku st_dom servisa kao razlog zasto nalog ne moze biti obrisan
+func (s *PrivacyService) callStDom(method string, userID primitive.ObjectID, action string) ([]byte, error) {
+	url := fmt.Sprintf("%s/api/v1/internal/users/%s/%s", s.stDomServiceURL, userID.Hex(), action)
+
+	// interne rute st_dom servisa prihvataju samo servisni token
+	token, err := s.serviceAuth.SelfToken()
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest(method, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, errors.New("dormitory service is unavailable: " + err.Error())
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusConflict {
+		var result struct {
+			Error string `json:"error"`
+		}
+		if err := json.Unmarshal(body, &result); err == nil && result.Error != "" {
+			return nil, errors.New("cannot delete account: " + result.Error)
+		}
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("dormitory service returned status %d", resp.StatusCode)
+	}
+
+	return body, nil
+}
+
+// ucitava sve dokumente koji odgovaraju filteru
+func findAll(ctx context.Context, collection *mongo.Collection, filter bson.M, findOptions *options.FindOptions, results interface{}) error {
+	cursor, err := collection.Find(ctx, filter, findOptions)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	return cursor.All(ctx, results)
+}
@@ -0,0 +1,73 @@
+package services
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"errors"
+	"sso_service/models"
+	"sso_service/utils"
+	"time"
+)
+
+// ErrInvalidClient - servis sa datim ID-em ne postoji ili tajna nije ispravna
+var ErrInvalidClient = errors.New("invalid client credentials")
+
+// ServiceAuthService - izdaje servisne tokene (client credentials) kojima se servisi predstavljaju jedni drugima
+// interne rute svih servisa prihvataju samo ove tokene
+type ServiceAuthService struct {
+	keys *utils.KeySet
+	// hesevi tajni registrovanih servisa po ID-u klijenta
+	clients      map[string][32]byte
+	selfClientID string
+	ttl          time.Duration
+}
+
+// kreira novi ServiceAuthService sa kljucevima za potpis, registrovanim servisima (ID -> tajna),
+// ID-em pod kojim se sam SSO servis predstavlja i trajanjem tokena
+func NewServiceAuthService(keys *utils.KeySet, clients map[string]string, selfClientID string, ttl time.Duration) *ServiceAuthService {
+	hashed := make(map[string][32]byte, len(clients))
+	for clientID, secret := range clients {
+		hashed[clientID] = sha256.Sum256([]byte(secret))
+	}
+
+	return &ServiceAuthService{
+		keys:         keys,
+		clients:      hashed,
+		selfClientID: selfClientID,
+		ttl:          ttl,
+	}
+}
+
+// proverava kredencijale servisa i izdaje mu token
+func (s *ServiceAuthService) IssueToken(clientID, clientSecret string) (*models.ServiceToken, error) {
+	expected, exists := s.clients[clientID]
+	provided := sha256.Sum256([]byte(clientSecret))
+	if !exists || clientSecret == "" || subtle.ConstantTimeCompare(expected[:], provided[:]) != 1 {
+		return nil, ErrInvalidClient
+	}
+
+	return s.issue(clientID)
+}
+
+// izdaje token kojim se SSO servis predstavlja ostalim servisima
+func (s *ServiceAuthService) SelfToken() (string, error) {
+	token, err := s.issue(s.selfClientID)
+	if err != nil {
+		return "", err
+	}
+	return token.AccessToken, nil
+}
+
+// potpisuje servisni token za klijenta
+func (s *ServiceAuthService) issue(clientID string) (*models.ServiceToken, error) {
+	signed, _, err := utils.GenerateServiceJWT(clientID, s.keys, s.ttl)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.ServiceToken{
+		AccessToken: signed,
+		TokenType:   "Bearer",
+		ExpiresIn:   int64(s.ttl.Seconds()),
+	}, nil
+}
@@ -0,0 +1,251 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sso_service/models"
+	"sso_service/utils"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// greske pri radu sa podacima o studiranju
+var (
+	ErrStudentProfileNotFound = errors.New("student profile not found")
+	ErrIndexNumberTaken       = errors.New("index number is already registered at this faculty")
+	ErrInvalidJMBG            = errors.New("invalid JMBG")
+)
+
+// StudentService - podaci o studiranju korisnika (broj indeksa, fakultet, JMBG...) i njihova potvrda
+type StudentService struct {
+	collection *mongo.Collection
+	// kljuc za sifrovanje JMBG-a - nil znaci da se JMBG ne moze uneti
+	encryptionKey []byte
+}
+
+// kreira novi StudentService sa kolekcijom korisnika i kljucem za sifrovanje JMBG-a
+func NewStudentService(collection *mongo.Collection, encryptionKey []byte) *StudentService {
+	return &StudentService{
+		collection:    collection,
+		encryptionKey: encryptionKey,
+	}
+}
+
+// kreira indeks koji sprecava da se isti broj indeksa na istom fakultetu veze za vise naloga
+func (s *StudentService) EnsureIndexes() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err := s.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "student.faculty", Value: 1}, {Key: "student.index_number", Value: 1}},
+		Options: options.Index().
+			SetUnique(true).
+			SetPartialFilterExpression(bson.M{"student.index_number": bson.M{"$exists": true}}),
+	})
+	return err
+}
+
+// cuva podatke o studiranju koje je uneo student
+// ako se bilo sta promeni, ranija potvrda administratora prestaje da vazi
+func (s *StudentService) UpdateStudentProfile(userID primitive.ObjectID, req models.UpdateStudentProfileRequest) (*models.User, error) {
+	user, err := s.getUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	profile := models.StudentProfile{}
+	if user.Student != nil {
+		profile = *user.Student
+	}
+	previous := profile
+
+	profile.IndexNumber = strings.TrimSpace(req.IndexNumber)
+	profile.Faculty = strings.TrimSpace(req.Faculty)
+	profile.StudyProgram = strings.TrimSpace(req.StudyProgram)
+	profile.YearOfStudy = req.YearOfStudy
+	profile.Gender = req.Gender
+
+	jmbgChanged := false
+	if req.JMBG != nil {
+		jmbg := strings.TrimSpace(*req.JMBG)
+		if jmbg == "" {
+			jmbgChanged = profile.HasJMBG
+			profile.JMBGEncrypted = ""
+			profile.HasJMBG = false
+		} else {
+			if !validJMBG(jmbg) {
+				return nil, ErrInvalidJMBG
+			}
+			if current, err := s.decryptJMBG(&previous); err != nil || current != jmbg {
+				encrypted, err := utils.EncryptField(s.encryptionKey, jmbg)
+				if err != nil {
+					return nil, err
+				}
+				profile.JMBGEncrypted = encrypted
+				profile.HasJMBG = true
+				jmbgChanged = true
+			}
+		}
+	}
+
+	if user.Student == nil || jmbgChanged ||
+		profile.IndexNumber != previous.IndexNumber ||
+		profile.Faculty != previous.Faculty ||
+		profile.StudyProgram != previous.StudyProgram ||
+		profile.YearOfStudy != previous.YearOfStudy ||
+		profile.Gender != previous.Gender {
+		profile.Verified = false
+		profile.VerifiedAt = nil
+		profile.VerifiedBy = nil
+	}
+	profile.UpdatedAt = time.Now()
+
+	if err := s.saveProfile(userID, profile); err != nil {
+		return nil, err
+	}
+
+	user.Student = &profile
+	return user, nil
+}
+
+// potvrdjuje ili ponistava potvrdu podataka o studiranju - administratorska akcija
+func (s *StudentService) SetVerified(actorID, userID primitive.ObjectID, verified bool) (*models.User, error) {
+	user, err := s.getUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.Student == nil {
+		return nil, ErrStudentProfileNotFound
+	}
+
+	profile := *user.Student
+	profile.Verified = verified
+	if verified {
+		now := time.Now()
+		profile.VerifiedAt = &now
+		profile.VerifiedBy = &actorID
+	} else {
+		profile.VerifiedAt = nil
+		profile.VerifiedBy = nil
+	}
+
+	if err := s.saveProfile(userID, profile); err != nil {
+		return nil, err
+	}
+
+	user.Student = &profile
+	return user, nil
+}
+
+// vraca podatke o studentu za ostale servise - JMBG se ne salje
+func (s *StudentService) GetStudentIdentity(userID primitive.ObjectID) (*models.StudentIdentity, error) {
+	user, err := s.getUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.Student == nil {
+		return nil, ErrStudentProfileNotFound
+	}
+
+	return &models.StudentIdentity{
+		UserID:    user.ID,
+		FirstName: user.FirstName,
+		LastName:  user.LastName,
+		Student:   *user.Student,
+	}, nil
+}
+
+// vraca podatke o studiranju sa desifrovanim JMBG-om - samo za administratore koji potvrdjuju podatke
+func (s *StudentService) GetStudentDetails(userID primitive.ObjectID) (*models.StudentProfile, string, error) {
+	user, err := s.getUser(userID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if user.Student == nil {
+		return nil, "", ErrStudentProfileNotFound
+	}
+
+	jmbg := ""
+	if user.Student.HasJMBG {
+		if jmbg, err = s.decryptJMBG(user.Student); err != nil {
+			return nil, "", err
+		}
+	}
+
+	return user.Student, jmbg, nil
+}
+
+// desifruje JMBG iz profila - prazan string ako JMBG nije unet
+func (s *StudentService) decryptJMBG(profile *models.StudentProfile) (string, error) {
+	if profile.JMBGEncrypted == "" {
+		return "", nil
+	}
+	return utils.DecryptField(s.encryptionKey, profile.JMBGEncrypted)
+}
+
+// pronalazi korisnika po ID-u
+func (s *StudentService) getUser(userID primitive.ObjectID) (*models.User, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var user models.User
+	err := s.collection.FindOne(ctx, bson.M{"_id": userID}).Decode(&user)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.New("user not found")
+		}
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+// cuva podatke o studiranju na nalogu korisnika
+func (s *StudentService) saveProfile(userID primitive.ObjectID, profile models.StudentProfile) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err := s.collection.UpdateOne(ctx,
+		bson.M{"_id": userID},
+		bson.M{"$set": bson.M{"student": profile, "updated_at": time.Now()}},
+	)
+	if mongo.IsDuplicateKeyError(err) {
+		return ErrIndexNumberTaken
+	}
+	return err
+}
+
+// proverava format i kontrolnu cifru JMBG-a (13 cifara, modul 11)
+func validJMBG(jmbg string) bool {
+	if len(jmbg) != 13 {
+		return false
+	}
+
+	digits := make([]int, 13)
+	for i, r := range jmbg {
+		if r < '0' || r > '9' {
+			return false
+		}
+		digits[i] = int(r - '0')
+	}
+
+	sum := 0
+	for i := 0; i < 6; i++ {
+		sum += (7 - i) * (digits[i] + digits[i+6])
+	}
+
+	control := 11 - sum%11
+	if control > 9 {
+		control = 0
+	}
+
+	return control == digits[12]
+}
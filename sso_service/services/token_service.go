@@ -0,0 +1,281 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sso_service/models"
+	"sso_service/utils"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ErrInvalidRefreshToken - refresh token ne postoji, istekao je ili je opozvan
+var ErrInvalidRefreshToken = errors.New("invalid or expired refresh token")
+
+// TokenService - izdaje, rotira i opoziva tokene
+// access tokeni su kratkotrajni JWT-ovi, refresh tokeni su neprozirni i cuvaju se kao hes
+type TokenService struct {
+	refreshTokens   *mongo.Collection
+	revokedTokens   *mongo.Collection
+	keys            *utils.KeySet
+	accessTokenTTL  time.Duration
+	refreshTokenTTL time.Duration
+}
+
+// kreira novi TokenService sa kolekcijama za refresh tokene i opozvane access tokene
+func NewTokenService(refreshTokens, revokedTokens *mongo.Collection, keys *utils.KeySet, accessTokenTTL, refreshTokenTTL time.Duration) *TokenService {
+	return &TokenService{
+		refreshTokens:   refreshTokens,
+		revokedTokens:   revokedTokens,
+		keys:            keys,
+		accessTokenTTL:  accessTokenTTL,
+		refreshTokenTTL: refreshTokenTTL,
+	}
+}
+
+// kreira indekse - jedinstven hes refresh tokena i TTL indekse koji brisu istekle zapise
+func (s *TokenService) EnsureIndexes() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err := s.refreshTokens.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "token_hash", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "user_id", Value: 1}}},
+		{Keys: bson.D{{Key: "session_id", Value: 1}}},
+		{Keys: bson.D{{Key: "expires_at", Value: 1}}, Options: options.Index().SetExpireAfterSeconds(0)},
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = s.revokedTokens.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "jti", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "expires_at", Value: 1}}, Options: options.Index().SetExpireAfterSeconds(0)},
+	})
+	return err
+}
+
+// izdaje par tokena za novu sesiju (prijava)
+func (s *TokenService) IssueTokens(user *models.User, client models.ClientInfo) (*models.TokenPair, error) {
+	pair, _, err := s.issue(user, primitive.NewObjectID().Hex(), client)
+	return pair, err
+}
+
+// izdaje access token i refresh token u okviru postojece sesije
+// vraca i ID zapisa novog refresh tokena
+func (s *TokenService) issue(user *models.User, sessionID string, client models.ClientInfo) (*models.TokenPair, primitive.ObjectID, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// opseg domova ima smisla samo za administratore
+	var stDomIDs []string
+	superAdmin := false
+	if user.Role == models.RoleAdmin {
+		stDomIDs = user.ManagedStDomIDs
+		superAdmin = user.SuperAdmin
+	}
+
+	accessToken, claims, err := utils.GenerateJWT(user.ID, user.Username, user.Email, user.Role, stDomIDs, superAdmin, sessionID, s.keys, s.accessTokenTTL)
+	if err != nil {
+		return nil, primitive.NilObjectID, err
+	}
+
+	refreshToken, err := utils.GenerateOpaqueToken()
+	if err != nil {
+		return nil, primitive.NilObjectID, err
+	}
+
+	now := time.Now()
+	record := models.RefreshToken{
+		ID:              primitive.NewObjectID(),
+		UserID:          user.ID,
+		SessionID:       sessionID,
+		TokenHash:       utils.HashToken(refreshToken),
+		AccessTokenID:   claims.ID,
+		AccessExpiresAt: claims.ExpiresAt.Time,
+		IPAddress:       client.IPAddress,
+		UserAgent:       client.UserAgent,
+		ExpiresAt:       now.Add(s.refreshTokenTTL),
+		CreatedAt:       now,
+	}
+
+	if _, err := s.refreshTokens.InsertOne(ctx, record); err != nil {
+		return nil, primitive.NilObjectID, err
+	}
+
+	return &models.TokenPair{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int64(s.accessTokenTTL.Seconds()),
+	}, record.ID, nil
+}
+
+// proverava refresh token i vraca njegov zapis
+// ako je vec iskoriscen (opozvan) token ponovo poslat, smatra se ukradenim i cela sesija se opoziva
+func (s *TokenService) ValidateRefreshToken(refreshToken string) (*models.RefreshToken, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var record models.RefreshToken
+	err := s.refreshTokens.FindOne(ctx, bson.M{"token_hash": utils.HashToken(refreshToken)}).Decode(&record)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, ErrInvalidRefreshToken
+		}
+		return nil, err
+	}
+
+	if record.RevokedAt != nil {
+		if err := s.RevokeSession(record.UserID, record.SessionID); err != nil {
+			return nil, err
+		}
+		return nil, ErrInvalidRefreshToken
+	}
+
+	if time.Now().After(record.ExpiresAt) {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	return &record, nil
+}
+
+// rotira refresh token - stari se opoziva, a izdaje se novi par u istoj sesiji
+// korisnik se prosledjuje ponovo da bi access token imao azurnu ulogu
+func (s *TokenService) RotateRefreshToken(record *models.RefreshToken, user *models.User, client models.ClientInfo) (*models.TokenPair, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	now := time.Now()
+	result, err := s.refreshTokens.UpdateOne(ctx,
+		bson.M{"_id": record.ID, "revoked_at": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"revoked_at": now}},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	// dva istovremena osvezavanja istim tokenom - drugo se tretira kao ponovna upotreba
+	if result.ModifiedCount == 0 {
+		if err := s.RevokeSession(record.UserID, record.SessionID); err != nil {
+			return nil, err
+		}
+		return nil, ErrInvalidRefreshToken
+	}
+
+	pair, replacementID, err := s.issue(user, record.SessionID, client)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = s.refreshTokens.UpdateOne(ctx, bson.M{"_id": record.ID}, bson.M{"$set": bson.M{"replaced_by": replacementID}})
+	if err != nil {
+		return nil, err
+	}
+
+	return pair, nil
+}
+
+// opoziva sesiju - sve refresh tokene sesije i access tokene izdate uz njih
+func (s *TokenService) RevokeSession(userID primitive.ObjectID, sessionID string) error {
+	return s.revokeWhere(userID, bson.M{"user_id": userID, "session_id": sessionID})
+}
+
+// opoziva sve sesije korisnika (odjava sa svih uredjaja)
+func (s *TokenService) RevokeAllSessions(userID primitive.ObjectID) error {
+	return s.revokeWhere(userID, bson.M{"user_id": userID})
+}
+
+// opoziva sve sesije korisnika osim trenutne (npr. posle promene lozinke)
+func (s *TokenService) RevokeOtherSessions(userID primitive.ObjectID, keepSessionID string) error {
+	return s.revokeWhere(userID, bson.M{"user_id": userID, "session_id": bson.M{"$ne": keepSessionID}})
+}
+
+// opoziva refresh tokene koji odgovaraju filteru i dodaje njihove access tokene na listu opozvanih
+func (s *TokenService) revokeWhere(userID primitive.ObjectID, filter bson.M) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	now := time.Now()
+
+	// access tokeni izdati uz ranije (vec rotirane) refresh tokene mogu i dalje biti vazeci
+	accessFilter := bson.M{"access_expires_at": bson.M{"$gt": now}}
+	revokeFilter := bson.M{"revoked_at": bson.M{"$exists": false}}
+	for key, value := range filter {
+		accessFilter[key] = value
+		revokeFilter[key] = value
+	}
+
+	cursor, err := s.refreshTokens.Find(ctx, accessFilter)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	var records []models.RefreshToken
+	if err = cursor.All(ctx, &records); err != nil {
+		return err
+	}
+
+	for _, record := range records {
+		if err := s.RevokeAccessToken(userID, record.AccessTokenID, record.AccessExpiresAt); err != nil {
+			return err
+		}
+	}
+
+	_, err = s.refreshTokens.UpdateMany(ctx, revokeFilter, bson.M{"$set": bson.M{"revoked_at": now}})
+	return err
+}
+
+// dodaje access token (jti) na listu opozvanih do njegovog isteka
+func (s *TokenService) RevokeAccessToken(userID primitive.ObjectID, jti string, expiresAt time.Time) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err := s.revokedTokens.UpdateOne(ctx,
+		bson.M{"jti": jti},
+		bson.M{"$setOnInsert": models.RevokedToken{
+			JTI:       jti,
+			UserID:    userID,
+			ExpiresAt: expiresAt,
+			RevokedAt: time.Now(),
+		}},
+		options.Update().SetUpsert(true),
+	)
+	return err
+}
+
+// proverava da li je access token opozvan
+func (s *TokenService) IsAccessTokenRevoked(jti string) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	count, err := s.revokedTokens.CountDocuments(ctx, bson.M{"jti": jti})
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// vraca sve opozvane access tokene koji jos nisu istekli
+// koriste ga ostali servisi da bi odrzavali svoju kopiju liste opozvanih tokena
+func (s *TokenService) GetActiveRevokedTokens() ([]models.RevokedToken, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	cursor, err := s.revokedTokens.Find(ctx, bson.M{"expires_at": bson.M{"$gt": time.Now()}})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	revoked := []models.RevokedToken{}
+	if err = cursor.All(ctx, &revoked); err != nil {
+		return nil, err
+	}
+
+	return revoked, nil
+}
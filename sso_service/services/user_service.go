@@ -2,10 +2,8 @@ package services
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
-	"fmt"
-	"net/http"
+	"regexp"
 	"sso_service/models"
 	"sso_service/utils"
 	"time"
@@ -13,27 +11,88 @@ import (
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 // UserService - rukuje operacijama vezanim za korisnike
 type UserService struct {
-	collection      *mongo.Collection
-	jwtSecret       string
-	stDomServiceURL string
+	collection               *mongo.Collection
+	tokenService             *TokenService
+	mfaService               *MFAService
+	loginAttempts            *LoginAttemptService
+	requireEmailVerification bool
+	localLoginAdminsOnly     bool
 }
 
-// kreira novi UserService sa kolekcijom baze, JWT tajnim kljucem i URL-om st_dom servisa
-func NewUserService(collection *mongo.Collection, jwtSecret string, stDomServiceURL string) *UserService {
+// greske pri prijavi koje nisu posledica pogresne lozinke
+var (
+	ErrEmailNotVerified      = errors.New("email address is not verified")
+	ErrAccountDisabled       = errors.New("account is disabled")
+	ErrPasswordResetRequired = errors.New("password reset required, check your email for the reset link")
+	ErrLocalLoginDisabled    = errors.New("password login is disabled, sign in with your university account")
+)
+
+// kreira novi UserService sa kolekcijom baze, servisom za tokene, servisom za drugi faktor i dnevnikom prijava
+// requireEmailVerification odredjuje da li se korisnici sa nepotvrdjenim email-om mogu prijaviti
+// localLoginAdminsOnly iskljucuje registraciju i prijavu lozinkom za sve osim administratora
+// (studenti se tada prijavljuju univerzitetskim nalogom)
+func NewUserService(collection *mongo.Collection, tokenService *TokenService, mfaService *MFAService, loginAttempts *LoginAttemptService, requireEmailVerification, localLoginAdminsOnly bool) *UserService {
 	return &UserService{
-		collection:      collection,
-		jwtSecret:       jwtSecret,
-		stDomServiceURL: stDomServiceURL,
+		collection:               collection,
+		tokenService:             tokenService,
+		mfaService:               mfaService,
+		loginAttempts:            loginAttempts,
+		requireEmailVerification: requireEmailVerification,
+		localLoginAdminsOnly:     localLoginAdminsOnly,
 	}
 }
 
+// administratori bez domova kreirani pre uvodjenja super_admin polja su bili globalni administratori,
+// pa im se to pravo dodeljuje eksplicitno; ostali postojeci nalozi dobijaju super_admin = false
+// da kasnija promena uloge ili domova ne bi dala globalni pristup
+func (s *UserService) MarkLegacySuperAdmins() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err := s.collection.UpdateMany(ctx,
+		bson.M{
+			"super_admin":        bson.M{"$exists": false},
+			"role":               models.RoleAdmin,
+			"managed_st_dom_ids": bson.M{"$in": bson.A{nil, bson.A{}}},
+		},
+		bson.M{"$set": bson.M{"super_admin": true}},
+	)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.collection.UpdateMany(ctx,
+		bson.M{"super_admin": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"super_admin": false}},
+	)
+	return err
+}
+
+// oznacava naloge kreirane pre uvodjenja verifikacije email-a kao potvrdjene
+// da postojeci korisnici ne bi ostali bez pristupa
+func (s *UserService) MarkLegacyUsersVerified() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err := s.collection.UpdateMany(ctx,
+		bson.M{"email_verified": bson.M{"$exists": false}},
+		bson.M{"$set": bson.M{"email_verified": true}},
+	)
+	return err
+}
+
 // registruje novog korisnika - proverava da li vec postoji, hesuje lozinku i cuva u bazu
 // vraca gresku ako korisnik sa istim email-om ili korisnickim imenom vec postoji
 func (s *UserService) RegisterUser(req models.RegisterRequest) (*models.User, error) {
+	if s.localLoginAdminsOnly {
+		return nil, ErrLocalLoginDisabled
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 	var existingUser models.User
@@ -67,37 +126,112 @@ func (s *UserService) RegisterUser(req models.RegisterRequest) (*models.User, er
 	return &user, nil
 }
 
-// prijavljuje korisnika - proverava email i lozinku, generiše JWT token
-// vraca token i podatke o korisniku ako su podaci ispravni
-func (s *UserService) LoginUser(req models.LoginRequest) (*models.LoginResponse, error) {
+// prijavljuje korisnika - proverava email i lozinku, izdaje access i refresh token
+// vraca tokene i podatke o korisniku ako su podaci ispravni
+// ako korisnik ima TOTP (ili je administrator) tokeni se ne izdaju, vec se vraca MFA izazov
+// svaki pokusaj se belezi u dnevnik; posle vise neuspelih pokusaja prijava se usporava pa zakljucava
+func (s *UserService) LoginUser(req models.LoginRequest, client models.ClientInfo) (*models.LoginResponse, error) {
+	if err := s.loginAttempts.Check(req.Email, client.IPAddress); err != nil {
+		s.loginAttempts.Record(nil, req.Email, client, models.LoginOutcomeLockedOut)
+		return nil, err
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 	var user models.User
 	err := s.collection.FindOne(ctx, bson.M{"email": req.Email}).Decode(&user)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
+			s.loginAttempts.Record(nil, req.Email, client, models.LoginOutcomeInvalidCredentials)
 			return nil, errors.New("invalid email or password")
 		}
 		return nil, err
 	}
 
 	if !utils.CheckPasswordHash(req.Password, user.Password) {
+		s.loginAttempts.Record(&user.ID, req.Email, client, models.LoginOutcomeInvalidCredentials)
 		return nil, errors.New("invalid email or password")
 	}
 
-	token, err := utils.GenerateJWT(user.ID, user.Username, user.Email, user.Role, s.jwtSecret)
+	if s.localLoginAdminsOnly && user.Role != models.RoleAdmin {
+		s.loginAttempts.Record(&user.ID, req.Email, client, models.LoginOutcomeLocalLoginDisabled)
+		return nil, ErrLocalLoginDisabled
+	}
+
+	if user.Disabled {
+		s.loginAttempts.Record(&user.ID, req.Email, client, models.LoginOutcomeAccountDisabled)
+		return nil, ErrAccountDisabled
+	}
+
+	if user.PasswordResetRequired {
+		s.loginAttempts.Record(&user.ID, req.Email, client, models.LoginOutcomePasswordResetRequired)
+		return nil, ErrPasswordResetRequired
+	}
+
+	if s.requireEmailVerification && !user.EmailVerified {
+		s.loginAttempts.Record(&user.ID, req.Email, client, models.LoginOutcomeEmailNotVerified)
+		return nil, ErrEmailNotVerified
+	}
+
+	if s.mfaService.IsRequired(&user) {
+		challenge, err := s.mfaService.CreateChallenge(&user)
+		if err != nil {
+			return nil, err
+		}
+		s.loginAttempts.Record(&user.ID, req.Email, client, models.LoginOutcomeMFAChallenge)
+		return &models.LoginResponse{MFA: challenge}, nil
+	}
+
+	tokens, err := s.tokenService.IssueTokens(&user, client)
 	if err != nil {
 		return nil, err
 	}
 
-	user.Password = ""
+	s.loginAttempts.Record(&user.ID, req.Email, client, models.LoginOutcomeSuccess)
 
 	return &models.LoginResponse{
-		Token: token,
-		User:  user,
+		TokenPair: *tokens,
+		User:      sanitizeUser(user),
 	}, nil
 }
 
+// osvezava tokene - proverava refresh token, rotira ga i izdaje novi access token
+// podaci o korisniku se ponovo citaju iz baze da bi token imao azurnu ulogu
+// administratori bez ukljucenog TOTP-a moraju ponovo da se prijave i podese ga
+func (s *UserService) RefreshTokens(refreshToken string, client models.ClientInfo) (*models.TokenPair, error) {
+	record, err := s.tokenService.ValidateRefreshToken(refreshToken)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.GetUserByID(record.UserID)
+	if err != nil || user.Disabled || user.PasswordResetRequired || (user.Role == models.RoleAdmin && !user.TOTPEnabled) {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	return s.tokenService.RotateRefreshToken(record, user, client)
+}
+
+// odjavljuje korisnika - opoziva trenutnu sesiju i access token kojim je zahtev poslat
+func (s *UserService) Logout(claims *utils.JWTClaims) error {
+	if claims.SessionID != "" {
+		if err := s.tokenService.RevokeSession(claims.UserID, claims.SessionID); err != nil {
+			return err
+		}
+	}
+
+	return s.revokeCurrentAccessToken(claims)
+}
+
+// odjavljuje korisnika sa svih uredjaja - opoziva sve sesije i trenutni access token
+func (s *UserService) LogoutAllSessions(claims *utils.JWTClaims) error {
+	if err := s.tokenService.RevokeAllSessions(claims.UserID); err != nil {
+		return err
+	}
+
+	return s.revokeCurrentAccessToken(claims)
+}
+
 // dobija korisnika po ID-u iz baze podataka
 // vraca podatke o korisniku bez lozinke
 func (s *UserService) GetUserByID(userID primitive.ObjectID) (*models.User, error) {
@@ -117,11 +251,109 @@ func (s *UserService) GetUserByID(userID primitive.ObjectID) (*models.User, erro
 	return &user, nil
 }
 
-// brise korisnikov nalog - prvo poziva st_dom_service da proveri da li ima aktivnu sobu
-// ne dozvoljava brisanje ako korisnik ima dodeljenu sobu
-func (s *UserService) DeleteUser(userID primitive.ObjectID) error {
+// dobija korisnika po email adresi
+// vraca podatke o korisniku bez lozinke
+func (s *UserService) GetUserByEmail(email string) (*models.User, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var user models.User
+	err := s.collection.FindOne(ctx, bson.M{"email": email}).Decode(&user)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.New("user not found")
+		}
+		return nil, err
+	}
+
+	user.Password = ""
+	return &user, nil
+}
+
+// oznacava email adresu korisnika kao potvrdjenu
+// adresa se potvrdjuje samo ako se nije promenila od slanja tokena
+func (s *UserService) MarkEmailVerified(userID primitive.ObjectID, email string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	now := time.Now()
+	result, err := s.collection.UpdateOne(ctx,
+		bson.M{"_id": userID, "email": email},
+		bson.M{"$set": bson.M{"email_verified": true, "email_verified_at": now, "updated_at": now}},
+	)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return errors.New("user not found")
+	}
+
+	return nil
+}
+
+// menja ime i prezime korisnika - polja koja nisu prosledjena ostaju ista
+func (s *UserService) UpdateProfile(userID primitive.ObjectID, firstName, lastName *string) (*models.User, error) {
+	fields := bson.M{}
+	if firstName != nil {
+		fields["first_name"] = *firstName
+	}
+	if lastName != nil {
+		fields["last_name"] = *lastName
+	}
+
+	if len(fields) > 0 {
+		if err := s.updateUser(userID, fields); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.GetUserByID(userID)
+}
+
+// cuva novu email adresu koja ceka potvrdu - adresa ne sme pripadati drugom nalogu
+func (s *UserService) SetPendingEmail(userID primitive.ObjectID, email string) error {
+	if err := s.checkEmailAvailable(userID, email); err != nil {
+		return err
+	}
+
+	return s.updateUser(userID, bson.M{"pending_email": email})
+}
+
+// potvrdjuje promenu email adrese - privremena adresa postaje glavna i oznacava se kao potvrdjena
+// promena se primenjuje samo ako je potvrdjena adresa i dalje ona koja ceka potvrdu
+func (s *UserService) ConfirmEmailChange(userID primitive.ObjectID, email string) error {
+	if err := s.checkEmailAvailable(userID, email); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	now := time.Now()
+	result, err := s.collection.UpdateOne(ctx,
+		bson.M{"_id": userID, "pending_email": email},
+		bson.M{
+			"$set":   bson.M{"email": email, "email_verified": true, "email_verified_at": now, "updated_at": now},
+			"$unset": bson.M{"pending_email": ""},
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return ErrInvalidAccountToken
+	}
+
+	return nil
+}
+
+// proverava trenutnu lozinku korisnika
+func (s *UserService) VerifyPassword(userID primitive.ObjectID, password string) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
+
 	var user models.User
 	err := s.collection.FindOne(ctx, bson.M{"_id": userID}).Decode(&user)
 	if err != nil {
@@ -131,50 +363,278 @@ func (s *UserService) DeleteUser(userID primitive.ObjectID) error {
 		return err
 	}
 
-	hasActiveRoom, err := s.checkUserHasActiveRoom(userID)
+	if !utils.CheckPasswordHash(password, user.Password) {
+		return errors.New("current password is incorrect")
+	}
+
+	return nil
+}
+
+// proverava da email adresu ne koristi neki drugi nalog
+func (s *UserService) checkEmailAvailable(userID primitive.ObjectID, email string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	count, err := s.collection.CountDocuments(ctx, bson.M{"email": email, "_id": bson.M{"$ne": userID}})
 	if err != nil {
-		return errors.New("failed to verify room status with dormitory service: " + err.Error())
+		return err
 	}
 
-	if hasActiveRoom {
-		return errors.New("cannot delete account: you must check out from your assigned room first")
+	if count > 0 {
+		return errors.New("email address is already in use")
 	}
 
-	result, err := s.collection.DeleteOne(ctx, bson.M{"_id": userID})
+	return nil
+}
+
+// postavlja novu lozinku korisniku (hesuje je pre cuvanja) i uklanja zahtev za promenu lozinke
+func (s *UserService) UpdatePassword(userID primitive.ObjectID, password string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	hashedPassword, err := utils.HashPassword(password)
 	if err != nil {
 		return err
 	}
 
-	if result.DeletedCount == 0 {
+	result, err := s.collection.UpdateOne(ctx,
+		bson.M{"_id": userID},
+		bson.M{"$set": bson.M{"password": hashedPassword, "password_reset_required": false, "updated_at": time.Now()}},
+	)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
 		return errors.New("user not found")
 	}
 
 	return nil
 }
 
-// poziva st_dom_service da proveri da li korisnik ima aktivnu sobu
-// salje HTTP GET zahtev i parsira odgovor
-func (s *UserService) checkUserHasActiveRoom(userID primitive.ObjectID) (bool, error) {
-	url := fmt.Sprintf("%s/api/v1/internal/users/%s/room-status", s.stDomServiceURL, userID.Hex())
-	
-	resp, err := http.Get(url)
+// pretrazuje korisnike sa stranicenjem - za administratore
+// pretraga se vrsi po korisnickom imenu, email-u, imenu i prezimenu (bez obzira na velika i mala slova)
+func (s *UserService) ListUsers(filter models.UserFilter) (*models.UserPage, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if filter.Page <= 0 {
+		filter.Page = 1
+	}
+	if filter.Limit <= 0 || filter.Limit > 100 {
+		filter.Limit = 20
+	}
+
+	query := bson.M{}
+	if filter.Search != "" {
+		pattern := primitive.Regex{Pattern: regexp.QuoteMeta(filter.Search), Options: "i"}
+		query["$or"] = []bson.M{
+			{"username": pattern},
+			{"email": pattern},
+			{"first_name": pattern},
+			{"last_name": pattern},
+		}
+	}
+	if filter.Role != "" {
+		query["role"] = filter.Role
+	}
+	switch filter.Status {
+	case "active":
+		query["disabled"] = bson.M{"$ne": true}
+	case "disabled":
+		query["disabled"] = true
+	}
+
+	total, err := s.collection.CountDocuments(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	findOptions := options.Find().
+		SetSort(bson.D{{Key: "created_at", Value: -1}}).
+		SetSkip(int64((filter.Page - 1) * filter.Limit)).
+		SetLimit(int64(filter.Limit)).
+		SetProjection(bson.M{"password": 0})
+
+	cursor, err := s.collection.Find(ctx, query, findOptions)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	users := []models.User{}
+	if err = cursor.All(ctx, &users); err != nil {
+		return nil, err
+	}
+
+	return &models.UserPage{
+		Users: users,
+		Total: total,
+		Page:  filter.Page,
+		Limit: filter.Limit,
+	}, nil
+}
+
+// menja ulogu korisnika - administrator ne moze promeniti sopstvenu ulogu
+// sve sesije korisnika se opozivaju da bi novi tokeni imali azurnu ulogu
+func (s *UserService) UpdateRole(actorID, userID primitive.ObjectID, role string) (*models.User, error) {
+	if !models.IsValidRole(role) {
+		return nil, errors.New("invalid role. Valid values: user, admin")
+	}
+
+	if actorID == userID {
+		return nil, errors.New("you cannot change your own role")
+	}
+
+	// korisnik vracen u ulogu "user" gubi i prava globalnog administratora
+	fields := bson.M{"role": role}
+	if role != models.RoleAdmin {
+		fields["super_admin"] = false
+	}
+	if err := s.updateUser(userID, fields); err != nil {
+		return nil, err
+	}
+
+	if err := s.tokenService.RevokeAllSessions(userID); err != nil {
+		return nil, err
+	}
+
+	return s.GetUserByID(userID)
+}
+
+// menja domove kojima administrator upravlja - prazna lista oduzima pristup svim domovima
+// postojece sesije korisnika se opozivaju da bi novi tokeni nosili azuran opseg
+func (s *UserService) UpdateManagedStDoms(actorID, userID primitive.ObjectID, stDomIDs []string) (*models.User, error) {
+	if actorID == userID {
+		return nil, errors.New("you cannot change your own dormitory scope")
+	}
+
+	for _, id := range stDomIDs {
+		if !primitive.IsValidObjectID(id) {
+			return nil, errors.New("invalid student dormitory ID: " + id)
+		}
+	}
+
+	var err error
+	if len(stDomIDs) == 0 {
+		err = s.updateUser(userID, bson.M{"managed_st_dom_ids": nil})
+	} else {
+		err = s.updateUser(userID, bson.M{"managed_st_dom_ids": stDomIDs})
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.tokenService.RevokeAllSessions(userID); err != nil {
+		return nil, err
+	}
+
+	return s.GetUserByID(userID)
+}
+
+// dodeljuje ili oduzima prava globalnog administratora - samo korisnicima sa ulogom administratora
+// postojece sesije korisnika se opozivaju da bi novi tokeni imali azurna prava
+func (s *UserService) SetSuperAdmin(actorID, userID primitive.ObjectID, superAdmin bool) (*models.User, error) {
+	if actorID == userID {
+		return nil, errors.New("you cannot change your own global administrator access")
+	}
+
+	user, err := s.GetUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if superAdmin && user.Role != models.RoleAdmin {
+		return nil, errors.New("only administrators can be global administrators")
+	}
+
+	if err := s.updateUser(userID, bson.M{"super_admin": superAdmin}); err != nil {
+		return nil, err
+	}
+
+	if err := s.tokenService.RevokeAllSessions(userID); err != nil {
+		return nil, err
+	}
+
+	return s.GetUserByID(userID)
+}
+
+// onemogucava ili ponovo omogucava nalog - administrator ne moze onemoguciti sopstveni nalog
+// pri onemogucavanju se opozivaju sve sesije, pa postojeci tokeni prestaju da vaze
+func (s *UserService) SetDisabled(actorID, userID primitive.ObjectID, disabled bool) (*models.User, error) {
+	if actorID == userID && disabled {
+		return nil, errors.New("you cannot disable your own account")
+	}
+
+	var err error
+	if disabled {
+		err = s.updateUser(userID, bson.M{"disabled": true, "disabled_at": time.Now()})
+	} else {
+		err = s.updateUser(userID, bson.M{"disabled": false, "disabled_at": nil})
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if disabled {
+		if err := s.tokenService.RevokeAllSessions(userID); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.GetUserByID(userID)
+}
+
+// oznacava da korisnik mora promeniti lozinku pre sledece prijave i opoziva sve njegove sesije
+func (s *UserService) RequirePasswordReset(userID primitive.ObjectID) error {
+	if err := s.updateUser(userID, bson.M{"password_reset_required": true}); err != nil {
+		return err
+	}
+
+	return s.tokenService.RevokeAllSessions(userID)
+}
+
+// azurira polja korisnika i vreme poslednje izmene
+func (s *UserService) updateUser(userID primitive.ObjectID, fields bson.M) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	fields["updated_at"] = time.Now()
+	result, err := s.collection.UpdateOne(ctx, bson.M{"_id": userID}, bson.M{"$set": fields})
 	if err != nil {
-		return false, err
+		return err
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return false, errors.New("failed to check room status")
+	if result.MatchedCount == 0 {
+		return errors.New("user not found")
 	}
 
-	var result struct {
-		HasActiveRoom bool `json:"has_active_room"`
+	return nil
+}
+
+// brise korisnikov nalog
+// podatke u st_dom_service i ostale zapise vezane za nalog brise PrivacyService
+func (s *UserService) DeleteUser(userID primitive.ObjectID) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	result, err := s.collection.DeleteOne(ctx, bson.M{"_id": userID})
+	if err != nil {
+		return err
 	}
 
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return false, err
+	if result.DeletedCount == 0 {
+		return errors.New("user not found")
 	}
 
-	return result.HasActiveRoom, nil
+	return nil
 }
 
+// opoziva access token kojim je zahtev poslat
+// tokeni izdati pre uvodjenja jti oznake nemaju sta da se opozove i isticu sami
+func (s *UserService) revokeCurrentAccessToken(claims *utils.JWTClaims) error {
+	if claims.ID == "" || claims.ExpiresAt == nil {
+		return nil
+	}
+
+	return s.tokenService.RevokeAccessToken(claims.UserID, claims.ID, claims.ExpiresAt.Time)
+}
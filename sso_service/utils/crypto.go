@@ -0,0 +1,83 @@
+package utils
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+)
+
+// ErrEncryptionDisabled - kljuc za sifrovanje licnih podataka nije podesen
+var ErrEncryptionDisabled = errors.New("encryption key is not configured")
+
+// ucitava AES-256 kljuc kodiran kao base64 (32 bajta)
+// prazna vrednost znaci da sifrovanje nije podeseno i vraca nil kljuc
+func ParseEncryptionKey(encoded string) ([]byte, error) {
+	if encoded == "" {
+		return nil, nil
+	}
+
+	key, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, errors.New("encryption key must be base64 encoded")
+	}
+	if len(key) != 32 {
+		return nil, errors.New("encryption key must be 32 bytes long")
+	}
+
+	return key, nil
+}
+
+// sifruje vrednost AES-GCM algoritmom i vraca base64(nonce || sifrat)
+func EncryptField(key []byte, plaintext string) (string, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+
+	sealed := gcm.Seal(nonce, nonce, []byte(plaintext), nil)
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// desifruje vrednost sifrovanu funkcijom EncryptField
+func DecryptField(key []byte, encrypted string) (string, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+
+	data, err := base64.StdEncoding.DecodeString(encrypted)
+	if err != nil {
+		return "", err
+	}
+	if len(data) < gcm.NonceSize() {
+		return "", errors.New("encrypted value is too short")
+	}
+
+	plaintext, err := gcm.Open(nil, data[:gcm.NonceSize()], data[gcm.NonceSize():], nil)
+	if err != nil {
+		return "", err
+	}
+
+	return string(plaintext), nil
+}
+
+// pravi AES-GCM sifru za dati kljuc
+func newGCM(key []byte) (cipher.AEAD, error) {
+	if key == nil {
+		return nil, ErrEncryptionDisabled
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
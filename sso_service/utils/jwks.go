@@ -0,0 +1,160 @@
+package utils
+
+import (
+	"context"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"log"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// minimalni razmak izmedju dva preuzimanja kljuceva zbog nepoznatog kid-a
+const jwksMinRefreshInterval = 30 * time.Second
+
+// JWKSCache - kesirani javni kljucevi spoljnog provajdera identiteta za proveru potpisa ID tokena
+// kljucevi se preuzimaju pri prvoj upotrebi, a token sa nepoznatim kid-om (rotacija) izaziva ponovno preuzimanje
+type JWKSCache struct {
+	jwksURL     string
+	httpClient  *http.Client
+	mu          sync.RWMutex
+	keys        map[string]publicKey
+	lastRefresh time.Time
+}
+
+// javni kljuc sa algoritmom kojim je potpisan token
+type publicKey struct {
+	algorithm string
+	key       crypto.PublicKey
+}
+
+// kreira novi prazan kes kljuceva za prosledjeni JWKS URL
+func NewJWKSCache(jwksURL string, httpClient *http.Client) *JWKSCache {
+	return &JWKSCache{
+		jwksURL:    jwksURL,
+		httpClient: httpClient,
+		keys:       make(map[string]publicKey),
+	}
+}
+
+// preuzima aktuelne javne kljuceve provajdera
+func (j *JWKSCache) Refresh(ctx context.Context) error {
+	j.mu.Lock()
+	j.lastRefresh = time.Now()
+	j.mu.Unlock()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, j.jwksURL, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := j.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return errors.New("failed to fetch JWKS")
+	}
+
+	var result JWKS
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return err
+	}
+
+	keys := make(map[string]publicKey, len(result.Keys))
+	for _, key := range result.Keys {
+		if key.Use != "" && key.Use != "sig" {
+			continue
+		}
+		parsed, algorithm, err := parseJWK(key)
+		if err != nil {
+			log.Printf("Skipping JWK %s: %v", key.KeyID, err)
+			continue
+		}
+		keys[key.KeyID] = publicKey{algorithm: algorithm, key: parsed}
+	}
+
+	j.mu.Lock()
+	j.keys = keys
+	j.mu.Unlock()
+
+	return nil
+}
+
+// vraca javni kljuc i algoritam za kid iz zaglavlja tokena
+// ako kid nije poznat, kljucevi se ponovo preuzimaju (najvise jednom u jwksMinRefreshInterval)
+func (j *JWKSCache) PublicKey(kid string) (crypto.PublicKey, string, error) {
+	if key, exists := j.lookup(kid); exists {
+		return key.key, key.algorithm, nil
+	}
+
+	j.mu.RLock()
+	canRefresh := time.Since(j.lastRefresh) >= jwksMinRefreshInterval
+	j.mu.RUnlock()
+
+	if canRefresh {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		if err := j.Refresh(ctx); err != nil {
+			log.Println("Failed to refresh JWKS:", err)
+		}
+		if key, exists := j.lookup(kid); exists {
+			return key.key, key.algorithm, nil
+		}
+	}
+
+	return nil, "", errors.New("unknown signing key")
+}
+
+// trazi kljuc u kesu
+func (j *JWKSCache) lookup(kid string) (publicKey, bool) {
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+
+	key, exists := j.keys[kid]
+	return key, exists
+}
+
+// pretvara JWK u RSA ili Ed25519 javni kljuc
+// ako provajder ne navede algoritam, uzima se podrazumevani za tip kljuca
+func parseJWK(key JWK) (crypto.PublicKey, string, error) {
+	switch key.KeyType {
+	case "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(key.N)
+		if err != nil {
+			return nil, "", err
+		}
+		e, err := base64.RawURLEncoding.DecodeString(key.E)
+		if err != nil {
+			return nil, "", err
+		}
+		algorithm := key.Algorithm
+		if algorithm == "" {
+			algorithm = AlgorithmRS256
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, algorithm, nil
+	case "OKP":
+		if key.Curve != "Ed25519" {
+			return nil, "", errors.New("unsupported curve")
+		}
+		x, err := base64.RawURLEncoding.DecodeString(key.X)
+		if err != nil {
+			return nil, "", err
+		}
+		if len(x) != ed25519.PublicKeySize {
+			return nil, "", errors.New("invalid Ed25519 key size")
+		}
+		return ed25519.PublicKey(x), AlgorithmEdDSA, nil
+	default:
+		return nil, "", errors.New("unsupported key type")
+	}
+}
@@ -9,51 +9,136 @@ import (
 )
 
 // JWTClaims - podaci koji se cuvaju u JWT tokenu
+// jti (RegisteredClaims.ID) jedinstveno oznacava token i koristi se za opoziv
+// sid oznacava sesiju (lanac refresh tokena) kojoj token pripada
+// st_dom_ids ogranicava administratora na domove kojima upravlja, a super_admin daje pristup svim domovima
+// administrator bez super_admin i bez st_dom_ids nema pristup nijednom domu
 type JWTClaims struct {
-	UserID   primitive.ObjectID `json:"user_id"`
-	Username string             `json:"username"`
-	Email    string             `json:"email"`
-	Role     string             `json:"role"`
+	UserID     primitive.ObjectID `json:"user_id"`
+	Username   string             `json:"username"`
+	Email      string             `json:"email"`
+	Role       string             `json:"role"`
+	StDomIDs   []string           `json:"st_dom_ids,omitempty"`
+	SuperAdmin bool               `json:"super_admin,omitempty"`
+	SessionID  string             `json:"sid,omitempty"`
 	jwt.RegisteredClaims
 }
 
-// generiše JWT token za korisnika sa prosledjenim podacima
-// token vazi 24 sata i potpisan je sa tajnim kljucem
-func GenerateJWT(userID primitive.ObjectID, username, email, role, secret string) (string, error) {
-	claims := JWTClaims{
-		UserID:   userID,
-		Username: username,
-		Email:    email,
-		Role:     role,
+// generiše kratkotrajni JWT access token za korisnika sa prosledjenim podacima
+// token vazi ttl i potpisan je aktivnim privatnim kljucem (kid u zaglavlju), vraca i claims da bi se jti mogao zapamtiti
+func GenerateJWT(userID primitive.ObjectID, username, email, role string, stDomIDs []string, superAdmin bool, sessionID string, keys *KeySet, ttl time.Duration) (string, *JWTClaims, error) {
+	now := time.Now()
+	claims := &JWTClaims{
+		UserID:     userID,
+		Username:   username,
+		Email:      email,
+		Role:       role,
+		StDomIDs:   stDomIDs,
+		SuperAdmin: superAdmin,
+		SessionID:  sessionID,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(24 * time.Hour)),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			NotBefore: jwt.NewNumericDate(time.Now()),
+			ID:        primitive.NewObjectID().Hex(),
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(secret))
+	signed, err := SignClaims(claims, keys)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return signed, claims, nil
 }
 
 // validira JWT token i vraca podatke iz njega
-// proverava potpis i da li je token jos uvek valjan
-func ValidateJWT(tokenString, secret string) (*JWTClaims, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &JWTClaims{}, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, errors.New("unexpected signing method")
-		}
-		return []byte(secret), nil
-	})
-
+// proverava potpis javnim kljucem iz kid zaglavlja i da li je token jos uvek valjan
+// servisni tokeni nemaju korisnika i ovde se odbijaju
+func ValidateJWT(tokenString string, keys *KeySet) (*JWTClaims, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &JWTClaims{}, keyFunc(keys))
 	if err != nil {
 		return nil, err
 	}
 
-	if claims, ok := token.Claims.(*JWTClaims); ok && token.Valid {
+	if claims, ok := token.Claims.(*JWTClaims); ok && token.Valid && !claims.UserID.IsZero() {
 		return claims, nil
 	}
 
 	return nil, errors.New("invalid token")
 }
 
+// oznake servisnih tokena - typ ih razlikuje od korisnickih, a aud ogranicava na interne rute
+const (
+	ServiceTokenType     = "service"
+	ServiceTokenAudience = "internal"
+)
+
+// ServiceClaims - podaci u tokenu kojim se servis predstavlja drugom servisu (client credentials)
+// sub (RegisteredClaims.Subject) je ID klijenta, npr. "st_dom_service"
+type ServiceClaims struct {
+	TokenType string `json:"typ"`
+	jwt.RegisteredClaims
+}
+
+// generiše kratkotrajni servisni token za prosledjenog klijenta
+func GenerateServiceJWT(clientID string, keys *KeySet, ttl time.Duration) (string, *ServiceClaims, error) {
+	now := time.Now()
+	claims := &ServiceClaims{
+		TokenType: ServiceTokenType,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        primitive.NewObjectID().Hex(),
+			Subject:   clientID,
+			Audience:  jwt.ClaimStrings{ServiceTokenAudience},
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+		},
+	}
+
+	signed, err := SignClaims(claims, keys)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return signed, claims, nil
+}
+
+// validira servisni token - pored potpisa i roka proverava tip i namenu tokena
+func ValidateServiceJWT(tokenString string, keys *KeySet) (*ServiceClaims, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &ServiceClaims{}, keyFunc(keys))
+	if err != nil {
+		return nil, err
+	}
+
+	claims, ok := token.Claims.(*ServiceClaims)
+	if !ok || !token.Valid || claims.TokenType != ServiceTokenType || claims.Subject == "" ||
+		!claims.VerifyAudience(ServiceTokenAudience, true) {
+		return nil, errors.New("invalid service token")
+	}
+
+	return claims, nil
+}
+
+// potpisuje claims aktivnim privatnim kljucem i upisuje kid u zaglavlje tokena
+func SignClaims(claims jwt.Claims, keys *KeySet) (string, error) {
+	key := keys.Active()
+	token := jwt.NewWithClaims(signingMethod(key.Algorithm), claims)
+	token.Header["kid"] = key.ID
+	return token.SignedString(key.PrivateKey)
+}
+
+// vraca funkciju koja bira javni kljuc po kid zaglavlju i proverava algoritam potpisa
+func keyFunc(keys *KeySet) jwt.Keyfunc {
+	return func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		publicKey, algorithm, err := keys.PublicKey(kid)
+		if err != nil {
+			return nil, err
+		}
+		if token.Method.Alg() != algorithm {
+			return nil, errors.New("unexpected signing method")
+		}
+		return publicKey, nil
+	}
+}
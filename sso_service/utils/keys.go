@@ -0,0 +1,223 @@
+package utils
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// podrzani algoritmi potpisivanja
+const (
+	AlgorithmRS256 = "RS256"
+	AlgorithmEdDSA = "EdDSA"
+)
+
+// SigningKey - jedan privatni kljuc za potpisivanje tokena oznacen sa kid
+type SigningKey struct {
+	ID         string
+	Algorithm  string
+	PrivateKey crypto.Signer
+}
+
+// KeySet - skup kljuceva SSO servisa
+// aktivnim kljucem se potpisuju novi tokeni, a svi kljucevi iz skupa se objavljuju u JWKS
+// da bi tokeni potpisani prethodnim kljucem vazili do isteka (rotacija kljuceva)
+type KeySet struct {
+	active *SigningKey
+	keys   map[string]*SigningKey
+}
+
+// JWK - javni kljuc u JSON Web Key formatu
+type JWK struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	N         string `json:"n,omitempty"`
+	E         string `json:"e,omitempty"`
+	Curve     string `json:"crv,omitempty"`
+	X         string `json:"x,omitempty"`
+}
+
+// JWKS - skup javnih kljuceva koji se objavljuje na /.well-known/jwks.json
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// ucitava sve privatne kljuceve (*.pem) iz direktorijuma - naziv fajla bez ekstenzije je kid
+// aktivni kljuc je activeKeyID, a ako nije zadat uzima se poslednji po nazivu
+// ako u direktorijumu nema kljuceva, generise se novi kljuc zadatog algoritma i cuva u direktorijum
+func LoadKeySet(dir, activeKeyID, algorithm string) (*KeySet, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+
+	paths, err := filepath.Glob(filepath.Join(dir, "*.pem"))
+	if err != nil {
+		return nil, err
+	}
+
+	if len(paths) == 0 {
+		path, err := generateKeyFile(dir, algorithm)
+		if err != nil {
+			return nil, err
+		}
+		paths = []string{path}
+	}
+
+	sort.Strings(paths)
+
+	keySet := &KeySet{keys: make(map[string]*SigningKey)}
+	for _, path := range paths {
+		key, err := loadSigningKey(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load key %s: %w", path, err)
+		}
+		keySet.keys[key.ID] = key
+		keySet.active = key
+	}
+
+	if activeKeyID != "" {
+		key, exists := keySet.keys[activeKeyID]
+		if !exists {
+			return nil, fmt.Errorf("active signing key %s not found", activeKeyID)
+		}
+		keySet.active = key
+	}
+
+	return keySet, nil
+}
+
+// vraca aktivni kljuc za potpisivanje
+func (ks *KeySet) Active() *SigningKey {
+	return ks.active
+}
+
+// vraca javni kljuc za kid iz zaglavlja tokena
+func (ks *KeySet) PublicKey(kid string) (crypto.PublicKey, string, error) {
+	key, exists := ks.keys[kid]
+	if !exists {
+		return nil, "", errors.New("unknown signing key")
+	}
+	return key.PrivateKey.Public(), key.Algorithm, nil
+}
+
+// vraca javne kljuceve u JWKS formatu
+func (ks *KeySet) JWKS() JWKS {
+	ids := make([]string, 0, len(ks.keys))
+	for id := range ks.keys {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	jwks := JWKS{Keys: make([]JWK, 0, len(ids))}
+	for _, id := range ids {
+		key := ks.keys[id]
+		jwk := JWK{KeyID: key.ID, Use: "sig", Algorithm: key.Algorithm}
+
+		switch publicKey := key.PrivateKey.Public().(type) {
+		case *rsa.PublicKey:
+			jwk.KeyType = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(publicKey.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(publicKey.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.KeyType = "OKP"
+			jwk.Curve = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(publicKey)
+		}
+
+		jwks.Keys = append(jwks.Keys, jwk)
+	}
+
+	return jwks
+}
+
+// vraca jwt metodu potpisivanja za algoritam kljuca
+func signingMethod(algorithm string) jwt.SigningMethod {
+	if algorithm == AlgorithmEdDSA {
+		return jwt.SigningMethodEdDSA
+	}
+	return jwt.SigningMethodRS256
+}
+
+// ucitava privatni kljuc iz PEM fajla (PKCS#8 ili PKCS#1 za RSA)
+func loadSigningKey(path string) (*SigningKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("invalid PEM data")
+	}
+
+	var parsed interface{}
+	if block.Type == "RSA PRIVATE KEY" {
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	} else {
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	key := &SigningKey{ID: strings.TrimSuffix(filepath.Base(path), ".pem")}
+	switch privateKey := parsed.(type) {
+	case *rsa.PrivateKey:
+		key.Algorithm = AlgorithmRS256
+		key.PrivateKey = privateKey
+	case ed25519.PrivateKey:
+		key.Algorithm = AlgorithmEdDSA
+		key.PrivateKey = privateKey
+	default:
+		return nil, errors.New("unsupported key type, expected RSA or Ed25519")
+	}
+
+	return key, nil
+}
+
+// generise novi privatni kljuc i cuva ga kao <datum>.pem u PKCS#8 formatu
+func generateKeyFile(dir, algorithm string) (string, error) {
+	var privateKey interface{}
+	var err error
+
+	switch algorithm {
+	case AlgorithmEdDSA:
+		_, privateKey, err = ed25519.GenerateKey(rand.Reader)
+	case AlgorithmRS256:
+		privateKey, err = rsa.GenerateKey(rand.Reader, 2048)
+	default:
+		return "", fmt.Errorf("unsupported signing algorithm %s", algorithm)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	der, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	if err != nil {
+		return "", err
+	}
+
+	path := filepath.Join(dir, time.Now().UTC().Format("20060102-150405")+".pem")
+	data := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return "", err
+	}
+
+	return path, nil
+}
@@ -0,0 +1,128 @@
+package utils
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// oznaka access tokena izdatog OIDC klijentu - vazi samo za userinfo, ne i za API-je nasih servisa
+const OIDCAccessTokenType = "oidc_access"
+
+// OIDCAccessClaims - access token koji dobija spoljna aplikacija (OIDC klijent)
+// nema user_id, pa ga ValidateJWT odbija i ne moze se koristiti za pozive nasih API-ja
+type OIDCAccessClaims struct {
+	TokenType string `json:"typ"`
+	Scope     string `json:"scope"`
+	ClientID  string `json:"client_id"`
+	jwt.RegisteredClaims
+}
+
+// IDTokenClaims - ID token (OpenID Connect) sa podacima o korisniku prema odobrenim scope-ovima
+type IDTokenClaims struct {
+	Nonce             string `json:"nonce,omitempty"`
+	Name              string `json:"name,omitempty"`
+	GivenName         string `json:"given_name,omitempty"`
+	FamilyName        string `json:"family_name,omitempty"`
+	PreferredUsername string `json:"preferred_username,omitempty"`
+	Email             string `json:"email,omitempty"`
+	EmailVerified     *bool  `json:"email_verified,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// generiše access token za OIDC klijenta - sub je ID korisnika, aud je ID klijenta
+func GenerateOIDCAccessToken(issuer, clientID string, userID primitive.ObjectID, scope string, keys *KeySet, ttl time.Duration) (string, error) {
+	now := time.Now()
+	claims := &OIDCAccessClaims{
+		TokenType: OIDCAccessTokenType,
+		Scope:     scope,
+		ClientID:  clientID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        primitive.NewObjectID().Hex(),
+			Issuer:    issuer,
+			Subject:   userID.Hex(),
+			Audience:  jwt.ClaimStrings{clientID},
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+		},
+	}
+
+	return SignClaims(claims, keys)
+}
+
+// validira access token izdat OIDC klijentu
+func ValidateOIDCAccessToken(tokenString, issuer string, keys *KeySet) (*OIDCAccessClaims, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &OIDCAccessClaims{}, keyFunc(keys))
+	if err != nil {
+		return nil, err
+	}
+
+	claims, ok := token.Claims.(*OIDCAccessClaims)
+	if !ok || !token.Valid || claims.TokenType != OIDCAccessTokenType || !claims.VerifyIssuer(issuer, true) {
+		return nil, errors.New("invalid access token")
+	}
+
+	return claims, nil
+}
+
+// generiše potpisan ID token - poziva ga OIDC servis posle popunjavanja podataka o korisniku
+func GenerateIDToken(claims *IDTokenClaims, keys *KeySet) (string, error) {
+	return SignClaims(claims, keys)
+}
+
+// proverava PKCE (RFC 7636, metoda S256): BASE64URL(SHA256(code_verifier)) mora biti jednak code_challenge
+func VerifyPKCE(verifier, challenge string) bool {
+	if len(verifier) < 43 || len(verifier) > 128 {
+		return false
+	}
+
+	return subtle.ConstantTimeCompare([]byte(PKCEChallenge(verifier)), []byte(challenge)) == 1
+}
+
+// racuna PKCE izazov metodom S256 za prosledjeni code_verifier
+func PKCEChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+// validira ID token spoljnog provajdera identiteta - potpis (JWKS provajdera), izdavaoca, namenu i nonce
+// vraca sve claim-ove jer se imena claim-ova za broj indeksa i fakultet podesavaju
+func ValidateExternalIDToken(tokenString, issuer, clientID, nonce string, jwks *JWKSCache) (jwt.MapClaims, error) {
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		publicKey, algorithm, err := jwks.PublicKey(kid)
+		if err != nil {
+			return nil, err
+		}
+		if token.Method.Alg() != algorithm {
+			return nil, errors.New("unexpected signing method")
+		}
+		return publicKey, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || !token.Valid || !claims.VerifyExpiresAt(time.Now().Unix(), true) ||
+		!claims.VerifyIssuer(issuer, true) || !claims.VerifyAudience(clientID, true) {
+		return nil, errors.New("invalid ID token")
+	}
+
+	tokenNonce, _ := claims["nonce"].(string)
+	if subtle.ConstantTimeCompare([]byte(tokenNonce), []byte(nonce)) != 1 {
+		return nil, errors.New("invalid ID token nonce")
+	}
+
+	if subject, _ := claims["sub"].(string); subject == "" {
+		return nil, errors.New("ID token has no subject")
+	}
+
+	return claims, nil
+}
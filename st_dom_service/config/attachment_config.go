@@ -0,0 +1,64 @@
+package config
+
+import (
+	"os"
+	"strconv"
+	"strings"
+)
+
+// AttachmentConfig holds attachment upload and storage configuration
+type AttachmentConfig struct {
+	StorageDir          string   // Base directory of the local blob store
+	MaxSizeBytes        int64    // Maximum size of a single uploaded file
+	AllowedContentTypes []string // Content types accepted after sniffing the file
+}
+
+// GetAttachmentConfig returns the attachment configuration
+// Values can be overridden via environment variables
+func GetAttachmentConfig() AttachmentConfig {
+	config := AttachmentConfig{
+		StorageDir:   "./uploads",      // Default: uploads directory next to the binary
+		MaxSizeBytes: 10 * 1024 * 1024, // Default: 10 MB (matches the gateway limit)
+		AllowedContentTypes: []string{
+			"image/jpeg",
+			"image/png",
+			"image/webp",
+			"application/pdf",
+		},
+	}
+
+	// Override from environment if set
+	if dir := os.Getenv("ATTACHMENT_STORAGE_DIR"); dir != "" {
+		config.StorageDir = dir
+	}
+
+	if maxSizeStr := os.Getenv("ATTACHMENT_MAX_SIZE_MB"); maxSizeStr != "" {
+		if maxSize, err := strconv.ParseInt(maxSizeStr, 10, 64); err == nil && maxSize > 0 {
+			config.MaxSizeBytes = maxSize * 1024 * 1024
+		}
+	}
+
+	if typesStr := os.Getenv("ATTACHMENT_ALLOWED_TYPES"); typesStr != "" {
+		var types []string
+		for _, t := range strings.Split(typesStr, ",") {
+			if t = strings.TrimSpace(t); t != "" {
+				types = append(types, t)
+			}
+		}
+		if len(types) > 0 {
+			config.AllowedContentTypes = types
+		}
+	}
+
+	return config
+}
+
+// IsContentTypeAllowed checks if the content type is in the allowed list
+func (c AttachmentConfig) IsContentTypeAllowed(contentType string) bool {
+	for _, allowed := range c.AllowedContentTypes {
+		if allowed == contentType {
+			return true
+		}
+	}
+	return false
+}
@@ -3,17 +3,25 @@ package config
 import (
 	"log"
 	"os"
+	"time"
 
 	"github.com/joho/godotenv"
 )
 
 // Config - drzi sve konfiguracione vrednosti za servis
 type Config struct {
-	MongoDBURI   string
-	DatabaseName string
-	JWTSecret    string
-	Port         string
-	GinMode      string
+	MongoDBURI           string
+	DatabaseName         string
+	Port                 string
+	GinMode              string
+	SSOServiceURL        string
+	TokenDenylistRefresh time.Duration
+	JWKSRefresh          time.Duration
+	// kredencijali kojima se servis predstavlja SSO servisu za pozive internih ruta
+	ServiceClientID     string
+	ServiceClientSecret string
+	// CSV fajl (address, latitude, longitude) iz kog se popunjavaju koordinate domova, prazno iskljucuje
+	GazetteerFile string
 }
 
 // ucitava konfiguraciju iz environment varijabli ili config.env fajla
@@ -24,11 +32,21 @@ func LoadConfig() *Config {
 	}
 
 	config := &Config{
-		MongoDBURI:   getEnv("MONGODB_URI", "mongodb://localhost:27018"),
-		DatabaseName: getEnv("DATABASE_NAME", "st_dom_db"),
-		JWTSecret:    getEnv("JWT_SECRET", "default_jwt_secret_change_in_production"),
-		Port:         getEnv("PORT", "8081"),
-		GinMode:      getEnv("GIN_MODE", "debug"),
+		MongoDBURI:           getEnv("MONGODB_URI", "mongodb://localhost:27018"),
+		DatabaseName:         getEnv("DATABASE_NAME", "st_dom_db"),
+		Port:                 getEnv("PORT", "8081"),
+		GinMode:              getEnv("GIN_MODE", "debug"),
+		SSOServiceURL:        getEnv("SSO_SERVICE_URL", "http://localhost:8080"),
+		TokenDenylistRefresh: getDurationEnv("TOKEN_DENYLIST_REFRESH", 30*time.Second),
+		JWKSRefresh:          getDurationEnv("JWKS_REFRESH", 10*time.Minute),
+		ServiceClientID:      getEnv("SERVICE_CLIENT_ID", "st_dom_service"),
+		ServiceClientSecret:  getEnv("SERVICE_CLIENT_SECRET", ""),
+		GazetteerFile:        getEnv("GAZETTEER_FILE", ""),
+	}
+
+	// bez tajne servis ne moze da pozove interne rute SSO servisa, a podrazumevana tajna bi bila javna
+	if config.ServiceClientSecret == "" {
+		log.Fatal("SERVICE_CLIENT_SECRET is not set")
 	}
 
 	return config
@@ -41,3 +59,13 @@ func getEnv(key, fallback string) string {
 	}
 	return fallback
 }
+
+// dobija trajanje iz environment varijable (npr. "30s") ili vraca default vrednost
+func getDurationEnv(key string, fallback time.Duration) time.Duration {
+	if value, exists := os.LookupEnv(key); exists {
+		if duration, err := time.ParseDuration(value); err == nil && duration > 0 {
+			return duration
+		}
+	}
+	return fallback
+}
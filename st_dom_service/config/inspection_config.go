@@ -0,0 +1,53 @@
+package config
+
+import (
+	"os"
+	"strconv"
+	"time"
+)
+
+// InspectionConfig holds inspection scheduler configuration
+type InspectionConfig struct {
+	SchedulerEnabled bool          // Whether the background scheduler runs
+	SchedulerPeriod  time.Duration // How often the scheduler checks templates
+	LookaheadDays    int           // How many days ahead inspections are generated
+	CheckoutDueDays  int           // Days after checkout by which the room check is due
+}
+
+// GetInspectionConfig returns the inspection configuration
+// Values can be overridden via environment variables
+func GetInspectionConfig() InspectionConfig {
+	config := InspectionConfig{
+		SchedulerEnabled: true,          // Default: scheduler enabled
+		SchedulerPeriod:  1 * time.Hour, // Default: check every hour
+		LookaheadDays:    14,            // Default: generate two weeks ahead
+		CheckoutDueDays:  2,             // Default: room check within two days of checkout
+	}
+
+	// Override from environment if set
+	if enabledStr := os.Getenv("INSPECTION_SCHEDULER_ENABLED"); enabledStr != "" {
+		if enabled, err := strconv.ParseBool(enabledStr); err == nil {
+			config.SchedulerEnabled = enabled
+		}
+	}
+
+	if periodStr := os.Getenv("INSPECTION_SCHEDULER_PERIOD"); periodStr != "" {
+		if period, err := time.ParseDuration(periodStr); err == nil && period > 0 {
+			config.SchedulerPeriod = period
+		}
+	}
+
+	if lookaheadStr := os.Getenv("INSPECTION_LOOKAHEAD_DAYS"); lookaheadStr != "" {
+		if lookahead, err := strconv.Atoi(lookaheadStr); err == nil && lookahead >= 0 {
+			config.LookaheadDays = lookahead
+		}
+	}
+
+	if dueDaysStr := os.Getenv("INSPECTION_CHECKOUT_DUE_DAYS"); dueDaysStr != "" {
+		if dueDays, err := strconv.Atoi(dueDaysStr); err == nil && dueDays >= 0 {
+			config.CheckoutDueDays = dueDays
+		}
+	}
+
+	return config
+}
@@ -0,0 +1,54 @@
+package config
+
+import (
+	"os"
+	"strconv"
+)
+
+// NotificationConfig holds configuration of the optional notification channels
+// The in-app inbox is always enabled
+type NotificationConfig struct {
+	EmailEnabled bool   // Whether notifications are also sent by email
+	SMTPHost     string // SMTP server host
+	SMTPPort     int    // SMTP server port
+	SMTPUsername string // SMTP username (empty for no authentication)
+	SMTPPassword string // SMTP password
+	SMTPFrom     string // Sender address
+}
+
+// GetNotificationConfig returns the notification configuration
+// Values can be overridden via environment variables
+func GetNotificationConfig() NotificationConfig {
+	config := NotificationConfig{
+		EmailEnabled: false, // Default: inbox only
+		SMTPHost:     "localhost",
+		SMTPPort:     25,
+		SMTPFrom:     "no-reply@studentski-domovi.local",
+	}
+
+	// Override from environment if set
+	if enabledStr := os.Getenv("NOTIFICATION_EMAIL_ENABLED"); enabledStr != "" {
+		if enabled, err := strconv.ParseBool(enabledStr); err == nil {
+			config.EmailEnabled = enabled
+		}
+	}
+
+	if host := os.Getenv("SMTP_HOST"); host != "" {
+		config.SMTPHost = host
+	}
+
+	if portStr := os.Getenv("SMTP_PORT"); portStr != "" {
+		if port, err := strconv.Atoi(portStr); err == nil && port > 0 {
+			config.SMTPPort = port
+		}
+	}
+
+	config.SMTPUsername = os.Getenv("SMTP_USERNAME")
+	config.SMTPPassword = os.Getenv("SMTP_PASSWORD")
+
+	if from := os.Getenv("SMTP_FROM"); from != "" {
+		config.SMTPFrom = from
+	}
+
+	return config
+}
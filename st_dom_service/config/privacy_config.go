@@ -0,0 +1,45 @@
+package config
+
+import (
+	"os"
+	"strconv"
+	"time"
+)
+
+// PrivacyConfig holds personal data erasure and retention configuration
+type PrivacyConfig struct {
+	PaymentRetentionYears int           // How long payment records must keep the student's index number
+	PurgeEnabled          bool          // Whether the background retention purge runs
+	PurgePeriod           time.Duration // How often expired retention is purged
+}
+
+// GetPrivacyConfig returns the privacy configuration
+// Values can be overridden via environment variables
+func GetPrivacyConfig() PrivacyConfig {
+	config := PrivacyConfig{
+		PaymentRetentionYears: 10,             // Default: ten years of payment record retention
+		PurgeEnabled:          true,           // Default: purge enabled
+		PurgePeriod:           24 * time.Hour, // Default: purge once a day
+	}
+
+	// Override from environment if set
+	if yearsStr := os.Getenv("PAYMENT_RETENTION_YEARS"); yearsStr != "" {
+		if years, err := strconv.Atoi(yearsStr); err == nil && years >= 0 {
+			config.PaymentRetentionYears = years
+		}
+	}
+
+	if enabledStr := os.Getenv("RETENTION_PURGE_ENABLED"); enabledStr != "" {
+		if enabled, err := strconv.ParseBool(enabledStr); err == nil {
+			config.PurgeEnabled = enabled
+		}
+	}
+
+	if periodStr := os.Getenv("RETENTION_PURGE_PERIOD"); periodStr != "" {
+		if period, err := time.ParseDuration(periodStr); err == nil && period > 0 {
+			config.PurgePeriod = period
+		}
+	}
+
+	return config
+}
@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"net/http"
+	"st_dom_service/models"
+	"st_dom_service/services"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// vraca opseg administratora iz konteksta (postavlja ga AuthMiddleware)
+// ako opseg ne postoji vraca prazan opseg koji ne dozvoljava nista
+func getAdminScope(c *gin.Context) models.AdminScope {
+	value, exists := c.Get("admin_scope")
+	if !exists {
+		return models.AdminScope{}
+	}
+
+	scope, _ := value.(models.AdminScope)
+	return scope
+}
+
+// proverava da li je administrator globalni i upisuje odgovor ako nije
+// kreiranje i brisanje domova i akcije nad svim domovima su dozvoljene samo globalnim administratorima
+func requireGlobalAdmin(c *gin.Context) bool {
+	if !getAdminScope(c).Global {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Global administrator access required"})
+		return false
+	}
+	return true
+}
+
+// proverava da li administrator upravlja domom i upisuje odgovor ako ne upravlja
+func requireStDomAccess(c *gin.Context, stDomID primitive.ObjectID) bool {
+	if !getAdminScope(c).Allows(stDomID) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Access denied: student dormitory is outside your scope"})
+		return false
+	}
+	return true
+}
+
+// proverava da li administrator upravlja domom kome soba pripada i upisuje odgovor ako ne upravlja
+func requireSobaAccess(c *gin.Context, scopeService *services.ScopeService, sobaID primitive.ObjectID) bool {
+	allowed, err := scopeService.CanManageSoba(getAdminScope(c), sobaID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return false
+	}
+
+	if !allowed {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Access denied: room is outside your scope"})
+		return false
+	}
+	return true
+}
+
+// vraca skup soba kojima administrator upravlja - nil znaci sve sobe (globalni administrator)
+func scopedSobaIDs(c *gin.Context, scopeService *services.ScopeService) (map[primitive.ObjectID]bool, bool) {
+	sobaIDs, err := scopeService.SobaIDsInScope(getAdminScope(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return nil, false
+	}
+	return sobaIDs, true
+}
+
+// vraca skup domova kojima administrator upravlja - nil znaci sve domove (globalni administrator)
+func scopedStDomIDs(c *gin.Context) map[primitive.ObjectID]bool {
+	scope := getAdminScope(c)
+	if scope.Global {
+		return nil
+	}
+
+	stDomIDs := make(map[primitive.ObjectID]bool, len(scope.StDomIDs))
+	for _, id := range scope.StDomIDs {
+		stDomIDs[id] = true
+	}
+	return stDomIDs
+}
+
+// vraca skup aplikacija za sobe kojima administrator upravlja - nil znaci sve aplikacije
+func scopedAplikacijaIDs(c *gin.Context, scopeService *services.ScopeService) (map[primitive.ObjectID]bool, bool) {
+	aplikacijaIDs, err := scopeService.AplikacijaIDsInScope(getAdminScope(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return nil, false
+	}
+	return aplikacijaIDs, true
+}
+
+// zadrzava samo stavke ciji je kljuc (soba, aplikacija, dom) u dozvoljenom skupu
+// nil skup znaci da se ne filtrira
+func filterInScope[T any](items []T, allowed map[primitive.ObjectID]bool, key func(T) primitive.ObjectID) []T {
+	if allowed == nil {
+		return items
+	}
+
+	filtered := make([]T, 0, len(items))
+	for _, item := range items {
+		if allowed[key(item)] {
+			filtered = append(filtered, item)
+		}
+	}
+	return filtered
+}
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"log"
 	"net/http"
 	"st_dom_service/models"
 	"st_dom_service/services"
@@ -13,13 +14,17 @@ import (
 type AplikacijaHandler struct {
 	aplikacijaService *services.AplikacijaService
 	sobaService       *services.SobaService
+	attachmentService *services.AttachmentService
+	scopeService      *services.ScopeService
 }
 
 // kreira novi AplikacijaHandler sa potrebnim servisima
-func NewAplikacijaHandler(aplikacijaService *services.AplikacijaService, sobaService *services.SobaService) *AplikacijaHandler {
+func NewAplikacijaHandler(aplikacijaService *services.AplikacijaService, sobaService *services.SobaService, attachmentService *services.AttachmentService, scopeService *services.ScopeService) *AplikacijaHandler {
 	return &AplikacijaHandler{
 		aplikacijaService: aplikacijaService,
 		sobaService:       sobaService,
+		attachmentService: attachmentService,
+		scopeService:      scopeService,
 	}
 }
 
@@ -74,7 +79,7 @@ func (h *AplikacijaHandler) CreateAplikacija(c *gin.Context) {
 }
 
 // dobija aplikaciju po ID-u - korisnici mogu videti samo svoje aplikacije
-// administratori mogu videti sve aplikacije
+// administratori mogu videti aplikacije za sobe u domovima kojima upravljaju
 func (h *AplikacijaHandler) GetAplikacija(c *gin.Context) {
 	idParam := c.Param("id")
 	id, err := primitive.ObjectIDFromHex(idParam)
@@ -112,6 +117,10 @@ func (h *AplikacijaHandler) GetAplikacija(c *gin.Context) {
 		return
 	}
 
+	if userRole == "admin" && !requireSobaAccess(c, h.scopeService, aplikacija.SobaID) {
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"aplikacija": aplikacija,
 	})
@@ -143,6 +152,7 @@ func (h *AplikacijaHandler) GetMyAplikacije(c *gin.Context) {
 }
 
 // dobija sve aplikacije - samo za administratore
+// administratori doma dobijaju samo aplikacije za sobe u svojim domovima
 func (h *AplikacijaHandler) GetAllAplikacije(c *gin.Context) {
 	userRole, exists := c.Get("role")
 	if !exists {
@@ -155,12 +165,19 @@ func (h *AplikacijaHandler) GetAllAplikacije(c *gin.Context) {
 		return
 	}
 
+	sobaIDs, ok := scopedSobaIDs(c, h.scopeService)
+	if !ok {
+		return
+	}
+
 	aplikacije, err := h.aplikacijaService.GetAllAplikacije()
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
+	aplikacije = filterInScope(aplikacije, sobaIDs, func(a models.Aplikacija) primitive.ObjectID { return a.SobaID })
+
 	c.JSON(http.StatusOK, gin.H{
 		"aplikacije": aplikacije,
 	})
@@ -192,6 +209,10 @@ func (h *AplikacijaHandler) GetAplikacijeForRoom(c *gin.Context) {
 		return
 	}
 
+	if !requireSobaAccess(c, h.scopeService, sobaID) {
+		return
+	}
+
 	aplikacije, err := h.aplikacijaService.GetAplikacijeBySobaID(sobaID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -242,7 +263,7 @@ func (h *AplikacijaHandler) UpdateAplikacija(c *gin.Context) {
 	})
 }
 
-// brise aplikaciju - korisnik moze brisati svoju, admin moze brisati bilo koju
+// brise aplikaciju - korisnik moze brisati svoju, admin bilo koju u domovima kojima upravlja
 func (h *AplikacijaHandler) DeleteAplikacija(c *gin.Context) {
 	idParam := c.Param("id")
 	id, err := primitive.ObjectIDFromHex(idParam)
@@ -258,12 +279,24 @@ func (h *AplikacijaHandler) DeleteAplikacija(c *gin.Context) {
 	}
 
 	if userRole == "admin" {
+		aplikacija, err := h.aplikacijaService.GetAplikacijaByID(id)
+		if err != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+
+		if !requireSobaAccess(c, h.scopeService, aplikacija.SobaID) {
+			return
+		}
+
 		err = h.aplikacijaService.DeleteAplikacijaByID(id)
 		if err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
 
+		h.deleteAttachments(c, id)
+
 		c.JSON(http.StatusOK, gin.H{
 			"message": "Application deleted successfully",
 		})
@@ -288,7 +321,16 @@ func (h *AplikacijaHandler) DeleteAplikacija(c *gin.Context) {
 		return
 	}
 
+	h.deleteAttachments(c, id)
+
 	c.JSON(http.StatusOK, gin.H{
 		"message": "Application deleted successfully",
 	})
-}
\ No newline at end of file
+}
+
+// brise priloge obrisane aplikacije - greska ne ponistava brisanje aplikacije
+func (h *AplikacijaHandler) deleteAttachments(c *gin.Context, aplikacijaID primitive.ObjectID) {
+	if err := h.attachmentService.DeleteAttachmentsByOwner(c.Request.Context(), models.AttachmentOwnerAplikacija, aplikacijaID); err != nil {
+		log.Println("Failed to delete application attachments:", err)
+	}
+}
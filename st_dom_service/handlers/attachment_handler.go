@@ -0,0 +1,267 @@
+package handlers
+
+import (
+	"mime"
+	"net/http"
+	"st_dom_service/models"
+	"st_dom_service/services"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// AttachmentHandler - rukuje zahtevima za priloge (fotografije i dokumenta) uz popravke i aplikacije
+type AttachmentHandler struct {
+	attachmentService *services.AttachmentService
+	aplikacijaService *services.AplikacijaService
+	repairService     *services.RepairService
+	scopeService      *services.ScopeService
+}
+
+// kreira novi AttachmentHandler sa potrebnim servisima
+func NewAttachmentHandler(attachmentService *services.AttachmentService, aplikacijaService *services.AplikacijaService, repairService *services.RepairService, scopeService *services.ScopeService) *AttachmentHandler {
+	return &AttachmentHandler{
+		attachmentService: attachmentService,
+		aplikacijaService: aplikacijaService,
+		repairService:     repairService,
+		scopeService:      scopeService,
+	}
+}
+
+// dodaje prilog uz aplikaciju (npr. prepis ocena ili potvrdu o prihodima)
+// korisnici mogu dodavati priloge samo uz svoje aplikacije, administratori uz sve
+func (h *AttachmentHandler) UploadAplikacijaAttachment(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+
+	if !h.authorizeOwner(c, models.AttachmentOwnerAplikacija, id) {
+		return
+	}
+
+	h.upload(c, models.AttachmentOwnerAplikacija, id)
+}
+
+// dobija sve priloge uz aplikaciju - ista pravila pristupa kao za samu aplikaciju
+func (h *AttachmentHandler) GetAplikacijaAttachments(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+
+	if !h.authorizeOwner(c, models.AttachmentOwnerAplikacija, id) {
+		return
+	}
+
+	h.list(c, models.AttachmentOwnerAplikacija, id)
+}
+
+// dodaje fotografiju ostecenja ili drugi prilog uz popravku - samo administratori
+func (h *AttachmentHandler) UploadRepairAttachment(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid repair ID"})
+		return
+	}
+
+	if !h.authorizeOwner(c, models.AttachmentOwnerRepair, id) {
+		return
+	}
+
+	h.upload(c, models.AttachmentOwnerRepair, id)
+}
+
+// dobija sve priloge uz popravku - samo administratori
+func (h *AttachmentHandler) GetRepairAttachments(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid repair ID"})
+		return
+	}
+
+	if !h.authorizeOwner(c, models.AttachmentOwnerRepair, id) {
+		return
+	}
+
+	h.list(c, models.AttachmentOwnerRepair, id)
+}
+
+// dobija podatke o prilogu po ID-u bez sadrzaja fajla
+func (h *AttachmentHandler) GetAttachment(c *gin.Context) {
+	attachment, ok := h.loadAuthorizedAttachment(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"attachment": attachment,
+	})
+}
+
+// preuzima sadrzaj priloga - proverava vlasnistvo isto kao GetAplikacija
+func (h *AttachmentHandler) DownloadAttachment(c *gin.Context) {
+	attachment, ok := h.loadAuthorizedAttachment(c)
+	if !ok {
+		return
+	}
+
+	content, err := h.attachmentService.OpenAttachment(c.Request.Context(), attachment)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	defer content.Close()
+
+	c.DataFromReader(http.StatusOK, attachment.Size, attachment.ContentType, content, map[string]string{
+		"Content-Disposition":    mime.FormatMediaType("attachment", map[string]string{"filename": attachment.FileName}),
+		"X-Content-Type-Options": "nosniff",
+	})
+}
+
+// brise prilog - ista pravila pristupa kao za preuzimanje
+func (h *AttachmentHandler) DeleteAttachment(c *gin.Context) {
+	attachment, ok := h.loadAuthorizedAttachment(c)
+	if !ok {
+		return
+	}
+
+	if err := h.attachmentService.DeleteAttachment(c.Request.Context(), attachment.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Attachment deleted successfully",
+	})
+}
+
+// prima multipart fajl iz polja "file" i cuva ga kao prilog
+func (h *AttachmentHandler) upload(c *gin.Context, ownerType models.AttachmentOwnerType, ownerID primitive.ObjectID) {
+	userIDClaim, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in token"})
+		return
+	}
+
+	userID, ok := userIDClaim.(primitive.ObjectID)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user ID format"})
+		return
+	}
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "File is required in the 'file' form field"})
+		return
+	}
+
+	attachment, err := h.attachmentService.UploadAttachment(c.Request.Context(), ownerType, ownerID, fileHeader, c.PostForm("description"), userID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":    "Attachment uploaded successfully",
+		"attachment": attachment,
+	})
+}
+
+// vraca listu priloga za vlasnika
+func (h *AttachmentHandler) list(c *gin.Context, ownerType models.AttachmentOwnerType, ownerID primitive.ObjectID) {
+	attachments, err := h.attachmentService.GetAttachmentsByOwner(c.Request.Context(), ownerType, ownerID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"attachments": attachments,
+		"count":       len(attachments),
+	})
+}
+
+// ucitava prilog iz :id parametra i proverava da li korisnik sme da mu pristupi
+func (h *AttachmentHandler) loadAuthorizedAttachment(c *gin.Context) (*models.Attachment, bool) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid attachment ID"})
+		return nil, false
+	}
+
+	attachment, err := h.attachmentService.GetAttachmentByID(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return nil, false
+	}
+
+	if !h.authorizeOwner(c, attachment.OwnerType, attachment.OwnerID) {
+		return nil, false
+	}
+
+	return attachment, true
+}
+
+// proverava pravo pristupa vlasniku priloga i upisuje odgovor ako pristup nije dozvoljen
+// administratori imaju pristup prilozima za sobe u domovima kojima upravljaju, korisnici samo svojim aplikacijama
+// popravke su dostupne samo administratorima
+func (h *AttachmentHandler) authorizeOwner(c *gin.Context, ownerType models.AttachmentOwnerType, ownerID primitive.ObjectID) bool {
+	userRole, exists := c.Get("role")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User role not found in token"})
+		return false
+	}
+
+	if userRole == "admin" {
+		var sobaID primitive.ObjectID
+		if ownerType == models.AttachmentOwnerAplikacija {
+			aplikacija, err := h.aplikacijaService.GetAplikacijaByID(ownerID)
+			if err != nil {
+				c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+				return false
+			}
+			sobaID = aplikacija.SobaID
+		} else {
+			repair, err := h.repairService.GetRepairByID(c.Request.Context(), ownerID)
+			if err != nil {
+				c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+				return false
+			}
+			sobaID = repair.SobaID
+		}
+		return requireSobaAccess(c, h.scopeService, sobaID)
+	}
+
+	if ownerType != models.AttachmentOwnerAplikacija {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Admin access required"})
+		return false
+	}
+
+	userIDClaim, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in token"})
+		return false
+	}
+
+	userID, ok := userIDClaim.(primitive.ObjectID)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user ID format"})
+		return false
+	}
+
+	aplikacija, err := h.aplikacijaService.GetAplikacijaByID(ownerID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return false
+	}
+
+	if aplikacija.UserID != userID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Access denied: application does not belong to user"})
+		return false
+	}
+
+	return true
+}
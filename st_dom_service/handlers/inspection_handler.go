@@ -0,0 +1,359 @@
+package handlers
+
+import (
+	"net/http"
+	"st_dom_service/config"
+	"st_dom_service/models"
+	"st_dom_service/services"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type InspectionHandler struct {
+	inspectionService *services.InspectionService
+	stDomService      *services.StDomService
+	scopeService      *services.ScopeService
+}
+
+func NewInspectionHandler(inspectionService *services.InspectionService, stDomService *services.StDomService, scopeService *services.ScopeService) *InspectionHandler {
+	return &InspectionHandler{
+		inspectionService: inspectionService,
+		stDomService:      stDomService,
+		scopeService:      scopeService,
+	}
+}
+
+// CreateTemplate creates a new inspection template
+// POST /api/v1/inspections/templates (admin only)
+func (h *InspectionHandler) CreateTemplate(c *gin.Context) {
+	var req models.CreateInspectionTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if req.SobaID == nil {
+		if _, err := h.stDomService.GetStDomByID(req.StDomID); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Student dormitory not found"})
+			return
+		}
+		if !requireStDomAccess(c, req.StDomID) {
+			return
+		}
+	} else if !requireSobaAccess(c, h.scopeService, *req.SobaID) {
+		return
+	}
+
+	// Get user ID from context (set by auth middleware)
+	userIDClaim, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	createdBy, ok := userIDClaim.(primitive.ObjectID)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user ID format"})
+		return
+	}
+
+	template, err := h.inspectionService.CreateTemplate(c.Request.Context(), req, createdBy)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message":  "Inspection template created successfully",
+		"template": template,
+	})
+}
+
+// GetAllTemplates retrieves all inspection templates
+// GET /api/v1/inspections/templates?st_dom_id= (admin only)
+func (h *InspectionHandler) GetAllTemplates(c *gin.Context) {
+	var stDomID *primitive.ObjectID
+	if stDomIDParam := c.Query("st_dom_id"); stDomIDParam != "" {
+		id, err := primitive.ObjectIDFromHex(stDomIDParam)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid dormitory ID"})
+			return
+		}
+		if !requireStDomAccess(c, id) {
+			return
+		}
+		stDomID = &id
+	}
+
+	templates, err := h.inspectionService.GetAllTemplates(c.Request.Context(), stDomID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	templates = filterInScope(templates, scopedStDomIDs(c), func(t models.InspectionTemplate) primitive.ObjectID { return t.StDomID })
+
+	c.JSON(http.StatusOK, gin.H{
+		"templates": templates,
+		"count":     len(templates),
+	})
+}
+
+// GetTemplate retrieves an inspection template by ID
+// GET /api/v1/inspections/templates/:id (admin only)
+func (h *InspectionHandler) GetTemplate(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid template ID"})
+		return
+	}
+
+	template, err := h.inspectionService.GetTemplateByID(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	if !requireStDomAccess(c, template.StDomID) {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"template": template})
+}
+
+// UpdateTemplate updates an inspection template
+// PUT /api/v1/inspections/templates/:id (admin only)
+func (h *InspectionHandler) UpdateTemplate(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid template ID"})
+		return
+	}
+
+	if !h.requireTemplateAccess(c, id) {
+		return
+	}
+
+	var req models.UpdateInspectionTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	template, err := h.inspectionService.UpdateTemplate(c.Request.Context(), id, req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "Inspection template updated successfully",
+		"template": template,
+	})
+}
+
+// DeleteTemplate deletes an inspection template
+// DELETE /api/v1/inspections/templates/:id (admin only)
+func (h *InspectionHandler) DeleteTemplate(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid template ID"})
+		return
+	}
+
+	if !h.requireTemplateAccess(c, id) {
+		return
+	}
+
+	if err := h.inspectionService.DeleteTemplate(c.Request.Context(), id); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Inspection template deleted successfully"})
+}
+
+// GenerateInspections runs the scheduler immediately instead of waiting for the next tick
+// POST /api/v1/inspections/generate (global admin only)
+func (h *InspectionHandler) GenerateInspections(c *gin.Context) {
+	if !requireGlobalAdmin(c) {
+		return
+	}
+
+	until := time.Now().AddDate(0, 0, config.GetInspectionConfig().LookaheadDays)
+
+	created, err := h.inspectionService.GenerateScheduledInspections(c.Request.Context(), until)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Inspections generated successfully",
+		"created": created,
+		"until":   until,
+	})
+}
+
+// GetInspections retrieves inspections with optional status, dormitory and room filters
+// GET /api/v1/inspections?status=&st_dom_id=&soba_id= (admin only)
+func (h *InspectionHandler) GetInspections(c *gin.Context) {
+	var filter services.InspectionFilter
+
+	if statusParam := c.Query("status"); statusParam != "" {
+		status := models.InspectionStatus(statusParam)
+		if !status.IsValid() {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid status. Must be 'scheduled', 'completed' or 'cancelled'"})
+			return
+		}
+		filter.Status = &status
+	}
+
+	if stDomIDParam := c.Query("st_dom_id"); stDomIDParam != "" {
+		id, err := primitive.ObjectIDFromHex(stDomIDParam)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid dormitory ID"})
+			return
+		}
+		if !requireStDomAccess(c, id) {
+			return
+		}
+		filter.StDomID = &id
+	}
+
+	if sobaIDParam := c.Query("soba_id"); sobaIDParam != "" {
+		id, err := primitive.ObjectIDFromHex(sobaIDParam)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid room ID"})
+			return
+		}
+		filter.SobaID = &id
+	}
+
+	inspections, err := h.inspectionService.GetInspections(c.Request.Context(), filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	inspections = filterInScope(inspections, scopedStDomIDs(c), func(i models.Inspection) primitive.ObjectID { return i.StDomID })
+
+	c.JSON(http.StatusOK, gin.H{
+		"inspections": inspections,
+		"count":       len(inspections),
+	})
+}
+
+// GetInspection retrieves an inspection by ID
+// GET /api/v1/inspections/:id (admin only)
+func (h *InspectionHandler) GetInspection(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid inspection ID"})
+		return
+	}
+
+	inspection, err := h.inspectionService.GetInspectionByID(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	if !requireStDomAccess(c, inspection.StDomID) {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"inspection": inspection})
+}
+
+// CompleteInspection records the checklist result of an inspection
+// POST /api/v1/inspections/:id/complete (admin only)
+func (h *InspectionHandler) CompleteInspection(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid inspection ID"})
+		return
+	}
+
+	if !h.requireInspectionAccess(c, id) {
+		return
+	}
+
+	var req models.CompleteInspectionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userIDClaim, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User not authenticated"})
+		return
+	}
+
+	completedBy, ok := userIDClaim.(primitive.ObjectID)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user ID format"})
+		return
+	}
+
+	inspection, err := h.inspectionService.CompleteInspection(c.Request.Context(), id, req, completedBy)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":    "Inspection completed successfully",
+		"inspection": inspection,
+	})
+}
+
+// CancelInspection cancels a scheduled inspection
+// PATCH /api/v1/inspections/:id/cancel (admin only)
+func (h *InspectionHandler) CancelInspection(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid inspection ID"})
+		return
+	}
+
+	if !h.requireInspectionAccess(c, id) {
+		return
+	}
+
+	inspection, err := h.inspectionService.CancelInspection(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":    "Inspection cancelled successfully",
+		"inspection": inspection,
+	})
+}
+
+// requireTemplateAccess loads the template and checks that the admin manages its dormitory
+func (h *InspectionHandler) requireTemplateAccess(c *gin.Context, id primitive.ObjectID) bool {
+	template, err := h.inspectionService.GetTemplateByID(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return false
+	}
+
+	return requireStDomAccess(c, template.StDomID)
+}
+
+// requireInspectionAccess loads the inspection and checks that the admin manages its dormitory
+func (h *InspectionHandler) requireInspectionAccess(c *gin.Context, id primitive.ObjectID) bool {
+	inspection, err := h.inspectionService.GetInspectionByID(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return false
+	}
+
+	return requireStDomAccess(c, inspection.StDomID)
+}
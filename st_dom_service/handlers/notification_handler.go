@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"net/http"
+	"st_dom_service/services"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// NotificationHandler - rukuje zahtevima za in-app inbox obavestenja
+type NotificationHandler struct {
+	notificationService *services.NotificationService
+}
+
+// kreira novi NotificationHandler sa potrebnim servisom
+func NewNotificationHandler(notificationService *services.NotificationService) *NotificationHandler {
+	return &NotificationHandler{
+		notificationService: notificationService,
+	}
+}
+
+// dobija obavestenja trenutno ulogovanog korisnika, najnovija prva
+// sa ?unread=true vraca samo neprocitana obavestenja
+func (h *NotificationHandler) GetMyNotifications(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	unreadOnly := c.Query("unread") == "true"
+
+	notificationList, err := h.notificationService.GetNotificationsByUserID(c.Request.Context(), userID, unreadOnly)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	unreadCount, err := h.notificationService.CountUnread(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"notifications": notificationList,
+		"count":         len(notificationList),
+		"unread_count":  unreadCount,
+	})
+}
+
+// oznacava obavestenje kao procitano - korisnik moze oznaciti samo svoja obavestenja
+func (h *NotificationHandler) MarkAsRead(c *gin.Context) {
+	id, err := primitive.ObjectIDFromHex(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.notificationService.MarkAsRead(c.Request.Context(), id, userID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Notification marked as read",
+	})
+}
+
+// oznacava sva obavestenja trenutno ulogovanog korisnika kao procitana
+func (h *NotificationHandler) MarkAllAsRead(c *gin.Context) {
+	userID, ok := getUserID(c)
+	if !ok {
+		return
+	}
+
+	updated, err := h.notificationService.MarkAllAsRead(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "All notifications marked as read",
+		"updated": updated,
+	})
+}
+
+// izvlaci ID korisnika iz JWT konteksta i upisuje odgovor ako ne postoji
+func getUserID(c *gin.Context) (primitive.ObjectID, bool) {
+	userIDClaim, exists := c.Get("user_id")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in token"})
+		return primitive.NilObjectID, false
+	}
+
+	userID, ok := userIDClaim.(primitive.ObjectID)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user ID format"})
+		return primitive.NilObjectID, false
+	}
+
+	return userID, true
+}
@@ -14,14 +14,16 @@ type PaymentHandler struct {
 	paymentService    *services.PaymentService
 	aplikacijaService *services.AplikacijaService
 	sobaService       *services.SobaService
+	scopeService      *services.ScopeService
 }
 
 // kreira novi PaymentHandler sa potrebnim servisima
-func NewPaymentHandler(paymentService *services.PaymentService, aplikacijaService *services.AplikacijaService, sobaService *services.SobaService) *PaymentHandler {
+func NewPaymentHandler(paymentService *services.PaymentService, aplikacijaService *services.AplikacijaService, sobaService *services.SobaService, scopeService *services.ScopeService) *PaymentHandler {
 	return &PaymentHandler{
 		paymentService:    paymentService,
 		aplikacijaService: aplikacijaService,
 		sobaService:       sobaService,
+		scopeService:      scopeService,
 	}
 }
 
@@ -45,6 +47,10 @@ func (h *PaymentHandler) CreatePayment(c *gin.Context) {
 		return
 	}
 
+	if !requireSobaAccess(c, h.scopeService, aplikacija.SobaID) {
+		return
+	}
+
 	payment, err := h.paymentService.CreatePayment(req, aplikacija)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
@@ -58,7 +64,7 @@ func (h *PaymentHandler) CreatePayment(c *gin.Context) {
 }
 
 // dobija placanje po ID-u - korisnici mogu videti samo svoja placanja
-// administratori mogu videti sva placanja
+// administratori mogu videti placanja za sobe u domovima kojima upravljaju
 func (h *PaymentHandler) GetPayment(c *gin.Context) {
 	idParam := c.Param("id")
 	id, err := primitive.ObjectIDFromHex(idParam)
@@ -103,6 +109,10 @@ func (h *PaymentHandler) GetPayment(c *gin.Context) {
 		}
 	}
 
+	if userRole == "admin" && !h.requireAplikacijaAccess(c, payment.AplikacijaID) {
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"payment": payment,
 	})
@@ -157,6 +167,11 @@ func (h *PaymentHandler) GetAllPayments(c *gin.Context) {
 		return
 	}
 
+	payments, ok := h.filterPayments(c, payments)
+	if !ok {
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"payments": payments,
 	})
@@ -188,6 +203,11 @@ func (h *PaymentHandler) SearchPaymentsByIndex(c *gin.Context) {
 		return
 	}
 
+	payments, ok := h.filterPayments(c, payments)
+	if !ok {
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"payments": payments,
 		"count":    len(payments),
@@ -211,6 +231,10 @@ func (h *PaymentHandler) GetPaymentsByRoom(c *gin.Context) {
 		return
 	}
 
+	if !requireSobaAccess(c, h.scopeService, sobaID) {
+		return
+	}
+
 	payments, err := h.paymentService.GetPaymentsBySobaID(sobaID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -237,6 +261,11 @@ func (h *PaymentHandler) GetPaymentsByUser(c *gin.Context) {
 		return
 	}
 
+	payments, ok := h.filterPayments(c, payments)
+	if !ok {
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"payments": payments,
 	})
@@ -251,12 +280,16 @@ func (h *PaymentHandler) GetPaymentsByAplikacija(c *gin.Context) {
 		return
 	}
 
-	_, err = h.aplikacijaService.GetAplikacijaByID(aplikacijaID)
+	aplikacija, err := h.aplikacijaService.GetAplikacijaByID(aplikacijaID)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Application not found"})
 		return
 	}
 
+	if !requireSobaAccess(c, h.scopeService, aplikacija.SobaID) {
+		return
+	}
+
 	payments, err := h.paymentService.GetPaymentsByAplikacijaID(aplikacijaID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -277,6 +310,10 @@ func (h *PaymentHandler) UpdatePayment(c *gin.Context) {
 		return
 	}
 
+	if !h.requirePaymentAccess(c, id) {
+		return
+	}
+
 	var req models.UpdatePaymentRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
@@ -305,6 +342,10 @@ func (h *PaymentHandler) MarkPaymentAsPaid(c *gin.Context) {
 		return
 	}
 
+	if !h.requirePaymentAccess(c, id) {
+		return
+	}
+
 	var req models.MarkPaymentPaidRequest
 	_ = c.ShouldBindJSON(&req)
 
@@ -329,6 +370,10 @@ func (h *PaymentHandler) MarkPaymentAsUnpaid(c *gin.Context) {
 		return
 	}
 
+	if !h.requirePaymentAccess(c, id) {
+		return
+	}
+
 	payment, err := h.paymentService.MarkPaymentAsUnpaid(id)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
@@ -350,6 +395,10 @@ func (h *PaymentHandler) DeletePayment(c *gin.Context) {
 		return
 	}
 
+	if !h.requirePaymentAccess(c, id) {
+		return
+	}
+
 	err = h.paymentService.DeletePayment(id)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
@@ -361,9 +410,13 @@ func (h *PaymentHandler) DeletePayment(c *gin.Context) {
 	})
 }
 
-// azurira zakasnela placanja - samo administratori
+// azurira zakasnela placanja - samo globalni administratori
 // prolazi kroz sva placanja i oznacava zakasnela
 func (h *PaymentHandler) UpdateOverduePayments(c *gin.Context) {
+	if !requireGlobalAdmin(c) {
+		return
+	}
+
 	count, err := h.paymentService.UpdateOverduePayments()
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -375,3 +428,35 @@ func (h *PaymentHandler) UpdateOverduePayments(c *gin.Context) {
 		"updated_count": count,
 	})
 }
+
+// proverava da li administrator upravlja domom kome pripada placanje (preko aplikacije i sobe)
+func (h *PaymentHandler) requirePaymentAccess(c *gin.Context, paymentID primitive.ObjectID) bool {
+	payment, err := h.paymentService.GetPaymentByID(paymentID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return false
+	}
+
+	return h.requireAplikacijaAccess(c, payment.AplikacijaID)
+}
+
+// proverava da li administrator upravlja domom kome pripada soba iz aplikacije
+func (h *PaymentHandler) requireAplikacijaAccess(c *gin.Context, aplikacijaID primitive.ObjectID) bool {
+	aplikacija, err := h.aplikacijaService.GetAplikacijaByID(aplikacijaID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Application not found"})
+		return false
+	}
+
+	return requireSobaAccess(c, h.scopeService, aplikacija.SobaID)
+}
+
+// zadrzava samo placanja za sobe u domovima kojima administrator upravlja
+func (h *PaymentHandler) filterPayments(c *gin.Context, payments []models.Payment) ([]models.Payment, bool) {
+	aplikacijaIDs, ok := scopedAplikacijaIDs(c, h.scopeService)
+	if !ok {
+		return nil, false
+	}
+
+	return filterInScope(payments, aplikacijaIDs, func(p models.Payment) primitive.ObjectID { return p.AplikacijaID }), true
+}
@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"st_dom_service/services"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// PersonalDataHandler - rukuje zahtevima SSO servisa za izvoz i brisanje licnih podataka korisnika
+type PersonalDataHandler struct {
+	personalDataService *services.PersonalDataService
+}
+
+// kreira novi PersonalDataHandler sa potrebnim servisom
+func NewPersonalDataHandler(personalDataService *services.PersonalDataService) *PersonalDataHandler {
+	return &PersonalDataHandler{
+		personalDataService: personalDataService,
+	}
+}
+
+// vraca sve podatke koje servis cuva o korisniku - SSO servis ih pakuje u arhivu za korisnika
+func (h *PersonalDataHandler) ExportPersonalData(c *gin.Context) {
+	userID, err := primitive.ObjectIDFromHex(c.Param("userId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID format"})
+		return
+	}
+
+	export, err := h.personalDataService.ExportPersonalData(c.Request.Context(), userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, export)
+}
+
+// anonimizuje podatke korisnika pre brisanja naloga u SSO servisu
+// vraca 409 ako korisnik ima sobu ili neplacene uplate - nalog tada ne sme biti obrisan
+func (h *PersonalDataHandler) ErasePersonalData(c *gin.Context) {
+	userID, err := primitive.ObjectIDFromHex(c.Param("userId"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user ID format"})
+		return
+	}
+
+	result, err := h.personalDataService.ErasePersonalData(c.Request.Context(), userID)
+	if err != nil {
+		if errors.Is(err, services.ErrErasureActiveRoom) || errors.Is(err, services.ErrErasureOutstandingPayments) {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
@@ -1,7 +1,6 @@
 package handlers
 
 import (
-	"fmt"
 	"net/http"
 	"st_dom_service/models"
 	"st_dom_service/services"
@@ -14,17 +13,22 @@ import (
 // PrihvacenaAplikacijaHandler - rukuje zahtevima vezanim za prihvacene aplikacije
 type PrihvacenaAplikacijaHandler struct {
 	prihvacenaAplikacijaService *services.PrihvacenaAplikacijaService
+	aplikacijaService           *services.AplikacijaService
+	scopeService                *services.ScopeService
 }
 
-// kreira novi PrihvacenaAplikacijaHandler sa potrebnim servisom
-func NewPrihvacenaAplikacijaHandler(prihvacenaAplikacijaService *services.PrihvacenaAplikacijaService) *PrihvacenaAplikacijaHandler {
+// kreira novi PrihvacenaAplikacijaHandler sa potrebnim servisima
+func NewPrihvacenaAplikacijaHandler(prihvacenaAplikacijaService *services.PrihvacenaAplikacijaService, aplikacijaService *services.AplikacijaService, scopeService *services.ScopeService) *PrihvacenaAplikacijaHandler {
 	return &PrihvacenaAplikacijaHandler{
 		prihvacenaAplikacijaService: prihvacenaAplikacijaService,
+		aplikacijaService:           aplikacijaService,
+		scopeService:                scopeService,
 	}
 }
 
 // odobrava aplikaciju za sobu - samo administratori mogu odobriti aplikacije
 // kreira prihvacenu aplikaciju i generiše racun za placanje
+// administrator doma moze odobriti samo aplikacije za sobe u svojim domovima
 func (h *PrihvacenaAplikacijaHandler) ApproveAplikacija(c *gin.Context) {
 	var req models.ApproveAplikacijaRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -32,6 +36,16 @@ func (h *PrihvacenaAplikacijaHandler) ApproveAplikacija(c *gin.Context) {
 		return
 	}
 
+	aplikacija, err := h.aplikacijaService.GetAplikacijaByID(req.AplikacijaID)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if !requireSobaAccess(c, h.scopeService, aplikacija.SobaID) {
+		return
+	}
+
 	prihvacenaAplikacija, err := h.prihvacenaAplikacijaService.ApproveAplikacija(req)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
@@ -59,6 +73,10 @@ func (h *PrihvacenaAplikacijaHandler) GetPrihvacenaAplikacija(c *gin.Context) {
 		return
 	}
 
+	if !requireSobaAccess(c, h.scopeService, prihvacenaAplikacija.SobaID) {
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"prihvacena_aplikacija": prihvacenaAplikacija,
 	})
@@ -121,12 +139,9 @@ func (h *PrihvacenaAplikacijaHandler) GetPrihvaceneAplikacijeForRoom(c *gin.Cont
 }
 
 // dobija sve prihvacene aplikacije za odredjenu skolsku godinu
+// administrator doma vidi samo aplikacije za sobe u svojim domovima
 func (h *PrihvacenaAplikacijaHandler) GetPrihvaceneAplikacijeForAcademicYear(c *gin.Context) {
 	academicYear := c.Query("academic_year")
-	fmt.Printf("DEBUG: st_dom_service received academicYear query: '%s'\n", academicYear)
-	fmt.Printf("DEBUG: Full request path: %s\n", c.Request.URL.Path)
-	fmt.Printf("DEBUG: Full request URL: %s\n", c.Request.URL.String())
-	
 	if academicYear == "" {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Academic year is required"})
 		return
@@ -138,6 +153,12 @@ func (h *PrihvacenaAplikacijaHandler) GetPrihvaceneAplikacijeForAcademicYear(c *
 		return
 	}
 
+	sobaIDs, ok := scopedSobaIDs(c, h.scopeService)
+	if !ok {
+		return
+	}
+	prihvaceneAplikacije = filterInScope(prihvaceneAplikacije, sobaIDs, func(p models.PrihvacenaAplikacija) primitive.ObjectID { return p.SobaID })
+
 	c.JSON(http.StatusOK, gin.H{
 		"prihvacene_aplikacije": prihvaceneAplikacije,
 		"count":                  len(prihvaceneAplikacije),
@@ -153,7 +174,7 @@ func (h *PrihvacenaAplikacijaHandler) GetTopStudentsByProsek(c *gin.Context) {
 		limit = 10
 	}
 
-	topStudents, err := h.prihvacenaAplikacijaService.GetTopStudentsByProsek(limit)
+	topStudents, err := h.topStudents(c, "", limit)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -180,7 +201,7 @@ func (h *PrihvacenaAplikacijaHandler) GetTopStudentsByProsekForAcademicYear(c *g
 		limit = 10
 	}
 
-	topStudents, err := h.prihvacenaAplikacijaService.GetTopStudentsByProsekForAcademicYear(academicYear, limit)
+	topStudents, err := h.topStudents(c, academicYear, limit)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -203,6 +224,10 @@ func (h *PrihvacenaAplikacijaHandler) GetTopStudentsByProsekForRoom(c *gin.Conte
 		return
 	}
 
+	if !requireSobaAccess(c, h.scopeService, sobaID) {
+		return
+	}
+
 	limitStr := c.DefaultQuery("limit", "10")
 	limit, err := strconv.Atoi(limitStr)
 	if err != nil || limit <= 0 {
@@ -258,6 +283,16 @@ func (h *PrihvacenaAplikacijaHandler) DeletePrihvacenaAplikacija(c *gin.Context)
 		return
 	}
 
+	prihvacenaAplikacija, err := h.prihvacenaAplikacijaService.GetPrihvacenaAplikacijaByID(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	if !requireSobaAccess(c, h.scopeService, prihvacenaAplikacija.SobaID) {
+		return
+	}
+
 	err = h.prihvacenaAplikacijaService.DeletePrihvacenaAplikacija(id)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
@@ -278,7 +313,20 @@ func (h *PrihvacenaAplikacijaHandler) EvictStudent(c *gin.Context) {
 		return
 	}
 
-	err := h.prihvacenaAplikacijaService.EvictStudent(req.UserID, req.Reason)
+	// administrator doma moze izbaciti samo studente iz soba u svojim domovima
+	prihvaceneAplikacije, err := h.prihvacenaAplikacijaService.GetPrihvaceneAplikacijeByUserID(req.UserID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	for _, prihvacenaAplikacija := range prihvaceneAplikacije {
+		if !requireSobaAccess(c, h.scopeService, prihvacenaAplikacija.SobaID) {
+			return
+		}
+	}
+
+	err = h.prihvacenaAplikacijaService.EvictStudent(req.UserID, req.Reason)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
@@ -291,6 +339,33 @@ func (h *PrihvacenaAplikacijaHandler) EvictStudent(c *gin.Context) {
 	})
 }
 
+// vraca najbolje studente - globalni administratori vide sve, administratori doma samo svoje domove
+// prazna skolska godina znaci sve godine
+func (h *PrihvacenaAplikacijaHandler) topStudents(c *gin.Context, academicYear string, limit int) ([]models.PrihvacenaAplikacija, error) {
+	scope := getAdminScope(c)
+	if scope.Global {
+		if academicYear == "" {
+			return h.prihvacenaAplikacijaService.GetTopStudentsByProsek(limit)
+		}
+		return h.prihvacenaAplikacijaService.GetTopStudentsByProsekForAcademicYear(academicYear, limit)
+	}
+
+	sobaIDs, err := h.scopeService.SobaIDsInScope(scope)
+	if err != nil {
+		return nil, err
+	}
+	if len(sobaIDs) == 0 {
+		return []models.PrihvacenaAplikacija{}, nil
+	}
+
+	ids := make([]primitive.ObjectID, 0, len(sobaIDs))
+	for id := range sobaIDs {
+		ids = append(ids, id)
+	}
+
+	return h.prihvacenaAplikacijaService.GetTopStudentsByProsekForSobas(ids, academicYear, limit)
+}
+
 // student dobrovoljno napusta sobu - korisnik moze sam da se odjavi iz sobe
 func (h *PrihvacenaAplikacijaHandler) CheckoutFromRoom(c *gin.Context) {
 	userIDClaim, exists := c.Get("user_id")
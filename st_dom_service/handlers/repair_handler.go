@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"log"
 	"net/http"
 	"st_dom_service/models"
 	"st_dom_service/services"
@@ -11,12 +12,16 @@ import (
 )
 
 type RepairHandler struct {
-	repairService *services.RepairService
+	repairService     *services.RepairService
+	attachmentService *services.AttachmentService
+	scopeService      *services.ScopeService
 }
 
-func NewRepairHandler(repairService *services.RepairService) *RepairHandler {
+func NewRepairHandler(repairService *services.RepairService, attachmentService *services.AttachmentService, scopeService *services.ScopeService) *RepairHandler {
 	return &RepairHandler{
-		repairService: repairService,
+		repairService:     repairService,
+		attachmentService: attachmentService,
+		scopeService:      scopeService,
 	}
 }
 
@@ -36,6 +41,10 @@ func (h *RepairHandler) CreateRepair(c *gin.Context) {
 		return
 	}
 
+	if !requireSobaAccess(c, h.scopeService, sobaID) {
+		return
+	}
+
 	// Parse estimated completion date
 	estimatedCompletionDate, err := time.Parse(time.RFC3339, req.EstimatedCompletionDate)
 	if err != nil {
@@ -77,6 +86,11 @@ func (h *RepairHandler) GetAllRepairs(c *gin.Context) {
 		return
 	}
 
+	repairs, ok := h.filterRepairs(c, repairs)
+	if !ok {
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"repairs": repairs,
 		"count":   len(repairs),
@@ -99,6 +113,10 @@ func (h *RepairHandler) GetRepair(c *gin.Context) {
 		return
 	}
 
+	if !requireSobaAccess(c, h.scopeService, repair.SobaID) {
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{"repair": repair})
 }
 
@@ -112,6 +130,10 @@ func (h *RepairHandler) GetRepairsByRoom(c *gin.Context) {
 		return
 	}
 
+	if !requireSobaAccess(c, h.scopeService, roomID) {
+		return
+	}
+
 	repairs, err := h.repairService.GetRepairsByRoom(c.Request.Context(), roomID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
@@ -135,6 +157,11 @@ func (h *RepairHandler) GetRepairsByStatus(c *gin.Context) {
 		return
 	}
 
+	repairs, ok := h.filterRepairs(c, repairs)
+	if !ok {
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"repairs": repairs,
 		"count":   len(repairs),
@@ -152,6 +179,10 @@ func (h *RepairHandler) UpdateRepair(c *gin.Context) {
 		return
 	}
 
+	if !h.requireRepairAccess(c, id) {
+		return
+	}
+
 	var req models.UpdateRepairRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
@@ -190,12 +221,41 @@ func (h *RepairHandler) DeleteRepair(c *gin.Context) {
 		return
 	}
 
+	if !h.requireRepairAccess(c, id) {
+		return
+	}
+
 	err = h.repairService.DeleteRepair(c.Request.Context(), id)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
+	// Remove photos of the repair as well; a failure only leaves orphaned files
+	if err := h.attachmentService.DeleteAttachmentsByOwner(c.Request.Context(), models.AttachmentOwnerRepair, id); err != nil {
+		log.Println("Failed to delete repair attachments:", err)
+	}
+
 	c.JSON(http.StatusOK, gin.H{"message": "Repair deleted successfully"})
 }
 
+// requireRepairAccess loads the repair and checks that the admin manages the dormitory of its room
+func (h *RepairHandler) requireRepairAccess(c *gin.Context, id primitive.ObjectID) bool {
+	repair, err := h.repairService.GetRepairByID(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return false
+	}
+
+	return requireSobaAccess(c, h.scopeService, repair.SobaID)
+}
+
+// filterRepairs keeps only repairs for rooms in dormitories the admin manages
+func (h *RepairHandler) filterRepairs(c *gin.Context, repairs []models.Repair) ([]models.Repair, bool) {
+	sobaIDs, ok := scopedSobaIDs(c, h.scopeService)
+	if !ok {
+		return nil, false
+	}
+
+	return filterInScope(repairs, sobaIDs, func(r models.Repair) primitive.ObjectID { return r.SobaID }), true
+}
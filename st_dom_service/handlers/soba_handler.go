@@ -13,18 +13,20 @@ import (
 type SobaHandler struct {
 	sobaService  *services.SobaService
 	stDomService *services.StDomService
+	scopeService *services.ScopeService
 }
 
 // kreira novi SobaHandler sa potrebnim servisima
-func NewSobaHandler(sobaService *services.SobaService, stDomService *services.StDomService) *SobaHandler {
+func NewSobaHandler(sobaService *services.SobaService, stDomService *services.StDomService, scopeService *services.ScopeService) *SobaHandler {
 	return &SobaHandler{
 		sobaService:  sobaService,
 		stDomService: stDomService,
+		scopeService: scopeService,
 	}
 }
 
 // kreira novu sobu u studentskom domu
-// proverava da li dom postoji pre kreiranja sobe
+// proverava da li dom postoji i da li njime upravlja administrator pre kreiranja sobe
 func (h *SobaHandler) CreateSoba(c *gin.Context) {
 	var req models.CreateSobaRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -32,6 +34,10 @@ func (h *SobaHandler) CreateSoba(c *gin.Context) {
 		return
 	}
 
+	if !requireStDomAccess(c, req.StDomID) {
+		return
+	}
+
 	_, err := h.stDomService.GetStDomByID(req.StDomID)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Student dormitory not found"})
@@ -93,6 +99,10 @@ func (h *SobaHandler) UpdateSoba(c *gin.Context) {
 		return
 	}
 
+	if !requireSobaAccess(c, h.scopeService, id) {
+		return
+	}
+
 	var req models.UpdateSobaRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
@@ -120,6 +130,10 @@ func (h *SobaHandler) DeleteSoba(c *gin.Context) {
 		return
 	}
 
+	if !requireSobaAccess(c, h.scopeService, id) {
+		return
+	}
+
 	err = h.sobaService.DeleteSoba(id)
 	if err != nil {
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 	"st_dom_service/models"
 	"st_dom_service/services"
@@ -24,8 +25,12 @@ func NewStDomHandler(stDomService *services.StDomService, sobaService *services.
 }
 
 // kreira novi studentski dom - prima podatke, validira ih i cuva u bazu
-// proverava da li vec postoji dom sa istom adresom
+// proverava da li vec postoji dom sa istom adresom, dozvoljeno samo globalnim administratorima
 func (h *StDomHandler) CreateStDom(c *gin.Context) {
+	if !requireGlobalAdmin(c) {
+		return
+	}
+
 	var req models.CreateStDomRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
@@ -33,6 +38,10 @@ func (h *StDomHandler) CreateStDom(c *gin.Context) {
 	}
 
 	stDom, err := h.stDomService.CreateStDom(req)
+	if errors.Is(err, services.ErrIncompleteCoordinates) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
 		return
@@ -79,7 +88,7 @@ func (h *StDomHandler) GetAllStDoms(c *gin.Context) {
 }
 
 // azurira podatke o studentskom domu
-// prima ID i nove podatke, validira ih i cuva promene
+// prima ID i nove podatke, validira ih i cuva promene - administrator mora upravljati domom
 func (h *StDomHandler) UpdateStDom(c *gin.Context) {
 	idParam := c.Param("id")
 	id, err := primitive.ObjectIDFromHex(idParam)
@@ -88,6 +97,10 @@ func (h *StDomHandler) UpdateStDom(c *gin.Context) {
 		return
 	}
 
+	if !requireStDomAccess(c, id) {
+		return
+	}
+
 	var req models.UpdateStDomRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
@@ -107,8 +120,12 @@ func (h *StDomHandler) UpdateStDom(c *gin.Context) {
 }
 
 // brise studentski dom i sve povezane sobe
-// prvo brise sve sobe koje pripadaju domu, zatim brise sam dom
+// prvo brise sve sobe koje pripadaju domu, zatim brise sam dom - samo globalni administratori
 func (h *StDomHandler) DeleteStDom(c *gin.Context) {
+	if !requireGlobalAdmin(c) {
+		return
+	}
+
 	idParam := c.Param("id")
 	id, err := primitive.ObjectIDFromHex(idParam)
 	if err != nil {
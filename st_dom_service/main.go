@@ -1,12 +1,16 @@
 package main
 
 import (
+	"context"
 	"log"
 	"st_dom_service/config"
 	"st_dom_service/database"
 	"st_dom_service/handlers"
+	"st_dom_service/notifications"
 	"st_dom_service/routes"
 	"st_dom_service/services"
+	"st_dom_service/storage"
+	"st_dom_service/utils"
 
 	"github.com/gin-gonic/gin"
 )
@@ -34,24 +38,79 @@ func main() {
 	prihvaceneAplikacijeCollection := db.GetCollection("prihvacene_aplikacije")
 	paymentsCollection := db.GetCollection("payments")
 
-	stDomService := services.NewStDomService(stDomsCollection)
+	// servisni token za pozive internih ruta SSO servisa
+	serviceTokens := utils.NewServiceTokenSource(cfg.SSOServiceURL, cfg.ServiceClientID, cfg.ServiceClientSecret)
+
+	// gazetteer je opcion - bez njega administratori sami unose koordinate domova
+	var gazetteer *services.Gazetteer
+	if cfg.GazetteerFile != "" {
+		gazetteer, err = services.LoadGazetteer(cfg.GazetteerFile)
+		if err != nil {
+			log.Fatal("Failed to load gazetteer:", err)
+		}
+		log.Printf("Loaded %d addresses from gazetteer %s", gazetteer.Len(), cfg.GazetteerFile)
+	}
+
+	stDomService := services.NewStDomService(stDomsCollection, gazetteer)
 	sobaService := services.NewSobaService(sobasCollection, prihvaceneAplikacijeCollection)
-	aplikacijaService := services.NewAplikacijaService(aplikacijeCollection)
+	aplikacijaService := services.NewAplikacijaService(aplikacijeCollection, services.NewStudentDirectory(cfg.SSOServiceURL, serviceTokens))
 	paymentService := services.NewPaymentService(paymentsCollection)
-	prihvacenaAplikacijaService := services.NewPrihvacenaAplikacijaService(prihvaceneAplikacijeCollection, aplikacijaService, paymentService)
-	repairService := services.NewRepairService(db.GetDatabase())
+	inspectionService := services.NewInspectionService(db.GetDatabase(), sobaService)
+	prihvacenaAplikacijaService := services.NewPrihvacenaAplikacijaService(prihvaceneAplikacijeCollection, aplikacijaService, paymentService, inspectionService)
+
+	var notificationChannels []notifications.Channel
+	notificationConfig := config.GetNotificationConfig()
+	if notificationConfig.EmailEnabled {
+		notificationChannels = append(notificationChannels, notifications.NewEmailChannel(notificationConfig, cfg.SSOServiceURL, serviceTokens))
+	}
+	notificationService := services.NewNotificationService(db.GetDatabase(), prihvacenaAplikacijaService, notificationChannels...)
+	repairService := services.NewRepairService(db.GetDatabase(), notificationService)
+
+	attachmentConfig := config.GetAttachmentConfig()
+	blobStore, err := storage.NewLocalBlobStore(attachmentConfig.StorageDir)
+	if err != nil {
+		log.Fatal("Failed to initialize attachment storage:", err)
+	}
+	attachmentService := services.NewAttachmentService(db.GetDatabase(), blobStore, attachmentConfig)
+	scopeService := services.NewScopeService(db.GetDatabase())
+
+	privacyConfig := config.GetPrivacyConfig()
+	personalDataService := services.NewPersonalDataService(db.GetDatabase(), prihvacenaAplikacijaService, paymentService, attachmentService, privacyConfig)
+	if erased, err := personalDataService.EraseLegacyProfileFields(context.Background()); err != nil {
+		log.Println("Failed to erase profile fields of anonymized applications:", err)
+	} else if erased > 0 {
+		log.Printf("Erased profile fields of %d anonymized applications", erased)
+	}
+	if privacyConfig.PurgeEnabled {
+		personalDataService.StartRetentionPurge(privacyConfig)
+	}
+
+	inspectionConfig := config.GetInspectionConfig()
+	if inspectionConfig.SchedulerEnabled {
+		inspectionService.StartScheduler(inspectionConfig)
+	}
 
 	stDomHandler := handlers.NewStDomHandler(stDomService, sobaService)
-	sobaHandler := handlers.NewSobaHandler(sobaService, stDomService)
-	aplikacijaHandler := handlers.NewAplikacijaHandler(aplikacijaService, sobaService)
-	prihvacenaAplikacijaHandler := handlers.NewPrihvacenaAplikacijaHandler(prihvacenaAplikacijaService)
-	paymentHandler := handlers.NewPaymentHandler(paymentService, aplikacijaService, sobaService)
-	repairHandler := handlers.NewRepairHandler(repairService)
+	sobaHandler := handlers.NewSobaHandler(sobaService, stDomService, scopeService)
+	aplikacijaHandler := handlers.NewAplikacijaHandler(aplikacijaService, sobaService, attachmentService, scopeService)
+	prihvacenaAplikacijaHandler := handlers.NewPrihvacenaAplikacijaHandler(prihvacenaAplikacijaService, aplikacijaService, scopeService)
+	paymentHandler := handlers.NewPaymentHandler(paymentService, aplikacijaService, sobaService, scopeService)
+	repairHandler := handlers.NewRepairHandler(repairService, attachmentService, scopeService)
+	inspectionHandler := handlers.NewInspectionHandler(inspectionService, stDomService, scopeService)
+	attachmentHandler := handlers.NewAttachmentHandler(attachmentService, aplikacijaService, repairService, scopeService)
+	notificationHandler := handlers.NewNotificationHandler(notificationService)
+	personalDataHandler := handlers.NewPersonalDataHandler(personalDataService)
 	healthHandler := handlers.NewHealthHandler()
 
+	jwksCache := utils.NewJWKSCache(cfg.SSOServiceURL + "/.well-known/jwks.json")
+	jwksCache.Start(cfg.JWKSRefresh)
+
+	tokenDenylist := utils.NewTokenDenylist(cfg.SSOServiceURL, serviceTokens)
+	tokenDenylist.Start(cfg.TokenDenylistRefresh)
+
 	router := gin.Default()
 
-	routes.SetupRoutes(router, stDomHandler, sobaHandler, aplikacijaHandler, prihvacenaAplikacijaHandler, paymentHandler, repairHandler, healthHandler, cfg.JWTSecret)
+	routes.SetupRoutes(router, stDomHandler, sobaHandler, aplikacijaHandler, prihvacenaAplikacijaHandler, paymentHandler, repairHandler, inspectionHandler, attachmentHandler, notificationHandler, personalDataHandler, healthHandler, jwksCache, tokenDenylist)
 
 	log.Printf("Server starting on port %s", cfg.Port)
 	if err := router.Run(":" + cfg.Port); err != nil {
@@ -2,15 +2,17 @@ package middleware
 
 import (
 	"net/http"
+	"st_dom_service/models"
 	"st_dom_service/utils"
 	"strings"
 
 	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 // middleware za validaciju JWT tokena - proverava Authorization header
-// izvlaci token, validira ga i postavlja korisnicke podatke u kontekst
-func AuthMiddleware(jwtSecret string) gin.HandlerFunc {
+// izvlaci token, validira ga, odbija opozvane tokene i postavlja korisnicke podatke u kontekst
+func AuthMiddleware(keys *utils.JWKSCache, denylist *utils.TokenDenylist) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
@@ -27,22 +29,72 @@ func AuthMiddleware(jwtSecret string) gin.HandlerFunc {
 
 		token := strings.TrimPrefix(authHeader, "Bearer ")
 
-		claims, err := utils.ValidateJWT(token, jwtSecret)
+		claims, err := utils.ValidateJWT(token, keys)
 		if err != nil {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
 			c.Abort()
 			return
 		}
 
+		if denylist.IsRevoked(claims.ID) {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Token has been revoked"})
+			c.Abort()
+			return
+		}
+
 		c.Set("user_id", claims.UserID)
 		c.Set("username", claims.Username)
 		c.Set("email", claims.Email)
 		c.Set("role", claims.Role)
 
+		if claims.Role == "admin" {
+			c.Set("admin_scope", adminScope(claims))
+		}
+
 		c.Next()
 	}
 }
 
+// middleware za interne rute - prihvata samo servisne tokene koje je izdao SSO servis
+// ID servisa koji poziva postavlja se u kontekst kao "service_client"
+func ServiceAuthMiddleware(keys *utils.JWKSCache) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		authHeader := c.GetHeader("Authorization")
+		if !strings.HasPrefix(authHeader, "Bearer ") {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Service token required"})
+			c.Abort()
+			return
+		}
+
+		claims, err := utils.ValidateServiceJWT(strings.TrimPrefix(authHeader, "Bearer "), keys)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid service token"})
+			c.Abort()
+			return
+		}
+
+		c.Set("service_client", claims.Subject)
+
+		c.Next()
+	}
+}
+
+// pravi opseg administratora iz tokena - globalni je samo administrator sa super_admin
+// administrator bez st_dom_ids ne upravlja nijednim domom, a neispravni ID-evi se preskacu
+func adminScope(claims *utils.JWTClaims) models.AdminScope {
+	if claims.SuperAdmin {
+		return models.AdminScope{Global: true}
+	}
+
+	scope := models.AdminScope{StDomIDs: make([]primitive.ObjectID, 0, len(claims.StDomIDs))}
+	for _, hex := range claims.StDomIDs {
+		if id, err := primitive.ObjectIDFromHex(hex); err == nil {
+			scope.StDomIDs = append(scope.StDomIDs, id)
+		}
+	}
+	return scope
+}
+
 // middleware za proveru korisnicke uloge - proverava da li korisnik ima potrebnu ulogu
 // blokira pristup ako korisnik nema odgovarajucu ulogu
 func RoleMiddleware(requiredRole string) gin.HandlerFunc {
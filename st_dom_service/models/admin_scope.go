@@ -0,0 +1,24 @@
+package models
+
+import "go.mongodb.org/mongo-driver/bson/primitive"
+
+// AdminScope describes which student dormitories an administrator may manage.
+// Global administrators (tokens with super_admin) manage every dormitory,
+// dorm managers only the dormitories listed in StDomIDs; an empty scope allows nothing.
+type AdminScope struct {
+	Global   bool
+	StDomIDs []primitive.ObjectID
+}
+
+// Allows reports whether the scope covers the given student dormitory
+func (s AdminScope) Allows(stDomID primitive.ObjectID) bool {
+	if s.Global {
+		return true
+	}
+	for _, id := range s.StDomIDs {
+		if id == stDomID {
+			return true
+		}
+	}
+	return false
+}
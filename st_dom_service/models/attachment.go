@@ -0,0 +1,40 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// AttachmentOwnerType represents the kind of record an attachment belongs to
+type AttachmentOwnerType string
+
+const (
+	AttachmentOwnerRepair     AttachmentOwnerType = "repair"
+	AttachmentOwnerAplikacija AttachmentOwnerType = "aplikacija"
+)
+
+// IsValid checks if the AttachmentOwnerType value is valid
+func (t AttachmentOwnerType) IsValid() bool {
+	switch t {
+	case AttachmentOwnerRepair, AttachmentOwnerAplikacija:
+		return true
+	}
+	return false
+}
+
+// Attachment represents an uploaded photo or document linked to a Repair or an Aplikacija
+// The file content lives in the blob store under StorageKey
+type Attachment struct {
+	ID          primitive.ObjectID  `bson:"_id,omitempty" json:"id,omitempty"`
+	OwnerType   AttachmentOwnerType `bson:"owner_type" json:"owner_type"`
+	OwnerID     primitive.ObjectID  `bson:"owner_id" json:"owner_id"`
+	FileName    string              `bson:"file_name" json:"file_name"`
+	ContentType string              `bson:"content_type" json:"content_type"`
+	Size        int64               `bson:"size" json:"size"`
+	Checksum    string              `bson:"checksum" json:"checksum"` // SHA-256, hex encoded
+	StorageKey  string              `bson:"storage_key" json:"-"`
+	Description string              `bson:"description,omitempty" json:"description,omitempty"`
+	UploadedBy  primitive.ObjectID  `bson:"uploaded_by" json:"uploaded_by"`
+	CreatedAt   time.Time           `bson:"created_at" json:"created_at"`
+}
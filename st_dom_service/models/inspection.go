@@ -0,0 +1,273 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// RecurrenceFrequency represents how often a scheduled inspection repeats
+type RecurrenceFrequency string
+
+const (
+	RecurrenceDaily   RecurrenceFrequency = "daily"
+	RecurrenceWeekly  RecurrenceFrequency = "weekly"
+	RecurrenceMonthly RecurrenceFrequency = "monthly"
+	RecurrenceYearly  RecurrenceFrequency = "yearly"
+)
+
+// IsValid checks if the RecurrenceFrequency value is valid
+func (f RecurrenceFrequency) IsValid() bool {
+	switch f {
+	case RecurrenceDaily, RecurrenceWeekly, RecurrenceMonthly, RecurrenceYearly:
+		return true
+	}
+	return false
+}
+
+// MaxInterval is the largest interval allowed for the frequency, about ten years for every frequency
+// It also keeps the period of a schedule far below the longest time.Duration
+func (f RecurrenceFrequency) MaxInterval() int {
+	switch f {
+	case RecurrenceDaily:
+		return 3660
+	case RecurrenceWeekly:
+		return 520
+	case RecurrenceYearly:
+		return 10
+	default:
+		return 120
+	}
+}
+
+// InspectionTrigger represents what generates inspections from a template
+type InspectionTrigger string
+
+const (
+	InspectionTriggerSchedule InspectionTrigger = "schedule" // Generated ahead of time by the scheduler
+	InspectionTriggerCheckout InspectionTrigger = "checkout" // Generated when a student checks out of a room
+)
+
+// IsValid checks if the InspectionTrigger value is valid
+func (t InspectionTrigger) IsValid() bool {
+	switch t {
+	case InspectionTriggerSchedule, InspectionTriggerCheckout:
+		return true
+	}
+	return false
+}
+
+// InspectionStatus represents the inspection status enum
+type InspectionStatus string
+
+const (
+	InspectionStatusScheduled InspectionStatus = "scheduled"
+	InspectionStatusCompleted InspectionStatus = "completed"
+	InspectionStatusCancelled InspectionStatus = "cancelled"
+)
+
+// IsValid checks if the InspectionStatus value is valid
+func (s InspectionStatus) IsValid() bool {
+	switch s {
+	case InspectionStatusScheduled, InspectionStatusCompleted, InspectionStatusCancelled:
+		return true
+	}
+	return false
+}
+
+// RecurrenceRule describes when a scheduled inspection repeats
+// e.g. {frequency: "monthly", interval: 6} means every six months
+type RecurrenceRule struct {
+	Frequency RecurrenceFrequency `bson:"frequency" json:"frequency"`
+	Interval  int                 `bson:"interval" json:"interval" binding:"min=0,max=3660"` // At most Frequency.MaxInterval()
+}
+
+// Occurrence returns the n-th occurrence of a schedule that starts at anchor (n = 0 is the anchor itself)
+// Monthly and yearly occurrences keep the day of the anchor and fall on the last day of shorter months,
+// so a schedule on the 31st stays at the end of every month instead of drifting into the next one
+func (r RecurrenceRule) Occurrence(anchor time.Time, n int) time.Time {
+	interval := r.interval()
+
+	switch r.Frequency {
+	case RecurrenceDaily:
+		return anchor.AddDate(0, 0, n*interval)
+	case RecurrenceWeekly:
+		return anchor.AddDate(0, 0, 7*n*interval)
+	case RecurrenceYearly:
+		return addMonthsClamped(anchor, 12*n*interval)
+	default:
+		return addMonthsClamped(anchor, n*interval)
+	}
+}
+
+// Next returns the first occurrence after the given time of a schedule that starts at anchor
+func (r RecurrenceRule) Next(anchor, after time.Time) time.Time {
+	if after.Before(anchor) {
+		return anchor
+	}
+
+	// The estimate uses the longest possible period, so it never passes the answer
+	n := int(after.Sub(anchor) / r.maxPeriod())
+	for !r.Occurrence(anchor, n).After(after) {
+		n++
+	}
+	return r.Occurrence(anchor, n)
+}
+
+func (r RecurrenceRule) interval() int {
+	if r.Interval < 1 {
+		return 1
+	}
+	return min(r.Interval, r.Frequency.MaxInterval())
+}
+
+// maxPeriod is the longest time between two occurrences, including a daylight saving hour
+func (r RecurrenceRule) maxPeriod() time.Duration {
+	day := 24 * time.Hour
+	switch r.Frequency {
+	case RecurrenceDaily:
+		return time.Duration(r.interval())*day + time.Hour
+	case RecurrenceWeekly:
+		return time.Duration(r.interval())*7*day + time.Hour
+	case RecurrenceYearly:
+		return time.Duration(r.interval())*366*day + time.Hour
+	default:
+		return time.Duration(r.interval())*31*day + time.Hour
+	}
+}
+
+// addMonthsClamped adds months to t and keeps its day, using the last day of the month when the month is shorter
+func addMonthsClamped(t time.Time, months int) time.Time {
+	year, month, day := t.Date()
+	first := time.Date(year, month+time.Month(months), 1, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+	lastDay := first.AddDate(0, 1, -1).Day()
+	return first.AddDate(0, 0, min(day, lastDay)-1)
+}
+
+// InspectionTemplate represents a recurring or checkout-triggered inspection
+// A template is scoped to a whole dormitory (StDomID) or to a single room (SobaID)
+type InspectionTemplate struct {
+	ID          primitive.ObjectID  `bson:"_id,omitempty" json:"id,omitempty"`
+	Name        string              `bson:"name" json:"name"`
+	Description string              `bson:"description,omitempty" json:"description,omitempty"`
+	StDomID     primitive.ObjectID  `bson:"st_dom_id" json:"st_dom_id"`
+	SobaID      *primitive.ObjectID `bson:"soba_id,omitempty" json:"soba_id,omitempty"`
+	Trigger     InspectionTrigger   `bson:"trigger" json:"trigger"`
+	Recurrence  *RecurrenceRule     `bson:"recurrence,omitempty" json:"recurrence,omitempty"`   // Only for "schedule" templates
+	AnchorDate  *time.Time          `bson:"anchor_date,omitempty" json:"anchor_date,omitempty"` // First occurrence; all occurrences are computed from it
+	NextDueDate *time.Time          `bson:"next_due_date,omitempty" json:"next_due_date,omitempty"`
+	Checklist   []string            `bson:"checklist" json:"checklist"`
+	IsActive    bool                `bson:"is_active" json:"is_active"`
+	CreatedBy   primitive.ObjectID  `bson:"created_by" json:"created_by"` // Admin user ID
+	CreatedAt   time.Time           `bson:"created_at" json:"created_at"`
+	UpdatedAt   time.Time           `bson:"updated_at" json:"updated_at"`
+}
+
+// ChecklistItemResult represents the outcome of one checklist item
+type ChecklistItemResult struct {
+	Item   string `bson:"item" json:"item"`
+	Passed *bool  `bson:"passed,omitempty" json:"passed,omitempty"` // nil until the inspection is completed
+	Note   string `bson:"note,omitempty" json:"note,omitempty"`
+}
+
+// Inspection represents a single inspection task generated from a template or a checkout
+type Inspection struct {
+	ID          primitive.ObjectID    `bson:"_id,omitempty" json:"id,omitempty"`
+	TemplateID  *primitive.ObjectID   `bson:"template_id,omitempty" json:"template_id,omitempty"`
+	Name        string                `bson:"name" json:"name"`
+	StDomID     primitive.ObjectID    `bson:"st_dom_id" json:"st_dom_id"`
+	SobaID      *primitive.ObjectID   `bson:"soba_id,omitempty" json:"soba_id,omitempty"`
+	Trigger     InspectionTrigger     `bson:"trigger" json:"trigger"`
+	DueDate     time.Time             `bson:"due_date" json:"due_date"`
+	Status      InspectionStatus      `bson:"status" json:"status"`
+	Checklist   []ChecklistItemResult `bson:"checklist" json:"checklist"`
+	Notes       string                `bson:"notes,omitempty" json:"notes,omitempty"`
+	CompletedAt *time.Time            `bson:"completed_at,omitempty" json:"completed_at,omitempty"`
+	CompletedBy *primitive.ObjectID   `bson:"completed_by,omitempty" json:"completed_by,omitempty"`
+	CreatedAt   time.Time             `bson:"created_at" json:"created_at"`
+	UpdatedAt   time.Time             `bson:"updated_at" json:"updated_at"`
+}
+
+// CreateInspectionTemplateRequest represents the request body for creating an inspection template
+type CreateInspectionTemplateRequest struct {
+	Name        string              `json:"name" binding:"required"`
+	Description string              `json:"description,omitempty"`
+	StDomID     primitive.ObjectID  `json:"st_dom_id"`
+	SobaID      *primitive.ObjectID `json:"soba_id,omitempty"`
+	Trigger     InspectionTrigger   `json:"trigger" binding:"required"`
+	Recurrence  *RecurrenceRule     `json:"recurrence,omitempty"`
+	StartDate   *time.Time          `json:"start_date,omitempty"` // First due date for "schedule" templates
+	Checklist   []string            `json:"checklist" binding:"required,min=1"`
+}
+
+// UpdateInspectionTemplateRequest represents the request body for updating an inspection template
+type UpdateInspectionTemplateRequest struct {
+	Name        *string         `json:"name,omitempty"`
+	Description *string         `json:"description,omitempty"`
+	Recurrence  *RecurrenceRule `json:"recurrence,omitempty"`
+	NextDueDate *time.Time      `json:"next_due_date,omitempty"`
+	Checklist   *[]string       `json:"checklist,omitempty"`
+	IsActive    *bool           `json:"is_active,omitempty"`
+}
+
+// CompleteInspectionRequest represents the request body for recording an inspection result
+type CompleteInspectionRequest struct {
+	Checklist []ChecklistItemResult `json:"checklist" binding:"required,min=1"`
+	Notes     string                `json:"notes,omitempty"`
+}
+
+// DefaultCheckoutChecklist is used for checkout inspections when no checkout template matches the room
+var DefaultCheckoutChecklist = []string{
+	"Keys returned",
+	"Furniture undamaged",
+	"Walls and floor undamaged",
+	"Room cleaned",
+}
+
+// NewInspectionTemplate creates a new inspection template with default values
+func NewInspectionTemplate(req CreateInspectionTemplateRequest, createdBy primitive.ObjectID) InspectionTemplate {
+	template := InspectionTemplate{
+		Name:        req.Name,
+		Description: req.Description,
+		StDomID:     req.StDomID,
+		SobaID:      req.SobaID,
+		Trigger:     req.Trigger,
+		Checklist:   req.Checklist,
+		IsActive:    true,
+		CreatedBy:   createdBy,
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
+	}
+
+	if req.Trigger == InspectionTriggerSchedule {
+		template.Recurrence = req.Recurrence
+		nextDueDate := time.Now()
+		if req.StartDate != nil {
+			nextDueDate = *req.StartDate
+		}
+		template.AnchorDate = &nextDueDate
+		template.NextDueDate = &nextDueDate
+	}
+
+	return template
+}
+
+// NewInspection creates a new scheduled inspection with an empty checklist result
+func NewInspection(name string, stDomID primitive.ObjectID, sobaID *primitive.ObjectID, trigger InspectionTrigger, dueDate time.Time, checklist []string) Inspection {
+	items := make([]ChecklistItemResult, 0, len(checklist))
+	for _, item := range checklist {
+		items = append(items, ChecklistItemResult{Item: item})
+	}
+
+	return Inspection{
+		Name:      name,
+		StDomID:   stDomID,
+		SobaID:    sobaID,
+		Trigger:   trigger,
+		DueDate:   dueDate,
+		Status:    InspectionStatusScheduled,
+		Checklist: items,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+}
@@ -0,0 +1,85 @@
+package models
+
+import (
+	"testing"
+	"time"
+	_ "time/tzdata"
+)
+
+func TestRecurrenceRuleOccurrence(t *testing.T) {
+	belgrade, err := time.LoadLocation("Europe/Belgrade")
+	if err != nil {
+		t.Fatal(err)
+	}
+	date := func(year int, month time.Month, day, hour int, loc *time.Location) time.Time {
+		return time.Date(year, month, day, hour, 0, 0, 0, loc)
+	}
+
+	tests := []struct {
+		name   string
+		rule   RecurrenceRule
+		anchor time.Time
+		n      int
+		want   time.Time
+	}{
+		{"monthly on the 31st in February of a leap year", RecurrenceRule{RecurrenceMonthly, 1}, date(2024, time.January, 31, 9, time.UTC), 1, date(2024, time.February, 29, 9, time.UTC)},
+		{"monthly on the 31st returns to the 31st", RecurrenceRule{RecurrenceMonthly, 1}, date(2024, time.January, 31, 9, time.UTC), 2, date(2024, time.March, 31, 9, time.UTC)},
+		{"monthly on the 31st in a 30 day month", RecurrenceRule{RecurrenceMonthly, 1}, date(2024, time.January, 31, 9, time.UTC), 3, date(2024, time.April, 30, 9, time.UTC)},
+		{"every six months across the year", RecurrenceRule{RecurrenceMonthly, 6}, date(2024, time.August, 31, 9, time.UTC), 1, date(2025, time.February, 28, 9, time.UTC)},
+		{"yearly on February 29th", RecurrenceRule{RecurrenceYearly, 1}, date(2024, time.February, 29, 9, time.UTC), 1, date(2025, time.February, 28, 9, time.UTC)},
+		{"yearly on February 29th in the next leap year", RecurrenceRule{RecurrenceYearly, 1}, date(2024, time.February, 29, 9, time.UTC), 4, date(2028, time.February, 29, 9, time.UTC)},
+		{"daily across the spring DST change", RecurrenceRule{RecurrenceDaily, 1}, date(2024, time.March, 30, 9, belgrade), 1, date(2024, time.March, 31, 9, belgrade)},
+		{"weekly across the autumn DST change", RecurrenceRule{RecurrenceWeekly, 1}, date(2024, time.October, 21, 9, belgrade), 1, date(2024, time.October, 28, 9, belgrade)},
+		{"monthly across the DST change", RecurrenceRule{RecurrenceMonthly, 1}, date(2024, time.March, 15, 9, belgrade), 1, date(2024, time.April, 15, 9, belgrade)},
+		{"zero interval is one", RecurrenceRule{RecurrenceWeekly, 0}, date(2024, time.January, 1, 9, time.UTC), 2, date(2024, time.January, 15, 9, time.UTC)},
+		{"interval above the limit is capped", RecurrenceRule{RecurrenceYearly, 1000}, date(2024, time.January, 1, 9, time.UTC), 1, date(2034, time.January, 1, 9, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		if got := tt.rule.Occurrence(tt.anchor, tt.n); !got.Equal(tt.want) {
+			t.Errorf("%s: got %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestRecurrenceRuleNext(t *testing.T) {
+	belgrade, err := time.LoadLocation("Europe/Belgrade")
+	if err != nil {
+		t.Fatal(err)
+	}
+	date := func(year int, month time.Month, day, hour int, loc *time.Location) time.Time {
+		return time.Date(year, month, day, hour, 0, 0, 0, loc)
+	}
+
+	tests := []struct {
+		name   string
+		rule   RecurrenceRule
+		anchor time.Time
+		after  time.Time
+		want   time.Time
+	}{
+		{"before the anchor", RecurrenceRule{RecurrenceMonthly, 1}, date(2024, time.January, 31, 9, time.UTC), date(2023, time.June, 1, 0, time.UTC), date(2024, time.January, 31, 9, time.UTC)},
+		{"at an occurrence returns the following one", RecurrenceRule{RecurrenceMonthly, 1}, date(2024, time.January, 31, 9, time.UTC), date(2024, time.February, 29, 9, time.UTC), date(2024, time.March, 31, 9, time.UTC)},
+		{"monthly on the 31st many months later", RecurrenceRule{RecurrenceMonthly, 1}, date(2024, time.January, 31, 9, time.UTC), date(2026, time.November, 15, 0, time.UTC), date(2026, time.November, 30, 9, time.UTC)},
+		{"daily just before the spring DST change", RecurrenceRule{RecurrenceDaily, 1}, date(2024, time.March, 1, 9, belgrade), date(2024, time.March, 31, 8, belgrade), date(2024, time.March, 31, 9, belgrade)},
+		{"daily just after the autumn DST change", RecurrenceRule{RecurrenceDaily, 1}, date(2024, time.October, 1, 9, belgrade), date(2024, time.October, 27, 9, belgrade), date(2024, time.October, 28, 9, belgrade)},
+		{"largest yearly interval", RecurrenceRule{RecurrenceYearly, 10}, date(2024, time.January, 1, 9, time.UTC), date(2100, time.June, 1, 0, time.UTC), date(2104, time.January, 1, 9, time.UTC)},
+		{"largest daily interval", RecurrenceRule{RecurrenceDaily, 3660}, date(2024, time.January, 1, 9, time.UTC), date(2030, time.January, 1, 0, time.UTC), date(2024, time.January, 1, 9, time.UTC).AddDate(0, 0, 3660)},
+		{"yearly interval above the limit does not overflow", RecurrenceRule{RecurrenceYearly, 1000}, date(2024, time.January, 1, 9, time.UTC), date(2040, time.January, 1, 0, time.UTC), date(2044, time.January, 1, 9, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		if got := tt.rule.Next(tt.anchor, tt.after); !got.Equal(tt.want) {
+			t.Errorf("%s: got %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestRecurrenceMaxIntervalFitsDuration(t *testing.T) {
+	for _, frequency := range []RecurrenceFrequency{RecurrenceDaily, RecurrenceWeekly, RecurrenceMonthly, RecurrenceYearly} {
+		rule := RecurrenceRule{Frequency: frequency, Interval: frequency.MaxInterval()}
+		if period := rule.maxPeriod(); period <= 0 || period > 20*366*24*time.Hour {
+			t.Errorf("%s: max period %s is out of range", frequency, period)
+		}
+	}
+}
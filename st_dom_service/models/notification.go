@@ -0,0 +1,46 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// NotificationType represents the event a notification is about
+type NotificationType string
+
+const (
+	NotificationRepairScheduled   NotificationType = "repair_scheduled"
+	NotificationRepairRescheduled NotificationType = "repair_rescheduled"
+	NotificationRepairCompleted   NotificationType = "repair_completed"
+)
+
+// Notification represents a message in a user's in-app inbox
+type Notification struct {
+	ID        primitive.ObjectID  `bson:"_id,omitempty" json:"id,omitempty"`
+	UserID    primitive.ObjectID  `bson:"user_id" json:"user_id"`
+	Type      NotificationType    `bson:"type" json:"type"`
+	Title     string              `bson:"title" json:"title"`
+	Message   string              `bson:"message" json:"message"`
+	SobaID    *primitive.ObjectID `bson:"soba_id,omitempty" json:"soba_id,omitempty"`
+	RepairID  *primitive.ObjectID `bson:"repair_id,omitempty" json:"repair_id,omitempty"`
+	IsRead    bool                `bson:"is_read" json:"is_read"`
+	ReadAt    *time.Time          `bson:"read_at,omitempty" json:"read_at,omitempty"`
+	CreatedAt time.Time           `bson:"created_at" json:"created_at"`
+}
+
+// NewRepairNotification creates an unread repair notification for a resident of the room
+func NewRepairNotification(userID primitive.ObjectID, notificationType NotificationType, title, message string, repair *Repair) Notification {
+	sobaID := repair.SobaID
+	repairID := repair.ID
+	return Notification{
+		UserID:    userID,
+		Type:      notificationType,
+		Title:     title,
+		Message:   message,
+		SobaID:    &sobaID,
+		RepairID:  &repairID,
+		IsRead:    false,
+		CreatedAt: time.Now(),
+	}
+}
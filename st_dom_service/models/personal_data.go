@@ -0,0 +1,30 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// PersonalDataExport contains every record st_dom_service keeps about a user
+// It is returned to sso_service, which packs it into the user's data export archive
+type PersonalDataExport struct {
+	UserID               primitive.ObjectID     `json:"user_id"`
+	GeneratedAt          time.Time              `json:"generated_at"`
+	Aplikacije           []Aplikacija           `json:"aplikacije"`
+	PrihvaceneAplikacije []PrihvacenaAplikacija `json:"prihvacene_aplikacije"`
+	Payments             []Payment              `json:"payments"`
+	Notifications        []Notification         `json:"notifications"`
+	Attachments          []Attachment           `json:"attachments"`
+}
+
+// ErasureResult summarizes what was anonymized or deleted when a user's personal data was erased
+// Applications with payment records under legal retention keep the index number until RetainUntil
+type ErasureResult struct {
+	UserID               primitive.ObjectID `json:"user_id"`
+	AnonymizedAplikacije int                `json:"anonymized_aplikacije"`
+	DeletedNotifications int64              `json:"deleted_notifications"`
+	DeletedAttachments   int                `json:"deleted_attachments"`
+	RetainedAplikacije   int                `json:"retained_aplikacije"`
+	RetainedUntil        *time.Time         `json:"retained_until,omitempty"`
+}
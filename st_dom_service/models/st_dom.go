@@ -34,6 +34,9 @@ type StDom struct {
 	Address       string             `bson:"address" json:"address" binding:"required"`
 	TelephoneNumber string           `bson:"telephone_number" json:"telephone_number" binding:"required"`
 	Email         string             `bson:"email" json:"email" binding:"required,email"`
+	// Location for maps (WGS 84); nil until an admin sets it or the address is found in the gazetteer
+	Latitude      *float64           `bson:"latitude,omitempty" json:"latitude,omitempty"`
+	Longitude     *float64           `bson:"longitude,omitempty" json:"longitude,omitempty"`
 	CreatedAt     time.Time          `bson:"created_at" json:"created_at"`
 	UpdatedAt     time.Time          `bson:"updated_at" json:"updated_at"`
 }
@@ -50,18 +53,24 @@ type Soba struct {
 
 // CreateStDomRequest represents the request body for creating a student dormitory
 type CreateStDomRequest struct {
-	Ime             string `json:"ime" binding:"required"`
-	Address         string `json:"address" binding:"required"`
-	TelephoneNumber string `json:"telephone_number" binding:"required"`
-	Email           string `json:"email" binding:"required,email"`
+	Ime             string   `json:"ime" binding:"required"`
+	Address         string   `json:"address" binding:"required"`
+	TelephoneNumber string   `json:"telephone_number" binding:"required"`
+	Email           string   `json:"email" binding:"required,email"`
+	Latitude        *float64 `json:"latitude,omitempty" binding:"omitempty,min=-90,max=90"`
+	Longitude       *float64 `json:"longitude,omitempty" binding:"omitempty,min=-180,max=180"`
 }
 
 // UpdateStDomRequest represents the request body for updating a student dormitory
 type UpdateStDomRequest struct {
-	Ime             *string `json:"ime,omitempty"`
-	Address         *string `json:"address,omitempty"`
-	TelephoneNumber *string `json:"telephone_number,omitempty"`
-	Email           *string `json:"email,omitempty"`
+	Ime             *string  `json:"ime,omitempty"`
+	Address         *string  `json:"address,omitempty"`
+	TelephoneNumber *string  `json:"telephone_number,omitempty"`
+	Email           *string  `json:"email,omitempty"`
+	Latitude        *float64 `json:"latitude,omitempty" binding:"omitempty,min=-90,max=90"`
+	Longitude       *float64 `json:"longitude,omitempty" binding:"omitempty,min=-180,max=180"`
+	// ClearCoordinates removes the coordinates, so the dorm is no longer shown on the map
+	ClearCoordinates bool `json:"clear_coordinates,omitempty"`
 }
 
 // CreateSobaRequest represents the request body for creating a room
@@ -84,6 +93,8 @@ func NewStDom(req CreateStDomRequest) StDom {
 		Address:         req.Address,
 		TelephoneNumber: req.TelephoneNumber,
 		Email:           req.Email,
+		Latitude:        req.Latitude,
+		Longitude:       req.Longitude,
 		CreatedAt:       time.Now(),
 		UpdatedAt:       time.Now(),
 	}
@@ -102,40 +113,52 @@ func NewSoba(req CreateSobaRequest) Soba {
 
 // Aplikacija represents a room application
 type Aplikacija struct {
-	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
-	BrojIndexa  string             `bson:"broj_indexa" json:"broj_indexa" binding:"required"`
-	Prosek      int                `bson:"prosek" json:"prosek" binding:"required,min=6,max=10"`
-	SobaID      primitive.ObjectID `bson:"soba_id" json:"soba_id" binding:"required"`
-	UserID      primitive.ObjectID `bson:"user_id" json:"user_id" binding:"required"`
-	IsActive    bool               `bson:"is_active" json:"is_active"`
-	CreatedAt   time.Time          `bson:"created_at" json:"created_at"`
-	UpdatedAt   time.Time          `bson:"updated_at" json:"updated_at"`
+	ID         primitive.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
+	BrojIndexa string             `bson:"broj_indexa" json:"broj_indexa" binding:"required"`
+	// Student identity copied from the verified SSO user account when the application is created
+	Fakultet         string             `bson:"fakultet,omitempty" json:"fakultet,omitempty"`
+	StudijskiProgram string             `bson:"studijski_program,omitempty" json:"studijski_program,omitempty"`
+	GodinaStudija    int                `bson:"godina_studija,omitempty" json:"godina_studija,omitempty"`
+	Pol              string             `bson:"pol,omitempty" json:"pol,omitempty"`
+	Prosek           int                `bson:"prosek" json:"prosek" binding:"required,min=6,max=10"`
+	SobaID           primitive.ObjectID `bson:"soba_id" json:"soba_id" binding:"required"`
+	UserID           primitive.ObjectID `bson:"user_id" json:"user_id" binding:"required"`
+	IsActive         bool               `bson:"is_active" json:"is_active"`
+	CreatedAt        time.Time          `bson:"created_at" json:"created_at"`
+	UpdatedAt        time.Time          `bson:"updated_at" json:"updated_at"`
+	// Set when the student's personal data is erased; the application stays for statistics under a pseudonymous user ID
+	// The index number is kept until RetainUntil while payment records linked to it are under legal retention
+	AnonymizedAt *time.Time `bson:"anonymized_at,omitempty" json:"anonymized_at,omitempty"`
+	RetainUntil  *time.Time `bson:"retain_until,omitempty" json:"retain_until,omitempty"`
 }
 
 // CreateAplikacijaRequest represents the request body for creating an application
+// The index number is not part of the request; it is taken from the student's SSO account
 type CreateAplikacijaRequest struct {
-	BrojIndexa string             `json:"broj_indexa" binding:"required"`
-	Prosek     int                `json:"prosek" binding:"required,min=6,max=10"`
-	SobaID     primitive.ObjectID `json:"soba_id" binding:"required"`
+	Prosek int                `json:"prosek" binding:"required,min=6,max=10"`
+	SobaID primitive.ObjectID `json:"soba_id" binding:"required"`
 }
 
 // UpdateAplikacijaRequest represents the request body for updating an application
 type UpdateAplikacijaRequest struct {
-	BrojIndexa *string `json:"broj_indexa,omitempty"`
-	Prosek     *int    `json:"prosek,omitempty"`
-	IsActive   *bool   `json:"is_active,omitempty"`
+	Prosek   *int  `json:"prosek,omitempty"`
+	IsActive *bool `json:"is_active,omitempty"`
 }
 
-// NewAplikacija creates a new application with default values
-func NewAplikacija(req CreateAplikacijaRequest, userID primitive.ObjectID) Aplikacija {
+// NewAplikacija creates a new application with default values and the student's verified identity
+func NewAplikacija(req CreateAplikacijaRequest, student *StudentIdentity) Aplikacija {
 	return Aplikacija{
-		BrojIndexa: req.BrojIndexa,
-		Prosek:     req.Prosek,
-		SobaID:     req.SobaID,
-		UserID:     userID,
-		IsActive:   true,
-		CreatedAt:  time.Now(),
-		UpdatedAt:  time.Now(),
+		BrojIndexa:       student.Student.IndexNumber,
+		Fakultet:         student.Student.Faculty,
+		StudijskiProgram: student.Student.StudyProgram,
+		GodinaStudija:    student.Student.YearOfStudy,
+		Pol:              student.Student.Gender,
+		Prosek:           req.Prosek,
+		SobaID:           req.SobaID,
+		UserID:           student.UserID,
+		IsActive:         true,
+		CreatedAt:        time.Now(),
+		UpdatedAt:        time.Now(),
 	}
 }
 
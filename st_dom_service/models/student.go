@@ -0,0 +1,19 @@
+package models
+
+import "go.mongodb.org/mongo-driver/bson/primitive"
+
+// StudentIdentity is the verified student data kept on the SSO user account.
+// Applications copy it instead of trusting values typed in by the student.
+type StudentIdentity struct {
+	UserID    primitive.ObjectID `json:"user_id"`
+	FirstName string             `json:"first_name"`
+	LastName  string             `json:"last_name"`
+	Student   struct {
+		IndexNumber  string `json:"index_number"`
+		Faculty      string `json:"faculty"`
+		StudyProgram string `json:"study_program"`
+		YearOfStudy  int    `json:"year_of_study"`
+		Gender       string `json:"gender"`
+		Verified     bool   `json:"verified"`
+	} `json:"student"`
+}
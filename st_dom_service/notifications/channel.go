@@ -0,0 +1,14 @@
+package notifications
+
+import (
+	"context"
+	"st_dom_service/models"
+)
+
+// Channel - dodatni kanal za isporuku obavestenja pored in-app inboxa (npr. email)
+type Channel interface {
+	// ime kanala, koristi se u logovima
+	Name() string
+	// salje obavestenje korisniku kome je namenjeno
+	Send(ctx context.Context, notification models.Notification) error
+}
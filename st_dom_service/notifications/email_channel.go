@@ -0,0 +1,104 @@
+package notifications
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/smtp"
+	"st_dom_service/config"
+	"st_dom_service/models"
+	"st_dom_service/utils"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// EmailChannel - salje obavestenja email-om preko SMTP servera
+// email adresu korisnika dobija od SSO servisa jer st_dom_service ne cuva email adrese
+type EmailChannel struct {
+	config        config.NotificationConfig
+	ssoServiceURL string
+	tokens        *utils.ServiceTokenSource
+	httpClient    *http.Client
+}
+
+// kreira novi EmailChannel sa SMTP podesavanjima, URL-om SSO servisa i izvorom servisnih tokena
+func NewEmailChannel(cfg config.NotificationConfig, ssoServiceURL string, tokens *utils.ServiceTokenSource) *EmailChannel {
+	return &EmailChannel{
+		config:        cfg,
+		ssoServiceURL: ssoServiceURL,
+		tokens:        tokens,
+		httpClient:    &http.Client{},
+	}
+}
+
+// vraca ime kanala
+func (ch *EmailChannel) Name() string {
+	return "email"
+}
+
+// pronalazi email adresu korisnika i salje mu obavestenje
+func (ch *EmailChannel) Send(ctx context.Context, notification models.Notification) error {
+	to, err := ch.lookupEmail(ctx, notification.UserID)
+	if err != nil {
+		return err
+	}
+
+	var message strings.Builder
+	fmt.Fprintf(&message, "From: %s\r\n", ch.config.SMTPFrom)
+	fmt.Fprintf(&message, "To: %s\r\n", to)
+	fmt.Fprintf(&message, "Subject: %s\r\n", notification.Title)
+	message.WriteString("MIME-Version: 1.0\r\n")
+	message.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	message.WriteString("\r\n")
+	message.WriteString(notification.Message)
+	message.WriteString("\r\n")
+
+	var auth smtp.Auth
+	if ch.config.SMTPUsername != "" {
+		auth = smtp.PlainAuth("", ch.config.SMTPUsername, ch.config.SMTPPassword, ch.config.SMTPHost)
+	}
+
+	addr := fmt.Sprintf("%s:%d", ch.config.SMTPHost, ch.config.SMTPPort)
+	return smtp.SendMail(addr, auth, ch.config.SMTPFrom, []string{to}, []byte(message.String()))
+}
+
+// poziva SSO servis da dobije email adresu korisnika
+func (ch *EmailChannel) lookupEmail(ctx context.Context, userID primitive.ObjectID) (string, error) {
+	url := fmt.Sprintf("%s/api/v1/internal/users/%s/contact", ch.ssoServiceURL, userID.Hex())
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", err
+	}
+
+	if err := ch.tokens.Authorize(req); err != nil {
+		return "", err
+	}
+
+	resp, err := ch.httpClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", errors.New("failed to get user contact")
+	}
+
+	var result struct {
+		Email string `json:"email"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", err
+	}
+
+	if result.Email == "" {
+		return "", errors.New("user has no email address")
+	}
+
+	return result.Email, nil
+}
@@ -3,12 +3,13 @@ package routes
 import (
 	"st_dom_service/handlers"
 	"st_dom_service/middleware"
+	"st_dom_service/utils"
 
 	"github.com/gin-gonic/gin"
 )
 
 // SetupRoutes configures all routes for the application
-func SetupRoutes(r *gin.Engine, stDomHandler *handlers.StDomHandler, sobaHandler *handlers.SobaHandler, aplikacijaHandler *handlers.AplikacijaHandler, prihvacenaAplikacijaHandler *handlers.PrihvacenaAplikacijaHandler, paymentHandler *handlers.PaymentHandler, repairHandler *handlers.RepairHandler, healthHandler *handlers.HealthHandler, jwtSecret string) {
+func SetupRoutes(r *gin.Engine, stDomHandler *handlers.StDomHandler, sobaHandler *handlers.SobaHandler, aplikacijaHandler *handlers.AplikacijaHandler, prihvacenaAplikacijaHandler *handlers.PrihvacenaAplikacijaHandler, paymentHandler *handlers.PaymentHandler, repairHandler *handlers.RepairHandler, inspectionHandler *handlers.InspectionHandler, attachmentHandler *handlers.AttachmentHandler, notificationHandler *handlers.NotificationHandler, personalDataHandler *handlers.PersonalDataHandler, healthHandler *handlers.HealthHandler, keys *utils.JWKSCache, denylist *utils.TokenDenylist) {
 	// Add CORS middleware
 	r.Use(middleware.CORSMiddleware())
 
@@ -33,21 +34,18 @@ func SetupRoutes(r *gin.Engine, stDomHandler *handlers.StDomHandler, sobaHandler
 			sobas.GET("/:id", sobaHandler.GetSoba)
 		}
 
-		// Public accepted applications routes (open data)
-		publicPrihvaceneAplikacije := v1.Group("/prihvacene_aplikacije")
-		{
-			publicPrihvaceneAplikacije.GET("/academic_year", prihvacenaAplikacijaHandler.GetPrihvaceneAplikacijeForAcademicYear) // Public: Get by academic year
-		}
-
-		// Inter-service communication endpoint (no auth required for service-to-service calls)
+		// Inter-service communication endpoints (require a service token issued by sso_service)
 		interService := v1.Group("/internal")
+		interService.Use(middleware.ServiceAuthMiddleware(keys))
 		{
 			interService.GET("/users/:userId/room-status", prihvacenaAplikacijaHandler.CheckUserRoomStatus) // Check if user has active room
+			interService.GET("/users/:userId/personal-data", personalDataHandler.ExportPersonalData)        // Export all records about the user
+			interService.POST("/users/:userId/erase", personalDataHandler.ErasePersonalData)                // Anonymize the user's records before account deletion
 		}
 
 		// User routes (authentication required)
 		user := v1.Group("/")
-		user.Use(middleware.AuthMiddleware(jwtSecret))
+		user.Use(middleware.AuthMiddleware(keys, denylist))
 		{
 			// User application routes
 			aplikacije := user.Group("/aplikacije")
@@ -57,6 +55,16 @@ func SetupRoutes(r *gin.Engine, stDomHandler *handlers.StDomHandler, sobaHandler
 				aplikacije.GET("/:id", aplikacijaHandler.GetAplikacija)        // User gets their own, admin gets any
 				aplikacije.PUT("/:id", aplikacijaHandler.UpdateAplikacija)     // User updates their own
 				aplikacije.DELETE("/:id", aplikacijaHandler.DeleteAplikacija)  // User deletes their own
+				aplikacije.POST("/:id/attachments", attachmentHandler.UploadAplikacijaAttachment) // Upload proof document (owner or admin)
+				aplikacije.GET("/:id/attachments", attachmentHandler.GetAplikacijaAttachments)     // List attachments (owner or admin)
+			}
+
+			// Attachment routes (access follows the owning application or repair)
+			attachments := user.Group("/attachments")
+			{
+				attachments.GET("/:id", attachmentHandler.GetAttachment)                // Get attachment metadata
+				attachments.GET("/:id/download", attachmentHandler.DownloadAttachment)  // Download attachment content
+				attachments.DELETE("/:id", attachmentHandler.DeleteAttachment)          // Delete attachment
 			}
 
 			// User accepted applications routes
@@ -66,7 +74,7 @@ func SetupRoutes(r *gin.Engine, stDomHandler *handlers.StDomHandler, sobaHandler
 				prihvaceneAplikacije.GET("/", prihvacenaAplikacijaHandler.GetAllPrihvaceneAplikacije)                    // Get all accepted applications (available to all authenticated users)
 				prihvaceneAplikacije.GET("/user/:userId", prihvacenaAplikacijaHandler.GetPrihvaceneAplikacijeForUser)    // Get by user (available to all authenticated users)
 				prihvaceneAplikacije.GET("/room/:sobaId", prihvacenaAplikacijaHandler.GetPrihvaceneAplikacijeForRoom)    // Get by room (available to all authenticated users)
-				// Note: /academic_year moved to public routes for open data access
+				// Note: /academic_year is admin-only, open_data_service publishes an anonymized version
 				prihvaceneAplikacije.POST("/checkout", prihvacenaAplikacijaHandler.CheckoutFromRoom)    // User voluntarily leaves room
 			}
 
@@ -76,11 +84,19 @@ func SetupRoutes(r *gin.Engine, stDomHandler *handlers.StDomHandler, sobaHandler
 				payments.GET("/my", paymentHandler.GetMyPayments)       // User gets their own payments
 				payments.GET("/:id", paymentHandler.GetPayment)         // User gets their own, admin gets any
 			}
+
+			// User notification inbox routes
+			notificationRoutes := user.Group("/notifications")
+			{
+				notificationRoutes.GET("/my", notificationHandler.GetMyNotifications)    // User gets their own (optional ?unread=true)
+				notificationRoutes.PATCH("/read-all", notificationHandler.MarkAllAsRead) // Mark all as read
+				notificationRoutes.PATCH("/:id/read", notificationHandler.MarkAsRead)    // Mark one as read
+			}
 		}
 
 		// Admin-only routes (authentication + admin role required)
 		admin := v1.Group("/")
-		admin.Use(middleware.AuthMiddleware(jwtSecret))
+		admin.Use(middleware.AuthMiddleware(keys, denylist))
 		admin.Use(middleware.RoleMiddleware("admin"))
 		{
 			// Admin student dormitory routes
@@ -111,6 +127,7 @@ func SetupRoutes(r *gin.Engine, stDomHandler *handlers.StDomHandler, sobaHandler
 			{
 				adminPrihvaceneAplikacije.POST("/approve", prihvacenaAplikacijaHandler.ApproveAplikacija)                       // Approve application
 				adminPrihvaceneAplikacije.POST("/evict", prihvacenaAplikacijaHandler.EvictStudent)                             // Evict student from room
+				adminPrihvaceneAplikacije.GET("/academic_year", prihvacenaAplikacijaHandler.GetPrihvaceneAplikacijeForAcademicYear) // Get by academic year (per-student rows)
 				adminPrihvaceneAplikacije.GET("/:id", prihvacenaAplikacijaHandler.GetPrihvacenaAplikacija)                    // Get accepted application by ID
 				adminPrihvaceneAplikacije.GET("/ranking/top", prihvacenaAplikacijaHandler.GetTopStudentsByProsek)             // Get top students overall
 				adminPrihvaceneAplikacije.GET("/ranking/top/academic_year/:academicYear", prihvacenaAplikacijaHandler.GetTopStudentsByProsekForAcademicYear) // Get top students by year
@@ -144,6 +161,23 @@ func SetupRoutes(r *gin.Engine, stDomHandler *handlers.StDomHandler, sobaHandler
 				adminRepairs.GET("/status/:status", repairHandler.GetRepairsByStatus)       // Get repairs by status
 				adminRepairs.PUT("/:id", repairHandler.UpdateRepair)                        // Update repair
 				adminRepairs.DELETE("/:id", repairHandler.DeleteRepair)                     // Delete repair
+				adminRepairs.POST("/:id/attachments", attachmentHandler.UploadRepairAttachment) // Upload damage photo
+				adminRepairs.GET("/:id/attachments", attachmentHandler.GetRepairAttachments)    // List repair attachments
+			}
+
+			// Admin inspection routes (recurring preventive inspections)
+			adminInspections := admin.Group("/inspections")
+			{
+				adminInspections.POST("/templates", inspectionHandler.CreateTemplate)         // Create inspection template
+				adminInspections.GET("/templates", inspectionHandler.GetAllTemplates)         // Get all templates (optional st_dom_id filter)
+				adminInspections.GET("/templates/:id", inspectionHandler.GetTemplate)         // Get template by ID
+				adminInspections.PUT("/templates/:id", inspectionHandler.UpdateTemplate)      // Update template
+				adminInspections.DELETE("/templates/:id", inspectionHandler.DeleteTemplate)   // Delete template
+				adminInspections.POST("/generate", inspectionHandler.GenerateInspections)     // Run the scheduler now
+				adminInspections.GET("/", inspectionHandler.GetInspections)                   // Get inspections (optional status/st_dom_id/soba_id filters)
+				adminInspections.GET("/:id", inspectionHandler.GetInspection)                 // Get inspection by ID
+				adminInspections.POST("/:id/complete", inspectionHandler.CompleteInspection)  // Record checklist result
+				adminInspections.PATCH("/:id/cancel", inspectionHandler.CancelInspection)     // Cancel inspection
 			}
 		}
 	}
@@ -14,12 +14,15 @@ import (
 // AplikacijaService handles application-related operations
 type AplikacijaService struct {
 	collection *mongo.Collection
+	students   *StudentDirectory
 }
 
 // NewAplikacijaService creates a new AplikacijaService
-func NewAplikacijaService(collection *mongo.Collection) *AplikacijaService {
+// students provides the verified index number and faculty that are copied into new applications
+func NewAplikacijaService(collection *mongo.Collection, students *StudentDirectory) *AplikacijaService {
 	return &AplikacijaService{
 		collection: collection,
+		students:   students,
 	}
 }
 
@@ -34,8 +37,14 @@ func (s *AplikacijaService) CreateAplikacija(req models.CreateAplikacijaRequest,
 		return nil, errors.New("user already has an active application for this room")
 	}
 
+	// Student identity comes from the SSO account, never from the request body
+	student, err := s.students.GetVerifiedStudent(userID)
+	if err != nil {
+		return nil, err
+	}
+
 	// Create new application
-	aplikacija := models.NewAplikacija(req, userID)
+	aplikacija := models.NewAplikacija(req, student)
 
 	// Insert into database
 	result, err := s.collection.InsertOne(ctx, aplikacija)
@@ -142,9 +151,6 @@ func (s *AplikacijaService) UpdateAplikacija(id primitive.ObjectID, req models.U
 	// Build update document
 	update := bson.M{"$set": bson.M{"updated_at": time.Now()}}
 	
-	if req.BrojIndexa != nil {
-		update["$set"].(bson.M)["broj_indexa"] = *req.BrojIndexa
-	}
 	if req.Prosek != nil {
 		update["$set"].(bson.M)["prosek"] = *req.Prosek
 	}
@@ -0,0 +1,200 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"path/filepath"
+	"st_dom_service/config"
+	"st_dom_service/models"
+	"st_dom_service/storage"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// sniffLength is the number of bytes http.DetectContentType looks at
+const sniffLength = 512
+
+// AttachmentService handles attachment metadata and file content
+type AttachmentService struct {
+	collection *mongo.Collection
+	store      storage.BlobStore
+	config     config.AttachmentConfig
+}
+
+// NewAttachmentService creates a new AttachmentService
+func NewAttachmentService(db *mongo.Database, store storage.BlobStore, cfg config.AttachmentConfig) *AttachmentService {
+	return &AttachmentService{
+		collection: db.Collection("attachments"),
+		store:      store,
+		config:     cfg,
+	}
+}
+
+// UploadAttachment validates an uploaded file, stores its content and saves the metadata
+// The content type is detected from the file itself, the client supplied header is ignored
+func (s *AttachmentService) UploadAttachment(ctx context.Context, ownerType models.AttachmentOwnerType, ownerID primitive.ObjectID, fileHeader *multipart.FileHeader, description string, uploadedBy primitive.ObjectID) (*models.Attachment, error) {
+	if !ownerType.IsValid() {
+		return nil, errors.New("invalid attachment owner type")
+	}
+
+	if fileHeader.Size > s.config.MaxSizeBytes {
+		return nil, fmt.Errorf("file is too large: maximum size is %d bytes", s.config.MaxSizeBytes)
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	head := make([]byte, sniffLength)
+	n, err := io.ReadFull(file, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return nil, err
+	}
+	head = head[:n]
+	if n == 0 {
+		return nil, errors.New("file is empty")
+	}
+
+	contentType, _, err := mime.ParseMediaType(http.DetectContentType(head))
+	if err != nil || !s.config.IsContentTypeAllowed(contentType) {
+		return nil, fmt.Errorf("file type %q is not allowed", contentType)
+	}
+
+	attachment := models.Attachment{
+		ID:          primitive.NewObjectID(),
+		OwnerType:   ownerType,
+		OwnerID:     ownerID,
+		FileName:    filepath.Base(fileHeader.Filename),
+		ContentType: contentType,
+		Description: description,
+		UploadedBy:  uploadedBy,
+		CreatedAt:   time.Now(),
+	}
+	attachment.StorageKey = fmt.Sprintf("%s/%s/%s", ownerType, ownerID.Hex(), attachment.ID.Hex())
+
+	// Read at most one byte over the limit so an oversized body is detected
+	// even when the multipart header reported a smaller size
+	hash := sha256.New()
+	content := io.TeeReader(io.LimitReader(io.MultiReader(bytes.NewReader(head), file), s.config.MaxSizeBytes+1), hash)
+
+	written, err := s.store.Put(ctx, attachment.StorageKey, content)
+	if err != nil {
+		return nil, err
+	}
+
+	if written > s.config.MaxSizeBytes {
+		s.deleteBlob(ctx, attachment.StorageKey)
+		return nil, fmt.Errorf("file is too large: maximum size is %d bytes", s.config.MaxSizeBytes)
+	}
+
+	attachment.Size = written
+	attachment.Checksum = hex.EncodeToString(hash.Sum(nil))
+
+	if _, err := s.collection.InsertOne(ctx, attachment); err != nil {
+		s.deleteBlob(ctx, attachment.StorageKey)
+		return nil, err
+	}
+
+	return &attachment, nil
+}
+
+// GetAttachmentsByOwner retrieves all attachments of a repair or an application, newest first
+func (s *AttachmentService) GetAttachmentsByOwner(ctx context.Context, ownerType models.AttachmentOwnerType, ownerID primitive.ObjectID) ([]models.Attachment, error) {
+	findOptions := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}})
+
+	cursor, err := s.collection.Find(ctx, bson.M{"owner_type": ownerType, "owner_id": ownerID}, findOptions)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var attachments []models.Attachment
+	if err = cursor.All(ctx, &attachments); err != nil {
+		return nil, err
+	}
+
+	return attachments, nil
+}
+
+// GetAttachmentByID retrieves attachment metadata by ID
+func (s *AttachmentService) GetAttachmentByID(ctx context.Context, id primitive.ObjectID) (*models.Attachment, error) {
+	var attachment models.Attachment
+	err := s.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&attachment)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.New("attachment not found")
+		}
+		return nil, err
+	}
+
+	return &attachment, nil
+}
+
+// OpenAttachment opens the stored content of an attachment, the caller must close it
+func (s *AttachmentService) OpenAttachment(ctx context.Context, attachment *models.Attachment) (io.ReadCloser, error) {
+	content, err := s.store.Get(ctx, attachment.StorageKey)
+	if err != nil {
+		if errors.Is(err, storage.ErrBlobNotFound) {
+			return nil, errors.New("attachment content not found")
+		}
+		return nil, err
+	}
+
+	return content, nil
+}
+
+// DeleteAttachment deletes an attachment and its stored content
+func (s *AttachmentService) DeleteAttachment(ctx context.Context, id primitive.ObjectID) error {
+	attachment, err := s.GetAttachmentByID(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if _, err := s.collection.DeleteOne(ctx, bson.M{"_id": id}); err != nil {
+		return err
+	}
+
+	s.deleteBlob(ctx, attachment.StorageKey)
+	return nil
+}
+
+// DeleteAttachmentsByOwner deletes all attachments of a repair or an application
+// Called when the owning record is deleted so no orphaned files are left behind
+func (s *AttachmentService) DeleteAttachmentsByOwner(ctx context.Context, ownerType models.AttachmentOwnerType, ownerID primitive.ObjectID) error {
+	attachments, err := s.GetAttachmentsByOwner(ctx, ownerType, ownerID)
+	if err != nil {
+		return err
+	}
+
+	if _, err := s.collection.DeleteMany(ctx, bson.M{"owner_type": ownerType, "owner_id": ownerID}); err != nil {
+		return err
+	}
+
+	for _, attachment := range attachments {
+		s.deleteBlob(ctx, attachment.StorageKey)
+	}
+
+	return nil
+}
+
+// deleteBlob removes stored content, a failure only leaves an unreferenced file behind
+func (s *AttachmentService) deleteBlob(ctx context.Context, key string) {
+	if err := s.store.Delete(ctx, key); err != nil {
+		log.Println("Failed to delete attachment content:", err)
+	}
+}
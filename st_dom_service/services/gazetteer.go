@@ -0,0 +1,92 @@
+package services
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Coordinates is a WGS 84 location
+type Coordinates struct {
+	Latitude  float64
+	Longitude float64
+}
+
+// Gazetteer geocodes dorm addresses from a local CSV file, so no external geocoding service is called
+// The file has a header row and the columns address, latitude, longitude
+type Gazetteer struct {
+	places map[string]Coordinates
+}
+
+// addressReplacer removes Serbian diacritics, so an address matches with or without them
+var addressReplacer = strings.NewReplacer(
+	"č", "c", "ć", "c", "š", "s", "ž", "z", "đ", "dj",
+)
+
+// LoadGazetteer reads a gazetteer file
+func LoadGazetteer(path string) (*Gazetteer, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = 3
+	reader.TrimLeadingSpace = true
+
+	if _, err := reader.Read(); err != nil {
+		return nil, fmt.Errorf("gazetteer header: %w", err)
+	}
+
+	gazetteer := &Gazetteer{places: make(map[string]Coordinates)}
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		line, _ := reader.FieldPos(0)
+		latitude, err := strconv.ParseFloat(record[1], 64)
+		if err != nil || latitude < -90 || latitude > 90 {
+			return nil, fmt.Errorf("gazetteer line %d: invalid latitude %q", line, record[1])
+		}
+		longitude, err := strconv.ParseFloat(record[2], 64)
+		if err != nil || longitude < -180 || longitude > 180 {
+			return nil, fmt.Errorf("gazetteer line %d: invalid longitude %q", line, record[2])
+		}
+
+		gazetteer.places[normalizeAddress(record[0])] = Coordinates{Latitude: latitude, Longitude: longitude}
+	}
+
+	return gazetteer, nil
+}
+
+// Lookup returns the coordinates of an address; a nil Gazetteer finds nothing
+func (g *Gazetteer) Lookup(address string) (Coordinates, bool) {
+	if g == nil {
+		return Coordinates{}, false
+	}
+	coordinates, found := g.places[normalizeAddress(address)]
+	return coordinates, found
+}
+
+// Len returns the number of addresses in the gazetteer
+func (g *Gazetteer) Len() int {
+	return len(g.places)
+}
+
+// normalizeAddress makes addresses that differ only in case, diacritics, punctuation or spacing equal
+func normalizeAddress(address string) string {
+	address = addressReplacer.Replace(strings.ToLower(address))
+	return strings.Join(strings.FieldsFunc(address, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}), " ")
+}
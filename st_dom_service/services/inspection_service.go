@@ -0,0 +1,471 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"st_dom_service/config"
+	"st_dom_service/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// maxOccurrencesPerRun limits how many inspections one template can generate in a single scheduler run
+const maxOccurrencesPerRun = 366
+
+// InspectionService handles inspection templates and generated inspection tasks
+type InspectionService struct {
+	templates   *mongo.Collection
+	collection  *mongo.Collection
+	sobaService *SobaService
+}
+
+// NewInspectionService creates a new InspectionService
+func NewInspectionService(db *mongo.Database, sobaService *SobaService) *InspectionService {
+	return &InspectionService{
+		templates:   db.Collection("inspection_templates"),
+		collection:  db.Collection("inspections"),
+		sobaService: sobaService,
+	}
+}
+
+// InspectionFilter holds optional filters for listing inspections
+type InspectionFilter struct {
+	Status  *models.InspectionStatus
+	StDomID *primitive.ObjectID
+	SobaID  *primitive.ObjectID
+}
+
+// CreateTemplate creates a new inspection template
+// Room-scoped templates take their dormitory from the room
+func (s *InspectionService) CreateTemplate(ctx context.Context, req models.CreateInspectionTemplateRequest, createdBy primitive.ObjectID) (*models.InspectionTemplate, error) {
+	if !req.Trigger.IsValid() {
+		return nil, errors.New("invalid trigger: must be 'schedule' or 'checkout'")
+	}
+
+	if req.Trigger == models.InspectionTriggerSchedule {
+		if req.Recurrence == nil || !req.Recurrence.Frequency.IsValid() {
+			return nil, errors.New("scheduled templates require a recurrence with frequency 'daily', 'weekly', 'monthly' or 'yearly'")
+		}
+		if req.Recurrence.Interval < 1 {
+			req.Recurrence.Interval = 1
+		}
+		if err := checkRecurrenceInterval(req.Recurrence); err != nil {
+			return nil, err
+		}
+	}
+
+	if req.SobaID != nil {
+		soba, err := s.sobaService.GetSobaByID(*req.SobaID)
+		if err != nil {
+			return nil, err
+		}
+		req.StDomID = soba.StDomID
+	}
+
+	if req.StDomID.IsZero() {
+		return nil, errors.New("either st_dom_id or soba_id is required")
+	}
+
+	template := models.NewInspectionTemplate(req, createdBy)
+
+	result, err := s.templates.InsertOne(ctx, template)
+	if err != nil {
+		return nil, err
+	}
+
+	template.ID = result.InsertedID.(primitive.ObjectID)
+	return &template, nil
+}
+
+// GetAllTemplates retrieves all inspection templates, optionally filtered by dormitory
+func (s *InspectionService) GetAllTemplates(ctx context.Context, stDomID *primitive.ObjectID) ([]models.InspectionTemplate, error) {
+	filter := bson.M{}
+	if stDomID != nil {
+		filter["st_dom_id"] = *stDomID
+	}
+
+	cursor, err := s.templates.Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var templates []models.InspectionTemplate
+	if err = cursor.All(ctx, &templates); err != nil {
+		return nil, err
+	}
+
+	return templates, nil
+}
+
+// GetTemplateByID retrieves an inspection template by ID
+func (s *InspectionService) GetTemplateByID(ctx context.Context, id primitive.ObjectID) (*models.InspectionTemplate, error) {
+	var template models.InspectionTemplate
+	err := s.templates.FindOne(ctx, bson.M{"_id": id}).Decode(&template)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.New("inspection template not found")
+		}
+		return nil, err
+	}
+
+	return &template, nil
+}
+
+// UpdateTemplate updates an inspection template
+func (s *InspectionService) UpdateTemplate(ctx context.Context, id primitive.ObjectID, req models.UpdateInspectionTemplateRequest) (*models.InspectionTemplate, error) {
+	current, err := s.GetTemplateByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	update := bson.M{"$set": bson.M{"updated_at": time.Now()}}
+
+	if req.Name != nil {
+		update["$set"].(bson.M)["name"] = *req.Name
+	}
+	if req.Description != nil {
+		update["$set"].(bson.M)["description"] = *req.Description
+	}
+	if req.Checklist != nil {
+		if len(*req.Checklist) == 0 {
+			return nil, errors.New("checklist must contain at least one item")
+		}
+		update["$set"].(bson.M)["checklist"] = *req.Checklist
+	}
+	if req.IsActive != nil {
+		update["$set"].(bson.M)["is_active"] = *req.IsActive
+	}
+	if req.Recurrence != nil || req.NextDueDate != nil {
+		if current.Trigger != models.InspectionTriggerSchedule {
+			return nil, errors.New("recurrence can only be set on scheduled templates")
+		}
+		if req.Recurrence != nil {
+			if !req.Recurrence.Frequency.IsValid() {
+				return nil, errors.New("invalid recurrence frequency")
+			}
+			if req.Recurrence.Interval < 1 {
+				req.Recurrence.Interval = 1
+			}
+			if err := checkRecurrenceInterval(req.Recurrence); err != nil {
+				return nil, err
+			}
+			update["$set"].(bson.M)["recurrence"] = *req.Recurrence
+		}
+		if req.NextDueDate != nil {
+			// A new due date starts the schedule again from that date
+			update["$set"].(bson.M)["anchor_date"] = *req.NextDueDate
+			update["$set"].(bson.M)["next_due_date"] = *req.NextDueDate
+		}
+	}
+
+	_, err = s.templates.UpdateOne(ctx, bson.M{"_id": id}, update)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetTemplateByID(ctx, id)
+}
+
+// DeleteTemplate deletes an inspection template
+// Inspections already generated from it are kept
+func (s *InspectionService) DeleteTemplate(ctx context.Context, id primitive.ObjectID) error {
+	result, err := s.templates.DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return err
+	}
+
+	if result.DeletedCount == 0 {
+		return errors.New("inspection template not found")
+	}
+
+	return nil
+}
+
+// GenerateScheduledInspections creates inspections for every active scheduled template
+// whose next due date falls before the given time, and advances the template
+// Returns the number of newly created inspections
+func (s *InspectionService) GenerateScheduledInspections(ctx context.Context, until time.Time) (int, error) {
+	cursor, err := s.templates.Find(ctx, bson.M{
+		"trigger":       models.InspectionTriggerSchedule,
+		"is_active":     true,
+		"next_due_date": bson.M{"$lte": until},
+	})
+	if err != nil {
+		return 0, err
+	}
+	defer cursor.Close(ctx)
+
+	var templates []models.InspectionTemplate
+	if err = cursor.All(ctx, &templates); err != nil {
+		return 0, err
+	}
+
+	created := 0
+	now := time.Now()
+	for _, template := range templates {
+		if template.Recurrence == nil || template.NextDueDate == nil {
+			continue
+		}
+
+		// Templates created before anchor dates were stored are anchored at their next due date
+		anchor := *template.NextDueDate
+		if template.AnchorDate != nil {
+			anchor = *template.AnchorDate
+		}
+
+		// Skip stale occurrences so a template created with an old start date
+		// produces only the most recent missed inspection
+		next := *template.NextDueDate
+		for template.Recurrence.Next(anchor, next).Before(now) {
+			next = template.Recurrence.Next(anchor, next)
+		}
+
+		for i := 0; i < maxOccurrencesPerRun && !next.After(until); i++ {
+			inserted, err := s.upsertScheduledInspection(ctx, template, next)
+			if err != nil {
+				return created, err
+			}
+			if inserted {
+				created++
+			}
+			next = template.Recurrence.Next(anchor, next)
+		}
+
+		_, err = s.templates.UpdateOne(ctx, bson.M{"_id": template.ID}, bson.M{
+			"$set": bson.M{"anchor_date": anchor, "next_due_date": next, "updated_at": time.Now()},
+		})
+		if err != nil {
+			return created, err
+		}
+	}
+
+	return created, nil
+}
+
+// upsertScheduledInspection inserts the inspection for a template occurrence unless it already exists
+func (s *InspectionService) upsertScheduledInspection(ctx context.Context, template models.InspectionTemplate, dueDate time.Time) (bool, error) {
+	inspection := models.NewInspection(template.Name, template.StDomID, template.SobaID, models.InspectionTriggerSchedule, dueDate, template.Checklist)
+	inspection.TemplateID = &template.ID
+
+	result, err := s.collection.UpdateOne(ctx,
+		bson.M{"template_id": template.ID, "due_date": dueDate},
+		bson.M{"$setOnInsert": inspection},
+		options.Update().SetUpsert(true),
+	)
+	if err != nil {
+		return false, err
+	}
+
+	return result.UpsertedCount > 0, nil
+}
+
+// CreateCheckoutInspections creates the room check that follows a student leaving a room
+// Uses the matching checkout templates, or the default checklist if none exist
+func (s *InspectionService) CreateCheckoutInspections(ctx context.Context, sobaID primitive.ObjectID) ([]models.Inspection, error) {
+	soba, err := s.sobaService.GetSobaByID(sobaID)
+	if err != nil {
+		return nil, err
+	}
+
+	cursor, err := s.templates.Find(ctx, bson.M{
+		"trigger":   models.InspectionTriggerCheckout,
+		"is_active": true,
+		"$or": []bson.M{
+			{"soba_id": sobaID},
+			{"st_dom_id": soba.StDomID, "soba_id": bson.M{"$exists": false}},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var templates []models.InspectionTemplate
+	if err = cursor.All(ctx, &templates); err != nil {
+		return nil, err
+	}
+
+	dueDate := time.Now().AddDate(0, 0, config.GetInspectionConfig().CheckoutDueDays)
+
+	var inspections []models.Inspection
+	if len(templates) == 0 {
+		inspections = append(inspections, models.NewInspection("Checkout room inspection", soba.StDomID, &sobaID, models.InspectionTriggerCheckout, dueDate, models.DefaultCheckoutChecklist))
+	}
+	for _, template := range templates {
+		templateID := template.ID
+		inspection := models.NewInspection(template.Name, soba.StDomID, &sobaID, models.InspectionTriggerCheckout, dueDate, template.Checklist)
+		inspection.TemplateID = &templateID
+		inspections = append(inspections, inspection)
+	}
+
+	for i := range inspections {
+		result, err := s.collection.InsertOne(ctx, inspections[i])
+		if err != nil {
+			return nil, err
+		}
+		inspections[i].ID = result.InsertedID.(primitive.ObjectID)
+	}
+
+	return inspections, nil
+}
+
+// GetInspections retrieves inspections matching the filter, ordered by due date
+func (s *InspectionService) GetInspections(ctx context.Context, filter InspectionFilter) ([]models.Inspection, error) {
+	query := bson.M{}
+	if filter.Status != nil {
+		query["status"] = *filter.Status
+	}
+	if filter.StDomID != nil {
+		query["st_dom_id"] = *filter.StDomID
+	}
+	if filter.SobaID != nil {
+		query["soba_id"] = *filter.SobaID
+	}
+
+	findOptions := options.Find().SetSort(bson.D{{Key: "due_date", Value: 1}})
+
+	cursor, err := s.collection.Find(ctx, query, findOptions)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var inspections []models.Inspection
+	if err = cursor.All(ctx, &inspections); err != nil {
+		return nil, err
+	}
+
+	return inspections, nil
+}
+
+// GetInspectionByID retrieves an inspection by ID
+func (s *InspectionService) GetInspectionByID(ctx context.Context, id primitive.ObjectID) (*models.Inspection, error) {
+	var inspection models.Inspection
+	err := s.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&inspection)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.New("inspection not found")
+		}
+		return nil, err
+	}
+
+	return &inspection, nil
+}
+
+// CompleteInspection records the checklist result and marks the inspection as completed
+// Every checklist item of the inspection must be reported as passed or failed
+func (s *InspectionService) CompleteInspection(ctx context.Context, id primitive.ObjectID, req models.CompleteInspectionRequest, completedBy primitive.ObjectID) (*models.Inspection, error) {
+	inspection, err := s.GetInspectionByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if inspection.Status != models.InspectionStatusScheduled {
+		return nil, errors.New("only scheduled inspections can be completed")
+	}
+
+	onChecklist := make(map[string]bool, len(inspection.Checklist))
+	for _, item := range inspection.Checklist {
+		onChecklist[item.Item] = true
+	}
+
+	results := make(map[string]models.ChecklistItemResult, len(req.Checklist))
+	for _, result := range req.Checklist {
+		if !onChecklist[result.Item] {
+			return nil, errors.New("checklist item '" + result.Item + "' is not on the inspection checklist")
+		}
+		if result.Passed == nil {
+			return nil, errors.New("checklist item '" + result.Item + "' is missing a result")
+		}
+		results[result.Item] = result
+	}
+
+	// The stored result follows the checklist of the inspection, one entry per item
+	checklist := make([]models.ChecklistItemResult, 0, len(inspection.Checklist))
+	for _, item := range inspection.Checklist {
+		result, ok := results[item.Item]
+		if !ok {
+			return nil, errors.New("checklist item '" + item.Item + "' is missing a result")
+		}
+		checklist = append(checklist, models.ChecklistItemResult{Item: item.Item, Passed: result.Passed, Note: result.Note})
+	}
+
+	now := time.Now()
+	update := bson.M{
+		"$set": bson.M{
+			"checklist":    checklist,
+			"notes":        req.Notes,
+			"status":       models.InspectionStatusCompleted,
+			"completed_at": now,
+			"completed_by": completedBy,
+			"updated_at":   now,
+		},
+	}
+
+	_, err = s.collection.UpdateOne(ctx, bson.M{"_id": id}, update)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetInspectionByID(ctx, id)
+}
+
+// CancelInspection cancels a scheduled inspection
+func (s *InspectionService) CancelInspection(ctx context.Context, id primitive.ObjectID) (*models.Inspection, error) {
+	result, err := s.collection.UpdateOne(ctx,
+		bson.M{"_id": id, "status": models.InspectionStatusScheduled},
+		bson.M{"$set": bson.M{"status": models.InspectionStatusCancelled, "updated_at": time.Now()}},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if result.MatchedCount == 0 {
+		return nil, errors.New("inspection not found or not scheduled")
+	}
+
+	return s.GetInspectionByID(ctx, id)
+}
+
+// StartScheduler runs GenerateScheduledInspections in the background on the configured period
+func (s *InspectionService) StartScheduler(cfg config.InspectionConfig) {
+	run := func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 1*time.Minute)
+		defer cancel()
+
+		until := time.Now().AddDate(0, 0, cfg.LookaheadDays)
+		created, err := s.GenerateScheduledInspections(ctx, until)
+		if err != nil {
+			log.Println("Inspection scheduler failed:", err)
+			return
+		}
+		if created > 0 {
+			log.Printf("Inspection scheduler generated %d inspections", created)
+		}
+	}
+
+	go func() {
+		run()
+
+		ticker := time.NewTicker(cfg.SchedulerPeriod)
+		defer ticker.Stop()
+		for range ticker.C {
+			run()
+		}
+	}()
+}
+
+// checkRecurrenceInterval rejects intervals longer than the frequency allows
+func checkRecurrenceInterval(rule *models.RecurrenceRule) error {
+	if limit := rule.Frequency.MaxInterval(); rule.Interval > limit {
+		return fmt.Errorf("recurrence interval must be at most %d for frequency '%s'", limit, rule.Frequency)
+	}
+	return nil
+}
@@ -0,0 +1,140 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"st_dom_service/models"
+	"st_dom_service/notifications"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// NotificationService handles the in-app inbox and delivery through optional channels
+type NotificationService struct {
+	collection                  *mongo.Collection
+	prihvacenaAplikacijaService *PrihvacenaAplikacijaService
+	channels                    []notifications.Channel
+}
+
+// NewNotificationService creates a new NotificationService
+// Channels are optional, the in-app inbox is always used
+func NewNotificationService(db *mongo.Database, prihvacenaAplikacijaService *PrihvacenaAplikacijaService, channels ...notifications.Channel) *NotificationService {
+	return &NotificationService{
+		collection:                  db.Collection("notifications"),
+		prihvacenaAplikacijaService: prihvacenaAplikacijaService,
+		channels:                    channels,
+	}
+}
+
+// NotifyRoomResidents sends a repair notification to every current resident of the repaired room
+// Returns the number of residents notified
+func (s *NotificationService) NotifyRoomResidents(ctx context.Context, repair *models.Repair, notificationType models.NotificationType, title, message string) (int, error) {
+	residents, err := s.prihvacenaAplikacijaService.GetPrihvaceneAplikacijeBySobaID(repair.SobaID)
+	if err != nil {
+		return 0, err
+	}
+
+	if len(residents) == 0 {
+		return 0, nil
+	}
+
+	documents := make([]interface{}, 0, len(residents))
+	created := make([]models.Notification, 0, len(residents))
+	for _, resident := range residents {
+		notification := models.NewRepairNotification(resident.UserID, notificationType, title, message, repair)
+		notification.ID = primitive.NewObjectID()
+		documents = append(documents, notification)
+		created = append(created, notification)
+	}
+
+	if _, err := s.collection.InsertMany(ctx, documents); err != nil {
+		return 0, err
+	}
+
+	s.deliver(created)
+
+	return len(created), nil
+}
+
+// deliver sends notifications through the additional channels in the background
+// Failures are logged, the notification stays in the inbox either way
+func (s *NotificationService) deliver(notificationsToSend []models.Notification) {
+	if len(s.channels) == 0 {
+		return
+	}
+
+	go func() {
+		for _, notification := range notificationsToSend {
+			for _, channel := range s.channels {
+				ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+				if err := channel.Send(ctx, notification); err != nil {
+					log.Printf("Failed to send %s notification to user %s: %v", channel.Name(), notification.UserID.Hex(), err)
+				}
+				cancel()
+			}
+		}
+	}()
+}
+
+// GetNotificationsByUserID retrieves a user's notifications, newest first
+func (s *NotificationService) GetNotificationsByUserID(ctx context.Context, userID primitive.ObjectID, unreadOnly bool) ([]models.Notification, error) {
+	filter := bson.M{"user_id": userID}
+	if unreadOnly {
+		filter["is_read"] = false
+	}
+
+	findOptions := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}})
+
+	cursor, err := s.collection.Find(ctx, filter, findOptions)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var notificationList []models.Notification
+	if err = cursor.All(ctx, &notificationList); err != nil {
+		return nil, err
+	}
+
+	return notificationList, nil
+}
+
+// CountUnread returns the number of unread notifications of a user
+func (s *NotificationService) CountUnread(ctx context.Context, userID primitive.ObjectID) (int64, error) {
+	return s.collection.CountDocuments(ctx, bson.M{"user_id": userID, "is_read": false})
+}
+
+// MarkAsRead marks a notification as read (only by its recipient)
+func (s *NotificationService) MarkAsRead(ctx context.Context, id, userID primitive.ObjectID) error {
+	result, err := s.collection.UpdateOne(ctx,
+		bson.M{"_id": id, "user_id": userID},
+		bson.M{"$set": bson.M{"is_read": true, "read_at": time.Now()}},
+	)
+	if err != nil {
+		return err
+	}
+
+	if result.MatchedCount == 0 {
+		return errors.New("notification not found")
+	}
+
+	return nil
+}
+
+// MarkAllAsRead marks all unread notifications of a user as read
+func (s *NotificationService) MarkAllAsRead(ctx context.Context, userID primitive.ObjectID) (int64, error) {
+	result, err := s.collection.UpdateMany(ctx,
+		bson.M{"user_id": userID, "is_read": false},
+		bson.M{"$set": bson.M{"is_read": true, "read_at": time.Now()}},
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.ModifiedCount, nil
+}
@@ -0,0 +1,262 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log"
+	"st_dom_service/config"
+	"st_dom_service/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+var (
+	ErrErasureActiveRoom          = errors.New("you must check out from your assigned room first")
+	ErrErasureOutstandingPayments = errors.New("you must settle all outstanding payments first")
+)
+
+// PersonalDataService handles data subject access requests and erasure of a user's personal data
+// Erasure anonymizes records instead of deleting them so they stay usable for statistics
+// Accepted applications are read through PrihvacenaAplikacijaService; erasure is refused while one exists
+type PersonalDataService struct {
+	aplikacije                  *mongo.Collection
+	payments                    *mongo.Collection
+	notifications               *mongo.Collection
+	prihvacenaAplikacijaService *PrihvacenaAplikacijaService
+	paymentService              *PaymentService
+	attachmentService           *AttachmentService
+	config                      config.PrivacyConfig
+}
+
+// NewPersonalDataService creates a new PersonalDataService
+func NewPersonalDataService(db *mongo.Database, prihvacenaAplikacijaService *PrihvacenaAplikacijaService, paymentService *PaymentService, attachmentService *AttachmentService, cfg config.PrivacyConfig) *PersonalDataService {
+	return &PersonalDataService{
+		aplikacije:                  db.Collection("aplikacije"),
+		payments:                    db.Collection("payments"),
+		notifications:               db.Collection("notifications"),
+		prihvacenaAplikacijaService: prihvacenaAplikacijaService,
+		paymentService:              paymentService,
+		attachmentService:           attachmentService,
+		config:                      cfg,
+	}
+}
+
+// ExportPersonalData collects every record kept about the user
+// Attachments are listed with their metadata, the file content is downloaded through the attachment routes
+func (s *PersonalDataService) ExportPersonalData(ctx context.Context, userID primitive.ObjectID) (*models.PersonalDataExport, error) {
+	export := &models.PersonalDataExport{
+		UserID:               userID,
+		GeneratedAt:          time.Now(),
+		Aplikacije:           []models.Aplikacija{},
+		PrihvaceneAplikacije: []models.PrihvacenaAplikacija{},
+		Payments:             []models.Payment{},
+		Notifications:        []models.Notification{},
+		Attachments:          []models.Attachment{},
+	}
+
+	if err := findAll(ctx, s.aplikacije, bson.M{"user_id": userID}, &export.Aplikacije); err != nil {
+		return nil, err
+	}
+
+	prihvaceneAplikacije, err := s.prihvacenaAplikacijaService.GetPrihvaceneAplikacijeByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	export.PrihvaceneAplikacije = append(export.PrihvaceneAplikacije, prihvaceneAplikacije...)
+
+	if err := findAll(ctx, s.notifications, bson.M{"user_id": userID}, &export.Notifications); err != nil {
+		return nil, err
+	}
+
+	payments, err := s.paymentService.GetPaymentsByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	export.Payments = append(export.Payments, payments...)
+
+	for _, aplikacija := range export.Aplikacije {
+		attachments, err := s.attachmentService.GetAttachmentsByOwner(ctx, models.AttachmentOwnerAplikacija, aplikacija.ID)
+		if err != nil {
+			return nil, err
+		}
+		export.Attachments = append(export.Attachments, attachments...)
+	}
+
+	return export, nil
+}
+
+// ErasePersonalData anonymizes the user's applications and deletes notifications and application attachments
+// All applications get the same new pseudonymous user ID, so statistics per student still add up
+// The faculty, study program, year of study and gender copied from the SSO account are removed,
+// because together with the grade average, room and date they would still single out the student
+// Applications with payment records keep the index number until the legal retention period ends,
+// after which PurgeExpiredRetention removes it
+// Erasure is refused while the user has a room or unpaid payments
+func (s *PersonalDataService) ErasePersonalData(ctx context.Context, userID primitive.ObjectID) (*models.ErasureResult, error) {
+	hasRoom, err := s.prihvacenaAplikacijaService.CheckUserHasActiveRoom(userID)
+	if err != nil {
+		return nil, err
+	}
+	if hasRoom {
+		return nil, ErrErasureActiveRoom
+	}
+
+	var aplikacije []models.Aplikacija
+	if err := findAll(ctx, s.aplikacije, bson.M{"user_id": userID}, &aplikacije); err != nil {
+		return nil, err
+	}
+
+	aplikacijaIDs := make([]primitive.ObjectID, 0, len(aplikacije))
+	for _, aplikacija := range aplikacije {
+		aplikacijaIDs = append(aplikacijaIDs, aplikacija.ID)
+	}
+
+	var payments []models.Payment
+	if err := findAll(ctx, s.payments, bson.M{"aplikacija_id": bson.M{"$in": aplikacijaIDs}}, &payments); err != nil {
+		return nil, err
+	}
+
+	// The latest payment date of each application determines how long its index number is retained
+	lastPayment := make(map[primitive.ObjectID]time.Time)
+	for _, payment := range payments {
+		if payment.Status != models.PaymentStatusPaid {
+			return nil, ErrErasureOutstandingPayments
+		}
+		paidAt := payment.DueDate
+		if payment.PaidAt != nil && payment.PaidAt.After(paidAt) {
+			paidAt = *payment.PaidAt
+		}
+		if paidAt.After(lastPayment[payment.AplikacijaID]) {
+			lastPayment[payment.AplikacijaID] = paidAt
+		}
+	}
+
+	result := &models.ErasureResult{UserID: userID}
+	now := time.Now()
+	pseudonym := primitive.NewObjectID()
+
+	for _, aplikacija := range aplikacije {
+		set := bson.M{
+			"user_id":       pseudonym,
+			"anonymized_at": now,
+			"updated_at":    now,
+		}
+
+		if paidAt, exists := lastPayment[aplikacija.ID]; exists && paidAt.AddDate(s.config.PaymentRetentionYears, 0, 0).After(now) {
+			retainUntil := paidAt.AddDate(s.config.PaymentRetentionYears, 0, 0)
+			set["retain_until"] = retainUntil
+			result.RetainedAplikacije++
+			if result.RetainedUntil == nil || retainUntil.After(*result.RetainedUntil) {
+				result.RetainedUntil = &retainUntil
+			}
+		} else {
+			set["broj_indexa"] = ""
+		}
+
+		if _, err := s.aplikacije.UpdateOne(ctx, bson.M{"_id": aplikacija.ID}, bson.M{"$set": set, "$unset": erasedProfileFields}); err != nil {
+			return nil, err
+		}
+		result.AnonymizedAplikacije++
+
+		attachments, err := s.attachmentService.GetAttachmentsByOwner(ctx, models.AttachmentOwnerAplikacija, aplikacija.ID)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.attachmentService.DeleteAttachmentsByOwner(ctx, models.AttachmentOwnerAplikacija, aplikacija.ID); err != nil {
+			return nil, err
+		}
+		result.DeletedAttachments += len(attachments)
+	}
+
+	deleted, err := s.notifications.DeleteMany(ctx, bson.M{"user_id": userID})
+	if err != nil {
+		return nil, err
+	}
+	result.DeletedNotifications = deleted.DeletedCount
+
+	return result, nil
+}
+
+// erasedProfileFields are the student profile fields removed from an application when personal data is erased
+var erasedProfileFields = bson.M{"fakultet": "", "studijski_program": "", "godina_studija": "", "pol": ""}
+
+// EraseLegacyProfileFields removes the student profile fields from applications anonymized before they were erased
+// Returns the number of applications updated
+func (s *PersonalDataService) EraseLegacyProfileFields(ctx context.Context) (int64, error) {
+	filter := bson.M{
+		"anonymized_at": bson.M{"$exists": true},
+		"$or": bson.A{
+			bson.M{"fakultet": bson.M{"$exists": true}},
+			bson.M{"studijski_program": bson.M{"$exists": true}},
+			bson.M{"godina_studija": bson.M{"$exists": true}},
+			bson.M{"pol": bson.M{"$exists": true}},
+		},
+	}
+
+	result, err := s.aplikacije.UpdateMany(ctx, filter, bson.M{"$unset": erasedProfileFields})
+	if err != nil {
+		return 0, err
+	}
+
+	return result.ModifiedCount, nil
+}
+
+// PurgeExpiredRetention removes index numbers kept on anonymized applications whose retention period has ended
+// Returns the number of applications purged
+func (s *PersonalDataService) PurgeExpiredRetention(ctx context.Context) (int64, error) {
+	now := time.Now()
+
+	result, err := s.aplikacije.UpdateMany(ctx,
+		bson.M{"retain_until": bson.M{"$lte": now}},
+		bson.M{
+			"$set":   bson.M{"broj_indexa": "", "updated_at": now},
+			"$unset": bson.M{"retain_until": ""},
+		},
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.ModifiedCount, nil
+}
+
+// StartRetentionPurge runs PurgeExpiredRetention in the background on the configured period
+func (s *PersonalDataService) StartRetentionPurge(cfg config.PrivacyConfig) {
+	run := func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 1*time.Minute)
+		defer cancel()
+
+		purged, err := s.PurgeExpiredRetention(ctx)
+		if err != nil {
+			log.Println("Retention purge failed:", err)
+			return
+		}
+		if purged > 0 {
+			log.Printf("Retention purge removed index numbers from %d applications", purged)
+		}
+	}
+
+	go func() {
+		run()
+
+		ticker := time.NewTicker(cfg.PurgePeriod)
+		defer ticker.Stop()
+		for range ticker.C {
+			run()
+		}
+	}()
+}
+
+// findAll decodes every document matching the filter into results
+func findAll(ctx context.Context, collection *mongo.Collection, filter interface{}, results interface{}) error {
+	cursor, err := collection.Find(ctx, filter)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	return cursor.All(ctx, results)
+}
@@ -3,6 +3,7 @@ package services
 import (
 	"context"
 	"errors"
+	"log"
 	"st_dom_service/config"
 	"st_dom_service/models"
 	"time"
@@ -18,14 +19,16 @@ type PrihvacenaAplikacijaService struct {
 	collection          *mongo.Collection
 	aplikacijaService   *AplikacijaService
 	paymentService      *PaymentService
+	inspectionService   *InspectionService
 }
 
 // NewPrihvacenaAplikacijaService creates a new PrihvacenaAplikacijaService
-func NewPrihvacenaAplikacijaService(collection *mongo.Collection, aplikacijaService *AplikacijaService, paymentService *PaymentService) *PrihvacenaAplikacijaService {
+func NewPrihvacenaAplikacijaService(collection *mongo.Collection, aplikacijaService *AplikacijaService, paymentService *PaymentService, inspectionService *InspectionService) *PrihvacenaAplikacijaService {
 	return &PrihvacenaAplikacijaService{
 		collection:        collection,
 		aplikacijaService: aplikacijaService,
 		paymentService:    paymentService,
+		inspectionService: inspectionService,
 	}
 }
 
@@ -320,6 +323,40 @@ func (s *PrihvacenaAplikacijaService) GetTopStudentsByProsekForRoom(sobaID primi
 	return topStudents, nil
 }
 
+// GetTopStudentsByProsekForSobas retrieves top N students living in the given rooms.
+// Used for dorm-scoped administrators; an empty academicYear means all years.
+func (s *PrihvacenaAplikacijaService) GetTopStudentsByProsekForSobas(sobaIDs []primitive.ObjectID, academicYear string, limit int) ([]models.PrihvacenaAplikacija, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// Set default limit if not specified
+	if limit <= 0 {
+		limit = 10
+	}
+
+	filter := bson.M{"soba_id": bson.M{"$in": sobaIDs}}
+	if academicYear != "" {
+		filter["academic_year"] = academicYear
+	}
+
+	findOptions := options.Find().
+		SetSort(bson.D{{Key: "prosek", Value: -1}}).
+		SetLimit(int64(limit))
+
+	cursor, err := s.collection.Find(ctx, filter, findOptions)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	topStudents := []models.PrihvacenaAplikacija{}
+	if err = cursor.All(ctx, &topStudents); err != nil {
+		return nil, err
+	}
+
+	return topStudents, nil
+}
+
 // VoidAllOtherUserApplications marks all other active applications from a user as inactive
 // This is called when one of their applications gets accepted
 func (s *PrihvacenaAplikacijaService) VoidAllOtherUserApplications(userID primitive.ObjectID, approvedAplikacijaID primitive.ObjectID) error {
@@ -395,6 +432,11 @@ func (s *PrihvacenaAplikacijaService) EvictStudent(userID primitive.ObjectID, re
 		return errors.New("failed to evict student")
 	}
 
+	// An eviction vacates the room just like a checkout, so the room is inspected as well
+	if _, err := s.inspectionService.CreateCheckoutInspections(ctx, prihvacenaAplikacija.SobaID); err != nil {
+		log.Println("Failed to create checkout inspection:", err)
+	}
+
 	return nil
 }
 
@@ -423,6 +465,12 @@ func (s *PrihvacenaAplikacijaService) CheckoutStudent(userID primitive.ObjectID)
 		return errors.New("failed to checkout from room")
 	}
 
+	// Schedule the checkout room inspection
+	// Don't fail the checkout - the inspection can be created manually later
+	if _, err := s.inspectionService.CreateCheckoutInspections(ctx, prihvacenaAplikacija.SobaID); err != nil {
+		log.Println("Failed to create checkout inspection:", err)
+	}
+
 	return nil
 }
 
@@ -3,6 +3,7 @@ package services
 import (
 	"context"
 	"fmt"
+	"log"
 	"st_dom_service/models"
 	"time"
 
@@ -12,12 +13,14 @@ import (
 )
 
 type RepairService struct {
-	collection *mongo.Collection
+	collection          *mongo.Collection
+	notificationService *NotificationService
 }
 
-func NewRepairService(db *mongo.Database) *RepairService {
+func NewRepairService(db *mongo.Database, notificationService *NotificationService) *RepairService {
 	return &RepairService{
-		collection: db.Collection("repairs"),
+		collection:          db.Collection("repairs"),
+		notificationService: notificationService,
 	}
 }
 
@@ -39,6 +42,10 @@ func (s *RepairService) CreateRepair(ctx context.Context, sobaID primitive.Objec
 		return nil, err
 	}
 
+	s.notifyResidents(ctx, repair, models.NotificationRepairScheduled,
+		"Repair scheduled in your room",
+		fmt.Sprintf("A repair has been scheduled in your room: %s. Estimated completion: %s.", repair.Description, repair.EstimatedCompletionDate.Format("02.01.2006. 15:04")))
+
 	return repair, nil
 }
 
@@ -105,7 +112,13 @@ func (s *RepairService) GetRepairsByStatus(ctx context.Context, status string) (
 }
 
 // UpdateRepair updates a repair
+// Residents of the room are notified when the repair is rescheduled or completed
 func (s *RepairService) UpdateRepair(ctx context.Context, id primitive.ObjectID, description string, estimatedCompletionDate *time.Time, status string) (*models.Repair, error) {
+	current, err := s.GetRepairByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
 	update := bson.M{
 		"$set": bson.M{
 			"updated_at": time.Now(),
@@ -122,12 +135,35 @@ func (s *RepairService) UpdateRepair(ctx context.Context, id primitive.ObjectID,
 		update["$set"].(bson.M)["status"] = status
 	}
 
-	_, err := s.collection.UpdateOne(ctx, bson.M{"_id": id}, update)
+	_, err = s.collection.UpdateOne(ctx, bson.M{"_id": id}, update)
 	if err != nil {
 		return nil, err
 	}
 
-	return s.GetRepairByID(ctx, id)
+	repair, err := s.GetRepairByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if repair.Status == "completed" && current.Status != "completed" {
+		s.notifyResidents(ctx, repair, models.NotificationRepairCompleted,
+			"Repair completed in your room",
+			fmt.Sprintf("The repair in your room has been completed: %s.", repair.Description))
+	} else if !repair.EstimatedCompletionDate.Equal(current.EstimatedCompletionDate) && repair.Status != "cancelled" {
+		s.notifyResidents(ctx, repair, models.NotificationRepairRescheduled,
+			"Repair rescheduled in your room",
+			fmt.Sprintf("The repair in your room (%s) has been rescheduled. New estimated completion: %s.", repair.Description, repair.EstimatedCompletionDate.Format("02.01.2006. 15:04")))
+	}
+
+	return repair, nil
+}
+
+// notifyResidents notifies the current residents of the repaired room
+// Notification failures are logged and never fail the repair operation
+func (s *RepairService) notifyResidents(ctx context.Context, repair *models.Repair, notificationType models.NotificationType, title, message string) {
+	if _, err := s.notificationService.NotifyRoomResidents(ctx, repair, notificationType, title, message); err != nil {
+		log.Println("Failed to notify room residents about repair:", err)
+	}
 }
 
 // DeleteRepair deletes a repair
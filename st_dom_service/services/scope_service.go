@@ -0,0 +1,104 @@
+package services
+
+import (
+	"context"
+	"st_dom_service/models"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ScopeService resolves which student dormitory a resource belongs to,
+// so dorm-scoped administrators can be limited to their own buildings
+type ScopeService struct {
+	sobas      *mongo.Collection
+	aplikacije *mongo.Collection
+}
+
+// NewScopeService creates a new scope service
+func NewScopeService(db *mongo.Database) *ScopeService {
+	return &ScopeService{
+		sobas:      db.Collection("sobas"),
+		aplikacije: db.Collection("aplikacije"),
+	}
+}
+
+// CanManageSoba reports whether the scope covers the dormitory the room belongs to
+func (s *ScopeService) CanManageSoba(scope models.AdminScope, sobaID primitive.ObjectID) (bool, error) {
+	if scope.Global {
+		return true, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var soba models.Soba
+	err := s.sobas.FindOne(ctx, bson.M{"_id": sobaID}, options.FindOne().SetProjection(bson.M{"st_dom_id": 1})).Decode(&soba)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return scope.Allows(soba.StDomID), nil
+}
+
+// SobaIDsInScope returns the IDs of all rooms in the scoped dormitories.
+// Returns nil for global administrators, meaning no filtering is needed.
+func (s *ScopeService) SobaIDsInScope(scope models.AdminScope) (map[primitive.ObjectID]bool, error) {
+	if scope.Global {
+		return nil, nil
+	}
+	if len(scope.StDomIDs) == 0 {
+		return map[primitive.ObjectID]bool{}, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	return s.distinctIDs(ctx, s.sobas, "_id", bson.M{"st_dom_id": bson.M{"$in": scope.StDomIDs}})
+}
+
+// AplikacijaIDsInScope returns the IDs of all applications for rooms in the scoped dormitories.
+// Payments reference applications, so this is used to filter payment lists.
+// Returns nil for global administrators, meaning no filtering is needed.
+func (s *ScopeService) AplikacijaIDsInScope(scope models.AdminScope) (map[primitive.ObjectID]bool, error) {
+	sobaIDs, err := s.SobaIDsInScope(scope)
+	if err != nil || sobaIDs == nil {
+		return nil, err
+	}
+	if len(sobaIDs) == 0 {
+		return map[primitive.ObjectID]bool{}, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	ids := make([]primitive.ObjectID, 0, len(sobaIDs))
+	for id := range sobaIDs {
+		ids = append(ids, id)
+	}
+
+	return s.distinctIDs(ctx, s.aplikacije, "_id", bson.M{"soba_id": bson.M{"$in": ids}})
+}
+
+// distinctIDs collects distinct ObjectID values of a field into a set
+func (s *ScopeService) distinctIDs(ctx context.Context, collection *mongo.Collection, field string, filter bson.M) (map[primitive.ObjectID]bool, error) {
+	values, err := collection.Distinct(ctx, field, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make(map[primitive.ObjectID]bool, len(values))
+	for _, value := range values {
+		if id, ok := value.(primitive.ObjectID); ok {
+			ids[id] = true
+		}
+	}
+
+	return ids, nil
+}
@@ -11,21 +11,35 @@ import (
 	"go.mongodb.org/mongo-driver/mongo"
 )
 
+// greska kada je poslata samo jedna od koordinata doma
+var ErrIncompleteCoordinates = errors.New("latitude and longitude must be set together")
+
+// greska kada se u istom zahtevu koordinate postavljaju i brisu
+var ErrConflictingCoordinates = errors.New("clear_coordinates cannot be combined with latitude and longitude")
+
 // StDomService - rukuje operacijama vezanim za studentske domove
 type StDomService struct {
 	collection *mongo.Collection
+	gazetteer  *Gazetteer
 }
 
 // kreira novi StDomService sa kolekcijom baze podataka
-func NewStDomService(collection *mongo.Collection) *StDomService {
+// gazetteer popunjava koordinate doma na osnovu adrese, moze biti nil
+func NewStDomService(collection *mongo.Collection, gazetteer *Gazetteer) *StDomService {
 	return &StDomService{
 		collection: collection,
+		gazetteer:  gazetteer,
 	}
 }
 
 // kreira novi studentski dom - proverava da li vec postoji dom sa istom adresom
 // cuva novi dom u bazu ako adresa nije zauzeta
+// ako koordinate nisu poslate, trazi adresu u gazetteer-u
 func (s *StDomService) CreateStDom(req models.CreateStDomRequest) (*models.StDom, error) {
+	if (req.Latitude == nil) != (req.Longitude == nil) {
+		return nil, ErrIncompleteCoordinates
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 	var existingStDom models.StDom
@@ -38,6 +52,13 @@ func (s *StDomService) CreateStDom(req models.CreateStDomRequest) (*models.StDom
 	}
 
 	stDom := models.NewStDom(req)
+	if stDom.Latitude == nil {
+		if coordinates, found := s.gazetteer.Lookup(req.Address); found {
+			stDom.Latitude = &coordinates.Latitude
+			stDom.Longitude = &coordinates.Longitude
+		}
+	}
+
 	result, err := s.collection.InsertOne(ctx, stDom)
 	if err != nil {
 		return nil, err
@@ -85,7 +106,16 @@ func (s *StDomService) GetAllStDoms() ([]models.StDom, error) {
 
 // azurira podatke o studentskom domu - prima ID i nove podatke
 // proverava da li nova adresa vec postoji pre azuriranja
+// kada se menja adresa bez koordinata, nove koordinate se traze u gazetteer-u
+// ako adresa nije u gazetteer-u, stare koordinate se brisu da dom ne bi ostao na mapi na staroj adresi
 func (s *StDomService) UpdateStDom(id primitive.ObjectID, req models.UpdateStDomRequest) (*models.StDom, error) {
+	if (req.Latitude == nil) != (req.Longitude == nil) {
+		return nil, ErrIncompleteCoordinates
+	}
+	if req.ClearCoordinates && req.Latitude != nil {
+		return nil, ErrConflictingCoordinates
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 	update := bson.M{"$set": bson.M{"updated_at": time.Now()}}
@@ -101,6 +131,25 @@ func (s *StDomService) UpdateStDom(id primitive.ObjectID, req models.UpdateStDom
 	if req.Email != nil {
 		update["$set"].(bson.M)["email"] = *req.Email
 	}
+	if req.Latitude != nil {
+		update["$set"].(bson.M)["latitude"] = *req.Latitude
+		update["$set"].(bson.M)["longitude"] = *req.Longitude
+	} else if req.ClearCoordinates {
+		update["$unset"] = bson.M{"latitude": "", "longitude": ""}
+	} else if req.Address != nil {
+		if coordinates, found := s.gazetteer.Lookup(*req.Address); found {
+			update["$set"].(bson.M)["latitude"] = coordinates.Latitude
+			update["$set"].(bson.M)["longitude"] = coordinates.Longitude
+		} else {
+			current, err := s.GetStDomByID(id)
+			if err != nil {
+				return nil, err
+			}
+			if current.Address != *req.Address {
+				update["$unset"] = bson.M{"latitude": "", "longitude": ""}
+			}
+		}
+	}
 
 	if req.Address != nil {
 		var existingStDom models.StDom
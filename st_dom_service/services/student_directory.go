@@ -0,0 +1,80 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"st_dom_service/models"
+	"st_dom_service/utils"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Errors returned when a student's identity cannot be used for an application
+var (
+	ErrStudentProfileMissing    = errors.New("student profile is missing, fill in your index number and faculty on your profile first")
+	ErrStudentProfileUnverified = errors.New("student profile has not been verified by an administrator yet")
+)
+
+// StudentDirectory looks up student identity data (index number, faculty...) in sso_service
+type StudentDirectory struct {
+	ssoServiceURL string
+	tokens        *utils.ServiceTokenSource
+	httpClient    *http.Client
+}
+
+// NewStudentDirectory creates a new StudentDirectory for the given SSO service
+// tokens authenticates this service on the SSO internal routes
+func NewStudentDirectory(ssoServiceURL string, tokens *utils.ServiceTokenSource) *StudentDirectory {
+	return &StudentDirectory{
+		ssoServiceURL: ssoServiceURL,
+		tokens:        tokens,
+		httpClient:    &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// GetVerifiedStudent returns the student identity of a user, failing if it is missing or unverified
+func (d *StudentDirectory) GetVerifiedStudent(userID primitive.ObjectID) (*models.StudentIdentity, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	url := fmt.Sprintf("%s/api/v1/internal/users/%s/student", d.ssoServiceURL, userID.Hex())
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := d.tokens.Authorize(req); err != nil {
+		return nil, err
+	}
+
+	resp, err := d.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrStudentProfileMissing
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.New("failed to get student profile")
+	}
+
+	var identity models.StudentIdentity
+	if err := json.NewDecoder(resp.Body).Decode(&identity); err != nil {
+		return nil, err
+	}
+
+	if identity.Student.IndexNumber == "" {
+		return nil, ErrStudentProfileMissing
+	}
+	if !identity.Student.Verified {
+		return nil, ErrStudentProfileUnverified
+	}
+
+	return &identity, nil
+}
@@ -0,0 +1,21 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+// ErrBlobNotFound - vraca se kada blob ne postoji u skladistu
+var ErrBlobNotFound = errors.New("blob not found")
+
+// BlobStore - apstrakcija za cuvanje binarnih fajlova (prilozi uz popravke i aplikacije)
+// podrazumevana implementacija je LocalBlobStore, a druge (npr. S3) mogu da se dodaju bez izmena servisa
+type BlobStore interface {
+	// cuva sadrzaj pod datim kljucem i vraca broj zapisanih bajtova
+	Put(ctx context.Context, key string, content io.Reader) (int64, error)
+	// otvara sadrzaj sacuvan pod datim kljucem
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// brise sadrzaj sacuvan pod datim kljucem
+	Delete(ctx context.Context, key string) error
+}
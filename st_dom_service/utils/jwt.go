@@ -2,7 +2,6 @@ package utils
 
 import (
 	"errors"
-	"time"
 
 	"github.com/golang-jwt/jwt/v4"
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -10,47 +9,71 @@ import (
 
 // JWTClaims represents the JWT claims
 type JWTClaims struct {
-	UserID   primitive.ObjectID `json:"user_id"`
-	Username string             `json:"username"`
-	Email    string             `json:"email"`
-	Role     string             `json:"role"`
+	UserID     primitive.ObjectID `json:"user_id"`
+	Username   string             `json:"username"`
+	Email      string             `json:"email"`
+	Role       string             `json:"role"`
+	StDomIDs   []string           `json:"st_dom_ids,omitempty"`  // dorms a scoped admin manages
+	SuperAdmin bool               `json:"super_admin,omitempty"` // global admin with access to every dorm
 	jwt.RegisteredClaims
 }
 
-// GenerateJWT generates a JWT token for a user
-func GenerateJWT(userID primitive.ObjectID, username, email, role, secret string) (string, error) {
-	claims := JWTClaims{
-		UserID:   userID,
-		Username: username,
-		Email:    email,
-		Role:     role,
-		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(24 * time.Hour)),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			NotBefore: jwt.NewNumericDate(time.Now()),
-		},
+// ValidateJWT validates a JWT token against the sso_service public keys and returns the claims.
+// Tokens are only issued by sso_service; this service can verify them but not mint them.
+// Service tokens carry no user and are rejected here.
+func ValidateJWT(tokenString string, keys *JWKSCache) (*JWTClaims, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &JWTClaims{}, keyFunc(keys))
+	if err != nil {
+		return nil, err
+	}
+
+	if claims, ok := token.Claims.(*JWTClaims); ok && token.Valid && !claims.UserID.IsZero() {
+		return claims, nil
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(secret))
+	return nil, errors.New("invalid token")
 }
 
-// ValidateJWT validates a JWT token and returns the claims
-func ValidateJWT(tokenString, secret string) (*JWTClaims, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &JWTClaims{}, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, errors.New("unexpected signing method")
-		}
-		return []byte(secret), nil
-	})
+// Service token markers set by sso_service: typ separates them from user tokens, aud limits them to internal routes
+const (
+	ServiceTokenType     = "service"
+	ServiceTokenAudience = "internal"
+)
+
+// ServiceClaims represents the claims of a service-to-service (client credentials) token.
+// The subject is the calling service's client ID.
+type ServiceClaims struct {
+	TokenType string `json:"typ"`
+	jwt.RegisteredClaims
+}
 
+// ValidateServiceJWT validates a service token issued by sso_service and returns its claims
+func ValidateServiceJWT(tokenString string, keys *JWKSCache) (*ServiceClaims, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &ServiceClaims{}, keyFunc(keys))
 	if err != nil {
 		return nil, err
 	}
 
-	if claims, ok := token.Claims.(*JWTClaims); ok && token.Valid {
-		return claims, nil
+	claims, ok := token.Claims.(*ServiceClaims)
+	if !ok || !token.Valid || claims.TokenType != ServiceTokenType || claims.Subject == "" ||
+		!claims.VerifyAudience(ServiceTokenAudience, true) {
+		return nil, errors.New("invalid service token")
 	}
 
-	return nil, errors.New("invalid token")
+	return claims, nil
+}
+
+// keyFunc selects the public key by the kid header and checks the signing algorithm
+func keyFunc(keys *JWKSCache) jwt.Keyfunc {
+	return func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		publicKey, algorithm, err := keys.PublicKey(kid)
+		if err != nil {
+			return nil, err
+		}
+		if token.Method.Alg() != algorithm {
+			return nil, errors.New("unexpected signing method")
+		}
+		return publicKey, nil
+	}
 }
@@ -0,0 +1,92 @@
+package utils
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// koliko pre isteka se servisni token obnavlja
+const serviceTokenRenewBefore = 30 * time.Second
+
+// ServiceTokenSource - pribavlja servisni token od SSO servisa (client credentials) i kesira ga do isteka
+// token se salje uz svaki poziv internih ruta drugih servisa
+type ServiceTokenSource struct {
+	tokenURL     string
+	clientID     string
+	clientSecret string
+	httpClient   *http.Client
+	mu           sync.Mutex
+	token        string
+	expiresAt    time.Time
+}
+
+// kreira novi izvor servisnih tokena za prosledjeni SSO servis i kredencijale ovog servisa
+func NewServiceTokenSource(ssoServiceURL, clientID, clientSecret string) *ServiceTokenSource {
+	return &ServiceTokenSource{
+		tokenURL:     ssoServiceURL + "/api/v1/oauth/token",
+		clientID:     clientID,
+		clientSecret: clientSecret,
+		httpClient:   &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// vraca vazeci servisni token - novi se preuzima samo kada kesirani uskoro istice
+func (s *ServiceTokenSource) Token(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token != "" && time.Now().Add(serviceTokenRenewBefore).Before(s.expiresAt) {
+		return s.token, nil
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "client_credentials")
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth(s.clientID, s.clientSecret)
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", errors.New("failed to obtain service token")
+	}
+
+	var result struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int64  `json:"expires_in"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", err
+	}
+
+	s.token = result.AccessToken
+	s.expiresAt = time.Now().Add(time.Duration(result.ExpiresIn) * time.Second)
+
+	return s.token, nil
+}
+
+// postavlja servisni token u Authorization zaglavlje zahteva
+func (s *ServiceTokenSource) Authorize(req *http.Request) error {
+	token, err := s.Token(req.Context())
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Authorization", "Bearer "+token)
+	return nil
+}
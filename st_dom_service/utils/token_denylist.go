@@ -0,0 +1,105 @@
+package utils
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// TokenDenylist - lokalna kopija liste opozvanih access tokena (jti) iz SSO servisa
+// lista se periodicno osvezava, pa se opozvan token odbija najkasnije posle jednog intervala osvezavanja
+type TokenDenylist struct {
+	ssoServiceURL string
+	tokens        *ServiceTokenSource
+	httpClient    *http.Client
+	mu            sync.RWMutex
+	revoked       map[string]time.Time
+}
+
+// kreira novu praznu listu opozvanih tokena za prosledjeni SSO servis
+// lista se preuzima sa interne rute, pa je potreban servisni token
+func NewTokenDenylist(ssoServiceURL string, tokens *ServiceTokenSource) *TokenDenylist {
+	return &TokenDenylist{
+		ssoServiceURL: ssoServiceURL,
+		tokens:        tokens,
+		httpClient:    &http.Client{Timeout: 10 * time.Second},
+		revoked:       make(map[string]time.Time),
+	}
+}
+
+// pokrece periodicno osvezavanje liste u pozadini
+// ako SSO servis nije dostupan zadrzava se poslednja preuzeta lista
+func (d *TokenDenylist) Start(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			if err := d.Refresh(context.Background()); err != nil {
+				log.Println("Failed to refresh token denylist:", err)
+			}
+			<-ticker.C
+		}
+	}()
+}
+
+// preuzima aktuelnu listu opozvanih tokena od SSO servisa
+func (d *TokenDenylist) Refresh(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, d.ssoServiceURL+"/api/v1/internal/revoked-tokens", nil)
+	if err != nil {
+		return err
+	}
+
+	if err := d.tokens.Authorize(req); err != nil {
+		return err
+	}
+
+	resp, err := d.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return errors.New("failed to fetch revoked tokens")
+	}
+
+	var result struct {
+		RevokedTokens []struct {
+			JTI       string    `json:"jti"`
+			ExpiresAt time.Time `json:"expires_at"`
+		} `json:"revoked_tokens"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return err
+	}
+
+	revoked := make(map[string]time.Time, len(result.RevokedTokens))
+	for _, token := range result.RevokedTokens {
+		revoked[token.JTI] = token.ExpiresAt
+	}
+
+	d.mu.Lock()
+	d.revoked = revoked
+	d.mu.Unlock()
+
+	return nil
+}
+
+// proverava da li je token sa datim jti opozvan
+func (d *TokenDenylist) IsRevoked(jti string) bool {
+	if jti == "" {
+		return false
+	}
+
+	d.mu.RLock()
+	expiresAt, exists := d.revoked[jti]
+	d.mu.RUnlock()
+
+	return exists && time.Now().Before(expiresAt)
+}